@@ -0,0 +1,199 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// signatureHeader carries the hex HMAC-SHA256 of a request's signed
+// payload (see signedPayload), keyed with Security.AdminSecret. A "sha256="
+// prefix is accepted.
+const signatureHeader = "X-Signature"
+
+// signatureTimestampHeader carries the Unix time, in seconds, the request
+// was signed at. It is part of the signed payload, and requests signed more
+// than maxSignatureSkew away from the server's clock are refused, so a
+// captured signature can't be replayed later.
+const signatureTimestampHeader = "X-Signature-Timestamp"
+
+// maxSignatureSkew is how far a signature's timestamp may be from now.
+const maxSignatureSkew = 5 * time.Minute
+
+// maxSignedBodyBytes bounds the body read to check a signature.
+const maxSignedBodyBytes = 1 << 20
+
+// adminEndpointsEnabled reports whether the mutating endpoints have a
+// credential to check, Security.AdminSecret or Security.AdminToken. Without
+// one they aren't registered at all.
+func (s *APIServer) adminEndpointsEnabled() bool {
+	return s.config.Security.AdminSecret != "" || s.config.Security.AdminToken != ""
+}
+
+// signedPayload is what X-Signature is the HMAC of: the timestamp, method,
+// escaped path, raw query and body, each but the body followed by a
+// newline. Every admin endpoint takes its parameters from the query, so
+// signing the body alone would let one signature stand for any parameters.
+func signedPayload(timestamp string, r *http.Request, body []byte) []byte {
+	var b bytes.Buffer
+	for _, part := range []string{timestamp, r.Method, r.URL.EscapedPath(), r.URL.RawQuery} {
+		b.WriteString(part)
+		b.WriteByte('\n')
+	}
+	b.Write(body)
+	return b.Bytes()
+}
+
+// requireSignature wraps a mutating endpoint so that, with
+// Security.AdminSecret set, only requests whose X-Signature matches the
+// HMAC-SHA256 of their signed payload, and whose X-Signature-Timestamp is
+// within maxSignatureSkew of now, reach it; others get 401. The body is
+// restored for next. Without a secret it passes requests through to
+// requireAdmin's bearer token check.
+func (s *APIServer) requireSignature(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		secret := s.config.Security.AdminSecret
+		if secret == "" {
+			next(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxSignedBodyBytes))
+		if err != nil {
+			http.Error(w, "Request body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		timestamp := r.Header.Get(signatureTimestampHeader)
+		signedAt, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		if skew := time.Since(time.Unix(signedAt, 0)); skew > maxSignatureSkew || skew < -maxSignatureSkew {
+			http.Error(w, "Unauthorized: signature timestamp outside the allowed clock skew", http.StatusUnauthorized)
+			return
+		}
+
+		given, err := hex.DecodeString(strings.TrimPrefix(r.Header.Get(signatureHeader), "sha256="))
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(signedPayload(timestamp, r, body))
+		if err != nil || !hmac.Equal(given, mac.Sum(nil)) {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		next(w, r)
+	}
+}
+
+// requireAdmin checks the request's bearer token against Security.AdminToken
+// and writes the error response if it doesn't match. With only
+// Security.AdminSecret configured, requireSignature has already vouched for
+// the request; with neither, the admin endpoints are disabled outright
+// rather than left open.
+func (s *APIServer) requireAdmin(w http.ResponseWriter, r *http.Request) bool {
+	token := s.config.Security.AdminToken
+	if token == "" {
+		if s.config.Security.AdminSecret != "" {
+			return true
+		}
+		http.Error(w, "Admin API disabled", http.StatusForbidden)
+		return false
+	}
+
+	given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return false
+	}
+	return true
+}
+
+// parsePurgeCutoff turns an older_than value into the publish-date cutoff it
+// names. It accepts a Go duration ("720h"), a whole number of days ("30d"),
+// an RFC 3339 timestamp or a plain date ("2024-01-31", midnight UTC). The
+// cutoff must be in the past.
+func parsePurgeCutoff(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, fmt.Errorf("older_than is required")
+	}
+
+	var cutoff time.Time
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return time.Time{}, fmt.Errorf("invalid day count %q", value)
+		}
+		cutoff = now.AddDate(0, 0, -n)
+	} else if d, err := time.ParseDuration(value); err == nil {
+		if d <= 0 {
+			return time.Time{}, fmt.Errorf("duration must be positive, got %q", value)
+		}
+		cutoff = now.Add(-d)
+	} else if t, err := time.Parse(time.RFC3339, value); err == nil {
+		cutoff = t
+	} else if t, err := time.Parse("2006-01-02", value); err == nil {
+		cutoff = t
+	} else {
+		return time.Time{}, fmt.Errorf("older_than must be a duration, day count or ISO date, got %q", value)
+	}
+
+	if !cutoff.Before(now) {
+		return time.Time{}, fmt.Errorf("cutoff %s is not in the past", cutoff.Format(time.RFC3339))
+	}
+	return cutoff.UTC(), nil
+}
+
+// PurgeResponse is the body returned by /admin/purge.
+type PurgeResponse struct {
+	Cutoff  time.Time `json:"cutoff"`
+	Deleted int64     `json:"deleted"`
+	*PurgeResult
+}
+
+// purgeArticles deletes articles published before ?older_than=, along with
+// their webhook and summary logs, and returns how many rows went.
+func (s *APIServer) purgeArticles(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !s.requireAdmin(w, r) {
+		return
+	}
+
+	cutoff, err := parsePurgeCutoff(r.URL.Query().Get("older_than"), time.Now())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	result, err := NewDatabaseOperations(s.db).DeleteArticlesOlderThan(cutoff)
+	if err != nil {
+		slog.Error("Failed to purge old articles", "cutoff", cutoff.Format(time.RFC3339), "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	if s.monitor != nil {
+		s.monitor.ForgetArticles(result.URLs, cutoff)
+	}
+	slog.Info("Purged old articles", "cutoff", cutoff.Format(time.RFC3339), "articles", result.Articles,
+		"webhook_logs", result.WebhookLogs, "summary_logs", result.SummaryLogs)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(PurgeResponse{Cutoff: cutoff, Deleted: result.Articles, PurgeResult: result})
+}
@@ -0,0 +1,186 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"information-broker/config"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParsePurgeCutoff(t *testing.T) {
+	now := time.Date(2024, 3, 31, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{"720h", now.Add(-720 * time.Hour), false},
+		{"30d", time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC), false},
+		{"2024-01-31", time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC), false},
+		{"2024-01-31T08:00:00+02:00", time.Date(2024, 1, 31, 6, 0, 0, 0, time.UTC), false},
+		{"", time.Time{}, true},
+		{"0d", time.Time{}, true},
+		{"-5h", time.Time{}, true},
+		{"xd", time.Time{}, true},
+		{"2025-01-01", time.Time{}, true}, // In the future
+		{"last week", time.Time{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parsePurgeCutoff(tt.value, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parsePurgeCutoff(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("parsePurgeCutoff(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPurgeArticlesRequiresAdmin(t *testing.T) {
+	tests := []struct {
+		name   string
+		token  string
+		header string
+		want   int
+	}{
+		{"disabled without a configured token", "", "Bearer anything", http.StatusForbidden},
+		{"missing header", "s3cret", "", http.StatusUnauthorized},
+		{"wrong token", "s3cret", "Bearer nope", http.StatusUnauthorized},
+		{"wrong scheme", "s3cret", "Basic s3cret", http.StatusUnauthorized},
+		// Authorized requests get as far as validating older_than
+		{"valid token", "s3cret", "Bearer s3cret", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &APIServer{config: &config.Config{Security: config.SecurityConfig{AdminToken: tt.token}}}
+			req := httptest.NewRequest(http.MethodDelete, "/admin/purge", nil)
+			if tt.header != "" {
+				req.Header.Set("Authorization", tt.header)
+			}
+			rec := httptest.NewRecorder()
+			s.purgeArticles(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
+
+// signRequest returns the X-Signature of a request signed with key "k3y".
+func signRequest(timestamp, method, target, body string) string {
+	mac := hmac.New(sha256.New, []byte("k3y"))
+	mac.Write(signedPayload(timestamp, httptest.NewRequest(method, target, nil), []byte(body)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func TestRequireSignature(t *testing.T) {
+	now := strconv.FormatInt(time.Now().Unix(), 10)
+	stale := strconv.FormatInt(time.Now().Add(-maxSignatureSkew-time.Minute).Unix(), 10)
+	const target = "/summarization/retry?id=42"
+	sign := func(body string) string { return signRequest(now, http.MethodPost, target, body) }
+	tests := []struct {
+		name      string
+		secret    string
+		target    string
+		body      string
+		timestamp string
+		signature string
+		want      int
+	}{
+		{"valid signature", "k3y", target, `{"reason":"cleanup"}`, now, sign(`{"reason":"cleanup"}`), http.StatusOK},
+		{"sha256= prefix", "k3y", target, `{"reason":"cleanup"}`, now, "sha256=" + sign(`{"reason":"cleanup"}`), http.StatusOK},
+		{"empty body", "k3y", target, "", now, sign(""), http.StatusOK},
+		{"missing signature", "k3y", target, `{"reason":"cleanup"}`, now, "", http.StatusUnauthorized},
+		{"signature of another body", "k3y", target, `{"reason":"other"}`, now, sign(`{"reason":"cleanup"}`), http.StatusUnauthorized},
+		{"signature of another query", "k3y", "/summarization/retry?all_failed=true", "", now, sign(""), http.StatusUnauthorized},
+		{"signature of another path", "k3y", "/admin/purge?id=42", "", now, sign(""), http.StatusUnauthorized},
+		{"missing timestamp", "k3y", target, "", "", sign(""), http.StatusUnauthorized},
+		{"timestamp not signed", "k3y", target, "", strconv.FormatInt(time.Now().Unix()+1, 10), sign(""), http.StatusUnauthorized},
+		{"stale timestamp", "k3y", target, "", stale, signRequest(stale, http.MethodPost, target, ""), http.StatusUnauthorized},
+		{"not hex", "k3y", target, "", now, "zz", http.StatusUnauthorized},
+		{"no secret passes through", "", target, "", "", "", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &APIServer{config: &config.Config{Security: config.SecurityConfig{AdminSecret: tt.secret}}}
+			var got string
+			handler := s.requireSignature(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				got = string(body)
+			})
+			req := httptest.NewRequest(http.MethodPost, tt.target, strings.NewReader(tt.body))
+			if tt.timestamp != "" {
+				req.Header.Set(signatureTimestampHeader, tt.timestamp)
+			}
+			if tt.signature != "" {
+				req.Header.Set(signatureHeader, tt.signature)
+			}
+			rec := httptest.NewRecorder()
+			handler(rec, req)
+			if rec.Code != tt.want {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.want)
+			}
+			if tt.want == http.StatusOK && got != tt.body {
+				t.Errorf("handler read body %q, want %q", got, tt.body)
+			}
+		})
+	}
+}
+
+func TestAdminEndpointsRegistration(t *testing.T) {
+	tests := []struct {
+		name     string
+		security config.SecurityConfig
+		want     int
+	}{
+		{"not registered without a credential", config.SecurityConfig{}, http.StatusNotFound},
+		{"signed with the secret", config.SecurityConfig{AdminSecret: "k3y"}, http.StatusBadRequest},
+		{"bearer token alone", config.SecurityConfig{AdminToken: "s3cret"}, http.StatusBadRequest},
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	signature := signRequest(timestamp, http.MethodDelete, "/admin/purge", "")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &APIServer{metrics: testMetrics(), config: &config.Config{Security: tt.security, Prometheus: config.PrometheusConfig{MetricsPath: "/metrics"}}}
+			req := httptest.NewRequest(http.MethodDelete, "/admin/purge", nil)
+			req.Header.Set(signatureTimestampHeader, timestamp)
+			req.Header.Set(signatureHeader, signature)
+			req.Header.Set("Authorization", "Bearer s3cret")
+			rec := httptest.NewRecorder()
+			// Authorized requests get as far as validating older_than
+			s.routes().ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d", rec.Code, tt.want)
+			}
+		})
+	}
+}
+
+func TestForgetArticles(t *testing.T) {
+	m := &RSSMonitor{seenArticles: map[string]bool{"https://a": true, "https://b": true}}
+	cutoff := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	m.ForgetArticles([]string{"https://a"}, cutoff)
+	if m.seenArticles["https://a"] || !m.seenArticles["https://b"] {
+		t.Errorf("seenArticles = %v, want only https://b left", m.seenArticles)
+	}
+	if !m.purgedBefore.Equal(cutoff) {
+		t.Errorf("purgedBefore = %v, want %v", m.purgedBefore, cutoff)
+	}
+
+	// An older purge must not lower the floor
+	m.ForgetArticles(nil, cutoff.AddDate(-1, 0, 0))
+	if !m.purgedBefore.Equal(cutoff) {
+		t.Errorf("purgedBefore moved back to %v", m.purgedBefore)
+	}
+}
@@ -7,9 +7,13 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"math"
 	"net/http"
 	"os"
 	"os/signal"
+	"sort"
+	"strconv"
+	"strings"
 	"syscall"
 	"time"
 
@@ -36,8 +40,35 @@ type Config struct {
 			URL     string `yaml:"url"`
 			Enabled bool   `yaml:"enabled"`
 		} `yaml:"slack"`
+
+		// Generic POSTs the Alert itself as JSON, for receivers that do
+		// their own formatting. Headers are sent as-is (e.g. an auth token).
+		Generic struct {
+			URL         string            `yaml:"url"`
+			Enabled     bool              `yaml:"enabled"`
+			ContentType string            `yaml:"content_type"`
+			Headers     map[string]string `yaml:"headers"`
+		} `yaml:"generic"`
 	} `yaml:"webhooks"`
 
+	// StaleTimeout auto-resolves a firing alert whose series has been missing
+	// from query results for this long (e.g. the scrape target disappeared).
+	StaleTimeout string `yaml:"stale_timeout"`
+
+	// NonFinitePolicy decides what a NaN or ±Inf sample does: "skip" ignores
+	// it, "treat_as_zero" compares it as 0, "alert" fires the rule.
+	NonFinitePolicy string `yaml:"non_finite_policy"`
+
+	// GroupBy batches the notifications of one evaluation cycle that share
+	// this label's value (e.g. "severity") into a single message. Empty
+	// sends one notification per alert.
+	GroupBy string `yaml:"group_by"`
+
+	// DedupWindow suppresses the notifications of an alert that fires again
+	// within this long of its last firing notification, and of its
+	// resolution, so a flapping series doesn't spam the webhooks.
+	DedupWindow string `yaml:"dedup_window"`
+
 	Rules []AlertRule `yaml:"rules"`
 }
 
@@ -47,29 +78,53 @@ type AlertRule struct {
 	Query       string            `yaml:"query"`
 	Threshold   float64           `yaml:"threshold"`
 	Operator    string            `yaml:"operator"` // gt, lt, eq, ne
-	Duration    string            `yaml:"duration"`
+	Duration    string            `yaml:"duration"` // How long the condition must hold before the alert fires
 	Severity    string            `yaml:"severity"`
 	Description string            `yaml:"description"`
 	Labels      map[string]string `yaml:"labels"`
+
+	forDuration time.Duration // Duration, parsed by loadConfig
 }
 
 // Alert represents an active alert
 type Alert struct {
-	Name        string            `json:"name"`
-	Status      string            `json:"status"`
-	Severity    string            `json:"severity"`
-	Description string            `json:"description"`
-	Value       float64           `json:"value"`
-	Threshold   float64           `json:"threshold"`
-	Labels      map[string]string `json:"labels"`
-	StartsAt    time.Time         `json:"starts_at"`
-	EndsAt      *time.Time        `json:"ends_at,omitempty"`
+	Name        string  `json:"name"`
+	Status      string  `json:"status"`
+	Severity    string  `json:"severity"`
+	Description string  `json:"description"`
+	Value       float64 `json:"value"`
+	// NonFiniteValue holds the raw sample ("NaN", "+Inf", "-Inf") when the
+	// alert fired under the "alert" policy; Value is 0 then, as JSON can't
+	// encode non-finite floats.
+	NonFiniteValue string            `json:"non_finite_value,omitempty"`
+	Threshold      float64           `json:"threshold"`
+	Labels         map[string]string `json:"labels"`
+	StartsAt       time.Time         `json:"starts_at"`
+	EndsAt         *time.Time        `json:"ends_at,omitempty"`
+	LastSeenAt     time.Time         `json:"last_seen_at"`
+	// ResolvedReason is "stale" when the alert was resolved because its
+	// series stopped appearing, rather than because the value recovered.
+	ResolvedReason string `json:"resolved_reason,omitempty"`
+
+	// suppressed is set when the firing notification fell inside the dedup
+	// window; the resolution is then not notified either.
+	suppressed bool
+}
+
+// AlertGroup is the alerts of one evaluation cycle sharing the value of the
+// GroupBy label, sent as one notification.
+type AlertGroup struct {
+	Label  string   `json:"label"`
+	Value  string   `json:"value"`
+	Alerts []*Alert `json:"alerts"`
 }
 
 // PrometheusResponse represents Prometheus query response
 type PrometheusResponse struct {
-	Status string `json:"status"`
-	Data   struct {
+	Status    string `json:"status"`
+	ErrorType string `json:"errorType"`
+	Error     string `json:"error"`
+	Data      struct {
 		ResultType string `json:"resultType"`
 		Result     []struct {
 			Metric map[string]string `json:"metric"`
@@ -81,8 +136,12 @@ type PrometheusResponse struct {
 // AlertManager manages alerting rules and notifications
 type AlertManager struct {
 	config       Config
-	activeAlerts map[string]*Alert
+	activeAlerts map[string]*Alert // Keyed by alertKey: rule name plus series labels
 	httpClient   *http.Client
+	staleTimeout time.Duration
+	dedupWindow  time.Duration
+	lastFired    map[string]time.Time // alertKey -> time of its last firing notification
+	pending      []*Alert             // Notifications of the current evaluation cycle, sent by flushNotifications
 }
 
 func main() {
@@ -104,17 +163,34 @@ func main() {
 		config.Webhooks.Slack.Enabled = true
 	}
 
+	if genericURL := os.Getenv("GENERIC_WEBHOOK_URL"); genericURL != "" {
+		config.Webhooks.Generic.URL = genericURL
+		config.Webhooks.Generic.Enabled = true
+	}
+
 	if promURL := os.Getenv("PROMETHEUS_URL"); promURL != "" {
 		config.Prometheus.URL = promURL
 	}
 
 	// Create alert manager
+	staleTimeout, err := time.ParseDuration(config.StaleTimeout)
+	if err != nil {
+		log.Fatalf("Invalid stale_timeout %q: %v", config.StaleTimeout, err)
+	}
+	dedupWindow, err := time.ParseDuration(config.DedupWindow)
+	if err != nil {
+		log.Fatalf("Invalid dedup_window %q: %v", config.DedupWindow, err)
+	}
+
 	am := &AlertManager{
 		config:       *config,
 		activeAlerts: make(map[string]*Alert),
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
+		staleTimeout: staleTimeout,
+		dedupWindow:  dedupWindow,
+		lastFired:    make(map[string]time.Time),
 	}
 
 	// Start HTTP server for health checks and status
@@ -153,6 +229,13 @@ func main() {
 	server.Shutdown(ctx)
 }
 
+// Policies for NaN/±Inf sample values (Config.NonFinitePolicy)
+const (
+	nonFiniteSkip        = "skip"
+	nonFiniteTreatAsZero = "treat_as_zero"
+	nonFiniteAlert       = "alert"
+)
+
 func loadConfig(filename string) (*Config, error) {
 	data, err := os.ReadFile(filename)
 	if err != nil {
@@ -171,6 +254,34 @@ func loadConfig(filename string) (*Config, error) {
 	if config.Prometheus.URL == "" {
 		config.Prometheus.URL = "http://prometheus:9090"
 	}
+	if config.Webhooks.Generic.ContentType == "" {
+		config.Webhooks.Generic.ContentType = "application/json"
+	}
+	if config.StaleTimeout == "" {
+		config.StaleTimeout = "10m"
+	}
+	if config.DedupWindow == "" {
+		config.DedupWindow = "0s"
+	}
+	for i := range config.Rules {
+		rule := &config.Rules[i]
+		if rule.Duration == "" {
+			continue
+		}
+		forDuration, err := time.ParseDuration(rule.Duration)
+		if err != nil || forDuration < 0 {
+			return nil, fmt.Errorf("invalid duration %q for rule %s", rule.Duration, rule.Name)
+		}
+		rule.forDuration = forDuration
+	}
+	switch config.NonFinitePolicy {
+	case "":
+		config.NonFinitePolicy = nonFiniteSkip
+	case nonFiniteSkip, nonFiniteTreatAsZero, nonFiniteAlert:
+	default:
+		return nil, fmt.Errorf("invalid non_finite_policy %q: want %s, %s or %s",
+			config.NonFinitePolicy, nonFiniteSkip, nonFiniteTreatAsZero, nonFiniteAlert)
+	}
 
 	return &config, nil
 }
@@ -187,31 +298,59 @@ func (am *AlertManager) evaluateRules(ctx context.Context) {
 			for _, rule := range am.config.Rules {
 				am.evaluateRule(rule)
 			}
+			am.flushNotifications(time.Now())
 		}
 	}
 }
 
+// evaluateRule queries Prometheus for the rule and applies the result. A
+// failed query leaves the rule's alerts as they are: an empty result would
+// resolve firing alerts as stale and restart pending ones.
 func (am *AlertManager) evaluateRule(rule AlertRule) {
-	// Query Prometheus
-	url := fmt.Sprintf("%s/api/v1/query?query=%s", am.config.Prometheus.URL, rule.Query)
-	resp, err := am.httpClient.Get(url)
+	promResp, err := am.queryPrometheus(rule.Query)
 	if err != nil {
 		log.Printf("Failed to query Prometheus for rule %s: %v", rule.Name, err)
 		return
 	}
+	am.applyRuleResult(rule, promResp, time.Now())
+}
+
+// queryPrometheus runs an instant query. Anything but a 200 with status
+// "success" is an error, with Prometheus's own message when it gave one.
+func (am *AlertManager) queryPrometheus(query string) (PrometheusResponse, error) {
+	var promResp PrometheusResponse
+	url := fmt.Sprintf("%s/api/v1/query?query=%s", am.config.Prometheus.URL, query)
+	resp, err := am.httpClient.Get(url)
+	if err != nil {
+		return promResp, err
+	}
 	defer resp.Body.Close()
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		log.Printf("Failed to read Prometheus response: %v", err)
-		return
+		return promResp, fmt.Errorf("read response: %w", err)
 	}
-
-	var promResp PrometheusResponse
-	if err := json.Unmarshal(body, &promResp); err != nil {
-		log.Printf("Failed to parse Prometheus response: %v", err)
-		return
+	// Error responses carry JSON too, so parse before judging the status
+	jsonErr := json.Unmarshal(body, &promResp)
+	if resp.StatusCode != http.StatusOK || promResp.Status != "success" {
+		if promResp.Error != "" {
+			return promResp, fmt.Errorf("HTTP %d: %s: %s", resp.StatusCode, promResp.ErrorType, promResp.Error)
+		}
+		return promResp, fmt.Errorf("HTTP %d, status %q", resp.StatusCode, promResp.Status)
+	}
+	if jsonErr != nil {
+		return promResp, fmt.Errorf("parse response: %w", jsonErr)
 	}
+	return promResp, nil
+}
+
+// applyRuleResult fires or resolves the rule's alerts from one query result,
+// one alert per series. A firing alert whose series is absent from the
+// result is left alone until it has been missing for longer than
+// staleTimeout, then resolved as stale; otherwise a vanished target would
+// leave it firing forever.
+func (am *AlertManager) applyRuleResult(rule AlertRule, promResp PrometheusResponse, now time.Time) {
+	seen := make(map[string]bool)
 
 	// Check if alert should fire
 	for _, result := range promResp.Data.Result {
@@ -224,125 +363,390 @@ func (am *AlertManager) evaluateRule(rule AlertRule) {
 			continue
 		}
 
-		var numValue float64
-		if _, err := fmt.Sscanf(value, "%f", &numValue); err != nil {
+		// ParseFloat understands Prometheus's "NaN", "+Inf" and "-Inf"
+		numValue, err := strconv.ParseFloat(value, 64)
+		if err != nil {
 			continue
 		}
 
-		shouldAlert := false
+		// NaN makes every comparison false, which would quietly resolve or
+		// suppress the alert, so non-finite values follow an explicit policy
+		forceAlert := false
+		nonFiniteValue := ""
+		if math.IsNaN(numValue) || math.IsInf(numValue, 0) {
+			log.Printf("Rule %s returned non-finite value %s, applying %q policy", rule.Name, value, am.config.NonFinitePolicy)
+			switch am.config.NonFinitePolicy {
+			case nonFiniteTreatAsZero:
+				numValue = 0
+			case nonFiniteAlert:
+				forceAlert = true
+				nonFiniteValue = value
+				numValue = 0
+			default:
+				continue
+			}
+		}
+		alertKey := alertKey(rule.Name, result.Metric)
+		seen[alertKey] = true
+
+		shouldAlert := forceAlert
 		switch rule.Operator {
 		case "gt":
-			shouldAlert = numValue > rule.Threshold
+			shouldAlert = shouldAlert || numValue > rule.Threshold
 		case "lt":
-			shouldAlert = numValue < rule.Threshold
+			shouldAlert = shouldAlert || numValue < rule.Threshold
 		case "eq":
-			shouldAlert = numValue == rule.Threshold
+			shouldAlert = shouldAlert || numValue == rule.Threshold
 		case "ne":
-			shouldAlert = numValue != rule.Threshold
+			shouldAlert = shouldAlert || numValue != rule.Threshold
 		}
 
-		alertKey := rule.Name
 		if shouldAlert {
-			if _, exists := am.activeAlerts[alertKey]; !exists {
-				// New alert
+			if alert, exists := am.activeAlerts[alertKey]; exists {
+				alert.Value = numValue
+				alert.NonFiniteValue = nonFiniteValue
+				alert.LastSeenAt = now
+				if alert.Status == "pending" && now.Sub(alert.StartsAt) >= rule.forDuration {
+					alert.Status = "firing"
+					am.fireAlert(alertKey, alert, now)
+					log.Printf("Alert fired: %s after %v pending (value: %f, threshold: %f)",
+						alertKey, now.Sub(alert.StartsAt), numValue, rule.Threshold)
+				}
+			} else {
+				// New alert, pending until the condition has held for the
+				// rule's duration
 				alert := &Alert{
-					Name:        rule.Name,
-					Status:      "firing",
-					Severity:    rule.Severity,
-					Description: rule.Description,
-					Value:       numValue,
-					Threshold:   rule.Threshold,
-					Labels:      rule.Labels,
-					StartsAt:    time.Now(),
+					Name:           rule.Name,
+					Status:         "pending",
+					Severity:       rule.Severity,
+					Description:    rule.Description,
+					Value:          numValue,
+					NonFiniteValue: nonFiniteValue,
+					Threshold:      rule.Threshold,
+					Labels:         alertLabels(result.Metric, rule.Labels),
+					StartsAt:       now,
+					LastSeenAt:     now,
 				}
 				am.activeAlerts[alertKey] = alert
-				am.sendAlert(alert)
-				log.Printf("Alert fired: %s (value: %f, threshold: %f)", rule.Name, numValue, rule.Threshold)
+				if rule.forDuration <= 0 {
+					alert.Status = "firing"
+					am.fireAlert(alertKey, alert, now)
+					log.Printf("Alert fired: %s (value: %f, threshold: %f)", alertKey, numValue, rule.Threshold)
+				} else {
+					log.Printf("Alert pending: %s (value: %f, threshold: %f, fires after %v)", alertKey, numValue, rule.Threshold, rule.forDuration)
+				}
 			}
 		} else {
 			if alert, exists := am.activeAlerts[alertKey]; exists {
+				if alert.Status == "pending" {
+					// Never fired, so there is nothing to resolve
+					delete(am.activeAlerts, alertKey)
+					log.Printf("Alert no longer pending: %s", alertKey)
+					continue
+				}
 				// Alert resolved
-				now := time.Now()
-				alert.EndsAt = &now
-				alert.Status = "resolved"
-				am.sendAlert(alert)
-				delete(am.activeAlerts, alertKey)
-				log.Printf("Alert resolved: %s", rule.Name)
+				am.resolveAlert(alertKey, alert, now, "")
+				log.Printf("Alert resolved: %s", alertKey)
 			}
 		}
 	}
+
+	for alertKey, alert := range am.activeAlerts {
+		if alert.Name != rule.Name || seen[alertKey] {
+			continue
+		}
+		// The condition wasn't observed to hold, so a pending alert starts over
+		if alert.Status == "pending" {
+			delete(am.activeAlerts, alertKey)
+			continue
+		}
+		if am.staleTimeout <= 0 {
+			continue
+		}
+		if missing := now.Sub(alert.LastSeenAt); missing > am.staleTimeout {
+			am.resolveAlert(alertKey, alert, now, "stale")
+			log.Printf("Alert resolved as stale: %s (series missing for %v)", alertKey, missing.Round(time.Second))
+		}
+	}
+}
+
+// alertKey identifies the alert of one series of a rule: the rule name, plus
+// the series labels in Prometheus notation when there are any.
+func alertKey(ruleName string, metric map[string]string) string {
+	if len(metric) == 0 {
+		return ruleName
+	}
+	names := make([]string, 0, len(metric))
+	for name := range metric {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = fmt.Sprintf("%s=%q", name, metric[name])
+	}
+	return ruleName + "{" + strings.Join(pairs, ",") + "}"
+}
+
+// alertLabels merges a series' labels with the rule's, the rule's winning
+// on conflict.
+func alertLabels(metric, ruleLabels map[string]string) map[string]string {
+	labels := make(map[string]string, len(metric)+len(ruleLabels))
+	for name, value := range metric {
+		labels[name] = value
+	}
+	for name, value := range ruleLabels {
+		labels[name] = value
+	}
+	return labels
+}
+
+// fireAlert queues the firing notification of a new alert, unless the same
+// alert already fired within the dedup window.
+func (am *AlertManager) fireAlert(alertKey string, alert *Alert, now time.Time) {
+	if last, ok := am.lastFired[alertKey]; ok && am.dedupWindow > 0 && now.Sub(last) < am.dedupWindow {
+		alert.suppressed = true
+		log.Printf("Alert %s fired again %v after its last notification, within the dedup window; not notifying",
+			alertKey, now.Sub(last).Round(time.Second))
+		return
+	}
+	am.lastFired[alertKey] = now
+	am.notify(alert)
+}
+
+// resolveAlert marks an active alert resolved, notifies, and forgets it.
+func (am *AlertManager) resolveAlert(alertKey string, alert *Alert, now time.Time, reason string) {
+	alert.EndsAt = &now
+	alert.Status = "resolved"
+	alert.ResolvedReason = reason
+	if !alert.suppressed {
+		am.notify(alert)
+	}
+	delete(am.activeAlerts, alertKey)
+}
+
+// notify queues a snapshot of the alert for the end of the evaluation cycle.
+func (am *AlertManager) notify(alert *Alert) {
+	snapshot := *alert
+	am.pending = append(am.pending, &snapshot)
+}
+
+// flushNotifications sends the notifications queued during an evaluation
+// cycle, batched by the GroupBy label if one is set, and forgets dedup
+// entries that have aged out of the window.
+func (am *AlertManager) flushNotifications(now time.Time) {
+	pending := am.pending
+	am.pending = nil
+
+	if am.config.GroupBy == "" {
+		for _, alert := range pending {
+			am.sendAlert(alert)
+		}
+	} else {
+		for _, group := range groupAlerts(pending, am.config.GroupBy) {
+			am.sendAlertGroup(group)
+		}
+	}
+
+	for alertKey, last := range am.lastFired {
+		if now.Sub(last) >= am.dedupWindow {
+			delete(am.lastFired, alertKey)
+		}
+	}
+}
+
+// groupAlerts splits alerts by their value of label, in order of each
+// group's first alert.
+func groupAlerts(alerts []*Alert, label string) []AlertGroup {
+	var groups []AlertGroup
+	index := make(map[string]int)
+	for _, alert := range alerts {
+		value := alert.labelValue(label)
+		i, ok := index[value]
+		if !ok {
+			i = len(groups)
+			index[value] = i
+			groups = append(groups, AlertGroup{Label: label, Value: value})
+		}
+		groups[i].Alerts = append(groups[i].Alerts, alert)
+	}
+	return groups
+}
+
+// labelValue returns the alert's value of a label, taking "severity" and
+// "alertname" from the alert itself when no label of that name is set.
+func (a *Alert) labelValue(label string) string {
+	if value, ok := a.Labels[label]; ok {
+		return value
+	}
+	switch label {
+	case "severity":
+		return a.Severity
+	case "alertname":
+		return a.Name
+	}
+	return ""
+}
+
+// displayValue formats the alert's value for notifications, showing the raw
+// NaN/Inf sample when that's what fired it.
+func (a *Alert) displayValue() string {
+	if a.NonFiniteValue != "" {
+		return a.NonFiniteValue
+	}
+	return fmt.Sprintf("%.2f", a.Value)
 }
 
 func (am *AlertManager) sendAlert(alert *Alert) {
 	if am.config.Webhooks.Discord.Enabled && am.config.Webhooks.Discord.URL != "" {
-		am.sendDiscordAlert(alert)
+		am.sendDiscordAlerts("", []*Alert{alert})
+	}
+
+	if am.config.Webhooks.Slack.Enabled && am.config.Webhooks.Slack.URL != "" {
+		am.sendSlackAlerts("", []*Alert{alert})
+	}
+
+	if am.config.Webhooks.Generic.Enabled && am.config.Webhooks.Generic.URL != "" {
+		am.sendGenericAlert(alert)
+	}
+}
+
+// sendAlertGroup sends a group of alerts as one notification per webhook.
+// The generic webhook receives the AlertGroup rather than a single Alert.
+func (am *AlertManager) sendAlertGroup(group AlertGroup) {
+	title := fmt.Sprintf("%d alert(s) with %s=%q", len(group.Alerts), group.Label, group.Value)
+
+	if am.config.Webhooks.Discord.Enabled && am.config.Webhooks.Discord.URL != "" {
+		am.sendDiscordAlerts(title, group.Alerts)
 	}
 
 	if am.config.Webhooks.Slack.Enabled && am.config.Webhooks.Slack.URL != "" {
-		am.sendSlackAlert(alert)
+		am.sendSlackAlerts(title, group.Alerts)
+	}
+
+	if am.config.Webhooks.Generic.Enabled && am.config.Webhooks.Generic.URL != "" {
+		generic := am.config.Webhooks.Generic
+		am.postWebhook(generic.URL, generic.ContentType, generic.Headers, group)
 	}
 }
 
-func (am *AlertManager) sendDiscordAlert(alert *Alert) {
+// maxDiscordEmbeds is the most embeds Discord accepts in one message.
+const maxDiscordEmbeds = 10
+
+// sendDiscordAlerts posts one embed per alert, with content as the message
+// text if set, split across messages as Discord's embed limit requires.
+func (am *AlertManager) sendDiscordAlerts(content string, alerts []*Alert) {
+	for start := 0; start < len(alerts); start += maxDiscordEmbeds {
+		end := min(start+maxDiscordEmbeds, len(alerts))
+		embeds := make([]map[string]interface{}, 0, end-start)
+		for _, alert := range alerts[start:end] {
+			embeds = append(embeds, discordEmbed(alert))
+		}
+
+		payload := map[string]interface{}{"embeds": embeds}
+		if content != "" {
+			payload["content"] = content
+		}
+		am.sendWebhook(am.config.Webhooks.Discord.URL, payload)
+	}
+}
+
+func discordEmbed(alert *Alert) map[string]interface{} {
 	color := 15158332 // Red for firing
 	if alert.Status == "resolved" {
 		color = 3066993 // Green for resolved
 	}
 
-	payload := map[string]interface{}{
-		"embeds": []map[string]interface{}{
-			{
-				"title":       fmt.Sprintf("Alert: %s", alert.Name),
-				"description": alert.Description,
-				"color":       color,
-				"fields": []map[string]interface{}{
-					{"name": "Status", "value": alert.Status, "inline": true},
-					{"name": "Severity", "value": alert.Severity, "inline": true},
-					{"name": "Value", "value": fmt.Sprintf("%.2f", alert.Value), "inline": true},
-					{"name": "Threshold", "value": fmt.Sprintf("%.2f", alert.Threshold), "inline": true},
-				},
-				"timestamp": alert.StartsAt.Format(time.RFC3339),
-			},
-		},
+	fields := []map[string]interface{}{
+		{"name": "Status", "value": alert.Status, "inline": true},
+		{"name": "Severity", "value": alert.Severity, "inline": true},
+		{"name": "Value", "value": alert.displayValue(), "inline": true},
+		{"name": "Threshold", "value": fmt.Sprintf("%.2f", alert.Threshold), "inline": true},
+	}
+	if alert.ResolvedReason != "" {
+		fields = append(fields, map[string]interface{}{"name": "Resolved Reason", "value": alert.ResolvedReason, "inline": true})
+	}
+
+	return map[string]interface{}{
+		"title":       fmt.Sprintf("Alert: %s", alert.Name),
+		"description": alert.Description,
+		"color":       color,
+		"fields":      fields,
+		"timestamp":   alert.StartsAt.Format(time.RFC3339),
+	}
+}
+
+// sendSlackAlerts posts one attachment per alert, with text as the message
+// text if set.
+func (am *AlertManager) sendSlackAlerts(text string, alerts []*Alert) {
+	attachments := make([]map[string]interface{}, 0, len(alerts))
+	for _, alert := range alerts {
+		attachments = append(attachments, slackAttachment(alert))
 	}
 
-	am.sendWebhook(am.config.Webhooks.Discord.URL, payload)
+	payload := map[string]interface{}{"attachments": attachments}
+	if text != "" {
+		payload["text"] = text
+	}
+	am.sendWebhook(am.config.Webhooks.Slack.URL, payload)
 }
 
-func (am *AlertManager) sendSlackAlert(alert *Alert) {
+func slackAttachment(alert *Alert) map[string]interface{} {
 	color := "danger"
 	if alert.Status == "resolved" {
 		color = "good"
 	}
 
-	payload := map[string]interface{}{
-		"attachments": []map[string]interface{}{
-			{
-				"title": fmt.Sprintf("Alert: %s", alert.Name),
-				"text":  alert.Description,
-				"color": color,
-				"fields": []map[string]interface{}{
-					{"title": "Status", "value": alert.Status, "short": true},
-					{"title": "Severity", "value": alert.Severity, "short": true},
-					{"title": "Value", "value": fmt.Sprintf("%.2f", alert.Value), "short": true},
-					{"title": "Threshold", "value": fmt.Sprintf("%.2f", alert.Threshold), "short": true},
-				},
-				"ts": alert.StartsAt.Unix(),
-			},
-		},
+	fields := []map[string]interface{}{
+		{"title": "Status", "value": alert.Status, "short": true},
+		{"title": "Severity", "value": alert.Severity, "short": true},
+		{"title": "Value", "value": alert.displayValue(), "short": true},
+		{"title": "Threshold", "value": fmt.Sprintf("%.2f", alert.Threshold), "short": true},
+	}
+	if alert.ResolvedReason != "" {
+		fields = append(fields, map[string]interface{}{"title": "Resolved Reason", "value": alert.ResolvedReason, "short": true})
 	}
 
-	am.sendWebhook(am.config.Webhooks.Slack.URL, payload)
+	return map[string]interface{}{
+		"title":  fmt.Sprintf("Alert: %s", alert.Name),
+		"text":   alert.Description,
+		"color":  color,
+		"fields": fields,
+		"ts":     alert.StartsAt.Unix(),
+	}
+}
+
+// sendGenericAlert POSTs the alert unformatted to the generic webhook.
+func (am *AlertManager) sendGenericAlert(alert *Alert) {
+	generic := am.config.Webhooks.Generic
+	am.postWebhook(generic.URL, generic.ContentType, generic.Headers, alert)
 }
 
 func (am *AlertManager) sendWebhook(url string, payload interface{}) {
+	am.postWebhook(url, "application/json", nil, payload)
+}
+
+// postWebhook marshals payload to JSON and POSTs it with the given
+// Content-Type and extra headers.
+func (am *AlertManager) postWebhook(url, contentType string, headers map[string]string, payload interface{}) {
 	data, err := json.Marshal(payload)
 	if err != nil {
 		log.Printf("Failed to marshal webhook payload: %v", err)
 		return
 	}
 
-	resp, err := am.httpClient.Post(url, "application/json", bytes.NewBuffer(data))
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		log.Printf("Failed to create webhook request: %v", err)
+		return
+	}
+	req.Header.Set("Content-Type", contentType)
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := am.httpClient.Do(req)
 	if err != nil {
 		log.Printf("Failed to send webhook: %v", err)
 		return
@@ -384,6 +788,7 @@ func (am *AlertManager) statusHandler(w http.ResponseWriter, r *http.Request) {
 		"webhooks": map[string]bool{
 			"discord": am.config.Webhooks.Discord.Enabled,
 			"slack":   am.config.Webhooks.Slack.Enabled,
+			"generic": am.config.Webhooks.Generic.Enabled,
 		},
 	})
 }
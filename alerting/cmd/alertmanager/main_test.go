@@ -0,0 +1,456 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+// promResult builds a Prometheus instant-query response with one series per value.
+func promResult(t *testing.T, values ...string) PrometheusResponse {
+	t.Helper()
+	var resp PrometheusResponse
+	body := `{"status":"success","data":{"resultType":"vector","result":[`
+	for i, v := range values {
+		if i > 0 {
+			body += ","
+		}
+		body += `{"metric":{},"value":[1700000000,"` + v + `"]}`
+	}
+	body += `]}}`
+	if err := json.Unmarshal([]byte(body), &resp); err != nil {
+		t.Fatalf("bad test fixture: %v", err)
+	}
+	return resp
+}
+
+func newTestAlertManager(staleTimeout time.Duration) *AlertManager {
+	return &AlertManager{
+		activeAlerts: make(map[string]*Alert),
+		staleTimeout: staleTimeout,
+		lastFired:    make(map[string]time.Time),
+	}
+}
+
+func TestApplyRuleResultStaleTimeout(t *testing.T) {
+	rule := AlertRule{Name: "circuit_breaker_open", Threshold: 0, Operator: "gt"}
+	start := time.Date(2026, 7, 15, 12, 0, 0, 0, time.UTC)
+
+	t.Run("disappearing series auto-resolves after the timeout", func(t *testing.T) {
+		am := newTestAlertManager(10 * time.Minute)
+
+		am.applyRuleResult(rule, promResult(t, "1"), start)
+		alert, ok := am.activeAlerts[rule.Name]
+		if !ok || alert.Status != "firing" {
+			t.Fatalf("expected firing alert, got %+v", alert)
+		}
+
+		// Series gone, but not yet for longer than the timeout
+		am.applyRuleResult(rule, promResult(t), start.Add(5*time.Minute))
+		if _, ok := am.activeAlerts[rule.Name]; !ok {
+			t.Fatal("alert resolved before the stale timeout elapsed")
+		}
+
+		am.applyRuleResult(rule, promResult(t), start.Add(11*time.Minute))
+		if _, ok := am.activeAlerts[rule.Name]; ok {
+			t.Fatal("alert still active after its series was missing past the stale timeout")
+		}
+		if alert.Status != "resolved" || alert.ResolvedReason != "stale" {
+			t.Errorf("got status %q reason %q, want resolved/stale", alert.Status, alert.ResolvedReason)
+		}
+		if alert.EndsAt == nil || !alert.EndsAt.Equal(start.Add(11*time.Minute)) {
+			t.Errorf("EndsAt = %v, want resolution time", alert.EndsAt)
+		}
+	})
+
+	t.Run("series that keeps reporting refreshes last-seen", func(t *testing.T) {
+		am := newTestAlertManager(10 * time.Minute)
+
+		am.applyRuleResult(rule, promResult(t, "1"), start)
+		am.applyRuleResult(rule, promResult(t, "2"), start.Add(9*time.Minute))
+		am.applyRuleResult(rule, promResult(t), start.Add(15*time.Minute))
+
+		alert, ok := am.activeAlerts[rule.Name]
+		if !ok {
+			t.Fatal("alert resolved as stale although its series was seen 6 minutes ago")
+		}
+		if alert.Value != 2 {
+			t.Errorf("value = %v, want latest value 2", alert.Value)
+		}
+	})
+
+	t.Run("recovered value resolves without a stale reason", func(t *testing.T) {
+		am := newTestAlertManager(10 * time.Minute)
+
+		am.applyRuleResult(rule, promResult(t, "1"), start)
+		alert := am.activeAlerts[rule.Name]
+		am.applyRuleResult(rule, promResult(t, "0"), start.Add(time.Minute))
+
+		if _, ok := am.activeAlerts[rule.Name]; ok {
+			t.Fatal("alert still active after value recovered")
+		}
+		if alert.ResolvedReason != "" {
+			t.Errorf("resolved reason = %q, want empty for a normal recovery", alert.ResolvedReason)
+		}
+	})
+
+	t.Run("zero timeout disables stale resolution", func(t *testing.T) {
+		am := newTestAlertManager(0)
+
+		am.applyRuleResult(rule, promResult(t, "1"), start)
+		am.applyRuleResult(rule, promResult(t), start.Add(24*time.Hour))
+		if _, ok := am.activeAlerts[rule.Name]; !ok {
+			t.Fatal("alert resolved with stale timeout disabled")
+		}
+	})
+}
+
+func TestApplyRuleResultNonFiniteValues(t *testing.T) {
+	rule := AlertRule{Name: "summary_api_failure_rate_high", Threshold: 0.1, Operator: "gt"}
+	now := time.Date(2026, 7, 15, 12, 0, 0, 0, time.UTC)
+
+	newAM := func(policy string) *AlertManager {
+		am := newTestAlertManager(10 * time.Minute)
+		am.config.NonFinitePolicy = policy
+		return am
+	}
+
+	for _, value := range []string{"NaN", "+Inf", "-Inf"} {
+		t.Run(value+"/skip ignores the sample", func(t *testing.T) {
+			am := newAM(nonFiniteSkip)
+			am.applyRuleResult(rule, promResult(t, value), now)
+			if len(am.activeAlerts) != 0 {
+				t.Errorf("skip policy fired an alert: %+v", am.activeAlerts)
+			}
+		})
+
+		t.Run(value+"/skip leaves a firing alert alone", func(t *testing.T) {
+			am := newAM(nonFiniteSkip)
+			am.applyRuleResult(rule, promResult(t, "0.5"), now)
+			am.applyRuleResult(rule, promResult(t, value), now.Add(time.Minute))
+			if _, ok := am.activeAlerts[rule.Name]; !ok {
+				t.Error("skipped non-finite sample resolved the alert")
+			}
+		})
+
+		t.Run(value+"/treat_as_zero compares as 0", func(t *testing.T) {
+			am := newAM(nonFiniteTreatAsZero)
+			am.applyRuleResult(rule, promResult(t, value), now)
+			if len(am.activeAlerts) != 0 {
+				t.Errorf("0 > 0.1 should not fire, got %+v", am.activeAlerts)
+			}
+
+			lt := AlertRule{Name: "queue_depth_low", Threshold: 1, Operator: "lt"}
+			am.applyRuleResult(lt, promResult(t, value), now)
+			if alert, ok := am.activeAlerts[lt.Name]; !ok || alert.Value != 0 {
+				t.Errorf("0 < 1 should fire with value 0, got %+v", alert)
+			}
+		})
+
+		t.Run(value+"/alert fires the rule", func(t *testing.T) {
+			am := newAM(nonFiniteAlert)
+			am.applyRuleResult(rule, promResult(t, value), now)
+			alert, ok := am.activeAlerts[rule.Name]
+			if !ok {
+				t.Fatal("alert policy did not fire")
+			}
+			if alert.NonFiniteValue != value || alert.displayValue() != value {
+				t.Errorf("non-finite value = %q (display %q), want %q", alert.NonFiniteValue, alert.displayValue(), value)
+			}
+			if _, err := json.Marshal(alert); err != nil {
+				t.Errorf("alert is not JSON-encodable: %v", err)
+			}
+		})
+	}
+}
+
+func TestLoadConfigNonFinitePolicy(t *testing.T) {
+	write := func(t *testing.T, body string) string {
+		t.Helper()
+		path := t.TempDir() + "/config.yaml"
+		if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	cfg, err := loadConfig(write(t, "rules: []\n"))
+	if err != nil {
+		t.Fatalf("loadConfig: %v", err)
+	}
+	if cfg.NonFinitePolicy != nonFiniteSkip {
+		t.Errorf("default policy = %q, want %q", cfg.NonFinitePolicy, nonFiniteSkip)
+	}
+
+	if _, err := loadConfig(write(t, "non_finite_policy: ignore\n")); err == nil {
+		t.Error("expected an error for an unknown policy")
+	}
+
+	cfg, err = loadConfig(write(t, "rules:\n  - name: slow\n    duration: 2m\n"))
+	if err != nil {
+		t.Fatalf("loadConfig: %v", err)
+	}
+	if cfg.Rules[0].forDuration != 2*time.Minute {
+		t.Errorf("rule duration = %v, want 2m", cfg.Rules[0].forDuration)
+	}
+	if _, err := loadConfig(write(t, "rules:\n  - name: slow\n    duration: soon\n")); err == nil {
+		t.Error("expected an error for an invalid rule duration")
+	}
+}
+
+func TestSendGenericAlert(t *testing.T) {
+	var gotContentType, gotAuth string
+	var got Alert
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotContentType = r.Header.Get("Content-Type")
+		gotAuth = r.Header.Get("Authorization")
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("payload is not an Alert: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	path := t.TempDir() + "/config.yaml"
+	body := "webhooks:\n  generic:\n    url: " + server.URL + "\n    enabled: true\n" +
+		"    content_type: application/vnd.alert+json\n    headers:\n      Authorization: Token s3cret\n"
+	if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := loadConfig(path)
+	if err != nil {
+		t.Fatalf("loadConfig: %v", err)
+	}
+
+	am := newTestAlertManager(0)
+	am.config = *cfg
+	am.httpClient = server.Client()
+	am.sendAlert(&Alert{Name: "feed_errors", Status: "firing", Severity: "warning", Value: 3, Threshold: 1})
+
+	if gotContentType != "application/vnd.alert+json" {
+		t.Errorf("Content-Type = %q, want the configured type", gotContentType)
+	}
+	if gotAuth != "Token s3cret" {
+		t.Errorf("Authorization = %q, want the configured header", gotAuth)
+	}
+	if got.Name != "feed_errors" || got.Status != "firing" || got.Value != 3 {
+		t.Errorf("received %+v, want the alert as-is", got)
+	}
+}
+
+// promSeriesResult builds a response with one series per host label.
+func promSeriesResult(t *testing.T, valuesByHost map[string]string) PrometheusResponse {
+	t.Helper()
+	var resp PrometheusResponse
+	for host, value := range valuesByHost {
+		series := promResult(t, value).Data.Result[0]
+		series.Metric = map[string]string{"host": host}
+		resp.Data.Result = append(resp.Data.Result, series)
+	}
+	return resp
+}
+
+// pendingStatuses lists the queued notifications as "host status".
+func pendingStatuses(am *AlertManager) []string {
+	var got []string
+	for _, alert := range am.pending {
+		got = append(got, alert.Labels["host"]+" "+alert.Status)
+	}
+	return got
+}
+
+func TestApplyRuleResultPerSeries(t *testing.T) {
+	rule := AlertRule{Name: "feed_errors", Threshold: 0, Operator: "gt", Labels: map[string]string{"team": "ops"}}
+	start := time.Date(2026, 7, 15, 12, 0, 0, 0, time.UTC)
+	am := newTestAlertManager(10 * time.Minute)
+
+	am.applyRuleResult(rule, promSeriesResult(t, map[string]string{"a": "1", "b": "0"}), start)
+	alertA, ok := am.activeAlerts[`feed_errors{host="a"}`]
+	if !ok || len(am.activeAlerts) != 1 {
+		t.Fatalf("want one alert for host a, got %v", am.activeAlerts)
+	}
+	if alertA.Labels["host"] != "a" || alertA.Labels["team"] != "ops" {
+		t.Errorf("labels = %v, want the series and rule labels", alertA.Labels)
+	}
+
+	// A second series firing doesn't touch the first
+	am.applyRuleResult(rule, promSeriesResult(t, map[string]string{"a": "2", "b": "1"}), start.Add(time.Minute))
+	if len(am.activeAlerts) != 2 {
+		t.Fatalf("want alerts for both hosts, got %v", am.activeAlerts)
+	}
+	if got := pendingStatuses(am); len(got) != 2 || got[0] != "a firing" || got[1] != "b firing" {
+		t.Errorf("notifications = %v, want one firing per host", got)
+	}
+
+	// Host a's series disappears; only its alert goes stale
+	am.applyRuleResult(rule, promSeriesResult(t, map[string]string{"b": "1"}), start.Add(12*time.Minute))
+	if _, ok := am.activeAlerts[`feed_errors{host="a"}`]; ok {
+		t.Error("host a's alert still active after its series went stale")
+	}
+	if _, ok := am.activeAlerts[`feed_errors{host="b"}`]; !ok {
+		t.Error("host b's alert resolved although its series is still reported")
+	}
+}
+
+func TestDedupWindow(t *testing.T) {
+	rule := AlertRule{Name: "feed_errors", Threshold: 0, Operator: "gt"}
+	start := time.Date(2026, 7, 15, 12, 0, 0, 0, time.UTC)
+	am := newTestAlertManager(0)
+	am.dedupWindow = 10 * time.Minute
+
+	flap := func(at time.Duration) {
+		am.applyRuleResult(rule, promSeriesResult(t, map[string]string{"a": "1"}), start.Add(at))
+		am.applyRuleResult(rule, promSeriesResult(t, map[string]string{"a": "0"}), start.Add(at+time.Minute))
+	}
+
+	flap(0)
+	if got := pendingStatuses(am); len(got) != 2 || got[0] != "a firing" || got[1] != "a resolved" {
+		t.Fatalf("first flap notified %v, want firing then resolved", got)
+	}
+	am.flushNotifications(start.Add(time.Minute))
+
+	flap(5 * time.Minute)
+	if got := pendingStatuses(am); len(got) != 0 {
+		t.Errorf("re-fire within the dedup window notified %v", got)
+	}
+	am.flushNotifications(start.Add(6 * time.Minute))
+
+	flap(20 * time.Minute)
+	if got := pendingStatuses(am); len(got) != 2 {
+		t.Errorf("re-fire after the dedup window notified %v, want firing and resolved", got)
+	}
+}
+
+func TestFlushNotificationsGroupBy(t *testing.T) {
+	var attachments []int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload struct {
+			Text        string            `json:"text"`
+			Attachments []json.RawMessage `json:"attachments"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("bad payload: %v", err)
+		}
+		if payload.Text == "" {
+			t.Error("grouped message has no text")
+		}
+		attachments = append(attachments, len(payload.Attachments))
+	}))
+	defer server.Close()
+
+	am := newTestAlertManager(0)
+	am.httpClient = server.Client()
+	am.config.GroupBy = "severity"
+	am.config.Webhooks.Slack.URL = server.URL
+	am.config.Webhooks.Slack.Enabled = true
+
+	now := time.Date(2026, 7, 15, 12, 0, 0, 0, time.UTC)
+	for i, severity := range []string{"critical", "warning", "critical"} {
+		am.fireAlert(string(rune('a'+i)), &Alert{Name: "rule", Status: "firing", Severity: severity}, now)
+	}
+	am.flushNotifications(now)
+
+	if len(attachments) != 2 || attachments[0] != 2 || attachments[1] != 1 {
+		t.Errorf("got messages with %v attachments, want [2 1]: critical then warning", attachments)
+	}
+	if len(am.pending) != 0 {
+		t.Errorf("%d notifications still pending after the flush", len(am.pending))
+	}
+}
+
+func TestApplyRuleResultForDuration(t *testing.T) {
+	rule := AlertRule{Name: "feed_errors", Threshold: 0, Operator: "gt", forDuration: 2 * time.Minute}
+	start := time.Date(2026, 7, 15, 12, 0, 0, 0, time.UTC)
+	key := `feed_errors{host="a"}`
+	breach := func(am *AlertManager, value string, at time.Duration) {
+		am.applyRuleResult(rule, promSeriesResult(t, map[string]string{"a": value}), start.Add(at))
+	}
+
+	t.Run("fires once the condition has held for the duration", func(t *testing.T) {
+		am := newTestAlertManager(10 * time.Minute)
+
+		breach(am, "1", 0)
+		breach(am, "1", time.Minute)
+		if alert := am.activeAlerts[key]; alert == nil || alert.Status != "pending" {
+			t.Fatalf("want a pending alert, got %+v", alert)
+		}
+		if len(am.pending) != 0 {
+			t.Fatalf("pending alert was notified: %v", pendingStatuses(am))
+		}
+
+		breach(am, "1", 2*time.Minute)
+		alert := am.activeAlerts[key]
+		if alert == nil || alert.Status != "firing" || !alert.StartsAt.Equal(start) {
+			t.Fatalf("want a firing alert starting at the first breach, got %+v", alert)
+		}
+		if got := pendingStatuses(am); len(got) != 1 || got[0] != "a firing" {
+			t.Errorf("notifications = %v, want one firing", got)
+		}
+	})
+
+	t.Run("clearing while pending is silent", func(t *testing.T) {
+		am := newTestAlertManager(10 * time.Minute)
+
+		breach(am, "1", 0)
+		breach(am, "0", time.Minute)
+		breach(am, "1", 2*time.Minute)
+		alert := am.activeAlerts[key]
+		if alert == nil || alert.Status != "pending" || !alert.StartsAt.Equal(start.Add(2*time.Minute)) {
+			t.Fatalf("want a new pending alert from the second breach, got %+v", alert)
+		}
+		if len(am.pending) != 0 {
+			t.Errorf("notifications = %v, want none", pendingStatuses(am))
+		}
+	})
+
+	t.Run("missing series resets a pending alert", func(t *testing.T) {
+		am := newTestAlertManager(10 * time.Minute)
+
+		breach(am, "1", 0)
+		am.applyRuleResult(rule, promSeriesResult(t, nil), start.Add(time.Minute))
+		if len(am.activeAlerts) != 0 || len(am.pending) != 0 {
+			t.Errorf("got alerts %v and notifications %v, want neither", am.activeAlerts, pendingStatuses(am))
+		}
+	})
+}
+
+func TestEvaluateRuleIgnoresFailedQueries(t *testing.T) {
+	var status int
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	rule := AlertRule{Name: "queue_full", Query: "queue_depth", Threshold: 0, Operator: "gt"}
+	am := newTestAlertManager(time.Nanosecond)
+	am.config.Prometheus.URL = server.URL
+	am.httpClient = server.Client()
+	am.applyRuleResult(rule, promResult(t, "1"), time.Now().Add(-time.Hour))
+
+	for _, c := range []struct {
+		name   string
+		status int
+		body   string
+	}{
+		{"server error", http.StatusServiceUnavailable, `{"status":"error","errorType":"unavailable","error":"overloaded"}`},
+		{"query timeout", http.StatusOK, `{"status":"error","errorType":"timeout","error":"query timed out"}`},
+		{"not JSON", http.StatusBadGateway, `<html>Bad Gateway</html>`},
+	} {
+		status, body = c.status, c.body
+		am.evaluateRule(rule)
+		if alert, ok := am.activeAlerts[rule.Name]; !ok || alert.Status != "firing" {
+			t.Errorf("%s: alert = %+v, want it left firing", c.name, alert)
+		}
+	}
+
+	// A successful empty result still resolves it
+	status, body = http.StatusOK, `{"status":"success","data":{"resultType":"vector","result":[]}}`
+	am.evaluateRule(rule)
+	if _, ok := am.activeAlerts[rule.Name]; ok {
+		t.Error("alert still active after a successful query without its series")
+	}
+}
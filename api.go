@@ -2,15 +2,20 @@ package main
 
 import (
 	"database/sql"
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"information-broker/config"
 	"log"
+	"log/slog"
 	"net/http"
+	"runtime"
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/lib/pq"
 )
 
 // APIServer provides HTTP endpoints for accessing article data
@@ -21,10 +26,11 @@ type APIServer struct {
 	config          *config.Config
 	circuitBreakers *CircuitBreakerManager
 	scheduler       *SummarizationScheduler
+	monitor         *RSSMonitor
 }
 
 // NewAPIServer creates a new API server instance
-func NewAPIServer(db *sql.DB, port int, metrics *PrometheusMetrics, cfg *config.Config, circuitBreakers *CircuitBreakerManager, scheduler *SummarizationScheduler) *APIServer {
+func NewAPIServer(db *sql.DB, port int, metrics *PrometheusMetrics, cfg *config.Config, circuitBreakers *CircuitBreakerManager, scheduler *SummarizationScheduler, monitor *RSSMonitor) *APIServer {
 	return &APIServer{
 		db:              db,
 		port:            port,
@@ -32,11 +38,32 @@ func NewAPIServer(db *sql.DB, port int, metrics *PrometheusMetrics, cfg *config.
 		config:          cfg,
 		circuitBreakers: circuitBreakers,
 		scheduler:       scheduler,
+		monitor:         monitor,
 	}
 }
 
 // Start starts the HTTP server
 func (s *APIServer) Start() {
+	addr := fmt.Sprintf(":%d", s.port)
+	log.Printf("Starting API server on %s", addr)
+
+	server := &http.Server{
+		Addr:         addr,
+		Handler:      s.routes(),
+		ReadTimeout:  s.config.Performance.HTTPReadTimeout,
+		WriteTimeout: s.config.Performance.HTTPWriteTimeout,
+		IdleTimeout:  s.config.Performance.HTTPIdleTimeout,
+	}
+
+	if err := server.ListenAndServe(); err != nil {
+		log.Fatalf("API server failed: %v", err)
+	}
+}
+
+// routes returns the API's handler. The mutating admin endpoints are only
+// registered while an admin credential is configured (see
+// adminEndpointsEnabled), so they are never served open.
+func (s *APIServer) routes() *http.ServeMux {
 	mux := http.NewServeMux()
 
 	// Add CORS middleware
@@ -59,29 +86,38 @@ func (s *APIServer) Start() {
 	mux.HandleFunc("/articles", corsHandler(s.metrics.HTTPMetricsMiddleware(s.getArticles, "/articles")))
 	mux.HandleFunc("/articles/latest", corsHandler(s.metrics.HTTPMetricsMiddleware(s.getLatestArticles, "/articles/latest")))
 	mux.HandleFunc("/articles/get", corsHandler(s.metrics.HTTPMetricsMiddleware(s.getArticleByID, "/articles/get")))
+	mux.HandleFunc("/articles/raw", corsHandler(s.metrics.HTTPMetricsMiddleware(s.getArticleRawItem, "/articles/raw")))
+	mux.HandleFunc("/articles/", corsHandler(s.metrics.HTTPMetricsMiddleware(s.getArticleDetail, "/articles/{id}")))
 	mux.HandleFunc("/articles/digest", corsHandler(s.metrics.HTTPMetricsMiddleware(s.getArticlesDigest, "/articles/digest")))
+	mux.HandleFunc("/search", corsHandler(s.metrics.HTTPMetricsMiddleware(s.searchArticles, "/search")))
 	mux.HandleFunc("/feeds", corsHandler(s.metrics.HTTPMetricsMiddleware(s.getFeeds, "/feeds")))
+	mux.HandleFunc("/feeds/export", corsHandler(s.metrics.HTTPMetricsMiddleware(s.exportFeeds, "/feeds/export")))
+	mux.HandleFunc("/feeds/duplicates", corsHandler(s.metrics.HTTPMetricsMiddleware(s.getFeedDuplicates, "/feeds/duplicates")))
+	mux.HandleFunc("/feeds/stale", corsHandler(s.metrics.HTTPMetricsMiddleware(s.getStaleFeeds, "/feeds/stale")))
+	mux.HandleFunc("/feeds/sla", corsHandler(s.metrics.HTTPMetricsMiddleware(s.getFeedSLAs, "/feeds/sla")))
+	mux.HandleFunc("/feeds/", corsHandler(s.metrics.HTTPMetricsMiddleware(s.getFeedDrops, "/feeds/{host}/drops")))
+	mux.HandleFunc("/fetch-logs", corsHandler(s.metrics.HTTPMetricsMiddleware(s.getFetchLogs, "/fetch-logs")))
 	mux.HandleFunc("/stats", corsHandler(s.metrics.HTTPMetricsMiddleware(s.getStats, "/stats")))
 	mux.HandleFunc("/summarization/stats", corsHandler(s.metrics.HTTPMetricsMiddleware(s.getSummarizationStats, "/summarization/stats")))
 	mux.HandleFunc("/health", corsHandler(s.metrics.HTTPMetricsMiddleware(s.healthCheck, "/health")))
+	mux.HandleFunc("/livez", corsHandler(s.metrics.HTTPMetricsMiddleware(s.livez, "/livez")))
+	mux.HandleFunc("/readyz", corsHandler(s.metrics.HTTPMetricsMiddleware(s.readyz, "/readyz")))
+	mux.HandleFunc("/metrics/summary", corsHandler(s.metrics.HTTPMetricsMiddleware(s.getMetricsSummary, "/metrics/summary")))
+
+	// Mutating endpoints, behind the X-Signature check
+	if s.adminEndpointsEnabled() {
+		mux.HandleFunc("/summarization/retry", corsHandler(s.metrics.HTTPMetricsMiddleware(s.requireSignature(s.retrySummaries), "/summarization/retry")))
+		mux.HandleFunc("/admin/purge", corsHandler(s.metrics.HTTPMetricsMiddleware(s.requireSignature(s.purgeArticles), "/admin/purge")))
+		mux.HandleFunc("/admin/feeds/enable", corsHandler(s.metrics.HTTPMetricsMiddleware(s.requireSignature(s.enableFeed), "/admin/feeds/enable")))
+		mux.HandleFunc("/feeds/fetch", corsHandler(s.metrics.HTTPMetricsMiddleware(s.requireSignature(s.triggerFeedFetch), "/feeds/fetch")))
+	} else {
+		log.Printf("Admin endpoints not registered: set ADMIN_API_SECRET or ADMIN_API_TOKEN to enable them")
+	}
 
 	// Prometheus metrics endpoint
 	mux.Handle(s.config.Prometheus.MetricsPath, MetricsHandler())
 
-	addr := fmt.Sprintf(":%d", s.port)
-	log.Printf("Starting API server on %s", addr)
-
-	server := &http.Server{
-		Addr:         addr,
-		Handler:      mux,
-		ReadTimeout:  s.config.Performance.HTTPReadTimeout,
-		WriteTimeout: s.config.Performance.HTTPWriteTimeout,
-		IdleTimeout:  s.config.Performance.HTTPIdleTimeout,
-	}
-
-	if err := server.ListenAndServe(); err != nil {
-		log.Fatalf("API server failed: %v", err)
-	}
+	return mux
 }
 
 // ArticleView is the JSON representation of an article returned by the API.
@@ -90,22 +126,65 @@ type ArticleView struct {
 	Title          string        `json:"title"`
 	URL            string        `json:"url"`
 	Summary        *string       `json:"summary"`
-	Content        string        `json:"content"`
+	Preview        string        `json:"preview,omitempty"`
+	Content        string        `json:"content,omitempty"`
 	PublishedAt    time.Time     `json:"published_at"`
 	FetchDuration  time.Duration `json:"fetch_duration"`
 	FeedURL        string        `json:"feed_url"`
 	ContentHash    string        `json:"content_hash"`
+	Language       string        `json:"language,omitempty"`
+	Author         string        `json:"author,omitempty"`
+	Tags           []string      `json:"tags,omitempty"`
 	CrossFeedCount int           `json:"cross_feed_count,omitempty"`
 }
 
+// articleCursor is the position of an article in the newest-first listing.
+// Clients get it as an opaque string from next_cursor and pass it back as
+// before to read the next page.
+type articleCursor struct {
+	publishedAt time.Time
+	id          int64
+}
+
+// encodeArticleCursor returns the cursor of the page following the article.
+func encodeArticleCursor(article ArticleView) string {
+	raw := article.PublishedAt.UTC().Format(time.RFC3339Nano) + "|" + strconv.FormatInt(article.ID, 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// decodeArticleCursor parses a cursor made by encodeArticleCursor.
+func decodeArticleCursor(s string) (*articleCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cursor %q", s)
+	}
+	published, id, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return nil, fmt.Errorf("invalid cursor %q", s)
+	}
+	cursor := &articleCursor{}
+	if cursor.publishedAt, err = time.Parse(time.RFC3339Nano, published); err != nil {
+		return nil, fmt.Errorf("invalid cursor %q", s)
+	}
+	if cursor.id, err = strconv.ParseInt(id, 10, 64); err != nil {
+		return nil, fmt.Errorf("invalid cursor %q", s)
+	}
+	return cursor, nil
+}
+
 // buildArticlesQuery constructs the SQL and ordered args for listing articles,
 // applying optional feed and case-insensitive search (q) filters, with optional sort order.
-func buildArticlesQuery(feed, q, sort string, limit, offset int) (string, []interface{}) {
+// It selects the short preview rather than full_content to keep list payloads
+// small; /articles/get returns the full text. With a before cursor it lists the
+// newest articles older than it with no OFFSET, so deep pages cost no more than
+// the first; the caller rejects a cursor combined with an offset or sort=oldest.
+func buildArticlesQuery(feed, tag, q, sort string, limit, offset int, before *articleCursor) (string, []interface{}) {
 	q = strings.TrimSpace(q)
 	if len(q) < 2 {
 		q = "" // ignore empty/too-short searches to avoid full-table ILIKE scans
 	}
-	query := `SELECT id, title, url, summary, full_content, publish_date, fetch_duration_ms, feed_url, content_hash
+	query := `SELECT id, title, url, summary, COALESCE(preview, ''), publish_date, fetch_duration_ms, feed_url, content_hash, COALESCE(language, ''),
+		COALESCE(author, ''), tags
 		FROM articles`
 	var conds []string
 	var args []interface{}
@@ -115,24 +194,84 @@ func buildArticlesQuery(feed, q, sort string, limit, offset int) (string, []inte
 		args = append(args, feed)
 		i++
 	}
+	if tag = normalizeTag(tag); tag != "" {
+		// Containment rather than = ANY, so the GIN index on tags is used
+		conds = append(conds, fmt.Sprintf("tags @> ARRAY[$%d]::text[]", i))
+		args = append(args, tag)
+		i++
+	}
 	if q != "" {
 		conds = append(conds, fmt.Sprintf("(title ILIKE $%d OR summary ILIKE $%d OR full_content ILIKE $%d)", i, i+1, i+2))
 		like := "%" + q + "%"
 		args = append(args, like, like, like)
 		i += 3
 	}
+	if before != nil {
+		conds = append(conds, fmt.Sprintf("(publish_date, id) < ($%d, $%d)", i, i+1))
+		args = append(args, before.publishedAt, before.id)
+		i += 2
+	}
 	if len(conds) > 0 {
 		query += " WHERE " + strings.Join(conds, " AND ")
 	}
 	order := "DESC"
-	if sort == "oldest" {
+	if sort == "oldest" && before == nil {
 		order = "ASC"
 	}
-	query += fmt.Sprintf(" ORDER BY publish_date %s LIMIT $%d OFFSET $%d", order, i, i+1)
-	args = append(args, limit, offset)
+	// id breaks ties between articles published at the same instant, so the
+	// cursor of the last article on a page is a strict position
+	query += fmt.Sprintf(" ORDER BY publish_date %s, id %s LIMIT $%d", order, order, i)
+	args = append(args, limit)
+	if before == nil {
+		query += fmt.Sprintf(" OFFSET $%d", i+1)
+		args = append(args, offset)
+	}
 	return query, args
 }
 
+// maxPaginationOffset bounds how deep the list endpoints page. Postgres still
+// reads every skipped row, so deeper offsets should narrow the query instead.
+const maxPaginationOffset = 10000
+
+// parsePagination reads the limit and offset query parameters shared by the
+// list endpoints. A missing limit is defaultLimit and one above maxLimit is
+// clamped to it; a missing offset is 0. Non-integers, a limit below 1, and an
+// offset that is negative or past maxPaginationOffset are errors, which
+// callers answer with 400.
+func parsePagination(r *http.Request, defaultLimit, maxLimit int) (limit, offset int, err error) {
+	limit = defaultLimit
+	if l := r.URL.Query().Get("limit"); l != "" {
+		limit, err = strconv.Atoi(l)
+		if err != nil || limit < 1 {
+			return 0, 0, fmt.Errorf("invalid limit %q: must be a positive integer", l)
+		}
+		limit = min(limit, maxLimit)
+	}
+
+	if o := r.URL.Query().Get("offset"); o != "" {
+		offset, err = strconv.Atoi(o)
+		if err != nil || offset < 0 || offset > maxPaginationOffset {
+			return 0, 0, fmt.Errorf("invalid offset %q: must be an integer from 0 to %d", o, maxPaginationOffset)
+		}
+	}
+	return limit, offset, nil
+}
+
+// parseDays reads the days query parameter of the endpoints that look back
+// over a window: defaultDays when missing, an error (answered with 400) when
+// it isn't an integer from 1 to maxDays.
+func parseDays(r *http.Request, defaultDays, maxDays int) (int, error) {
+	d := r.URL.Query().Get("days")
+	if d == "" {
+		return defaultDays, nil
+	}
+	days, err := strconv.Atoi(d)
+	if err != nil || days < 1 || days > maxDays {
+		return 0, fmt.Errorf("invalid days %q: must be an integer from 1 to %d", d, maxDays)
+	}
+	return days, nil
+}
+
 // getArticles returns paginated articles
 func (s *APIServer) getArticles(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -141,25 +280,30 @@ func (s *APIServer) getArticles(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Parse query parameters
-	limit := 50 // default
-	offset := 0 // default
-
-	if l := r.URL.Query().Get("limit"); l != "" {
-		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 100 {
-			limit = parsed
-		}
-	}
-
-	if o := r.URL.Query().Get("offset"); o != "" {
-		if parsed, err := strconv.Atoi(o); err == nil && parsed >= 0 {
-			offset = parsed
-		}
+	limit, offset, err := parsePagination(r, 50, 100)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
 	feedURL := r.URL.Query().Get("feed")
+	tag := r.URL.Query().Get("tag")
 	searchQ := r.URL.Query().Get("q")
+	sort := r.URL.Query().Get("sort")
+
+	var before *articleCursor
+	if cursor := r.URL.Query().Get("before"); cursor != "" {
+		if before, err = decodeArticleCursor(cursor); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if offset != 0 || sort == "oldest" {
+			http.Error(w, "before can't be combined with offset or sort=oldest", http.StatusBadRequest)
+			return
+		}
+	}
 
-	query, args := buildArticlesQuery(feedURL, searchQ, r.URL.Query().Get("sort"), limit, offset)
+	query, args := buildArticlesQuery(feedURL, tag, searchQ, sort, limit, offset, before)
 
 	rows, err := s.db.Query(query, args...)
 	if err != nil {
@@ -179,11 +323,14 @@ func (s *APIServer) getArticles(w http.ResponseWriter, r *http.Request) {
 			&article.Title,
 			&article.URL,
 			&article.Summary,
-			&article.Content,
+			&article.Preview,
 			&article.PublishedAt,
 			&fetchDurationMs,
 			&article.FeedURL,
 			&article.ContentHash,
+			&article.Language,
+			&article.Author,
+			pq.Array(&article.Tags),
 		)
 		if err != nil {
 			log.Printf("Row scan error: %v", err)
@@ -194,12 +341,20 @@ func (s *APIServer) getArticles(w http.ResponseWriter, r *http.Request) {
 		articles = append(articles, article)
 	}
 
+	// A full newest-first page may have more after it; offset pages get a
+	// cursor too, so clients can switch over mid-listing
+	var nextCursor interface{}
+	if len(articles) == limit && sort != "oldest" {
+		nextCursor = encodeArticleCursor(articles[len(articles)-1])
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"articles": articles,
-		"count":    len(articles),
-		"limit":    limit,
-		"offset":   offset,
+		"articles":    articles,
+		"count":       len(articles),
+		"limit":       limit,
+		"offset":      offset,
+		"next_cursor": nextCursor,
 	})
 }
 
@@ -228,7 +383,8 @@ func (s *APIServer) getArticleByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	query := `SELECT id, title, url, summary, full_content, publish_date, fetch_duration_ms, feed_url, content_hash
+	query := `SELECT id, title, url, summary, COALESCE(preview, ''), full_content, publish_date, fetch_duration_ms, feed_url, content_hash, COALESCE(language, ''),
+		COALESCE(author, ''), tags
 		FROM articles WHERE id = $1`
 
 	var article ArticleView
@@ -238,11 +394,15 @@ func (s *APIServer) getArticleByID(w http.ResponseWriter, r *http.Request) {
 		&article.Title,
 		&article.URL,
 		&article.Summary,
+		&article.Preview,
 		&article.Content,
 		&article.PublishedAt,
 		&fetchDurationMs,
 		&article.FeedURL,
 		&article.ContentHash,
+		&article.Language,
+		&article.Author,
+		pq.Array(&article.Tags),
 	)
 	if errors.Is(err, sql.ErrNoRows) {
 		http.Error(w, "Not found", http.StatusNotFound)
@@ -260,6 +420,59 @@ func (s *APIServer) getArticleByID(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(article)
 }
 
+// ArticleDetail is the /articles/{id} response: the stored article row plus
+// how many Discord webhook attempts were logged for it.
+type ArticleDetail struct {
+	*DatabaseArticle
+	WebhookAttempts int `json:"webhook_attempts"`
+}
+
+// articleIDFromPath extracts the id from an /articles/{id} request path.
+func articleIDFromPath(path string) (int64, error) {
+	rest, ok := strings.CutPrefix(path, "/articles/")
+	if !ok || strings.Contains(rest, "/") {
+		return 0, fmt.Errorf("invalid article path: %q", path)
+	}
+	return parseArticleID(rest)
+}
+
+// getArticleDetail returns the full database row for /articles/{id},
+// including full content and summary, plus its webhook delivery count.
+func (s *APIServer) getArticleDetail(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := articleIDFromPath(r.URL.Path)
+	if err != nil {
+		http.Error(w, "Invalid id", http.StatusBadRequest)
+		return
+	}
+
+	dbOps := NewDatabaseOperations(s.db)
+	article, err := dbOps.GetArticleByID(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		slog.Error("Database query error", "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	attempts, err := dbOps.CountWebhookLogsByArticle(id)
+	if err != nil {
+		slog.Error("Database query error", "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ArticleDetail{DatabaseArticle: article, WebhookAttempts: attempts})
+}
+
 // getLatestArticles returns the most recent articles across all feeds
 func (s *APIServer) getLatestArticles(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
@@ -267,20 +480,19 @@ func (s *APIServer) getLatestArticles(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	limit := 20 // default for latest
-	if l := r.URL.Query().Get("limit"); l != "" {
-		if parsed, err := strconv.Atoi(l); err == nil && parsed > 0 && parsed <= 50 {
-			limit = parsed
-		}
+	limit, offset, err := parsePagination(r, 20, 50)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
 	}
 
 	query := `
 		SELECT title, url, full_content, publish_date, fetch_time, fetch_duration_ms, feed_url, content_hash
 		FROM articles
 		ORDER BY fetched_at DESC 
-		LIMIT $1`
+		LIMIT $1 OFFSET $2`
 
-	rows, err := s.db.Query(query, limit)
+	rows, err := s.db.Query(query, limit, offset)
 	if err != nil {
 		log.Printf("Database query error: %v", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -327,33 +539,93 @@ func (s *APIServer) getFeeds(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	query := `
-		SELECT 
-			feed_url,
-			COUNT(*) as article_count,
-			MAX(publish_date) as latest_article,
-			MIN(publish_date) as oldest_article,
-			AVG(fetch_duration_ms) as avg_fetch_duration_ms
-		FROM articles 
-		GROUP BY feed_url 
-		ORDER BY article_count DESC`
-
-	rows, err := s.db.Query(query)
+	feeds, err := s.loadFeedStats()
 	if err != nil {
-		log.Printf("Database query error: %v", err)
+		slog.Error("Database query error", "error", err)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		return
 	}
-	defer rows.Close()
 
-	type FeedStats struct {
-		FeedURL            string     `json:"feed_url"`
-		ArticleCount       int        `json:"article_count"`
-		LatestArticle      *time.Time `json:"latest_article"`
-		OldestArticle      *time.Time `json:"oldest_article"`
-		AvgFetchDurationMs *float64   `json:"avg_fetch_duration_ms"`
+	if s.monitor != nil {
+		for i := range feeds {
+			if at, ok := s.monitor.deadFeeds.disabledAt(feeds[i].FeedURL); ok {
+				feeds[i].DisabledAt = &at
+			}
+		}
+	}
+
+	// Feeds not fetched since startup have no breaker yet
+	if s.circuitBreakers != nil {
+		breakers := s.circuitBreakers.GetStatus()
+		for i := range feeds {
+			if cb, ok := breakers["rss_feed_"+feeds[i].FeedURL]; ok {
+				state := cb.State
+				feeds[i].CircuitBreakerState = &state
+			}
+		}
 	}
 
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"feeds": feeds,
+		"count": len(feeds),
+	})
+}
+
+// FeedStats aggregates the stored articles of one feed, along with its
+// health: the state of its circuit breaker and how its last fetch went.
+type FeedStats struct {
+	FeedURL            string     `json:"feed_url"`
+	ArticleCount       int        `json:"article_count"`
+	LatestArticle      *time.Time `json:"latest_article"`
+	OldestArticle      *time.Time `json:"oldest_article"`
+	AvgFetchDurationMs *float64   `json:"avg_fetch_duration_ms"`
+
+	CircuitBreakerState *CircuitBreakerState `json:"circuit_breaker_state"` // null until the feed is fetched
+	LastFetchStatus     *string              `json:"last_fetch_status"`     // Of the feed's latest fetch_logs row
+	LastFetchMessage    *string              `json:"last_fetch_message"`
+	LastFetchAt         *time.Time           `json:"last_fetch_at"`
+	DisabledAt          *time.Time           `json:"disabled_at"` // Set while the feed is disabled as dead
+}
+
+// loadFeedStats returns the stats of every feed with stored articles, the
+// busiest first, with the latest fetch_logs row of each.
+func (s *APIServer) loadFeedStats() ([]FeedStats, error) {
+	query := `
+		SELECT
+			f.feed_url,
+			f.article_count,
+			f.latest_article,
+			f.oldest_article,
+			f.avg_fetch_duration_ms,
+			l.status,
+			l.message,
+			l.created_at
+		FROM (
+			SELECT
+				feed_url,
+				COUNT(*) as article_count,
+				MAX(publish_date) as latest_article,
+				MIN(publish_date) as oldest_article,
+				AVG(fetch_duration_ms) as avg_fetch_duration_ms
+			FROM articles
+			GROUP BY feed_url
+		) f
+		LEFT JOIN LATERAL (
+			SELECT status, message, created_at
+			FROM fetch_logs
+			WHERE fetch_logs.feed_url = f.feed_url
+			ORDER BY created_at DESC
+			LIMIT 1
+		) l ON TRUE
+		ORDER BY f.article_count DESC`
+
+	rows, err := s.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
 	var feeds []FeedStats
 	for rows.Next() {
 		var feed FeedStats
@@ -363,6 +635,9 @@ func (s *APIServer) getFeeds(w http.ResponseWriter, r *http.Request) {
 			&feed.LatestArticle,
 			&feed.OldestArticle,
 			&feed.AvgFetchDurationMs,
+			&feed.LastFetchStatus,
+			&feed.LastFetchMessage,
+			&feed.LastFetchAt,
 		)
 		if err != nil {
 			log.Printf("Row scan error: %v", err)
@@ -370,12 +645,7 @@ func (s *APIServer) getFeeds(w http.ResponseWriter, r *http.Request) {
 		}
 		feeds = append(feeds, feed)
 	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"feeds": feeds,
-		"count": len(feeds),
-	})
+	return feeds, rows.Err()
 }
 
 // getStats returns overall system statistics
@@ -417,10 +687,10 @@ func (s *APIServer) getStats(w http.ResponseWriter, r *http.Request) {
 		log.Printf("Error getting last fetch time: %v", err)
 	}
 
-	// Get 24h fetch statistics
+	// Get 24h fetch statistics (a 304 Not Modified is a successful fetch)
 	err = s.db.QueryRow(`
 		SELECT COUNT(*) FROM fetch_logs 
-		WHERE status = 'success' AND created_at > NOW() - INTERVAL '24 hours'
+		WHERE status IN ('success', 'not_modified') AND created_at > NOW() - INTERVAL '24 hours'
 	`).Scan(&stats.SuccessfulFetches)
 	if err != nil {
 		log.Printf("Error getting successful fetches: %v", err)
@@ -471,6 +741,7 @@ type HealthStatus struct {
 	CircuitBreakers map[string]CircuitBreakerStatus `json:"circuit_breakers"`
 	SystemMetrics   SystemMetrics                   `json:"system_metrics"`
 	Services        map[string]ServiceHealth        `json:"services"`
+	FetchInterval   *FetchIntervalStatus            `json:"fetch_interval,omitempty"`
 }
 
 // DatabaseHealth represents database health information
@@ -488,7 +759,25 @@ type DatabaseHealth struct {
 type SystemMetrics struct {
 	UptimeSeconds int64 `json:"uptime_seconds"`
 	GoRoutines    int   `json:"goroutines"`
-	MemoryMB      int   `json:"memory_mb"`
+	MemoryMB      int   `json:"memory_mb"`     // Heap allocated (MemStats.Alloc)
+	MemorySysMB   int   `json:"memory_sys_mb"` // Obtained from the OS (MemStats.Sys)
+}
+
+// collectSystemMetrics reads the goroutine count and memory use, and
+// updates their gauges as well.
+func collectSystemMetrics(metrics *PrometheusMetrics) SystemMetrics {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	goroutines := runtime.NumGoroutine()
+	if metrics != nil {
+		metrics.UpdateRuntimeStats(goroutines, mem.Alloc, mem.Sys)
+	}
+	return SystemMetrics{
+		UptimeSeconds: int64(time.Since(startTime).Seconds()),
+		GoRoutines:    goroutines,
+		MemoryMB:      int(mem.Alloc / (1 << 20)),
+		MemorySysMB:   int(mem.Sys / (1 << 20)),
+	}
 }
 
 // ServiceHealth represents individual service health
@@ -501,6 +790,18 @@ type ServiceHealth struct {
 
 var startTime = time.Now()
 
+// getMetricsSummary returns the key Prometheus counters as a flat JSON
+// object, a lightweight dashboard source for those not scraping /metrics.
+func (s *APIServer) getMetricsSummary(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(s.metrics.Summary())
+}
+
 // healthCheck returns the comprehensive health status of the service
 func (s *APIServer) healthCheck(w http.ResponseWriter, r *http.Request) {
 	health := HealthStatus{
@@ -537,13 +838,14 @@ func (s *APIServer) healthCheck(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// System metrics
-	health.SystemMetrics = SystemMetrics{
-		UptimeSeconds: int64(time.Since(startTime).Seconds()),
-		GoRoutines:    0, // runtime.NumGoroutine() if needed
-		MemoryMB:      0, // Can add memory stats if needed
+	if s.monitor != nil {
+		fetchInterval := s.monitor.FetchIntervalStatus()
+		health.FetchInterval = &fetchInterval
 	}
 
+	// System metrics
+	health.SystemMetrics = collectSystemMetrics(s.metrics)
+
 	// Overall health status
 	if health.Status == "" {
 		if overallHealthy && dbHealth.Status == "healthy" {
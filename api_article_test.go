@@ -28,3 +28,30 @@ func TestParseArticleID(t *testing.T) {
 		}
 	}
 }
+
+func TestArticleIDFromPath(t *testing.T) {
+	cases := []struct {
+		in      string
+		want    int64
+		wantErr bool
+	}{
+		{"/articles/42", 42, false},
+		{"/articles/", 0, true},
+		{"/articles/abc", 0, true},
+		{"/articles/0", 0, true},
+		{"/articles/42/extra", 0, true},
+		{"/feeds/42", 0, true},
+	}
+	for _, c := range cases {
+		got, err := articleIDFromPath(c.in)
+		if c.wantErr && err == nil {
+			t.Errorf("articleIDFromPath(%q): expected error, got nil", c.in)
+		}
+		if !c.wantErr && err != nil {
+			t.Errorf("articleIDFromPath(%q): unexpected error %v", c.in, err)
+		}
+		if !c.wantErr && got != c.want {
+			t.Errorf("articleIDFromPath(%q) = %d, want %d", c.in, got, c.want)
+		}
+	}
+}
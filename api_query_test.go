@@ -1,13 +1,19 @@
 package main
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestBuildArticlesQuery(t *testing.T) {
 	t.Run("no filters", func(t *testing.T) {
-		q, args := buildArticlesQuery("", "", "", 50, 0)
+		q, args := buildArticlesQuery("", "", "", "", 50, 0, nil)
 		if strings.Contains(q, "WHERE") {
 			t.Fatalf("expected no WHERE clause, got: %s", q)
 		}
@@ -19,8 +25,19 @@ func TestBuildArticlesQuery(t *testing.T) {
 		}
 	})
 
+	t.Run("lists preview instead of full content", func(t *testing.T) {
+		q, _ := buildArticlesQuery("", "", "", "", 50, 0, nil)
+		sel := q[:strings.Index(q, "FROM")]
+		if !strings.Contains(sel, "preview") {
+			t.Fatalf("expected preview column in SELECT: %s", q)
+		}
+		if strings.Contains(sel, "full_content") {
+			t.Fatalf("list query should not select full_content: %s", q)
+		}
+	})
+
 	t.Run("feed only", func(t *testing.T) {
-		q, args := buildArticlesQuery("https://example.com/rss", "", "", 50, 0)
+		q, args := buildArticlesQuery("https://example.com/rss", "", "", "", 50, 0, nil)
 		if !strings.Contains(q, "feed_url = $1") {
 			t.Fatalf("missing feed filter: %s", q)
 		}
@@ -30,7 +47,7 @@ func TestBuildArticlesQuery(t *testing.T) {
 	})
 
 	t.Run("query only", func(t *testing.T) {
-		q, args := buildArticlesQuery("", "ransomware", "", 50, 0)
+		q, args := buildArticlesQuery("", "", "ransomware", "", 50, 0, nil)
 		if !strings.Contains(q, "ILIKE") {
 			t.Fatalf("missing ILIKE search: %s", q)
 		}
@@ -43,7 +60,7 @@ func TestBuildArticlesQuery(t *testing.T) {
 	})
 
 	t.Run("feed and query", func(t *testing.T) {
-		q, args := buildArticlesQuery("https://example.com/rss", "cve", "", 10, 20)
+		q, args := buildArticlesQuery("https://example.com/rss", "", "cve", "", 10, 20, nil)
 		if !strings.Contains(q, "feed_url = $1") || !strings.Contains(q, "ILIKE $2") {
 			t.Fatalf("expected both filters with correct placeholders: %s", q)
 		}
@@ -62,7 +79,7 @@ func TestBuildArticlesQuery(t *testing.T) {
 	})
 
 	t.Run("short query ignored", func(t *testing.T) {
-		q, args := buildArticlesQuery("", "a", "", 50, 0)
+		q, args := buildArticlesQuery("", "", "a", "", 50, 0, nil)
 		if strings.Contains(q, "ILIKE") {
 			t.Fatalf("expected no ILIKE search for short query, got: %s", q)
 		}
@@ -70,7 +87,7 @@ func TestBuildArticlesQuery(t *testing.T) {
 			t.Fatalf("expected 2 args, got %d: %v", len(args), args)
 		}
 
-		q, args = buildArticlesQuery("", "   ", "", 50, 0)
+		q, args = buildArticlesQuery("", "", "   ", "", 50, 0, nil)
 		if strings.Contains(q, "ILIKE") {
 			t.Fatalf("expected no ILIKE search for whitespace query, got: %s", q)
 		}
@@ -79,15 +96,25 @@ func TestBuildArticlesQuery(t *testing.T) {
 		}
 	})
 
+	t.Run("tag", func(t *testing.T) {
+		q, args := buildArticlesQuery("https://example.com/rss", "  Zero   Day ", "", "", 50, 0, nil)
+		if !strings.Contains(q, "feed_url = $1 AND tags @> ARRAY[$2]::text[]") {
+			t.Fatalf("missing tag filter: %s", q)
+		}
+		if len(args) != 4 || args[1] != "zero day" {
+			t.Fatalf("tag should be normalized like stored tags: %v", args)
+		}
+	})
+
 	t.Run("sort oldest", func(t *testing.T) {
-		q, _ := buildArticlesQuery("", "", "oldest", 50, 0)
+		q, _ := buildArticlesQuery("", "", "", "oldest", 50, 0, nil)
 		if !strings.Contains(q, "ORDER BY publish_date ASC") {
 			t.Fatalf("expected ASC order: %s", q)
 		}
 	})
 
 	t.Run("unknown sort falls back to newest", func(t *testing.T) {
-		q, _ := buildArticlesQuery("", "", "garbage'; DROP TABLE articles;--", 50, 0)
+		q, _ := buildArticlesQuery("", "", "", "garbage'; DROP TABLE articles;--", 50, 0, nil)
 		if !strings.Contains(q, "ORDER BY publish_date DESC") {
 			t.Fatalf("expected DESC fallback: %s", q)
 		}
@@ -95,4 +122,163 @@ func TestBuildArticlesQuery(t *testing.T) {
 			t.Fatalf("sort value leaked into SQL: %s", q)
 		}
 	})
+
+	t.Run("before cursor", func(t *testing.T) {
+		published := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+		q, args := buildArticlesQuery("https://example.com/rss", "", "", "", 25, 0, &articleCursor{publishedAt: published, id: 42})
+		if !strings.Contains(q, "feed_url = $1 AND (publish_date, id) < ($2, $3)") {
+			t.Fatalf("missing keyset condition: %s", q)
+		}
+		if !strings.HasSuffix(q, "ORDER BY publish_date DESC, id DESC LIMIT $4") {
+			t.Fatalf("cursor page should order newest first with no OFFSET: %s", q)
+		}
+		if len(args) != 4 || args[1] != published || args[2] != int64(42) || args[3] != 25 {
+			t.Fatalf("unexpected args: %v", args)
+		}
+	})
+}
+
+func TestArticleCursor(t *testing.T) {
+	article := ArticleView{ID: 7, PublishedAt: time.Date(2024, 5, 1, 12, 0, 0, 123456000, time.FixedZone("CEST", 2*3600))}
+	cursor, err := decodeArticleCursor(encodeArticleCursor(article))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cursor.publishedAt.Equal(article.PublishedAt) || cursor.id != 7 {
+		t.Errorf("decoded cursor = %+v, want %v and id 7", cursor, article.PublishedAt)
+	}
+
+	for _, bad := range []string{"!!", "bm8tc2VwYXJhdG9y", "eWVzdGVyZGF5fDc", "MjAyNC0wNS0wMVQxMjowMDowMFp8c2V2ZW4"} {
+		if _, err := decodeArticleCursor(bad); err == nil {
+			t.Errorf("decodeArticleCursor(%q) succeeded, want an error", bad)
+		}
+	}
+}
+
+func TestGetArticlesNextCursor(t *testing.T) {
+	db, recorder := openExecRecorder(t)
+	older := time.Date(2024, 5, 1, 11, 0, 0, 0, time.UTC)
+	recorder.answer("FROM articles",
+		[]driver.Value{int64(9), "Newer", "https://a/9", "s", "", older.Add(time.Hour), int64(0), "https://a/rss", "h9", "", "Ada Lovelace", "{security,\"zero day\"}"},
+		[]driver.Value{int64(8), "Older", "https://a/8", "s", "", older, int64(0), "https://a/rss", "h8", "", "", "{}"})
+	s := &APIServer{db: db}
+
+	get := func(target string) map[string]interface{} {
+		rec := httptest.NewRecorder()
+		s.getArticles(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("GET %s: status = %d: %s", target, rec.Code, rec.Body)
+		}
+		var body map[string]interface{}
+		json.NewDecoder(rec.Body).Decode(&body)
+		return body
+	}
+
+	body := get("/articles?limit=2")
+	first := body["articles"].([]interface{})[0].(map[string]interface{})
+	if tags, _ := json.Marshal(first["tags"]); first["author"] != "Ada Lovelace" || string(tags) != `["security","zero day"]` {
+		t.Errorf("first article author = %v, tags = %s", first["author"], tags)
+	}
+	want := encodeArticleCursor(ArticleView{ID: 8, PublishedAt: older})
+	if body["next_cursor"] != want {
+		t.Errorf("next_cursor of a full page = %v, want %q", body["next_cursor"], want)
+	}
+	if body := get("/articles?limit=2&before=" + want); body["next_cursor"] != want {
+		t.Errorf("next_cursor of a full cursor page = %v, want %q", body["next_cursor"], want)
+	}
+	if body := get("/articles?limit=3"); body["next_cursor"] != nil {
+		t.Errorf("next_cursor of the last page = %v, want null", body["next_cursor"])
+	}
+}
+
+func TestParsePagination(t *testing.T) {
+	cases := []struct {
+		target     string
+		wantLimit  int
+		wantOffset int
+		wantErr    bool
+	}{
+		{"/articles", 50, 0, false},
+		{"/articles?limit=10&offset=30", 10, 30, false},
+		{"/articles?limit=1&offset=0", 1, 0, false},
+		{"/articles?limit=100&offset=10000", 100, 10000, false},
+		{"/articles?limit=500", 100, 0, false}, // Over max is clamped
+		{"/articles?limit=0", 0, 0, true},
+		{"/articles?limit=-5", 0, 0, true},
+		{"/articles?offset=-1", 0, 0, true},
+		{"/articles?offset=10001", 0, 0, true},
+		{"/articles?offset=99999999999999999999", 0, 0, true},
+		{"/articles?limit=abc", 0, 0, true},
+		{"/articles?limit=10.5", 0, 0, true},
+		{"/articles?offset=ten", 0, 0, true},
+	}
+	for _, c := range cases {
+		limit, offset, err := parsePagination(httptest.NewRequest(http.MethodGet, c.target, nil), 50, 100)
+		if (err != nil) != c.wantErr {
+			t.Errorf("parsePagination(%s) error = %v, wantErr %v", c.target, err, c.wantErr)
+			continue
+		}
+		if limit != c.wantLimit || offset != c.wantOffset {
+			t.Errorf("parsePagination(%s) = (%d, %d), want (%d, %d)", c.target, limit, offset, c.wantLimit, c.wantOffset)
+		}
+	}
+}
+
+func TestListEndpointsRejectMalformedPagination(t *testing.T) {
+	s := &APIServer{}
+	handlers := map[string]http.HandlerFunc{
+		"/articles?limit=abc":        s.getArticles,
+		"/articles/latest?limit=-1":  s.getLatestArticles,
+		"/search?q=x&offset=nope":    s.searchArticles,
+		"/articles/latest?offset=-3": s.getLatestArticles,
+		"/articles?before=!!":        s.getArticles,
+		"/articles?before=" + encodeArticleCursor(ArticleView{ID: 1}) + "&offset=50":   s.getArticles,
+		"/articles?before=" + encodeArticleCursor(ArticleView{ID: 1}) + "&sort=oldest": s.getArticles,
+	}
+	for target, handler := range handlers {
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("GET %s: status = %d, want 400", target, rec.Code)
+		}
+	}
+}
+
+func TestGetFeedsHealth(t *testing.T) {
+	db, recorder := openExecRecorder(t)
+	fetched := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	recorder.answer("LEFT JOIN LATERAL",
+		[]driver.Value{"https://broken.example/rss", int64(7), fetched, fetched, 90.0, "error", "HTTP 503", fetched},
+		[]driver.Value{"https://fine.example/rss", int64(3), fetched, fetched, 40.0, "success", nil, fetched},
+		[]driver.Value{"https://removed.example/rss", int64(1), fetched, fetched, nil, nil, nil, nil})
+
+	breakers := NewCircuitBreakerManager()
+	broken := breakers.GetOrCreateBreaker("rss_feed_https://broken.example/rss", &CircuitBreakerConfig{FailureThreshold: 1, Timeout: time.Hour})
+	broken.Execute(func() error { return errors.New("HTTP 503") }, nil)
+	breakers.GetOrCreateBreaker("rss_feed_https://fine.example/rss", &CircuitBreakerConfig{FailureThreshold: 1, Timeout: time.Hour})
+
+	rec := httptest.NewRecorder()
+	(&APIServer{db: db, circuitBreakers: breakers}).getFeeds(rec, httptest.NewRequest(http.MethodGet, "/feeds", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+	}
+	var body struct {
+		Feeds []FeedStats `json:"feeds"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil || len(body.Feeds) != 3 {
+		t.Fatalf("decode: %v, %d feeds", err, len(body.Feeds))
+	}
+
+	if feed := body.Feeds[0]; feed.CircuitBreakerState == nil || *feed.CircuitBreakerState != StateOpen ||
+		feed.LastFetchStatus == nil || *feed.LastFetchStatus != "error" ||
+		feed.LastFetchMessage == nil || *feed.LastFetchMessage != "HTTP 503" || !feed.LastFetchAt.Equal(fetched) {
+		t.Errorf("broken feed = %+v, want an open breaker and its last error", feed)
+	}
+	if feed := body.Feeds[1]; feed.CircuitBreakerState == nil || *feed.CircuitBreakerState != StateClosed ||
+		feed.LastFetchStatus == nil || *feed.LastFetchStatus != "success" || feed.LastFetchMessage != nil {
+		t.Errorf("fine feed = %+v, want a closed breaker and a successful fetch", feed)
+	}
+	if feed := body.Feeds[2]; feed.CircuitBreakerState != nil || feed.LastFetchStatus != nil || feed.LastFetchAt != nil {
+		t.Errorf("feed without fetches = %+v, want no health data", feed)
+	}
 }
@@ -102,33 +102,11 @@ func TestArticleDateFiltering(t *testing.T) {
 	}
 }
 
-// shouldProcessArticle extracts the date filtering logic for testing
+// shouldProcessArticle reports whether processArticle lets item past its
+// link and date checks, using the same articleDateDropReason.
 func shouldProcessArticle(item *gofeed.Item, cfg *config.Config) bool {
-	if item.Link == "" {
-		return false
-	}
-
-	// Parse and normalize the publish date to UTC
-	var publishDate time.Time
-	if item.PublishedParsed != nil {
-		publishDate = item.PublishedParsed.UTC()
-	} else {
-		// If no publish date is available, skip the article as per requirements
-		return false
-	}
-
-	// Check publication date against the cutoff date (2025-05-31T00:00:00Z)
-	cutoffDate := cfg.App.ArticleCutoffDate.UTC()
-	if publishDate.Before(cutoffDate) {
-		return false
-	}
-
-	// Check publication date against initiation date (keep existing logic for backward compatibility)
-	if publishDate.Before(cfg.App.InitiationDate) {
-		return false
-	}
-
-	return true
+	_, drop := articleDateDropReason(item, cfg.App)
+	return item.Link != "" && drop == ""
 }
 
 // timePtr returns a pointer to a time.Time value
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/mmcdole/gofeed"
+)
+
+// Bounds on the tags stored per article, so a feed that stuffs its category
+// list can't bloat the row or the tags index.
+const (
+	maxArticleTags   = 20
+	maxArticleTagLen = 64
+)
+
+// feedItemAuthor returns the item's author names joined by ", ", or "" when
+// the feed names none. gofeed fills Authors from RSS author/dc:creator, Atom
+// author and JSON Feed authors alike.
+func feedItemAuthor(item *gofeed.Item) string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, person := range item.Authors {
+		if person == nil {
+			continue
+		}
+		name := strings.Join(strings.Fields(person.Name), " ")
+		if name == "" {
+			name = strings.TrimSpace(person.Email)
+		}
+		if name != "" && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
+// feedItemTags returns the item's categories as tags: lowercased, with
+// whitespace collapsed, deduplicated and in feed order, so /articles?tag=
+// matches regardless of how a feed capitalizes them. It never returns nil,
+// since the tags column isn't nullable.
+func feedItemTags(item *gofeed.Item) []string {
+	tags := []string{}
+	seen := make(map[string]bool)
+	for _, category := range item.Categories {
+		tag := normalizeTag(category)
+		if tag == "" || len(tag) > maxArticleTagLen || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		tags = append(tags, tag)
+		if len(tags) == maxArticleTags {
+			break
+		}
+	}
+	return tags
+}
+
+// normalizeTag lowercases a category or tag filter and collapses its
+// whitespace.
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.Join(strings.Fields(tag), " "))
+}
+
+// sanitizeTags strips invalid UTF-8 from tags for the insert, turning nil
+// into an empty array.
+func sanitizeTags(tags []string) []string {
+	clean := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		clean = append(clean, sanitizeUTF8(tag))
+	}
+	return clean
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mmcdole/gofeed"
+)
+
+func TestFeedItemAuthorAndTags(t *testing.T) {
+	tests := []struct {
+		name       string
+		feed       string
+		wantAuthor string
+		wantTags   []string
+	}{
+		{"RSS", `<rss version="2.0" xmlns:dc="http://purl.org/dc/elements/1.1/"><channel><title>F</title><item><title>A</title>
+			<dc:creator>Ada  Lovelace</dc:creator><category>Security</category><category> Zero  Day </category><category>security</category></item></channel></rss>`,
+			"Ada Lovelace", []string{"security", "zero day"}},
+		{"Atom", `<feed xmlns="http://www.w3.org/2005/Atom"><title>F</title><entry><title>A</title>
+			<author><name>Ada Lovelace</name></author><author><name>Charles Babbage</name></author><category term="Cloud"/></entry></feed>`,
+			"Ada Lovelace, Charles Babbage", []string{"cloud"}},
+		{"JSON Feed", `{"version": "https://jsonfeed.org/version/1.1", "title": "F",
+			"items": [{"id": "1", "authors": [{"name": "Ada Lovelace"}], "tags": ["Privacy", ""]}]}`,
+			"Ada Lovelace", []string{"privacy"}},
+		{"none", `<rss version="2.0"><channel><title>F</title><item><title>A</title></item></channel></rss>`,
+			"", []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			feed, err := gofeed.NewParser().ParseString(tt.feed)
+			if err != nil {
+				t.Fatal(err)
+			}
+			item := feed.Items[0]
+			if got := feedItemAuthor(item); got != tt.wantAuthor {
+				t.Errorf("author = %q, want %q", got, tt.wantAuthor)
+			}
+			if got := feedItemTags(item); !reflect.DeepEqual(got, tt.wantTags) {
+				t.Errorf("tags = %q, want %q", got, tt.wantTags)
+			}
+		})
+	}
+
+	var many gofeed.Item
+	for i := 0; i < maxArticleTags+5; i++ {
+		many.Categories = append(many.Categories, strings.Repeat("x", i+1))
+	}
+	many.Categories = append([]string{strings.Repeat("y", maxArticleTagLen+1)}, many.Categories...)
+	if tags := feedItemTags(&many); len(tags) != maxArticleTags || tags[0] != "x" {
+		t.Errorf("got %d tags starting %q, want %d with the overlong one dropped", len(tags), tags[0], maxArticleTags)
+	}
+}
@@ -0,0 +1,275 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+)
+
+// Discord.OnArticleUpdate policies. Anything else, including empty, ignores
+// updates.
+const (
+	articleUpdateRepost = "repost"
+	articleUpdateEdit   = "edit"
+)
+
+// articleUpdatedPrefix marks a reposted article's title.
+const articleUpdatedPrefix = "Updated: "
+
+// tracksArticleUpdates reports whether policy does anything with updates,
+// and so whether feed items are watched for them at all.
+func tracksArticleUpdates(policy string) bool {
+	return policy == articleUpdateRepost || policy == articleUpdateEdit
+}
+
+// itemFingerprint hashes what a feed item says about its article, so a
+// changed item can be told from one merely listed again without fetching
+// the article page.
+func itemFingerprint(item *gofeed.Item) string {
+	content, _ := feedItemContent(item)
+	hasher := sha256.New()
+	hasher.Write([]byte(item.Title))
+	hasher.Write([]byte(content))
+	if item.UpdatedParsed != nil {
+		hasher.Write([]byte(item.UpdatedParsed.UTC().Format(time.RFC3339)))
+	}
+	return hex.EncodeToString(hasher.Sum(nil))
+}
+
+// articleChanged records the fingerprint of item for articleURL and reports
+// whether it differs from the one recorded before. The first sighting since
+// startup is only recorded. It always reports false while updates aren't
+// tracked.
+func (m *RSSMonitor) articleChanged(item *gofeed.Item, articleURL string) bool {
+	if !tracksArticleUpdates(m.config.Discord.OnArticleUpdate) {
+		return false
+	}
+	fingerprint := itemFingerprint(item)
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if m.itemVersions == nil {
+		m.itemVersions = make(map[string]string)
+	}
+	previous, known := m.itemVersions[articleURL]
+	m.itemVersions[articleURL] = fingerprint
+	return known && previous != fingerprint
+}
+
+// processArticleUpdate reloads an article whose feed item changed and, if
+// its content hash changed too, stores the new version. An article that was
+// posted to Discord is then summarized again to announce the update. It
+// reports whether the stored article changed.
+func (m *RSSMonitor) processArticleUpdate(item *gofeed.Item, articleURL, feedURL string, budget *contentBudget) bool {
+	content, source, _, _ := m.loadArticleContent(item, feedURL, budget)
+	article := Article{
+		Title:         item.Title,
+		URL:           articleURL,
+		Content:       content,
+		ContentSource: source,
+		Lead:          articleLead(item, content, source),
+		Preview:       buildArticlePreview(content, m.config.Content.PreviewLength),
+		FeedURL:       feedURL,
+		FeedSummary:   m.feedSummary(item, feedURL),
+		Updated:       true,
+	}
+	article.Language, _ = detectLanguage(article.Content)
+	article.ContentHash = m.generateContentHash(article.Title, article.URL, article.Content)
+
+	var posted bool
+	err := m.db.QueryRow(`
+		UPDATE articles
+		SET title = $1, full_content = $2, preview = $3, content_hash = $4, language = NULLIF($5, ''), updated_at = NOW()
+		WHERE url = $6 AND content_hash IS DISTINCT FROM $4
+		RETURNING COALESCE(posted_to_discord, FALSE)`,
+		sanitizeUTF8(article.Title),
+		sanitizeUTF8(article.Content),
+		sanitizeUTF8(article.Preview),
+		article.ContentHash,
+		article.Language,
+		article.URL,
+	).Scan(&posted)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false // Same content under a reworded feed item
+	}
+	if err != nil {
+		slog.Error("Failed to save update of article", "article_url", articleURL, "error", err)
+		return false
+	}
+
+	slog.Info("Article updated by its feed", "feed_url", article.FeedURL, "article_url", article.URL)
+	if posted {
+		m.generateSummaryAsync(article)
+	}
+	return true
+}
+
+// sendArticleUpdate announces the new version of an article that was
+// already posted to Discord, per OnArticleUpdate: as a new message titled
+// "Updated: ...", or by editing the messages recorded for the original post.
+// Articles without recorded messages are reposted instead.
+func (s *SummarizationScheduler) sendArticleUpdate(request SummarizationRequest, summary string) {
+	policy := s.config.Discord.OnArticleUpdate
+	webhookGroups := s.config.Discord.WebhookGroups()
+	if !tracksArticleUpdates(policy) || len(webhookGroups) == 0 {
+		return
+	}
+
+	feedURL, feedTitle, publishDate := s.getArticleDetails(request.ArticleURL)
+	if s.config.Discord.IsFeedExcluded(feedURL) {
+		return
+	}
+	articleMessage := ArticleMessage{
+		RequestID:    request.RequestID,
+		Title:        request.ArticleTitle,
+		URL:          request.ArticleURL,
+		Summary:      summary,
+		PublishDate:  publishDate,
+		FeedTitle:    feedTitle,
+		FeedURL:      feedURL,
+		Category:     request.Category,
+		Priority:     request.Priority,
+		SummaryLimit: contentSummaryLimit(s.config.Content, request.SummaryWords),
+	}
+
+	if policy == articleUpdateEdit {
+		messages, err := s.discordSender.postedMessages(request.ArticleURL)
+		if err != nil {
+			slog.Error("Failed to load Discord messages of article", "article_url", request.ArticleURL, "request_id", request.RequestID, "error", err)
+		}
+		if len(messages) > 0 {
+			edited := 0
+			for _, message := range messages {
+				ctx, cancel := context.WithTimeout(context.Background(), s.discordSender.sendTimeout())
+				err := s.discordSender.EditArticleMessage(ctx, message.webhookURL, message.messageID, articleMessage)
+				cancel()
+				if err != nil {
+					slog.Warn("Failed to edit Discord message", "message_id", message.messageID, "article_url", request.ArticleURL, "request_id", request.RequestID, "error", err)
+					continue
+				}
+				edited++
+			}
+			slog.Info("Edited Discord messages for updated article", "article_url", request.ArticleURL, "request_id", request.RequestID,
+				"edited", edited, "messages", len(messages))
+			return
+		}
+		slog.Info("No Discord messages recorded for updated article, reposting it instead", "article_url", request.ArticleURL, "request_id", request.RequestID)
+	}
+
+	articleMessage.Title = articleUpdatedPrefix + articleMessage.Title
+	successCount := s.sendToWebhookGroups(request, articleMessage, webhookGroups)
+	slog.Info("Reposted updated article", "article_url", request.ArticleURL, "request_id", request.RequestID,
+		"webhooks", len(webhookGroups), "successful", successCount)
+}
+
+// postedMessage is a Discord message recorded for an article.
+type postedMessage struct {
+	webhookURL string
+	messageID  string
+}
+
+// recordsMessageIDs reports whether posts should have Discord return their
+// message, so the edit policy can find them later.
+func (d *DiscordWebhookSender) recordsMessageIDs() bool {
+	return d.config != nil && d.config.OnArticleUpdate == articleUpdateEdit
+}
+
+// recordMessageID remembers the message an article was posted as on
+// webhookURL, replacing any earlier one.
+func (d *DiscordWebhookSender) recordMessageID(articleURL, webhookURL, messageID string) {
+	if d.db == nil || messageID == "" {
+		return
+	}
+	_, err := d.db.Exec(`
+		INSERT INTO discord_messages (article_url, webhook_url, message_id, posted_at)
+		VALUES ($1, $2, $3, NOW())
+		ON CONFLICT (article_url, webhook_url) DO UPDATE
+		SET message_id = EXCLUDED.message_id, posted_at = EXCLUDED.posted_at`,
+		articleURL, webhookURL, messageID)
+	if err != nil {
+		slog.Error("Failed to record Discord message", "message_id", messageID, "article_url", articleURL, "error", err)
+	}
+}
+
+// postedMessages returns the messages recorded for an article.
+func (d *DiscordWebhookSender) postedMessages(articleURL string) ([]postedMessage, error) {
+	if d.db == nil {
+		return nil, nil
+	}
+	rows, err := d.db.Query(`SELECT webhook_url, message_id FROM discord_messages WHERE article_url = $1`, articleURL)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var messages []postedMessage
+	for rows.Next() {
+		var message postedMessage
+		if err := rows.Scan(&message.webhookURL, &message.messageID); err != nil {
+			return nil, err
+		}
+		messages = append(messages, message)
+	}
+	return messages, rows.Err()
+}
+
+// EditArticleMessage replaces the content of a message previously posted
+// through webhookURL with the given article.
+func (d *DiscordWebhookSender) EditArticleMessage(ctx context.Context, webhookURL, messageID string, article ArticleMessage) error {
+	message := d.createDiscordMessage(article)
+	message.ThreadName = "" // Only a new post can start a thread
+	_, err := d.sendMessageWithRetry(ctx, http.MethodPatch, webhookMessageURL(webhookURL, messageID), message, article.Title, article.URL, article.RequestID)
+	return err
+}
+
+// waitForMessageURL adds wait=true to a webhook URL, which makes Discord
+// answer a post with the created message instead of 204 No Content.
+func waitForMessageURL(webhookURL string) string {
+	u, err := url.Parse(webhookURL)
+	if err != nil {
+		return webhookURL
+	}
+	query := u.Query()
+	query.Set("wait", "true")
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// threadURL adds thread_id to a webhook URL, which makes Discord post into
+// that thread of the webhook's channel. An empty threadID leaves it as is.
+func threadURL(webhookURL, threadID string) string {
+	if threadID == "" {
+		return webhookURL
+	}
+	u, err := url.Parse(webhookURL)
+	if err != nil {
+		return webhookURL
+	}
+	query := u.Query()
+	query.Set("thread_id", threadID)
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// webhookMessageURL returns the URL of a message posted through webhookURL,
+// keeping its thread_id so messages in threads are found.
+func webhookMessageURL(webhookURL, messageID string) string {
+	u, err := url.Parse(webhookURL)
+	if err != nil {
+		return strings.TrimSuffix(webhookURL, "/") + "/messages/" + messageID
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/messages/" + url.PathEscape(messageID)
+	query := u.Query()
+	query.Del("wait")
+	u.RawQuery = query.Encode()
+	return u.String()
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"information-broker/config"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// discordCall is one request received by the Discord stub.
+type discordCall struct {
+	method string
+	path   string
+	wait   bool
+	title  string
+}
+
+func TestArticleUpdatePolicies(t *testing.T) {
+	tests := []struct {
+		name       string
+		policy     string
+		recorded   bool // A message id is on record for the original post
+		wantMethod string
+		wantPath   string
+		wantTitle  string
+	}{
+		{"ignore", "ignore", false, "", "", ""},
+		{"repost", "repost", false, http.MethodPost, "/webhook", "Updated: Gateway advisory"},
+		{"edit", "edit", true, http.MethodPatch, "/webhook/messages/m-1", "Gateway advisory"},
+		{"edit without a recorded message reposts", "edit", false, http.MethodPost, "/webhook", "Updated: Gateway advisory"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var description atomic.Value
+			description.Store("The vendor is investigating.")
+			mux := http.NewServeMux()
+			var feedURL, articleURL string
+			mux.HandleFunc("/feed", func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprintf(w, `<?xml version="1.0"?><rss version="2.0"><channel><title>Test</title>
+					<item><title>Gateway advisory</title><link>%s</link><pubDate>%s</pubDate><description>%s</description></item>
+					</channel></rss>`, articleURL, time.Now().UTC().Format(time.RFC1123Z), description.Load())
+			})
+			mux.HandleFunc("/article", func(w http.ResponseWriter, r *http.Request) {
+				io.WriteString(w, "<html><body><article>"+strings.Repeat(description.Load().(string)+" ", 20)+"</article></body></html>")
+			})
+			feeds := httptest.NewServer(mux)
+			defer feeds.Close()
+			feedURL, articleURL = feeds.URL+"/feed", feeds.URL+"/article"
+
+			var mu sync.Mutex
+			var calls []discordCall
+			discord := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var msg DiscordWebhookMessage
+				json.NewDecoder(r.Body).Decode(&msg)
+				call := discordCall{method: r.Method, path: r.URL.Path, wait: r.URL.Query().Get("wait") == "true"}
+				if len(msg.Embeds) > 0 {
+					call.title = msg.Embeds[0].Title
+				}
+				mu.Lock()
+				calls = append(calls, call)
+				mu.Unlock()
+				if call.wait || r.Method == http.MethodPatch {
+					json.NewEncoder(w).Encode(map[string]string{"id": "m-2"})
+					return
+				}
+				w.WriteHeader(http.StatusNoContent)
+			}))
+			defer discord.Close()
+			received := func() []discordCall {
+				mu.Lock()
+				defer mu.Unlock()
+				return append([]discordCall(nil), calls...)
+			}
+
+			var hits int32
+			ollama := newOllamaStub(t, http.StatusOK, "The vendor has shipped a fix.", &hits)
+
+			db, recorder := openExecRecorder(t)
+			recorder.answer("RETURNING COALESCE(posted_to_discord", []driver.Value{true})
+			recorder.answer("SELECT feed_url, publish_date", []driver.Value{feedURL, time.Now()})
+			if tt.recorded {
+				recorder.answer("FROM discord_messages", []driver.Value{discord.URL + "/webhook", "m-1"})
+			} else {
+				recorder.answer("FROM discord_messages")
+			}
+
+			metrics := testMetrics()
+			cfg := &config.Config{
+				API:         config.APIConfig{Timeout: 5 * time.Second},
+				OLLAMA:      config.OLLAMAConfig{Model: "llama3", MaxRetries: 1},
+				Discord:     config.DiscordConfig{WebhookURL: discord.URL + "/webhook", Timeout: 5 * time.Second, OnArticleUpdate: tt.policy},
+				Performance: config.PerformanceConfig{MaxConcurrentFeeds: 1, MaxArticleContentLength: 10000},
+			}
+			summarizer := newFallbackTestSummarizer(ollama.URL, nil, NewCircuitBreakerManager())
+			summarizer.metrics = metrics
+			summarizer.config.OLLAMA.MaxRetries = 1
+			s := &SummarizationScheduler{
+				db:         db,
+				config:     cfg,
+				metrics:    metrics,
+				summarizer: summarizer,
+				discordSender: &DiscordWebhookSender{
+					db:         db,
+					httpClient: &http.Client{Timeout: 5 * time.Second},
+					metrics:    metrics,
+					config:     &cfg.Discord,
+				},
+				deferredPosts: &deferredPostQueue{},
+				queueCap:      10,
+				queueReady:    make(chan struct{}, 1),
+			}
+			m := NewRSSMonitor(db, []Feed{{URL: feedURL}}, metrics, cfg, NewCircuitBreakerManager(), s, nil)
+			m.seenArticles[articleURL] = true // Posted in an earlier run
+
+			// The first sighting is the baseline; the second carries the update
+			m.fetchFeed(context.Background(), feedURL)
+			if depth := s.getQueueDepth(); depth != 0 {
+				t.Fatalf("queue depth = %d after an unchanged sighting, want 0", depth)
+			}
+			description.Store("The vendor has shipped a fix.")
+			m.fetchFeed(context.Background(), feedURL)
+
+			request, ok := s.dequeue()
+			if tt.wantMethod == "" {
+				if ok {
+					t.Fatalf("ignore policy queued %+v", request)
+				}
+				return
+			}
+			if !ok || !request.Update || request.ArticleURL != articleURL {
+				t.Fatalf("dequeued (%+v, %v), want the update of %s", request, ok, articleURL)
+			}
+			s.handleRequest(context.Background(), request, SummarizationSchedulerConfig{MaxRetries: 1, WorkerTimeout: 5 * time.Second})
+
+			deadline := time.Now().Add(5 * time.Second)
+			for len(received()) < 1 && time.Now().Before(deadline) {
+				time.Sleep(10 * time.Millisecond)
+			}
+			got := received()
+			if len(got) != 1 || got[0].method != tt.wantMethod || got[0].path != tt.wantPath || got[0].title != tt.wantTitle {
+				t.Fatalf("Discord received %+v, want one %s %s titled %q", got, tt.wantMethod, tt.wantPath, tt.wantTitle)
+			}
+
+			// Reposts under the edit policy record their message for next time
+			if tt.policy == "edit" && !tt.recorded {
+				if !got[0].wait {
+					t.Error("repost under the edit policy should ask Discord for the message")
+				}
+				var recordedID bool
+				for _, args := range recorder.recordedWrites("INSERT INTO discord_messages") {
+					recordedID = recordedID || (args[0] == articleURL && args[2] == "m-2")
+				}
+				if !recordedID {
+					t.Errorf("message id of the repost wasn't recorded: %v", recorder.recordedWrites("INSERT"))
+				}
+			}
+		})
+	}
+}
+
+func TestWebhookMessageURL(t *testing.T) {
+	got := webhookMessageURL("https://discord.com/api/webhooks/1/tok?thread_id=9&wait=true", "42")
+	if want := "https://discord.com/api/webhooks/1/tok/messages/42?thread_id=9"; got != want {
+		t.Errorf("webhookMessageURL() = %q, want %q", got, want)
+	}
+	if got := waitForMessageURL("https://discord.com/api/webhooks/1/tok"); got != "https://discord.com/api/webhooks/1/tok?wait=true" {
+		t.Errorf("waitForMessageURL() = %q", got)
+	}
+}
@@ -13,23 +13,47 @@ import (
 	"information-broker/config"
 )
 
+// deferredBackfillPattern selects articles flagged needs_content_refetch
+// instead of matching by URL.
+const deferredBackfillPattern = "--deferred"
+
+// previewBackfillPattern fills in the preview of articles saved before the
+// preview column existed, and rebuilds previews saved with feed markup in
+// them, from their stored content, without fetching.
+const previewBackfillPattern = "--previews"
+
+// previewBackfillBatch is how many articles backfillPreviews reads at a time.
+const previewBackfillBatch = 500
+
 // runBackfill re-fetches and re-extracts every article whose URL matches
-// pattern (e.g. "theregister.com") with the current extractMainContent,
-// updates full_content, and clears summary so the pipeline regenerates it.
+// pattern (e.g. "theregister.com") with the current extractor (domain rules
+// from CONTENT_EXTRACTION_RULES_FILE, then extractMainContent),
+// updates full_content and preview, and clears summary so the pipeline regenerates it.
 // One-off maintenance command: `information-broker backfill <pattern>`.
 func runBackfill(db *sql.DB, cfg *config.Config, pattern string) error {
-	rows, err := db.Query(`SELECT id, url FROM articles WHERE url ILIKE '%' || $1 || '%' ORDER BY id`, pattern)
+	if pattern == previewBackfillPattern {
+		return backfillPreviews(db, cfg.Content.PreviewLength)
+	}
+
+	var rows *sql.Rows
+	var err error
+	if pattern == deferredBackfillPattern {
+		rows, err = db.Query(`SELECT id, url, COALESCE(feed_url, '') FROM articles WHERE needs_content_refetch ORDER BY id`)
+	} else {
+		rows, err = db.Query(`SELECT id, url, COALESCE(feed_url, '') FROM articles WHERE url ILIKE '%' || $1 || '%' ORDER BY id`, pattern)
+	}
 	if err != nil {
 		return fmt.Errorf("select: %w", err)
 	}
 	type item struct {
-		id  int64
-		url string
+		id      int64
+		url     string
+		feedURL string
 	}
 	var items []item
 	for rows.Next() {
 		var it item
-		if err := rows.Scan(&it.id, &it.url); err != nil {
+		if err := rows.Scan(&it.id, &it.url, &it.feedURL); err != nil {
 			rows.Close()
 			return err
 		}
@@ -38,11 +62,16 @@ func runBackfill(db *sql.DB, cfg *config.Config, pattern string) error {
 	rows.Close()
 	log.Printf("backfill: %d articles matching %q", len(items), pattern)
 
+	extractor, err := LoadContentExtractor(cfg.Content.ExtractionRulesFile)
+	if err != nil {
+		return err
+	}
+
 	client := &http.Client{Timeout: 30 * time.Second}
 	maxLen := cfg.Performance.MaxArticleContentLength
 	updated, skipped, failed := 0, 0, 0
 	for i, it := range items {
-		content, err := backfillFetch(client, cfg.API.UserAgent, it.url, maxLen)
+		content, err := backfillFetch(client, extractor, cfg.API.UserAgent, it.url, it.feedURL, maxLen)
 		if err != nil {
 			log.Printf("  [%d/%d] id=%d FAIL: %v", i+1, len(items), it.id, err)
 			failed++
@@ -55,8 +84,8 @@ func runBackfill(db *sql.DB, cfg *config.Config, pattern string) error {
 			time.Sleep(2 * time.Second)
 			continue
 		}
-		if _, err := db.Exec(`UPDATE articles SET full_content=$1, summary=NULL, updated_at=NOW() WHERE id=$2`,
-			sanitizeUTF8(content), it.id); err != nil {
+		if _, err := db.Exec(`UPDATE articles SET full_content=$1, preview=$2, summary=NULL, needs_content_refetch=FALSE, updated_at=NOW() WHERE id=$3`,
+			sanitizeUTF8(content), buildArticlePreview(content, cfg.Content.PreviewLength), it.id); err != nil {
 			log.Printf("  id=%d UPDATE FAIL: %v", it.id, err)
 			failed++
 			continue
@@ -71,7 +100,53 @@ func runBackfill(db *sql.DB, cfg *config.Config, pattern string) error {
 	return nil
 }
 
-func backfillFetch(client *http.Client, ua, url string, maxLen int) (string, error) {
+// backfillPreviews sets the preview of every article that has content but
+// no preview, or one with HTML tags or entities left in it, built by
+// buildArticlePreview as processArticle would now.
+func backfillPreviews(db *sql.DB, previewLength int) error {
+	type item struct {
+		id      int64
+		content string
+	}
+	var lastID int64
+	updated := 0
+	for {
+		rows, err := db.Query(`SELECT id, full_content FROM articles WHERE (preview IS NULL OR preview ~ '<[a-zA-Z/]|&#?[a-zA-Z0-9]+;') AND full_content IS NOT NULL AND id > $1 ORDER BY id LIMIT $2`,
+			lastID, previewBackfillBatch)
+		if err != nil {
+			return fmt.Errorf("select: %w", err)
+		}
+		var items []item
+		for rows.Next() {
+			var it item
+			if err := rows.Scan(&it.id, &it.content); err != nil {
+				rows.Close()
+				return err
+			}
+			items = append(items, it)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		for _, it := range items {
+			if _, err := db.Exec(`UPDATE articles SET preview=$1 WHERE id=$2`, buildArticlePreview(it.content, previewLength), it.id); err != nil {
+				return fmt.Errorf("update id=%d: %w", it.id, err)
+			}
+			updated++
+			lastID = it.id
+		}
+		if len(items) < previewBackfillBatch {
+			break
+		}
+		log.Printf("  progress: %d previews set", updated)
+	}
+	log.Printf("backfill done: %d previews set", updated)
+	return nil
+}
+
+func backfillFetch(client *http.Client, extractor *ContentExtractor, ua, url, feedURL string, maxLen int) (string, error) {
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
 		return "", err
@@ -89,7 +164,7 @@ func backfillFetch(client *http.Client, ua, url string, maxLen int) (string, err
 	if err != nil {
 		return "", err
 	}
-	content := strings.TrimSpace(extractMainContent(doc))
+	content := strings.TrimSpace(extractor.Extract(doc, url, feedURL))
 	if len(content) > maxLen {
 		content = safeTruncate(content, maxLen) + "..."
 	}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"database/sql/driver"
+	"information-broker/config"
+	"strings"
+	"testing"
+)
+
+func TestBackfillPreviews(t *testing.T) {
+	db, recorder := openExecRecorder(t)
+	recorder.answer("WHERE (preview IS NULL",
+		[]driver.Value{int64(3), "Short   article\n\ntext."},
+		[]driver.Value{int64(5), strings.Repeat("A sentence of the article. ", 10)})
+
+	cfg := &config.Config{Content: config.ContentConfig{PreviewLength: 40}}
+	if err := runBackfill(db, cfg, previewBackfillPattern); err != nil {
+		t.Fatal(err)
+	}
+
+	updates := recorder.recorded()
+	if len(updates) != 2 {
+		t.Fatalf("%d previews set, want 2", len(updates))
+	}
+	if updates[0][0] != "Short article text." || updates[0][1] != int64(3) {
+		t.Errorf("first preview = %v, want the whitespace collapsed", updates[0])
+	}
+	if want := "A sentence of the article. A sentence of..."; updates[1][0] != want || updates[1][1] != int64(5) {
+		t.Errorf("second preview = %v, want %q cut at ARTICLE_PREVIEW_LENGTH", updates[1], want)
+	}
+}
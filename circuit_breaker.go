@@ -24,6 +24,11 @@ type CircuitBreakerConfig struct {
 	SuccessThreshold int           // Number of successes to close from half-open
 	Timeout          time.Duration // Time to wait before transitioning from open to half-open
 	ResetTimeout     time.Duration // Time to reset failure count in closed state
+
+	// HalfOpenMaxConcurrent caps how many executions may be in flight while
+	// half-open; the rest are rejected as if the breaker were open. Values
+	// below 1 are treated as 1.
+	HalfOpenMaxConcurrent int
 }
 
 // CircuitBreaker implements the circuit breaker pattern
@@ -33,8 +38,10 @@ type CircuitBreaker struct {
 	state           CircuitBreakerState
 	failureCount    int
 	successCount    int
+	halfOpenActive  int // Half-open probes admitted and not yet recorded
 	lastFailureTime time.Time
 	lastSuccessTime time.Time
+	store           *breakerStateStore // Persists transitions; nil unless LoadState was called
 	mutex           sync.RWMutex
 }
 
@@ -42,16 +49,19 @@ type CircuitBreaker struct {
 type CircuitBreakerManager struct {
 	breakers map[string]*CircuitBreaker
 	metrics  *PrometheusMetrics
+	store    *breakerStateStore      // Set by LoadState
+	restored map[string]breakerState // Loaded by LoadState, applied on creation
 	mutex    sync.RWMutex
 }
 
 var (
 	ErrCircuitBreakerOpen = errors.New("circuit breaker is open")
 	DefaultConfig         = CircuitBreakerConfig{
-		FailureThreshold: 5,
-		SuccessThreshold: 3,
-		Timeout:          time.Minute * 2,
-		ResetTimeout:     time.Minute * 5,
+		FailureThreshold:      5,
+		SuccessThreshold:      3,
+		Timeout:               time.Minute * 2,
+		ResetTimeout:          time.Minute * 5,
+		HalfOpenMaxConcurrent: 1,
 	}
 )
 
@@ -84,6 +94,15 @@ func (cbm *CircuitBreakerManager) GetOrCreateBreaker(name string, config *Circui
 		name:   name,
 		config: *config,
 		state:  StateClosed,
+		store:  cbm.store,
+	}
+
+	if saved, ok := cbm.restored[name]; ok {
+		breaker.restore(saved, time.Now())
+		delete(cbm.restored, name)
+		if cbm.metrics != nil {
+			cbm.metrics.UpdateCircuitBreakerState(name, breaker.state)
+		}
 	}
 
 	cbm.breakers[name] = breaker
@@ -115,22 +134,25 @@ type CircuitBreakerStatus struct {
 
 // Execute executes a function with circuit breaker protection
 func (cb *CircuitBreaker) Execute(fn func() error, metrics *PrometheusMetrics) error {
-	if !cb.canExecute() {
+	allowed, probe := cb.canExecute()
+	if !allowed {
 		return ErrCircuitBreakerOpen
 	}
 
 	err := fn()
 	if err != nil {
-		cb.recordFailure(metrics)
+		cb.recordFailure(metrics, probe)
 		return err
 	}
 
-	cb.recordSuccess(metrics)
+	cb.recordSuccess(metrics, probe)
 	return nil
 }
 
-// canExecute checks if the circuit breaker allows execution
-func (cb *CircuitBreaker) canExecute() bool {
+// canExecute checks if the circuit breaker allows execution. probe reports
+// that the execution was admitted as a half-open probe and holds one of the
+// HalfOpenMaxConcurrent slots until its result is recorded.
+func (cb *CircuitBreaker) canExecute() (allowed, probe bool) {
 	cb.mutex.Lock()
 	defer cb.mutex.Unlock()
 
@@ -142,30 +164,60 @@ func (cb *CircuitBreaker) canExecute() bool {
 		if !cb.lastFailureTime.IsZero() && now.Sub(cb.lastFailureTime) > cb.config.ResetTimeout {
 			cb.failureCount = 0
 		}
-		return true
+		return true, false
 
 	case StateOpen:
 		// Check if enough time has passed to transition to half-open
 		if now.Sub(cb.lastFailureTime) > cb.config.Timeout {
 			cb.state = StateHalfOpen
 			cb.successCount = 0
-			return true
+			cb.persist()
+			return cb.admitProbe(), true
 		}
-		return false
+		return false, false
 
 	case StateHalfOpen:
-		return true
+		if !cb.admitProbe() {
+			return false, false
+		}
+		return true, true
 
 	default:
+		return false, false
+	}
+}
+
+// admitProbe takes a half-open probe slot if one is free. Probes from an
+// earlier half-open period that are still in flight keep their slots, so a
+// breaker that flaps back to half-open doesn't admit a fresh batch alongside
+// them. Callers must hold cb.mutex.
+func (cb *CircuitBreaker) admitProbe() bool {
+	limit := cb.config.HalfOpenMaxConcurrent
+	if limit < 1 {
+		limit = 1
+	}
+	if cb.halfOpenActive >= limit {
 		return false
 	}
+	cb.halfOpenActive++
+	return true
+}
+
+// releaseProbe frees the slot held by a finished half-open probe. Callers
+// must hold cb.mutex.
+func (cb *CircuitBreaker) releaseProbe(probe bool) {
+	if probe && cb.halfOpenActive > 0 {
+		cb.halfOpenActive--
+	}
 }
 
 // recordFailure records a failure and updates circuit breaker state
-func (cb *CircuitBreaker) recordFailure(metrics *PrometheusMetrics) {
+func (cb *CircuitBreaker) recordFailure(metrics *PrometheusMetrics, probe bool) {
 	cb.mutex.Lock()
 	defer cb.mutex.Unlock()
 
+	cb.releaseProbe(probe)
+
 	cb.failureCount++
 	cb.lastFailureTime = time.Now()
 
@@ -187,17 +239,22 @@ func (cb *CircuitBreaker) recordFailure(metrics *PrometheusMetrics) {
 		}
 	}
 
-	// Update metrics if state changed
-	if metrics != nil && oldState != cb.state {
-		metrics.UpdateCircuitBreakerState(cb.name, cb.state)
+	// Update metrics and persisted state if state changed
+	if oldState != cb.state {
+		if metrics != nil {
+			metrics.UpdateCircuitBreakerState(cb.name, cb.state)
+		}
+		cb.persist()
 	}
 }
 
 // recordSuccess records a success and updates circuit breaker state
-func (cb *CircuitBreaker) recordSuccess(metrics *PrometheusMetrics) {
+func (cb *CircuitBreaker) recordSuccess(metrics *PrometheusMetrics, probe bool) {
 	cb.mutex.Lock()
 	defer cb.mutex.Unlock()
 
+	cb.releaseProbe(probe)
+
 	cb.lastSuccessTime = time.Now()
 	oldState := cb.state
 
@@ -217,9 +274,12 @@ func (cb *CircuitBreaker) recordSuccess(metrics *PrometheusMetrics) {
 		}
 	}
 
-	// Update metrics if state changed
-	if metrics != nil && oldState != cb.state {
-		metrics.UpdateCircuitBreakerState(cb.name, cb.state)
+	// Update metrics and persisted state if state changed
+	if oldState != cb.state {
+		if metrics != nil {
+			metrics.UpdateCircuitBreakerState(cb.name, cb.state)
+		}
+		cb.persist()
 	}
 }
 
@@ -247,6 +307,27 @@ func (cb *CircuitBreaker) GetStatus() CircuitBreakerStatus {
 	return status
 }
 
+// ReadyToTry reports whether Execute would run a call now: the breaker is
+// closed, half-open with a probe slot free, or open for longer than Timeout,
+// in which case the call becomes the half-open probe. Unlike IsHealthy it
+// sees an open breaker that is due a probe, for callers with nothing else
+// to trigger one.
+func (cb *CircuitBreaker) ReadyToTry() bool {
+	cb.mutex.RLock()
+	defer cb.mutex.RUnlock()
+
+	switch cb.state {
+	case StateClosed:
+		return true
+	case StateOpen:
+		return time.Since(cb.lastFailureTime) > cb.config.Timeout
+	case StateHalfOpen:
+		return cb.halfOpenActive < max(cb.config.HalfOpenMaxConcurrent, 1)
+	default:
+		return false
+	}
+}
+
 // IsHealthy returns true if the circuit breaker is in a healthy state
 func (cb *CircuitBreaker) IsHealthy() bool {
 	cb.mutex.RLock()
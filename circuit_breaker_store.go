@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// breakerStateWriteTimeout bounds each persisted state write so a slow
+// database can't back the writer up indefinitely.
+const breakerStateWriteTimeout = 5 * time.Second
+
+// breakerState is the part of a circuit breaker that survives a restart.
+type breakerState struct {
+	State           CircuitBreakerState
+	FailureCount    int
+	SuccessCount    int
+	LastFailureTime time.Time
+}
+
+// breakerStateStore writes breaker states to the circuit_breaker_state table
+// in the background. Breakers hand it their state on every transition and
+// move on; only the latest state per breaker is kept, so a stalled database
+// costs at most one pending write per breaker and never blocks execution.
+type breakerStateStore struct {
+	db      *sql.DB
+	mu      sync.Mutex
+	pending map[string]breakerState
+	ready   chan struct{} // Signalled (buffer 1) when pending has entries
+}
+
+func newBreakerStateStore(db *sql.DB) *breakerStateStore {
+	return &breakerStateStore{
+		db:      db,
+		pending: make(map[string]breakerState),
+		ready:   make(chan struct{}, 1),
+	}
+}
+
+// save queues the breaker's state for writing, replacing any not yet written.
+func (s *breakerStateStore) save(name string, state breakerState) {
+	s.mu.Lock()
+	s.pending[name] = state
+	s.mu.Unlock()
+
+	select {
+	case s.ready <- struct{}{}:
+	default:
+	}
+}
+
+// run writes queued states until the process exits. A failed write is logged
+// and dropped; the breaker's next transition will be written as usual.
+func (s *breakerStateStore) run() {
+	for range s.ready {
+		s.mu.Lock()
+		batch := s.pending
+		s.pending = make(map[string]breakerState)
+		s.mu.Unlock()
+
+		for name, state := range batch {
+			if err := s.write(name, state); err != nil {
+				slog.Error("Failed to persist circuit breaker state", "breaker", name, "error", err)
+			}
+		}
+	}
+}
+
+func (s *breakerStateStore) write(name string, state breakerState) error {
+	ctx, cancel := context.WithTimeout(context.Background(), breakerStateWriteTimeout)
+	defer cancel()
+
+	var lastFailure *time.Time
+	if !state.LastFailureTime.IsZero() {
+		lastFailure = &state.LastFailureTime
+	}
+
+	_, err := s.db.ExecContext(ctx, `
+		INSERT INTO circuit_breaker_state (name, state, failure_count, success_count, last_failure_time, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		ON CONFLICT (name) DO UPDATE SET
+			state = EXCLUDED.state,
+			failure_count = EXCLUDED.failure_count,
+			success_count = EXCLUDED.success_count,
+			last_failure_time = EXCLUDED.last_failure_time,
+			updated_at = NOW()`,
+		name, string(state.State), state.FailureCount, state.SuccessCount, lastFailure)
+	return err
+}
+
+// LoadState reads persisted breaker states from db and turns on persistence
+// for every breaker from now on. Breakers are created lazily, so the loaded
+// states are applied as each one is first requested by GetOrCreateBreaker.
+// Call it before anything starts executing through the manager.
+func (cbm *CircuitBreakerManager) LoadState(db *sql.DB) error {
+	rows, err := db.Query(`
+		SELECT name, state, failure_count, success_count, last_failure_time
+		FROM circuit_breaker_state`)
+	if err != nil {
+		return fmt.Errorf("failed to load circuit breaker state: %w", err)
+	}
+	defer rows.Close()
+
+	restored := make(map[string]breakerState)
+	for rows.Next() {
+		var name, state string
+		var saved breakerState
+		var lastFailure sql.NullTime
+		if err := rows.Scan(&name, &state, &saved.FailureCount, &saved.SuccessCount, &lastFailure); err != nil {
+			return fmt.Errorf("failed to scan circuit breaker state: %w", err)
+		}
+		saved.State = CircuitBreakerState(state)
+		if lastFailure.Valid {
+			saved.LastFailureTime = lastFailure.Time
+		}
+		restored[name] = saved
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to load circuit breaker state: %w", err)
+	}
+
+	store := newBreakerStateStore(db)
+	go store.run()
+
+	cbm.mutex.Lock()
+	cbm.restored = restored
+	cbm.store = store
+	cbm.mutex.Unlock()
+
+	slog.Info("Loaded circuit breaker state", "breakers", len(restored))
+	return nil
+}
+
+// restore applies a persisted state to a newly created breaker. An open
+// breaker whose timeout already ran out while the process was down comes
+// back half-open, so its first execution is a probe rather than a rejection.
+func (cb *CircuitBreaker) restore(saved breakerState, now time.Time) {
+	cb.mutex.Lock()
+	defer cb.mutex.Unlock()
+
+	cb.state = saved.State
+	cb.failureCount = saved.FailureCount
+	cb.successCount = saved.SuccessCount
+	cb.lastFailureTime = saved.LastFailureTime
+
+	switch cb.state {
+	case StateClosed, StateHalfOpen:
+	case StateOpen:
+		if now.Sub(cb.lastFailureTime) > cb.config.Timeout {
+			cb.state = StateHalfOpen
+			cb.successCount = 0
+		}
+	default:
+		// Unknown state from an older or hand-edited row; start fresh
+		cb.state = StateClosed
+		cb.failureCount = 0
+		cb.successCount = 0
+	}
+}
+
+// persist hands the breaker's current state to the store, if persistence is
+// on. Callers must hold cb.mutex.
+func (cb *CircuitBreaker) persist() {
+	if cb.store == nil {
+		return
+	}
+	cb.store.save(cb.name, breakerState{
+		State:           cb.state,
+		FailureCount:    cb.failureCount,
+		SuccessCount:    cb.successCount,
+		LastFailureTime: cb.lastFailureTime,
+	})
+}
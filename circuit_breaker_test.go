@@ -0,0 +1,168 @@
+package main
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newHalfOpenBreaker returns a breaker that has tripped and whose open
+// timeout has already elapsed, so the next execution finds it half-open.
+func newHalfOpenBreaker(t *testing.T, maxConcurrent int) *CircuitBreaker {
+	t.Helper()
+	cb := NewCircuitBreakerManager().GetOrCreateBreaker("test", &CircuitBreakerConfig{
+		FailureThreshold:      1,
+		SuccessThreshold:      1,
+		Timeout:               time.Millisecond,
+		ResetTimeout:          time.Hour,
+		HalfOpenMaxConcurrent: maxConcurrent,
+	})
+	cb.Execute(func() error { return errors.New("boom") }, nil)
+	time.Sleep(5 * time.Millisecond)
+	return cb
+}
+
+// startProbes runs n executions that block until release is closed and
+// returns once all of them have been admitted or rejected.
+func startProbes(cb *CircuitBreaker, n int, release <-chan struct{}) (admitted int, wait func()) {
+	var wg sync.WaitGroup
+	results := make(chan bool, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := cb.Execute(func() error {
+				results <- true
+				<-release
+				return nil
+			}, nil)
+			if errors.Is(err, ErrCircuitBreakerOpen) {
+				results <- false
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		if <-results {
+			admitted++
+		}
+	}
+	return admitted, wg.Wait
+}
+
+func TestCircuitBreakerHalfOpenLimit(t *testing.T) {
+	tests := []struct {
+		name          string
+		maxConcurrent int
+		want          int
+	}{
+		{"default of one", 0, 1},
+		{"one", 1, 1},
+		{"three", 3, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cb := newHalfOpenBreaker(t, tt.maxConcurrent)
+			release := make(chan struct{})
+
+			admitted, wait := startProbes(cb, 10, release)
+			close(release)
+			wait()
+
+			if admitted != tt.want {
+				t.Errorf("admitted %d concurrent half-open executions, want %d", admitted, tt.want)
+			}
+			if state := cb.GetStatus().State; state != StateClosed {
+				t.Errorf("state after successful probes = %s, want closed", state)
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerHalfOpenReleasesSlots(t *testing.T) {
+	cb := newHalfOpenBreaker(t, 1)
+	cb.config.SuccessThreshold = 2
+
+	if err := cb.Execute(func() error { return nil }, nil); err != nil {
+		t.Fatalf("first probe rejected: %v", err)
+	}
+	if state := cb.GetStatus().State; state != StateHalfOpen {
+		t.Fatalf("state after one success = %s, want half_open", state)
+	}
+	// The first probe's slot must be free again for the second
+	if err := cb.Execute(func() error { return nil }, nil); err != nil {
+		t.Fatalf("second probe rejected: %v", err)
+	}
+	if state := cb.GetStatus().State; state != StateClosed {
+		t.Errorf("state after two successes = %s, want closed", state)
+	}
+
+	// A failed probe also frees its slot once the breaker reopens and times out
+	cb = newHalfOpenBreaker(t, 1)
+	cb.Execute(func() error { return errors.New("still down") }, nil)
+	if state := cb.GetStatus().State; state != StateOpen {
+		t.Fatalf("state after failed probe = %s, want open", state)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if err := cb.Execute(func() error { return nil }, nil); err != nil {
+		t.Errorf("probe after reopening rejected: %v", err)
+	}
+}
+
+func TestCircuitBreakerRestore(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		saved     breakerState
+		wantState CircuitBreakerState
+	}{
+		{"open within timeout stays open", breakerState{State: StateOpen, FailureCount: 3, LastFailureTime: now.Add(-time.Minute)}, StateOpen},
+		{"open past timeout comes back half-open", breakerState{State: StateOpen, FailureCount: 3, SuccessCount: 1, LastFailureTime: now.Add(-time.Hour)}, StateHalfOpen},
+		{"closed stays closed", breakerState{State: StateClosed, FailureCount: 1, LastFailureTime: now.Add(-time.Hour)}, StateClosed},
+		{"unknown state resets", breakerState{State: "bogus", FailureCount: 9}, StateClosed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cb := &CircuitBreaker{name: "test", config: CircuitBreakerConfig{Timeout: 2 * time.Minute}, state: StateClosed}
+			cb.restore(tt.saved, now)
+
+			status := cb.GetStatus()
+			if status.State != tt.wantState {
+				t.Errorf("state = %s, want %s", status.State, tt.wantState)
+			}
+			if tt.wantState == StateHalfOpen && status.SuccessCount != 0 {
+				t.Errorf("half-open breaker restored with %d successes, want 0", status.SuccessCount)
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerPersistsTransitions(t *testing.T) {
+	manager := NewCircuitBreakerManager()
+	manager.store = newBreakerStateStore(nil) // Not running, so saves just queue
+	manager.restored = map[string]breakerState{
+		"restored": {State: StateOpen, FailureCount: 2, LastFailureTime: time.Now()},
+	}
+
+	if state := manager.GetOrCreateBreaker("restored", nil).GetStatus().State; state != StateOpen {
+		t.Errorf("restored breaker state = %s, want open", state)
+	}
+
+	cb := manager.GetOrCreateBreaker("test", &CircuitBreakerConfig{FailureThreshold: 2, SuccessThreshold: 1, Timeout: time.Hour, ResetTimeout: time.Hour})
+	cb.Execute(func() error { return errors.New("boom") }, nil)
+	if len(manager.store.pending) != 0 {
+		t.Fatalf("a failure without a transition should not be persisted: %v", manager.store.pending)
+	}
+
+	cb.Execute(func() error { return errors.New("boom") }, nil)
+	saved, ok := manager.store.pending["test"]
+	if !ok || saved.State != StateOpen || saved.FailureCount != 2 || saved.LastFailureTime.IsZero() {
+		t.Errorf("pending state = %+v (queued %v), want open with 2 failures", saved, ok)
+	}
+	select {
+	case <-manager.store.ready:
+	default:
+		t.Error("store was not signalled")
+	}
+}
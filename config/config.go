@@ -2,6 +2,7 @@ package config
 
 import (
 	"fmt"
+	"log/slog"
 	"os"
 	"strconv"
 	"strings"
@@ -15,6 +16,7 @@ type Config struct {
 	API           APIConfig
 	OLLAMA        OLLAMAConfig
 	Discord       DiscordConfig
+	Notifications NotificationsConfig
 	Prometheus    PrometheusConfig
 	Security      SecurityConfig
 	Performance   PerformanceConfig
@@ -22,6 +24,10 @@ type Config struct {
 	Summarization SummarizationConfig
 	Clustering    ClusteringConfig
 	FlareSolverr  FlareSolverrConfig
+	Events        EventsConfig
+
+	fileErr   error // Set by Load when CONFIG_FILE can't be read; reported by Validate
+	secretErr error // Set by Load when a *_FILE secret can't be read; reported by Validate
 }
 
 // DatabaseConfig holds database-related configuration
@@ -31,22 +37,96 @@ type DatabaseConfig struct {
 	User     string
 	Password string
 	Name     string
+
+	// TLS for the connection. SSLMode is a libpq sslmode the driver
+	// supports: disable, require, verify-ca or verify-full. The paths are
+	// optional: a CA bundle to verify the server against, and a client
+	// certificate and key.
+	SSLMode     string
+	SSLRootCert string
+	SSLCert     string
+	SSLKey      string
+
+	// Connection pool bounds, applied when the pool is opened. 0 leaves
+	// database/sql's default: unlimited open connections, 2 idle ones and
+	// no lifetime limit.
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
 }
 
 // AppConfig holds general application configuration
 type AppConfig struct {
 	Port              int
 	RSSFetchInterval  time.Duration
+	MinFeedRefetch    time.Duration // Minimum gap between two fetches of one feed, whatever triggered them (0 = no guard)
 	RSSFeedsFile      string
-	LogLevel          string
+	RSSFeedsReload    time.Duration // How often RSSFeedsFile is checked for changes, which are applied without a restart (0 = read once at startup)
+	LogLevel          string        // debug, info, warn or error (see ParseLogLevel)
 	InitiationDate    time.Time
 	ArticleCutoffDate time.Time
+
+	// PersistCircuitBreakers saves circuit breaker state to the database on
+	// every transition and restores it at startup, so a restart doesn't
+	// forget which feeds and backends were failing.
+	PersistCircuitBreakers bool
+
+	// StartupSelfTest pings the database, runs a tiny summarization and, if
+	// SelfTestDiscordWebhook is set, posts a test message there before the
+	// service starts. Failures are logged, and abort startup if SelfTestAbort.
+	StartupSelfTest        bool
+	SelfTestAbort          bool
+	SelfTestDiscordWebhook string
+
+	// FeedFetchRetries is how many times a feed fetch that failed with a
+	// transient error (unparseable body, 5xx, connection error) is retried
+	// immediately, waiting FeedFetchRetryBackoff doubled each time, before
+	// the failure counts against the feed's circuit breaker.
+	FeedFetchRetries      int
+	FeedFetchRetryBackoff time.Duration
+
+	// AdaptiveFetchMaxMultiplier caps how far the feed polling intervals are
+	// stretched, doubling per health check, while at least
+	// AdaptiveFetchFailureRatio of the feeds have an open circuit breaker or
+	// the database is unhealthy (1 = never stretch; a zero ratio only reacts
+	// to the database).
+	AdaptiveFetchFailureRatio  float64
+	AdaptiveFetchMaxMultiplier int
+
+	// MaxArticlesPerHour caps the new articles taken in per clock hour
+	// across all feeds; the rest are left for later fetches (0 = no cap).
+	MaxArticlesPerHour int
+
+	// DeadFeedThreshold disables a feed whose fetches have all failed for
+	// this long: it isn't polled again until re-enabled through
+	// /admin/feeds/enable (0 = never disable).
+	DeadFeedThreshold time.Duration
+
+	// ValidateOnly checks the configuration, database and feeds file,
+	// reports, and exits instead of starting; ValidateCheckFeeds also
+	// sends every feed a HEAD request. Same as --validate [--check-feeds].
+	ValidateOnly       bool
+	ValidateCheckFeeds bool
+
+	// Per-feed SLAs reported by /feeds/sla over the last SLAWindow: the share
+	// of fetches that succeed, the median time a summarization takes, and
+	// the share of posted articles that reached Discord within SLAPostWithin
+	// of their publish date.
+	SLAWindow           time.Duration
+	SLAFetchSuccessRate float64
+	SLASummaryLatency   time.Duration
+	SLAPostWithin       time.Duration
+	SLAPostedOnTimeRate float64
 }
 
 // APIConfig holds API-related configuration
 type APIConfig struct {
 	Timeout   time.Duration
 	UserAgent string
+
+	// UserAgents, when set, are rotated through for feed and article page
+	// requests instead of UserAgent, for publishers that block a fixed one.
+	UserAgents []string
 }
 
 // FlareSolverrConfig holds settings for the optional FlareSolverr challenge
@@ -57,21 +137,108 @@ type FlareSolverrConfig struct {
 	Timeout time.Duration
 }
 
+// EventsConfig holds settings for the pipeline event stream: a compact JSON
+// event per article ingested, summarized and posted and per failed feed
+// fetch, published to Topic on a NATS server. Brokers are nats:// or tls://
+// server URLs; none disables the stream. At most one of the credential
+// settings applies; the TLS files are needed only for a private CA or
+// client certificates.
+type EventsConfig struct {
+	Brokers    []string
+	Topic      string
+	BufferSize int // Events held while the broker is slow or down; further ones are dropped
+
+	User         string
+	Password     string
+	Token        string
+	NKeySeedFile string // NKey seed to sign the server's challenge with
+	CredsFile    string // Decentralized-auth credentials file (JWT and NKey seed)
+
+	TLSCAFile   string // CA bundle the server certificate is verified against
+	TLSCertFile string // Client certificate, when the server requires one
+	TLSKeyFile  string
+}
+
 // OLLAMAConfig holds OLLAMA AI service configuration
 type OLLAMAConfig struct {
-	URL        string
-	Model      string
-	Timeout    time.Duration
-	MaxRetries int
+	Backend       string // API spoken by URL and FallbackURLs: "ollama" (/api/generate) or "openai" (/v1/chat/completions)
+	APIKey        string // Bearer token sent to "openai" backends; unused by Ollama
+	URL           string
+	FallbackURLs  []string // Tried in order when the primary URL errors or its circuit breaker is open
+	Model         string
+	AllowedModels []string      // Models summarization may use; empty allows any
+	Timeout       time.Duration // Bounds a single call to one backend; a timed-out call is retried if the request budget allows
+	MaxRetries    int
+	Stream        bool // Use Ollama's streaming mode and stop reading once the summary is past the word limit
 }
 
 // DiscordConfig holds Discord webhook configuration
 type DiscordConfig struct {
 	WebhookURL    string   // Deprecated: Use WebhookURLs for multiple webhooks
-	WebhookURLs   []string // Multiple webhook URLs for multi-cast notifications
+	WebhookURLs   []string // Multiple webhook URLs for multi-cast notifications; "primary|backup" entries add failover (see WebhookGroups)
 	ExcludedFeeds []string // Feed-URL substrings whose articles are never posted to Discord
+	FeedThreads   []string // "feed-substring=thread" entries routing a feed's posts into a thread (see FeedThread)
+	FeedBranding  []string // "feed-substring=color:#hex;username:Name;avatar:URL" entries styling a feed's posts (see Branding)
 	MaxRetries    int
 	Timeout       time.Duration
+
+	// Quiet hours: posts that would go out inside the window are held and
+	// released once it ends. QuietHours is "HH:MM-HH:MM" (may wrap midnight),
+	// evaluated in DisplayTimezone; empty disables the feature.
+	QuietHours               string
+	DisplayTimezone          string
+	DeferredPostTTL          time.Duration // Held posts older than this are dropped instead of released (0 = never drop)
+	DeferredReleasePerMinute int           // Upper bound on held posts released per minute after quiet hours
+
+	ShowPublishDateField bool // Add an explicit "Published" embed field, formatted in DisplayTimezone
+
+	// Digest mode: articles summarized within DigestInterval of each other
+	// are sent together, up to DigestMaxEmbeds per message (capped at
+	// Discord's 10), with DigestMessageInterval between the messages of one
+	// digest. 0 sends each one right away. Digests don't go through the
+	// Discord breaker.
+	DigestInterval        time.Duration
+	DigestMaxEmbeds       int
+	DigestMessageInterval time.Duration
+
+	// Breaker over the whole post step: after BreakerFailureThreshold posts in
+	// a row reach no webhook, posts are skipped and flagged for replay until
+	// BreakerTimeout passes and a probe succeeds. 0 disables the breaker.
+	BreakerFailureThreshold int
+	BreakerTimeout          time.Duration
+
+	// MinPostSpacing is the least time between two posts to the same
+	// webhook; posts arriving faster wait their turn in the background. 0
+	// posts as fast as they come.
+	MinPostSpacing time.Duration
+
+	// OnArticleUpdate is what happens when a feed changes an article that was
+	// already posted: "ignore" it, "repost" it as a new "Updated:" message,
+	// or "edit" the original messages in place (falling back to a repost for
+	// posts made before their message ids were recorded).
+	OnArticleUpdate string
+
+	// Importance highlighting: an article scores ImportanceKeywordWeight for
+	// each ImportantKeywords entry found in its title or summary, plus
+	// ImportancePriorityWeight times its feed's priority. At or above
+	// ImportanceThreshold its embed is colored red and, with ImportantRoleID
+	// set, the post mentions that role. A threshold of 0 disables it.
+	ImportantKeywords        []string
+	ImportanceKeywordWeight  float64
+	ImportancePriorityWeight float64
+	ImportanceThreshold      float64
+	ImportantRoleID          string
+}
+
+// NotificationsConfig holds settings for generic (non-Discord) webhooks that
+// receive every summarized article as JSON. Format is "raw" for the bare
+// article object or "cloudevents" for a CloudEvents 1.0 structured-mode
+// envelope whose source attribute is Source.
+type NotificationsConfig struct {
+	WebhookURLs []string
+	Format      string
+	Source      string
+	Timeout     time.Duration
 }
 
 // PrometheusConfig holds Prometheus metrics configuration
@@ -84,6 +251,8 @@ type SecurityConfig struct {
 	CORSAllowedOrigins string
 	CORSAllowedMethods string
 	CORSAllowedHeaders string
+	AdminToken         string // Bearer token required by the admin endpoints; with AdminSecret also empty they are disabled
+	AdminSecret        string // Key of the X-Signature HMAC-SHA256 of the request required on the admin endpoints
 }
 
 // PerformanceConfig holds performance-related configuration
@@ -93,22 +262,110 @@ type PerformanceConfig struct {
 	HTTPReadTimeout         time.Duration
 	HTTPWriteTimeout        time.Duration
 	HTTPIdleTimeout         time.Duration
+	PerFeedContentBudget    time.Duration // Cap on a feed's cumulative full-content fetch time per cycle (0 = unlimited)
+
+	// Memory guards for full-content fetches: MaxRawContentBytes caps how much
+	// of each page's raw HTML is read (0 = unlimited), and
+	// MaxConcurrentContentFetches bounds fetches in flight across all feeds.
+	// MaxResponseBytes caps feed responses the same way.
+	MaxRawContentBytes          int64
+	MaxConcurrentContentFetches int
+	MaxResponseBytes            int64
+
+	// Connection pool of the feed and article page HTTP client: idle
+	// connections kept overall and per host, the cap on connections per
+	// host (0 = unlimited), and how long an idle connection is kept.
+	FeedMaxIdleConns        int
+	FeedMaxIdleConnsPerHost int
+	FeedMaxConnsPerHost     int
+	FeedIdleConnTimeout     time.Duration
 }
 
 // ContentConfig holds content processing configuration
 type ContentConfig struct {
-	MaxSummaryLength     int
+	MaxSummaryLength     int    // Summary length limit, in SummaryLimitUnit
+	SummaryLimitUnit     string // "words" or "characters"; the Discord embed is cut by the same limit
 	ContentHashAlgorithm string
+	PromptInjectionGuard bool // Neutralize instruction-like text in articles and reject summaries that look hijacked
+	PreviewLength        int  // Max characters of cleaned content stored as the list-view preview
+
+	// SummaryLanguageCheck rejects summaries whose detected language differs
+	// from the article's, retrying with an explicit language instruction.
+	// ArticleLanguage pins the expected language (e.g. "fr"); empty detects it
+	// per article. SummaryLanguage picks the language the prompt asks for:
+	// "auto" the article's when it isn't English, "source" always the
+	// article's, or a code such as "en" for that language.
+	SummaryLanguageCheck bool
+	ArticleLanguage      string
+	SummaryLanguage      string
+
+	// SummaryIncludeTitle and SummaryIncludeLead add the article title and
+	// the feed's lead/standfirst to the summarization prompt as labeled
+	// lines ahead of the body.
+	SummaryIncludeTitle bool
+	SummaryIncludeLead  bool
+
+	// SummaryMinWords and SummaryMaxTitleSimilarity reject, as a retriable
+	// failure, summaries shorter than that many words or whose words overlap
+	// the article title by more than that fraction (0-1); 0 disables each.
+	SummaryMinWords           int
+	SummaryMaxTitleSimilarity float64
+
+	// FeedContentMinLength skips the article page fetch when the feed item's
+	// own full content (content:encoded, Atom content, JSON Feed content) has
+	// at least this many characters of text; 0 always fetches the page.
+	FeedContentMinLength int
+
+	// ExtractionRulesFile is a YAML or JSON file of per-domain CSS selectors
+	// tried before the generic ones when extracting a fetched article.
+	ExtractionRulesFile string
+
+	// TrackingParams are query parameters stripped from article links before
+	// deduplication; a trailing "*" matches by prefix (e.g. "utm_*").
+	TrackingParams []string
+
+	// SummaryGracePeriod keeps retrying, every SummaryGraceRetryInterval,
+	// the summarization of articles from SummaryGraceFeeds (feed-URL
+	// substrings; empty means every feed) that failed, and posts the
+	// article to Discord without a summary once it expires. 0 disables it.
+	SummaryGracePeriod        time.Duration
+	SummaryGraceRetryInterval time.Duration
+	SummaryGraceFeeds         []string
+
+	// StoreRawItem keeps each new article's feed item, as JSON, alongside
+	// the article for debugging and reprocessing. Off by default: items
+	// often carry the full content a second time.
+	StoreRawItem bool
+
+	// NearDuplicateIndex stores a SimHash fingerprint of each new article's
+	// content, indexed by band, for fast near-duplicate lookup.
+	NearDuplicateIndex bool
+
+	// RespectRobotsTxt skips fetching article pages that the site's
+	// robots.txt disallows for API.UserAgent, using the feed's own content
+	// instead. Each host's robots.txt is cached for RobotsTxtCacheTTL.
+	RespectRobotsTxt  bool
+	RobotsTxtCacheTTL time.Duration
 }
 
 // SummarizationConfig holds summarization scheduler configuration
 type SummarizationConfig struct {
 	MaxQueueSize      int
-	WorkerTimeout     time.Duration
+	WorkerTimeout     time.Duration // Overall budget for one request, covering every attempt and backoff
 	MaxRetries        int
 	RetryBackoffBase  time.Duration
 	MetricsInterval   time.Duration
 	QueuePurgeTimeout time.Duration
+
+	// Fairness serves queued articles of equal priority round-robin across
+	// feeds instead of first come, first served.
+	Fairness bool
+
+	// DeadLetterReplayInterval is how often articles whose summarization
+	// failed are re-queued at low priority (0 = never). Each article is
+	// replayed at most DeadLetterMaxAttempts times, then abandoned.
+	DeadLetterReplayInterval time.Duration
+	DeadLetterMaxAttempts    int
 }
 
 // ClusteringConfig holds configuration for the precomputed story-clustering scheduler.
@@ -120,44 +377,123 @@ type ClusteringConfig struct {
 	EmbedModel          string
 }
 
-// Load loads configuration from environment variables
+// Load loads configuration from environment variables. If CONFIG_FILE names
+// a YAML file, its settings fill in for any variables that aren't set, so a
+// base file per environment can be overridden piecemeal from the environment.
+// A file that can't be loaded is reported by Validate.
 func Load() *Config {
-	return &Config{
+	var fileErr error
+	fileValues, fileErr = loadConfigFile(os.Getenv("CONFIG_FILE"))
+	secretFileErr = nil
+
+	cfg := &Config{
 		Database: DatabaseConfig{
 			Host:     getEnv("DB_HOST", "localhost"),
 			Port:     getEnv("DB_PORT", "5432"),
 			User:     getEnv("DB_USER", "postgres"),
-			Password: getEnv("DB_PASSWORD", "postgres"),
+			Password: getEnvFromFileOrValue("DB_PASSWORD", "DB_PASSWORD_FILE", "postgres"),
 			Name:     getEnv("DB_NAME", "information_broker"),
+
+			SSLMode:     getEnv("DB_SSLMODE", "disable"),
+			SSLRootCert: getEnv("DB_SSLROOTCERT", ""),
+			SSLCert:     getEnv("DB_SSLCERT", ""),
+			SSLKey:      getEnv("DB_SSLKEY", ""),
+
+			MaxOpenConns:    getEnvInt("DB_MAX_OPEN_CONNS", 25),
+			MaxIdleConns:    getEnvInt("DB_MAX_IDLE_CONNS", 5),
+			ConnMaxLifetime: getEnvDuration("DB_CONN_MAX_LIFETIME", 30*time.Minute),
 		},
 		App: AppConfig{
 			Port:              getEnvInt("APP_PORT", 8080),
 			RSSFetchInterval:  getEnvDuration("RSS_FETCH_INTERVAL", 5*time.Minute),
+			MinFeedRefetch:    getEnvDuration("MIN_FEED_REFETCH_INTERVAL", 30*time.Second),
 			RSSFeedsFile:      getEnv("RSS_FEEDS_FILE", "/app/feeds.txt"),
+			RSSFeedsReload:    getEnvDuration("RSS_FEEDS_RELOAD_INTERVAL", 30*time.Second),
 			LogLevel:          getEnv("LOG_LEVEL", "info"),
 			InitiationDate:    getEnvTime("APP_INITIATION_DATE", time.Date(2025, 5, 31, 0, 0, 0, 0, time.UTC)),
 			ArticleCutoffDate: getEnvTime("ARTICLE_CUTOFF_DATE", time.Date(2025, 5, 31, 0, 0, 0, 0, time.UTC)),
+
+			PersistCircuitBreakers: getEnvBool("CIRCUIT_BREAKER_PERSIST", false),
+			StartupSelfTest:        getEnvBool("STARTUP_SELF_TEST", false),
+			SelfTestAbort:          getEnvBool("STARTUP_SELF_TEST_ABORT", false),
+			SelfTestDiscordWebhook: getEnvFromFileOrValue("STARTUP_SELF_TEST_DISCORD_WEBHOOK", "STARTUP_SELF_TEST_DISCORD_WEBHOOK_FILE", ""),
+
+			FeedFetchRetries:      getEnvInt("FEED_FETCH_RETRIES", 1),
+			FeedFetchRetryBackoff: getEnvDuration("FEED_FETCH_RETRY_BACKOFF", 2*time.Second),
+
+			AdaptiveFetchFailureRatio:  getEnvFloat("ADAPTIVE_FETCH_FAILURE_RATIO", 0.5),
+			AdaptiveFetchMaxMultiplier: getEnvInt("ADAPTIVE_FETCH_MAX_MULTIPLIER", 4),
+
+			MaxArticlesPerHour: getEnvInt("MAX_ARTICLES_PER_HOUR", 0),
+			DeadFeedThreshold:  getEnvDuration("DEAD_FEED_THRESHOLD", 0),
+
+			ValidateOnly:       getEnvBool("VALIDATE_ONLY", false),
+			ValidateCheckFeeds: getEnvBool("VALIDATE_CHECK_FEEDS", false),
+
+			SLAWindow:           getEnvDuration("SLA_WINDOW", 24*time.Hour),
+			SLAFetchSuccessRate: getEnvFloat("SLA_FETCH_SUCCESS_RATE", 0.95),
+			SLASummaryLatency:   getEnvDuration("SLA_SUMMARY_LATENCY", 2*time.Minute),
+			SLAPostWithin:       getEnvDuration("SLA_POST_WITHIN", 30*time.Minute),
+			SLAPostedOnTimeRate: getEnvFloat("SLA_POSTED_ON_TIME_RATE", 0.9),
 		},
 		API: APIConfig{
 			Timeout:   getEnvDuration("API_TIMEOUT", 30*time.Second),
 			UserAgent: getEnv("API_USER_AGENT", "Information-Broker/1.0"),
+
+			// User agents contain commas, so the list is |-separated
+			UserAgents: getEnvList("API_USER_AGENTS", "|", []string{}),
 		},
 		FlareSolverr: FlareSolverrConfig{
 			URL:     getEnv("FLARESOLVERR_URL", ""),
 			Timeout: getEnvDuration("FLARESOLVERR_TIMEOUT", 60*time.Second),
 		},
 		OLLAMA: OLLAMAConfig{
-			URL:        getEnv("OLLAMA_URL", "http://localhost:11434"),
-			Model:      getEnv("OLLAMA_MODEL", "llama2"),
-			Timeout:    getEnvDuration("OLLAMA_TIMEOUT", 60*time.Second),
-			MaxRetries: getEnvInt("OLLAMA_MAX_RETRIES", 3),
+			Backend:       getEnv("OLLAMA_BACKEND", "ollama"),
+			APIKey:        getEnvFromFileOrValue("OLLAMA_API_KEY", "OLLAMA_API_KEY_FILE", ""),
+			URL:           getEnv("OLLAMA_URL", "http://localhost:11434"),
+			FallbackURLs:  getEnvStringSlice("OLLAMA_FALLBACK_URLS", []string{}),
+			Model:         getEnv("OLLAMA_MODEL", "llama2"),
+			AllowedModels: getEnvStringSlice("OLLAMA_ALLOWED_MODELS", []string{}),
+			Timeout:       getEnvDuration("OLLAMA_TIMEOUT", 60*time.Second),
+			MaxRetries:    getEnvInt("OLLAMA_MAX_RETRIES", 3),
+			Stream:        getEnvBool("OLLAMA_STREAM", false),
 		},
 		Discord: DiscordConfig{
-			WebhookURL:    getEnv("DISCORD_WEBHOOK_URL", ""),
-			WebhookURLs:   getEnvStringSlice("DISCORD_WEBHOOK_URLS", []string{}),
+			WebhookURL:    getEnvFromFileOrValue("DISCORD_WEBHOOK_URL", "DISCORD_WEBHOOK_URL_FILE", ""),
+			WebhookURLs:   splitList(getEnvFromFileOrValue("DISCORD_WEBHOOK_URLS", "DISCORD_WEBHOOK_URLS_FILE", ""), ","),
 			ExcludedFeeds: getEnvStringSlice("DISCORD_EXCLUDED_FEEDS", []string{}),
+			FeedThreads:   getEnvStringSlice("DISCORD_FEED_THREADS", []string{}),
+			FeedBranding:  getEnvStringSlice("DISCORD_FEED_BRANDING", []string{}),
 			MaxRetries:    getEnvInt("DISCORD_MAX_RETRIES", 2),
 			Timeout:       getEnvDuration("DISCORD_TIMEOUT", 30*time.Second),
+
+			QuietHours:               getEnv("DISCORD_QUIET_HOURS", ""),
+			DisplayTimezone:          getEnv("DISCORD_DISPLAY_TIMEZONE", "UTC"),
+			DeferredPostTTL:          getEnvDuration("DISCORD_DEFERRED_POST_TTL", 6*time.Hour),
+			DeferredReleasePerMinute: getEnvInt("DISCORD_DEFERRED_RELEASE_PER_MINUTE", 5),
+			ShowPublishDateField:     getEnvBool("DISCORD_SHOW_PUBLISH_DATE_FIELD", false),
+			DigestInterval:           getEnvDuration("DISCORD_DIGEST_INTERVAL", 0),
+			DigestMaxEmbeds:          getEnvInt("DISCORD_DIGEST_MAX_EMBEDS", 10),
+			DigestMessageInterval:    getEnvDuration("DISCORD_DIGEST_MESSAGE_INTERVAL", time.Second),
+
+			BreakerFailureThreshold: getEnvInt("DISCORD_BREAKER_FAILURE_THRESHOLD", 5),
+			BreakerTimeout:          getEnvDuration("DISCORD_BREAKER_TIMEOUT", 2*time.Minute),
+
+			MinPostSpacing: getEnvDuration("DISCORD_MIN_POST_SPACING", 0),
+
+			OnArticleUpdate: getEnv("DISCORD_ON_ARTICLE_UPDATE", "ignore"),
+
+			ImportantKeywords:        getEnvStringSlice("DISCORD_IMPORTANT_KEYWORDS", []string{}),
+			ImportanceKeywordWeight:  getEnvFloat("DISCORD_IMPORTANCE_KEYWORD_WEIGHT", 1),
+			ImportancePriorityWeight: getEnvFloat("DISCORD_IMPORTANCE_PRIORITY_WEIGHT", 1),
+			ImportanceThreshold:      getEnvFloat("DISCORD_IMPORTANCE_THRESHOLD", 0),
+			ImportantRoleID:          getEnv("DISCORD_IMPORTANT_ROLE_ID", ""),
+		},
+		Notifications: NotificationsConfig{
+			WebhookURLs: splitList(getEnvFromFileOrValue("NOTIFICATION_WEBHOOK_URLS", "NOTIFICATION_WEBHOOK_URLS_FILE", ""), ","),
+			Format:      getEnv("NOTIFICATION_FORMAT", "raw"),
+			Source:      getEnv("NOTIFICATION_SOURCE", "/information-broker"),
+			Timeout:     getEnvDuration("NOTIFICATION_TIMEOUT", 10*time.Second),
 		},
 		Prometheus: PrometheusConfig{
 			MetricsPath: getEnv("PROMETHEUS_METRICS_PATH", "/metrics"),
@@ -166,6 +502,8 @@ func Load() *Config {
 			CORSAllowedOrigins: getEnv("CORS_ALLOWED_ORIGINS", "*"),
 			CORSAllowedMethods: getEnv("CORS_ALLOWED_METHODS", "GET,POST,PUT,DELETE,OPTIONS"),
 			CORSAllowedHeaders: getEnv("CORS_ALLOWED_HEADERS", "Content-Type,Authorization"),
+			AdminToken:         getEnvFromFileOrValue("ADMIN_API_TOKEN", "ADMIN_API_TOKEN_FILE", ""),
+			AdminSecret:        getEnvFromFileOrValue("ADMIN_API_SECRET", "ADMIN_API_SECRET_FILE", ""),
 		},
 		Performance: PerformanceConfig{
 			MaxConcurrentFeeds:      getEnvInt("MAX_CONCURRENT_FEEDS", 10),
@@ -173,10 +511,44 @@ func Load() *Config {
 			HTTPReadTimeout:         getEnvDuration("HTTP_READ_TIMEOUT", 15*time.Second),
 			HTTPWriteTimeout:        getEnvDuration("HTTP_WRITE_TIMEOUT", 15*time.Second),
 			HTTPIdleTimeout:         getEnvDuration("HTTP_IDLE_TIMEOUT", 60*time.Second),
+			PerFeedContentBudget:    getEnvDuration("PER_FEED_CONTENT_BUDGET", 0),
+
+			MaxRawContentBytes:          int64(getEnvInt("MAX_RAW_CONTENT_BYTES", 5*1024*1024)),
+			MaxConcurrentContentFetches: getEnvInt("MAX_CONCURRENT_CONTENT_FETCHES", 5),
+			MaxResponseBytes:            int64(getEnvInt("MAX_RESPONSE_BYTES", 10*1024*1024)),
+
+			FeedMaxIdleConns:        getEnvInt("FEED_MAX_IDLE_CONNS", 100),
+			FeedMaxIdleConnsPerHost: getEnvInt("FEED_MAX_IDLE_CONNS_PER_HOST", 10),
+			FeedMaxConnsPerHost:     getEnvInt("FEED_MAX_CONNS_PER_HOST", 10),
+			FeedIdleConnTimeout:     getEnvDuration("FEED_IDLE_CONN_TIMEOUT", 90*time.Second),
 		},
 		Content: ContentConfig{
 			MaxSummaryLength:     getEnvInt("MAX_SUMMARY_LENGTH", 200),
+			SummaryLimitUnit:     getEnv("SUMMARY_LIMIT_UNIT", "words"),
 			ContentHashAlgorithm: getEnv("CONTENT_HASH_ALGORITHM", "sha256"),
+			PromptInjectionGuard: getEnvBool("PROMPT_INJECTION_GUARD", false),
+			PreviewLength:        getEnvInt("ARTICLE_PREVIEW_LENGTH", 200),
+			SummaryLanguageCheck: getEnvBool("SUMMARY_LANGUAGE_CHECK", false),
+			ArticleLanguage:      getEnv("ARTICLE_LANGUAGE", ""),
+			SummaryLanguage:      getEnv("SUMMARY_LANGUAGE", "auto"),
+			SummaryIncludeTitle:  getEnvBool("SUMMARY_INCLUDE_TITLE", true),
+			SummaryIncludeLead:   getEnvBool("SUMMARY_INCLUDE_LEAD", true),
+			FeedContentMinLength: getEnvInt("FEED_CONTENT_MIN_LENGTH", 1500),
+			ExtractionRulesFile:  getEnv("CONTENT_EXTRACTION_RULES_FILE", ""),
+			TrackingParams:       getEnvStringSlice("CONTENT_TRACKING_PARAMS", []string{"utm_*", "fbclid", "gclid"}),
+
+			SummaryMinWords:           getEnvInt("SUMMARY_MIN_WORDS", 8),
+			SummaryMaxTitleSimilarity: getEnvFloat("SUMMARY_MAX_TITLE_SIMILARITY", 0.8),
+
+			SummaryGracePeriod:        getEnvDuration("SUMMARY_GRACE_PERIOD", 0),
+			SummaryGraceRetryInterval: getEnvDuration("SUMMARY_GRACE_RETRY_INTERVAL", 5*time.Minute),
+			SummaryGraceFeeds:         getEnvStringSlice("SUMMARY_GRACE_FEEDS", []string{}),
+
+			StoreRawItem:       getEnvBool("CONTENT_STORE_RAW_ITEM", false),
+			NearDuplicateIndex: getEnvBool("CONTENT_NEAR_DUPLICATE_INDEX", true),
+
+			RespectRobotsTxt:  getEnvBool("CONTENT_RESPECT_ROBOTS_TXT", false),
+			RobotsTxtCacheTTL: getEnvDuration("ROBOTS_TXT_CACHE_TTL", 24*time.Hour),
 		},
 		Summarization: SummarizationConfig{
 			MaxQueueSize:      getEnvInt("SUMMARIZATION_MAX_QUEUE_SIZE", 100),
@@ -185,6 +557,11 @@ func Load() *Config {
 			RetryBackoffBase:  getEnvDuration("SUMMARIZATION_RETRY_BACKOFF_BASE", 1*time.Second),
 			MetricsInterval:   getEnvDuration("SUMMARIZATION_METRICS_INTERVAL", 10*time.Second),
 			QueuePurgeTimeout: getEnvDuration("SUMMARIZATION_QUEUE_PURGE_TIMEOUT", 1*time.Hour),
+
+			Fairness: getEnvBool("SUMMARIZATION_FAIRNESS", false),
+
+			DeadLetterReplayInterval: getEnvDuration("SUMMARIZATION_DEAD_LETTER_REPLAY_INTERVAL", 0),
+			DeadLetterMaxAttempts:    getEnvInt("SUMMARIZATION_DEAD_LETTER_MAX_ATTEMPTS", 5),
 		},
 		Clustering: ClusteringConfig{
 			Interval:            getEnvDuration("CLUSTERING_INTERVAL", 15*time.Minute),
@@ -193,19 +570,38 @@ func Load() *Config {
 			SimilarityThreshold: getEnvFloat("CLUSTERING_SIMILARITY_THRESHOLD", 0.85),
 			EmbedModel:          getEnv("CLUSTERING_EMBED_MODEL", "nomic-embed-text"),
 		},
+		Events: EventsConfig{
+			Brokers:    getEnvStringSlice("EVENTS_BROKERS", []string{}),
+			Topic:      getEnv("EVENTS_TOPIC", "information-broker.events"),
+			BufferSize: getEnvInt("EVENTS_BUFFER_SIZE", 1000),
+
+			User:         getEnv("EVENTS_NATS_USER", ""),
+			Password:     getEnvFromFileOrValue("EVENTS_NATS_PASSWORD", "EVENTS_NATS_PASSWORD_FILE", ""),
+			Token:        getEnvFromFileOrValue("EVENTS_NATS_TOKEN", "EVENTS_NATS_TOKEN_FILE", ""),
+			NKeySeedFile: getEnv("EVENTS_NATS_NKEY_SEED_FILE", ""),
+			CredsFile:    getEnv("EVENTS_NATS_CREDS_FILE", ""),
+
+			TLSCAFile:   getEnv("EVENTS_NATS_TLS_CA_FILE", ""),
+			TLSCertFile: getEnv("EVENTS_NATS_TLS_CERT_FILE", ""),
+			TLSKeyFile:  getEnv("EVENTS_NATS_TLS_KEY_FILE", ""),
+		},
 	}
+	cfg.fileErr = fileErr
+	cfg.secretErr = secretFileErr
+	return cfg
 }
 
-// Helper functions for environment variable parsing
+// Helper functions for environment variable parsing. Each reads through
+// lookup, so a value from CONFIG_FILE is used when the variable isn't set.
 func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
+	if value := lookup(key); value != "" {
 		return value
 	}
 	return defaultValue
 }
 
 func getEnvInt(key string, defaultValue int) int {
-	if value := os.Getenv(key); value != "" {
+	if value := lookup(key); value != "" {
 		if intValue, err := strconv.Atoi(value); err == nil {
 			return intValue
 		}
@@ -214,7 +610,7 @@ func getEnvInt(key string, defaultValue int) int {
 }
 
 func getEnvFloat(key string, defaultValue float64) float64 {
-	if value := os.Getenv(key); value != "" {
+	if value := lookup(key); value != "" {
 		if floatValue, err := strconv.ParseFloat(value, 64); err == nil {
 			return floatValue
 		}
@@ -222,8 +618,17 @@ func getEnvFloat(key string, defaultValue float64) float64 {
 	return defaultValue
 }
 
+func getEnvBool(key string, defaultValue bool) bool {
+	if value := lookup(key); value != "" {
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	}
+	return defaultValue
+}
+
 func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
-	if value := os.Getenv(key); value != "" {
+	if value := lookup(key); value != "" {
 		if duration, err := time.ParseDuration(value); err == nil {
 			return duration
 		}
@@ -232,22 +637,54 @@ func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
 }
 
 func getEnvStringSlice(key string, defaultValue []string) []string {
-	if value := os.Getenv(key); value != "" {
-		// Split by comma and trim whitespace
-		parts := strings.Split(value, ",")
-		result := make([]string, 0, len(parts))
-		for _, part := range parts {
-			if trimmed := strings.TrimSpace(part); trimmed != "" {
-				result = append(result, trimmed)
-			}
-		}
-		return result
+	return getEnvList(key, ",", defaultValue)
+}
+
+// getEnvList splits the value of key on sep, trimming whitespace and
+// dropping empty items.
+func getEnvList(key, sep string, defaultValue []string) []string {
+	if value := lookup(key); value != "" {
+		return splitList(value, sep)
 	}
 	return defaultValue
 }
 
+// splitList splits value on sep, trimming whitespace and dropping empty
+// items. An empty value gives an empty list.
+func splitList(value, sep string) []string {
+	parts := strings.Split(value, sep)
+	result := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if trimmed := strings.TrimSpace(part); trimmed != "" {
+			result = append(result, trimmed)
+		}
+	}
+	return result
+}
+
+// secretFileErr is the first *_FILE secret the running Load couldn't read.
+var secretFileErr error
+
+// getEnvFromFileOrValue returns a sensitive setting. If fileKey names a file,
+// as with Docker and Kubernetes secrets, its contents win over the value of
+// key and keep the secret out of the environment and process listings.
+// Trailing newlines are trimmed. A file that can't be read is reported by
+// Validate.
+func getEnvFromFileOrValue(key, fileKey, defaultValue string) string {
+	if path := lookup(fileKey); path != "" {
+		data, err := os.ReadFile(path)
+		if err == nil {
+			return strings.TrimRight(string(data), "\r\n")
+		}
+		if secretFileErr == nil {
+			secretFileErr = fmt.Errorf("%s: %w", fileKey, err)
+		}
+	}
+	return getEnv(key, defaultValue)
+}
+
 func getEnvTime(key string, defaultValue time.Time) time.Time {
-	if value := os.Getenv(key); value != "" {
+	if value := lookup(key); value != "" {
 		// Try parsing in RFC3339 format first (2006-01-02T15:04:05Z07:00)
 		if parsedTime, err := time.Parse(time.RFC3339, value); err == nil {
 			return parsedTime
@@ -264,20 +701,228 @@ func getEnvTime(key string, defaultValue time.Time) time.Time {
 	return defaultValue
 }
 
-// GetWebhookURLs returns all configured webhook URLs, supporting both single and multiple webhook configurations
+// BackendURLs returns the summarization backends in failover order: the
+// primary URL first, then each fallback, with blanks and duplicates dropped.
+func (o *OLLAMAConfig) BackendURLs() []string {
+	seen := make(map[string]bool)
+	urls := make([]string, 0, 1+len(o.FallbackURLs))
+	for _, u := range append([]string{o.URL}, o.FallbackURLs...) {
+		u = strings.TrimRight(strings.TrimSpace(u), "/")
+		if u == "" || seen[u] {
+			continue
+		}
+		seen[u] = true
+		urls = append(urls, u)
+	}
+	return urls
+}
+
+// IsModelAllowed reports whether model may be used for summarization. An
+// empty allowlist allows every model. Names compare the way Ollama resolves
+// them, so "llama3" and "llama3:latest" are the same model.
+func (o *OLLAMAConfig) IsModelAllowed(model string) bool {
+	if len(o.AllowedModels) == 0 {
+		return true
+	}
+	model = normalizeModelName(model)
+	for _, allowed := range o.AllowedModels {
+		if normalizeModelName(allowed) == model {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeModelName lowercases a model name and adds Ollama's implicit
+// ":latest" tag when none is given.
+func normalizeModelName(model string) string {
+	model = strings.ToLower(strings.TrimSpace(model))
+	if model != "" && !strings.Contains(model, ":") {
+		model += ":latest"
+	}
+	return model
+}
+
+// Validate checks settings that can't be fixed up with a default and should
+// stop startup instead.
+func (c *Config) Validate() error {
+	if c.fileErr != nil {
+		return fmt.Errorf("CONFIG_FILE: %w", c.fileErr)
+	}
+	if c.secretErr != nil {
+		return c.secretErr
+	}
+	if _, err := ParseLogLevel(c.App.LogLevel); err != nil {
+		return err
+	}
+	if c.App.RSSFeedsReload < 0 {
+		return fmt.Errorf("RSS_FEEDS_RELOAD_INTERVAL must not be negative, got %v", c.App.RSSFeedsReload)
+	}
+	if c.App.DeadFeedThreshold < 0 {
+		return fmt.Errorf("DEAD_FEED_THRESHOLD must not be negative, got %v", c.App.DeadFeedThreshold)
+	}
+	switch c.Database.SSLMode {
+	case "", "disable", "require", "verify-ca", "verify-full":
+	case "allow", "prefer":
+		return fmt.Errorf("DB_SSLMODE %q is not supported by the PostgreSQL driver (use disable, require, verify-ca or verify-full)", c.Database.SSLMode)
+	default:
+		return fmt.Errorf("DB_SSLMODE %q is not a valid sslmode (use disable, require, verify-ca or verify-full)", c.Database.SSLMode)
+	}
+	if (c.Database.SSLCert == "") != (c.Database.SSLKey == "") {
+		return fmt.Errorf("DB_SSLCERT and DB_SSLKEY must be set together")
+	}
+	switch strings.ToLower(c.OLLAMA.Backend) {
+	case "", "ollama", "openai":
+	default:
+		return fmt.Errorf("OLLAMA_BACKEND %q is not supported (use ollama or openai)", c.OLLAMA.Backend)
+	}
+	switch c.Notifications.Format {
+	case "", "raw", "cloudevents":
+	default:
+		return fmt.Errorf("NOTIFICATION_FORMAT %q is not supported (use raw or cloudevents)", c.Notifications.Format)
+	}
+	switch c.Discord.OnArticleUpdate {
+	case "", "ignore", "repost", "edit":
+	default:
+		return fmt.Errorf("DISCORD_ON_ARTICLE_UPDATE %q is not supported (use ignore, repost or edit)", c.Discord.OnArticleUpdate)
+	}
+	for _, entry := range c.Discord.FeedThreads {
+		if _, _, _, ok := parseFeedThread(entry); !ok {
+			return fmt.Errorf("DISCORD_FEED_THREADS entry %q is not valid (use feed=thread-id or feed=name:Thread name)", entry)
+		}
+	}
+	for _, entry := range c.Discord.FeedBranding {
+		if _, _, err := parseFeedBranding(entry); err != nil {
+			return fmt.Errorf("DISCORD_FEED_BRANDING entry %q is not valid: %w", entry, err)
+		}
+	}
+	if c.Performance.FeedMaxIdleConns < 0 || c.Performance.FeedMaxIdleConnsPerHost < 0 || c.Performance.FeedMaxConnsPerHost < 0 {
+		return fmt.Errorf("FEED_MAX_IDLE_CONNS, FEED_MAX_IDLE_CONNS_PER_HOST and FEED_MAX_CONNS_PER_HOST must not be negative")
+	}
+	switch c.Content.SummaryLimitUnit {
+	case "", "words":
+	case "characters":
+		// Discord embed descriptions hold at most 4096 characters
+		if c.Content.MaxSummaryLength > 4096 {
+			return fmt.Errorf("MAX_SUMMARY_LENGTH must be at most 4096 characters, got %d", c.Content.MaxSummaryLength)
+		}
+	default:
+		return fmt.Errorf("SUMMARY_LIMIT_UNIT %q is not supported (use words or characters)", c.Content.SummaryLimitUnit)
+	}
+	if c.Content.FeedContentMinLength < 0 {
+		return fmt.Errorf("FEED_CONTENT_MIN_LENGTH must not be negative, got %d", c.Content.FeedContentMinLength)
+	}
+	if c.Content.SummaryMinWords < 0 {
+		return fmt.Errorf("SUMMARY_MIN_WORDS must not be negative, got %d", c.Content.SummaryMinWords)
+	}
+	if c.Content.SummaryMaxTitleSimilarity < 0 || c.Content.SummaryMaxTitleSimilarity > 1 {
+		return fmt.Errorf("SUMMARY_MAX_TITLE_SIMILARITY must be between 0 and 1, got %v", c.Content.SummaryMaxTitleSimilarity)
+	}
+	if c.Discord.ImportanceThreshold < 0 {
+		return fmt.Errorf("DISCORD_IMPORTANCE_THRESHOLD must not be negative, got %v", c.Discord.ImportanceThreshold)
+	}
+	if c.Discord.ImportantRoleID != "" && !isSnowflake(c.Discord.ImportantRoleID) {
+		return fmt.Errorf("DISCORD_IMPORTANT_ROLE_ID %q is not a Discord role id", c.Discord.ImportantRoleID)
+	}
+	for _, broker := range c.Events.Brokers {
+		if scheme, _, ok := strings.Cut(broker, "://"); ok && scheme != "nats" && scheme != "tls" {
+			return fmt.Errorf("EVENTS_BROKERS entry %q is not supported (use nats://host:port or tls://host:port)", broker)
+		}
+	}
+	credentials := 0
+	for _, set := range []bool{c.Events.User != "", c.Events.Token != "", c.Events.NKeySeedFile != "", c.Events.CredsFile != ""} {
+		if set {
+			credentials++
+		}
+	}
+	if credentials > 1 {
+		return fmt.Errorf("set only one of EVENTS_NATS_USER, EVENTS_NATS_TOKEN, EVENTS_NATS_NKEY_SEED_FILE and EVENTS_NATS_CREDS_FILE")
+	}
+	if c.Events.Password != "" && c.Events.User == "" {
+		return fmt.Errorf("EVENTS_NATS_PASSWORD requires EVENTS_NATS_USER")
+	}
+	if (c.Events.TLSCertFile == "") != (c.Events.TLSKeyFile == "") {
+		return fmt.Errorf("EVENTS_NATS_TLS_CERT_FILE and EVENTS_NATS_TLS_KEY_FILE must be set together")
+	}
+	if !c.OLLAMA.IsModelAllowed(c.OLLAMA.Model) {
+		return fmt.Errorf("OLLAMA_MODEL %q is not in OLLAMA_ALLOWED_MODELS (%s)",
+			c.OLLAMA.Model, strings.Join(c.OLLAMA.AllowedModels, ", "))
+	}
+	return nil
+}
+
+// ParseLogLevel maps a LOG_LEVEL value to its slog level, case-insensitively.
+// "warning" is accepted for warn and an empty value means info.
+func ParseLogLevel(level string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return slog.LevelInfo, fmt.Errorf("LOG_LEVEL %q is not supported (use debug, info, warn or error)", level)
+}
+
+// GetWebhookURLs returns the primary URL of every configured webhook group,
+// supporting both single and multiple webhook configurations
 func (d *DiscordConfig) GetWebhookURLs() []string {
-	// If multiple webhooks are configured, use them
-	if len(d.WebhookURLs) > 0 {
-		return d.WebhookURLs
+	groups := d.WebhookGroups()
+	urls := make([]string, 0, len(groups))
+	for _, group := range groups {
+		urls = append(urls, group[0])
 	}
+	return urls
+}
 
+// WebhookGroups returns the configured webhooks as failover groups. Each
+// WebhookURLs entry is a primary URL optionally followed by backups for the
+// same channel, separated by "|". Notifications are multi-cast to every
+// group; within a group the next URL is only tried when the previous one
+// keeps failing with a retryable error.
+func (d *DiscordConfig) WebhookGroups() [][]string {
+	entries := d.WebhookURLs
 	// Fall back to single webhook URL for backward compatibility
-	if d.WebhookURL != "" {
-		return []string{d.WebhookURL}
+	if len(entries) == 0 && d.WebhookURL != "" {
+		entries = []string{d.WebhookURL}
 	}
 
-	// No webhooks configured
-	return []string{}
+	groups := make([][]string, 0, len(entries))
+	for _, entry := range entries {
+		var group []string
+		for _, u := range strings.Split(entry, "|") {
+			if u = strings.TrimSpace(u); u != "" {
+				group = append(group, u)
+			}
+		}
+		if len(group) > 0 {
+			groups = append(groups, group)
+		}
+	}
+	return groups
+}
+
+// IsSummaryGraceFeed reports whether articles from the given feed URL get a
+// summary grace period, matching SummaryGraceFeeds like
+// DiscordConfig.IsFeedExcluded. An empty list covers every feed.
+func (c *ContentConfig) IsSummaryGraceFeed(feedURL string) bool {
+	if c.SummaryGracePeriod <= 0 || feedURL == "" {
+		return false
+	}
+	if len(c.SummaryGraceFeeds) == 0 {
+		return true
+	}
+	haystack := strings.ToLower(feedURL)
+	for _, feed := range c.SummaryGraceFeeds {
+		needle := strings.ToLower(strings.TrimSpace(feed))
+		if needle != "" && strings.Contains(haystack, needle) {
+			return true
+		}
+	}
+	return false
 }
 
 // IsFeedExcluded reports whether articles from the given feed URL should be
@@ -298,8 +943,236 @@ func (d *DiscordConfig) IsFeedExcluded(feedURL string) bool {
 	return false
 }
 
+// FeedThread returns the Discord thread that articles from feedURL are posted
+// to: the id of an existing thread, or the name of a thread for Discord to
+// create (forum channels only). The first FeedThreads entry whose feed part
+// matches, like IsFeedExcluded, wins; with none both are empty and posts go
+// to the webhook's channel.
+func (d *DiscordConfig) FeedThread(feedURL string) (threadID, threadName string) {
+	if feedURL == "" {
+		return "", ""
+	}
+	haystack := strings.ToLower(feedURL)
+	for _, entry := range d.FeedThreads {
+		feed, threadID, threadName, ok := parseFeedThread(entry)
+		if ok && strings.Contains(haystack, strings.ToLower(feed)) {
+			return threadID, threadName
+		}
+	}
+	return "", ""
+}
+
+// parseFeedThread splits a FeedThreads entry, "feed=123" for a thread id or
+// "feed=name:Thread name" for a thread to create. Thread ids are Discord
+// snowflakes, so anything else without the name: prefix is rejected.
+func parseFeedThread(entry string) (feed, threadID, threadName string, ok bool) {
+	feed, thread, found := strings.Cut(entry, "=")
+	feed, thread = strings.TrimSpace(feed), strings.TrimSpace(thread)
+	if !found || feed == "" || thread == "" {
+		return "", "", "", false
+	}
+	if name, isName := strings.CutPrefix(thread, "name:"); isName {
+		name = strings.TrimSpace(name)
+		return feed, "", name, name != ""
+	}
+	if !isSnowflake(thread) {
+		return "", "", "", false
+	}
+	return feed, thread, "", true
+}
+
+// FeedBranding is how a feed's Discord posts look. Zero fields keep the
+// sender's defaults.
+type FeedBranding struct {
+	Color     int // Embed color as 0xRRGGBB
+	Username  string
+	AvatarURL string
+}
+
+// Branding returns the FeedBranding of the first FeedBranding entry that
+// matches the feed: "category:name" entries match a feed category exactly,
+// other entries match the feed URL by substring, both case-insensitively.
+// With no match it is the zero FeedBranding.
+func (d *DiscordConfig) Branding(feedURL, category string) FeedBranding {
+	haystack := strings.ToLower(feedURL)
+	for _, entry := range d.FeedBranding {
+		match, branding, err := parseFeedBranding(entry)
+		if err != nil {
+			continue
+		}
+		if name, isCategory := strings.CutPrefix(match, "category:"); isCategory {
+			if category != "" && strings.EqualFold(strings.TrimSpace(name), category) {
+				return branding
+			}
+		} else if feedURL != "" && strings.Contains(haystack, strings.ToLower(match)) {
+			return branding
+		}
+	}
+	return FeedBranding{}
+}
+
+// parseFeedBranding splits a FeedBranding entry, "match=key:value;..." with
+// the keys color (#RRGGBB), username and avatar (an http(s) URL). The pairs
+// are separated by semicolons since entries themselves are comma-separated.
+func parseFeedBranding(entry string) (match string, branding FeedBranding, err error) {
+	match, spec, found := strings.Cut(entry, "=")
+	match = strings.TrimSpace(match)
+	if !found || match == "" || match == "category:" {
+		return "", FeedBranding{}, fmt.Errorf("expected feed=key:value;... or category:name=key:value;...")
+	}
+	for _, pair := range strings.Split(spec, ";") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(pair, ":")
+		key, value = strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value)
+		if !ok || value == "" {
+			return "", FeedBranding{}, fmt.Errorf("%q is not a key:value pair", strings.TrimSpace(pair))
+		}
+		switch key {
+		case "color":
+			hex := strings.TrimPrefix(value, "#")
+			color, parseErr := strconv.ParseUint(hex, 16, 32)
+			if parseErr != nil || len(hex) != 6 || color == 0 {
+				return "", FeedBranding{}, fmt.Errorf("color %q must be a non-black #RRGGBB value", value)
+			}
+			branding.Color = int(color)
+		case "username":
+			// Discord rejects webhook usernames over 80 characters
+			if len([]rune(value)) > 80 {
+				return "", FeedBranding{}, fmt.Errorf("username %q is longer than 80 characters", value)
+			}
+			branding.Username = value
+		case "avatar":
+			if !strings.HasPrefix(value, "https://") && !strings.HasPrefix(value, "http://") {
+				return "", FeedBranding{}, fmt.Errorf("avatar %q must be an http(s) URL", value)
+			}
+			branding.AvatarURL = value
+		default:
+			return "", FeedBranding{}, fmt.Errorf("unknown key %q (use color, username or avatar)", key)
+		}
+	}
+	if branding == (FeedBranding{}) {
+		return "", FeedBranding{}, fmt.Errorf("no color, username or avatar given")
+	}
+	return match, branding, nil
+}
+
+// isSnowflake reports whether s looks like a Discord id: digits only.
+func isSnowflake(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// ArticleImportance scores an article for highlighting: ImportanceKeywordWeight
+// per ImportantKeywords entry in its title or summary (case-insensitive, each
+// counted once) plus ImportancePriorityWeight times its feed's priority.
+func (d *DiscordConfig) ArticleImportance(title, summary string, feedPriority int) float64 {
+	haystack := strings.ToLower(title + "\n" + summary)
+	hits := 0
+	for _, keyword := range d.ImportantKeywords {
+		if keyword = strings.ToLower(strings.TrimSpace(keyword)); keyword != "" && strings.Contains(haystack, keyword) {
+			hits++
+		}
+	}
+	return float64(hits)*d.ImportanceKeywordWeight + float64(feedPriority)*d.ImportancePriorityWeight
+}
+
+// IsImportant reports whether an article scores at least ImportanceThreshold,
+// and so is highlighted. It is always false while the threshold is 0.
+func (d *DiscordConfig) IsImportant(title, summary string, feedPriority int) bool {
+	return d.ImportanceThreshold > 0 && d.ArticleImportance(title, summary, feedPriority) >= d.ImportanceThreshold
+}
+
+// Location returns the configured display timezone, falling back to UTC when
+// it is unset or not a valid IANA zone name.
+func (d *DiscordConfig) Location() *time.Location {
+	if d.DisplayTimezone == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(d.DisplayTimezone)
+	if err != nil {
+		return time.UTC
+	}
+	return loc
+}
+
+// QuietHoursWindow parses QuietHours ("22:00-07:00") into start and end
+// offsets in minutes since midnight. ok is false when quiet hours are
+// disabled or the value is malformed.
+func (d *DiscordConfig) QuietHoursWindow() (start, end int, ok bool) {
+	parts := strings.Split(strings.TrimSpace(d.QuietHours), "-")
+	if len(parts) != 2 {
+		return 0, 0, false
+	}
+	start, okStart := parseClock(parts[0])
+	end, okEnd := parseClock(parts[1])
+	if !okStart || !okEnd || start == end {
+		return 0, 0, false
+	}
+	return start, end, true
+}
+
+// InQuietHours reports whether t falls inside the quiet-hours window, in the
+// display timezone. Windows that wrap midnight (22:00-07:00) are supported.
+func (d *DiscordConfig) InQuietHours(t time.Time) bool {
+	start, end, ok := d.QuietHoursWindow()
+	if !ok {
+		return false
+	}
+	local := t.In(d.Location())
+	minute := local.Hour()*60 + local.Minute()
+	if start < end {
+		return minute >= start && minute < end
+	}
+	return minute >= start || minute < end
+}
+
+// parseClock parses "HH:MM" into minutes since midnight.
+func parseClock(s string) (int, bool) {
+	parsed, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, false
+	}
+	return parsed.Hour()*60 + parsed.Minute(), true
+}
+
 // GetConnectionString returns the database connection string
 func (c *Config) GetConnectionString() string {
-	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-		c.Database.Host, c.Database.Port, c.Database.User, c.Database.Password, c.Database.Name)
+	sslMode := c.Database.SSLMode
+	if sslMode == "" {
+		sslMode = "disable"
+	}
+	params := []string{
+		"host=" + connParam(c.Database.Host),
+		"port=" + connParam(c.Database.Port),
+		"user=" + connParam(c.Database.User),
+		"password=" + connParam(c.Database.Password),
+		"dbname=" + connParam(c.Database.Name),
+		"sslmode=" + sslMode,
+	}
+	for _, param := range []struct{ key, value string }{
+		{"sslrootcert", c.Database.SSLRootCert},
+		{"sslcert", c.Database.SSLCert},
+		{"sslkey", c.Database.SSLKey},
+	} {
+		if param.value != "" {
+			params = append(params, param.key+"="+connParam(param.value))
+		}
+	}
+	return strings.Join(params, " ")
+}
+
+// connParam quotes a connection string value when it is empty or holds
+// spaces, quotes or backslashes, which would otherwise break the key=value
+// format.
+func connParam(value string) string {
+	if value != "" && !strings.ContainsAny(value, ` '\`) {
+		return value
+	}
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
 }
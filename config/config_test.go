@@ -1,6 +1,13 @@
 package config
 
-import "testing"
+import (
+	"log/slog"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
 
 func TestIsFeedExcluded(t *testing.T) {
 	tests := []struct {
@@ -69,3 +76,510 @@ func TestIsFeedExcluded(t *testing.T) {
 		})
 	}
 }
+
+func TestInQuietHours(t *testing.T) {
+	tests := []struct {
+		name       string
+		quietHours string
+		timezone   string
+		at         time.Time
+		want       bool
+	}{
+		{"disabled when empty", "", "UTC", time.Date(2026, 7, 15, 23, 0, 0, 0, time.UTC), false},
+		{"malformed value disables", "late-early", "UTC", time.Date(2026, 7, 15, 23, 0, 0, 0, time.UTC), false},
+		{"inside same-day window", "01:00-05:00", "UTC", time.Date(2026, 7, 15, 3, 0, 0, 0, time.UTC), true},
+		{"end is exclusive", "01:00-05:00", "UTC", time.Date(2026, 7, 15, 5, 0, 0, 0, time.UTC), false},
+		{"wrapping window before midnight", "22:00-07:00", "UTC", time.Date(2026, 7, 15, 23, 30, 0, 0, time.UTC), true},
+		{"wrapping window after midnight", "22:00-07:00", "UTC", time.Date(2026, 7, 16, 6, 59, 0, 0, time.UTC), true},
+		{"outside wrapping window", "22:00-07:00", "UTC", time.Date(2026, 7, 16, 12, 0, 0, 0, time.UTC), false},
+		// 03:00 UTC is 23:00 the previous day in New York (EDT, UTC-4).
+		{"evaluated in display timezone", "22:00-07:00", "America/New_York", time.Date(2026, 7, 16, 3, 0, 0, 0, time.UTC), true},
+		{"display timezone shifts window", "01:00-05:00", "America/New_York", time.Date(2026, 7, 16, 3, 0, 0, 0, time.UTC), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &DiscordConfig{QuietHours: tt.quietHours, DisplayTimezone: tt.timezone}
+			if got := d.InQuietHours(tt.at); got != tt.want {
+				t.Errorf("InQuietHours(%s) with %q in %s = %v, want %v",
+					tt.at.Format(time.RFC3339), tt.quietHours, tt.timezone, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackendURLs(t *testing.T) {
+	tests := []struct {
+		name      string
+		primary   string
+		fallbacks []string
+		want      []string
+	}{
+		{"primary only", "http://ollama:11434", nil, []string{"http://ollama:11434"}},
+		{"primary then fallbacks in order", "http://a:11434", []string{"http://b:11434", "http://c:11434"},
+			[]string{"http://a:11434", "http://b:11434", "http://c:11434"}},
+		{"duplicates and trailing slashes collapse", "http://a:11434/", []string{"http://a:11434", " ", "http://b:11434"},
+			[]string{"http://a:11434", "http://b:11434"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := &OLLAMAConfig{URL: tt.primary, FallbackURLs: tt.fallbacks}
+			got := o.BackendURLs()
+			if len(got) != len(tt.want) {
+				t.Fatalf("BackendURLs() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("BackendURLs()[%d] = %q, want %q", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestWebhookGroups(t *testing.T) {
+	tests := []struct {
+		name        string
+		cfg         DiscordConfig
+		wantGroups  [][]string
+		wantPrimary []string
+	}{
+		{"none configured", DiscordConfig{}, [][]string{}, []string{}},
+		{"legacy single webhook", DiscordConfig{WebhookURL: "https://d/1"},
+			[][]string{{"https://d/1"}}, []string{"https://d/1"}},
+		{"multi-cast without backups", DiscordConfig{WebhookURLs: []string{"https://d/1", "https://d/2"}},
+			[][]string{{"https://d/1"}, {"https://d/2"}}, []string{"https://d/1", "https://d/2"}},
+		{"primary with backups", DiscordConfig{WebhookURLs: []string{"https://d/1|https://d/1b| https://d/1c", "https://d/2"}},
+			[][]string{{"https://d/1", "https://d/1b", "https://d/1c"}, {"https://d/2"}}, []string{"https://d/1", "https://d/2"}},
+		{"empty segments dropped", DiscordConfig{WebhookURLs: []string{"|https://d/1||", "|"}},
+			[][]string{{"https://d/1"}}, []string{"https://d/1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cfg.WebhookGroups(); !reflect.DeepEqual(got, tt.wantGroups) {
+				t.Errorf("WebhookGroups() = %v, want %v", got, tt.wantGroups)
+			}
+			if got := tt.cfg.GetWebhookURLs(); !reflect.DeepEqual(got, tt.wantPrimary) {
+				t.Errorf("GetWebhookURLs() = %v, want %v", got, tt.wantPrimary)
+			}
+		})
+	}
+}
+
+func TestValidateAllowedModels(t *testing.T) {
+	tests := []struct {
+		name    string
+		model   string
+		allowed []string
+		wantErr bool
+	}{
+		{"empty allowlist allows any model", "mixtral:8x22b", nil, false},
+		{"listed model passes", "llama3:8b", []string{"llama3:8b", "mistral"}, false},
+		{"untagged name matches latest", "mistral:latest", []string{"llama3:8b", "mistral"}, false},
+		{"latest tag matches untagged model", "llama3", []string{"llama3:latest"}, false},
+		{"different tag is rejected", "llama3:70b", []string{"llama3:8b"}, true},
+		{"unlisted model fails startup", "gpt-oss:120b", []string{"llama3:8b", "mistral"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{OLLAMA: OLLAMAConfig{Model: tt.model, AllowedModels: tt.allowed}}
+			err := cfg.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !strings.Contains(err.Error(), tt.model) {
+				t.Errorf("error should name the rejected model: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateBackend(t *testing.T) {
+	for _, backend := range []string{"", "ollama", "openai", "OpenAI"} {
+		cfg := &Config{OLLAMA: OLLAMAConfig{Backend: backend}}
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("Validate() with backend %q: unexpected error %v", backend, err)
+		}
+	}
+	cfg := &Config{OLLAMA: OLLAMAConfig{Backend: "vertex"}}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() should reject an unknown backend")
+	}
+}
+
+func TestValidateOnArticleUpdate(t *testing.T) {
+	for _, policy := range []string{"", "ignore", "repost", "edit"} {
+		cfg := &Config{Discord: DiscordConfig{OnArticleUpdate: policy}}
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("Validate() with policy %q: unexpected error %v", policy, err)
+		}
+	}
+	cfg := &Config{Discord: DiscordConfig{OnArticleUpdate: "delete"}}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() should reject an unknown article update policy")
+	}
+}
+
+func TestFeedThread(t *testing.T) {
+	d := &DiscordConfig{FeedThreads: []string{
+		"bleepingcomputer.com=1122334455",
+		"CISA.gov = name:CISA Advisories",
+		"cisa.gov=999", // Shadowed by the entry above
+	}}
+	tests := []struct {
+		feedURL  string
+		wantID   string
+		wantName string
+	}{
+		{"https://www.bleepingcomputer.com/feed/", "1122334455", ""},
+		{"https://www.cisa.gov/cybersecurity-advisories/all.xml", "", "CISA Advisories"},
+		{"https://krebsonsecurity.com/feed/", "", ""},
+		{"", "", ""},
+	}
+	for _, tt := range tests {
+		id, name := d.FeedThread(tt.feedURL)
+		if id != tt.wantID || name != tt.wantName {
+			t.Errorf("FeedThread(%q) = (%q, %q), want (%q, %q)", tt.feedURL, id, name, tt.wantID, tt.wantName)
+		}
+	}
+}
+
+func TestValidateSummaryLimitUnit(t *testing.T) {
+	for _, content := range []ContentConfig{
+		{MaxSummaryLength: 200, SummaryLimitUnit: "words"},
+		{MaxSummaryLength: 10000, SummaryLimitUnit: "words"},
+		{MaxSummaryLength: 1000, SummaryLimitUnit: "characters"},
+	} {
+		cfg := &Config{Content: content}
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("Validate() with %+v: unexpected error %v", content, err)
+		}
+	}
+	for _, content := range []ContentConfig{
+		{MaxSummaryLength: 200, SummaryLimitUnit: "tokens"},
+		{MaxSummaryLength: 5000, SummaryLimitUnit: "characters"},
+	} {
+		cfg := &Config{Content: content}
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("Validate() should reject %+v", content)
+		}
+	}
+}
+
+func TestValidateFeedThreads(t *testing.T) {
+	for _, entry := range []string{"example.com=123", "example.com=name:News"} {
+		cfg := &Config{Discord: DiscordConfig{FeedThreads: []string{entry}}}
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("Validate() with %q: unexpected error %v", entry, err)
+		}
+	}
+	for _, entry := range []string{"example.com", "=123", "example.com=", "example.com=news", "example.com=name:"} {
+		cfg := &Config{Discord: DiscordConfig{FeedThreads: []string{entry}}}
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("Validate() should reject %q", entry)
+		}
+	}
+}
+
+func TestBranding(t *testing.T) {
+	d := &DiscordConfig{FeedBranding: []string{
+		"category:Security = color:#E74C3C;username:Security Desk",
+		"krebsonsecurity.com=avatar:https://example.com/krebs.png;color:2ecc71",
+		"example.com=color:#123", // Invalid, skipped
+	}}
+	tests := []struct {
+		feedURL  string
+		category string
+		want     FeedBranding
+	}{
+		{"https://www.bleepingcomputer.com/feed/", "security", FeedBranding{Color: 0xE74C3C, Username: "Security Desk"}},
+		{"https://KrebsOnSecurity.com/feed/", "", FeedBranding{Color: 0x2ECC71, AvatarURL: "https://example.com/krebs.png"}},
+		{"https://krebsonsecurity.com/feed/", "Security", FeedBranding{Color: 0xE74C3C, Username: "Security Desk"}}, // First entry wins
+		{"https://example.com/feed", "news", FeedBranding{}},
+		{"", "", FeedBranding{}},
+	}
+	for _, tt := range tests {
+		if got := d.Branding(tt.feedURL, tt.category); got != tt.want {
+			t.Errorf("Branding(%q, %q) = %+v, want %+v", tt.feedURL, tt.category, got, tt.want)
+		}
+	}
+}
+
+func TestValidateFeedBranding(t *testing.T) {
+	for _, entry := range []string{"example.com=color:#5865F2", "category:news=username:Newsroom;avatar:https://example.com/a.png;"} {
+		cfg := &Config{Discord: DiscordConfig{FeedBranding: []string{entry}}}
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("Validate() with %q: unexpected error %v", entry, err)
+		}
+	}
+	for _, entry := range []string{
+		"example.com", "=color:#5865F2", "category:=color:#5865F2", "example.com=",
+		"example.com=color:blue", "example.com=color:#000000", "example.com=avatar:example.com/a.png",
+		"example.com=emoji:fire", "example.com=username:" + strings.Repeat("x", 81),
+	} {
+		cfg := &Config{Discord: DiscordConfig{FeedBranding: []string{entry}}}
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("Validate() should reject %q", entry)
+		}
+	}
+}
+
+func TestArticleImportance(t *testing.T) {
+	d := &DiscordConfig{
+		ImportantKeywords:        []string{"zero-day", "Ransomware", " "},
+		ImportanceKeywordWeight:  2,
+		ImportancePriorityWeight: 1,
+		ImportanceThreshold:      3,
+	}
+	tests := []struct {
+		title, summary string
+		priority       int
+		wantScore      float64
+		wantImportant  bool
+	}{
+		{"Weekly roundup", "Nothing new.", 0, 0, false},
+		{"Zero-day in gateway", "Patch now.", 0, 2, false},
+		{"Zero-day in gateway", "Ransomware gangs exploit it, another zero-day follows.", 0, 4, true},
+		{"Vendor advisory", "A ransomware note.", 1, 3, true},
+		{"Vendor advisory", "Nothing new.", 5, 5, true},
+	}
+	for _, tt := range tests {
+		if got := d.ArticleImportance(tt.title, tt.summary, tt.priority); got != tt.wantScore {
+			t.Errorf("ArticleImportance(%q, %q, %d) = %v, want %v", tt.title, tt.summary, tt.priority, got, tt.wantScore)
+		}
+		if got := d.IsImportant(tt.title, tt.summary, tt.priority); got != tt.wantImportant {
+			t.Errorf("IsImportant(%q, %q, %d) = %v, want %v", tt.title, tt.summary, tt.priority, got, tt.wantImportant)
+		}
+	}
+
+	d.ImportanceThreshold = 0
+	if d.IsImportant("Zero-day ransomware", "", 10) {
+		t.Error("a threshold of 0 should disable highlighting")
+	}
+
+	for _, cfg := range []*Config{
+		{Discord: DiscordConfig{ImportanceThreshold: -1}},
+		{Discord: DiscordConfig{ImportantRoleID: "@security"}},
+	} {
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("Validate() should reject %+v", cfg.Discord)
+		}
+	}
+}
+
+func TestParseLogLevel(t *testing.T) {
+	tests := []struct {
+		value string
+		want  slog.Level
+	}{
+		{"", slog.LevelInfo},
+		{"debug", slog.LevelDebug},
+		{"INFO", slog.LevelInfo},
+		{"warn", slog.LevelWarn},
+		{"warning", slog.LevelWarn},
+		{" error ", slog.LevelError},
+	}
+	for _, tt := range tests {
+		got, err := ParseLogLevel(tt.value)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseLogLevel(%q) = %v, %v; want %v", tt.value, got, err, tt.want)
+		}
+	}
+
+	cfg := &Config{App: AppConfig{LogLevel: "verbose"}}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() should reject LOG_LEVEL=verbose")
+	}
+}
+
+func TestLoadConfigFile(t *testing.T) {
+	path := t.TempDir() + "/config.yaml"
+	file := `OLLAMA_MODEL: llama3
+OLLAMA_MAX_RETRIES: 5
+OLLAMA_STREAM: true
+RSS_FETCH_INTERVAL: 10m
+DISCORD_EXCLUDED_FEEDS: [cvefeed.io, example.com]
+DB_HOST: file-db
+`
+	if err := os.WriteFile(path, []byte(file), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CONFIG_FILE", path)
+	for _, key := range []string{"OLLAMA_MODEL", "OLLAMA_MAX_RETRIES", "OLLAMA_STREAM", "RSS_FETCH_INTERVAL", "DISCORD_EXCLUDED_FEEDS", "DB_PORT", "OLLAMA_TIMEOUT"} {
+		t.Setenv(key, "")
+	}
+	t.Setenv("DB_HOST", "env-db")
+	t.Cleanup(func() { fileValues = nil })
+
+	cfg := Load()
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() = %v", err)
+	}
+
+	// Values from the file
+	if cfg.OLLAMA.Model != "llama3" || cfg.OLLAMA.MaxRetries != 5 || !cfg.OLLAMA.Stream || cfg.App.RSSFetchInterval != 10*time.Minute {
+		t.Errorf("file values not loaded: model=%q retries=%d stream=%v interval=%v",
+			cfg.OLLAMA.Model, cfg.OLLAMA.MaxRetries, cfg.OLLAMA.Stream, cfg.App.RSSFetchInterval)
+	}
+	if want := []string{"cvefeed.io", "example.com"}; !reflect.DeepEqual(cfg.Discord.ExcludedFeeds, want) {
+		t.Errorf("ExcludedFeeds = %v, want %v", cfg.Discord.ExcludedFeeds, want)
+	}
+	// The environment wins over the file
+	if cfg.Database.Host != "env-db" {
+		t.Errorf("Database.Host = %q, want the environment's env-db", cfg.Database.Host)
+	}
+	// Defaults fill in whatever neither sets
+	if cfg.Database.Port != "5432" || cfg.OLLAMA.Timeout != 60*time.Second {
+		t.Errorf("defaults not applied: port=%q timeout=%v", cfg.Database.Port, cfg.OLLAMA.Timeout)
+	}
+}
+
+func TestLoadConfigFileErrors(t *testing.T) {
+	dir := t.TempDir()
+	nested := dir + "/nested.yaml"
+	if err := os.WriteFile(nested, []byte("ollama:\n  model: llama3\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { fileValues = nil })
+
+	for name, path := range map[string]string{"missing": dir + "/missing.yaml", "nested": nested} {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv("CONFIG_FILE", path)
+			if err := Load().Validate(); err == nil || !strings.Contains(err.Error(), "CONFIG_FILE") {
+				t.Errorf("Validate() = %v, want a CONFIG_FILE error", err)
+			}
+		})
+	}
+
+	t.Run("no file keeps env-only behavior", func(t *testing.T) {
+		t.Setenv("CONFIG_FILE", "")
+		t.Setenv("OLLAMA_MODEL", "")
+		cfg := Load()
+		if err := cfg.Validate(); err != nil || cfg.OLLAMA.Model != "llama2" {
+			t.Errorf("got model %q, err %v; want the llama2 default", cfg.OLLAMA.Model, err)
+		}
+	})
+}
+
+func TestValidateNotificationFormat(t *testing.T) {
+	for _, format := range []string{"", "raw", "cloudevents"} {
+		cfg := &Config{Notifications: NotificationsConfig{Format: format}}
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("Validate() with format %q = %v", format, err)
+		}
+	}
+	cfg := &Config{Notifications: NotificationsConfig{Format: "xml"}}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() should reject an unknown notification format")
+	}
+}
+
+func TestValidateEventBrokers(t *testing.T) {
+	cfg := &Config{Events: EventsConfig{Brokers: []string{"nats://nats:4222", "nats-2:4222", "tls://nats-3:4222"}}}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() with NATS brokers: unexpected error %v", err)
+	}
+	cfg.Events.Brokers = []string{"kafka://kafka:9092"}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() should reject a broker that isn't NATS")
+	}
+
+	for name, events := range map[string]EventsConfig{
+		"two kinds of credentials":  {User: "ib", Password: "pw", Token: "t"},
+		"password without a user":   {Password: "pw"},
+		"certificate without a key": {TLSCertFile: "/run/secrets/nats.crt"},
+	} {
+		cfg := &Config{Events: events}
+		if err := cfg.Validate(); err == nil {
+			t.Errorf("Validate() should reject %s", name)
+		}
+	}
+	cfg = &Config{Events: EventsConfig{User: "ib", Password: "pw", TLSCertFile: "/run/secrets/nats.crt", TLSKeyFile: "/run/secrets/nats.key"}}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() with a user and a client certificate: unexpected error %v", err)
+	}
+}
+
+func TestLoadUserAgents(t *testing.T) {
+	t.Setenv("API_USER_AGENTS", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) | Bot/2 |")
+	cfg := Load()
+	want := []string{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko)", "Bot/2"}
+	if !reflect.DeepEqual(cfg.API.UserAgents, want) {
+		t.Errorf("UserAgents = %q, want %q", cfg.API.UserAgents, want)
+	}
+}
+
+func TestSecretsFromFiles(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(dir+"/db_password", []byte("s3cret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dir+"/webhooks", []byte("https://d/1|https://d/1b,https://d/2\r\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CONFIG_FILE", "")
+	t.Setenv("DB_PASSWORD", "inline")
+	t.Setenv("DB_PASSWORD_FILE", dir+"/db_password")
+	t.Setenv("DISCORD_WEBHOOK_URLS", "https://d/inline")
+	t.Setenv("DISCORD_WEBHOOK_URLS_FILE", dir+"/webhooks")
+	t.Setenv("ADMIN_API_TOKEN", "token")
+	t.Setenv("ADMIN_API_TOKEN_FILE", "")
+
+	cfg := Load()
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() = %v", err)
+	}
+	if cfg.Database.Password != "s3cret" {
+		t.Errorf("Database.Password = %q, want the file's s3cret", cfg.Database.Password)
+	}
+	if want := []string{"https://d/1|https://d/1b", "https://d/2"}; !reflect.DeepEqual(cfg.Discord.WebhookURLs, want) {
+		t.Errorf("Discord.WebhookURLs = %q, want %q", cfg.Discord.WebhookURLs, want)
+	}
+	// Without a file the inline value is used
+	if cfg.Security.AdminToken != "token" {
+		t.Errorf("Security.AdminToken = %q, want the inline token", cfg.Security.AdminToken)
+	}
+
+	t.Setenv("DB_PASSWORD_FILE", dir+"/missing")
+	if err := Load().Validate(); err == nil || !strings.Contains(err.Error(), "DB_PASSWORD_FILE") {
+		t.Errorf("Validate() = %v, want a DB_PASSWORD_FILE error", err)
+	}
+}
+
+func TestGetConnectionString(t *testing.T) {
+	db := DatabaseConfig{Host: "db", Port: "5432", User: "broker", Password: "pa ss'word", Name: "information_broker"}
+	cfg := &Config{Database: db}
+	if got, want := cfg.GetConnectionString(), `host=db port=5432 user=broker password='pa ss\'word' dbname=information_broker sslmode=disable`; got != want {
+		t.Errorf("GetConnectionString() = %q, want %q", got, want)
+	}
+
+	db.SSLMode, db.SSLRootCert, db.SSLCert, db.SSLKey = "verify-full", "/certs/ca.pem", "/certs/client.pem", "/certs/client key.pem"
+	cfg = &Config{Database: db}
+	want := `host=db port=5432 user=broker password='pa ss\'word' dbname=information_broker sslmode=verify-full` +
+		` sslrootcert=/certs/ca.pem sslcert=/certs/client.pem sslkey='/certs/client key.pem'`
+	if got := cfg.GetConnectionString(); got != want {
+		t.Errorf("GetConnectionString() = %q, want %q", got, want)
+	}
+}
+
+func TestValidateSSLMode(t *testing.T) {
+	for _, mode := range []string{"", "disable", "require", "verify-ca", "verify-full"} {
+		cfg := &Config{Database: DatabaseConfig{SSLMode: mode}}
+		if err := cfg.Validate(); err != nil {
+			t.Errorf("Validate() with sslmode %q: unexpected error %v", mode, err)
+		}
+	}
+	for _, mode := range []string{"prefer", "on", "REQUIRE"} {
+		cfg := &Config{Database: DatabaseConfig{SSLMode: mode}}
+		if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "DB_SSLMODE") {
+			t.Errorf("Validate() with sslmode %q = %v, want a DB_SSLMODE error", mode, err)
+		}
+	}
+	cfg := &Config{Database: DatabaseConfig{SSLMode: "require", SSLCert: "/certs/client.pem"}}
+	if err := cfg.Validate(); err == nil {
+		t.Error("Validate() should reject a client certificate without its key")
+	}
+}
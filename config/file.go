@@ -0,0 +1,89 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// fileValues holds the settings read from CONFIG_FILE by the last Load, keyed
+// by environment variable name. lookup consults it only for variables that
+// aren't set in the environment.
+var fileValues map[string]string
+
+// lookup returns the value of the setting key: the environment variable if it
+// is set, otherwise the config file's value, otherwise "".
+func lookup(key string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fileValues[key]
+}
+
+// loadConfigFile reads a YAML config file of top-level settings named after
+// their environment variables, for example:
+//
+//	OLLAMA_MODEL: llama3
+//	RSS_FETCH_INTERVAL: 10m
+//	DISCORD_EXCLUDED_FEEDS: [cvefeed.io, example.com]
+//
+// Scalars are used as written; lists are joined with commas the way the
+// slice-valued variables expect. An empty path means no file.
+func loadConfigFile(path string) (map[string]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var raw map[string]interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	values := make(map[string]string, len(raw))
+	for key, value := range raw {
+		s, err := configFileValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("config file %s: %s: %w", path, key, err)
+		}
+		values[key] = s
+	}
+	return values, nil
+}
+
+// configFileValue renders a decoded YAML value the way it would be written
+// in the environment.
+func configFileValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case time.Time:
+		return v.Format(time.RFC3339), nil
+	case []interface{}:
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			s, err := configFileValue(item)
+			if err != nil {
+				return "", err
+			}
+			if strings.Contains(s, ",") {
+				return "", fmt.Errorf("list item %q contains a comma", s)
+			}
+			parts = append(parts, s)
+		}
+		return strings.Join(parts, ","), nil
+	case map[interface{}]interface{}:
+		return "", fmt.Errorf("nested mappings are not supported; use the flat environment variable name")
+	default:
+		return fmt.Sprint(v), nil
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import "time"
+
+// contentBudget caps how long one feed may spend fetching full article
+// content in a single cycle, so a feed with a burst of new items can't hold a
+// fetch slot indefinitely. Items are processed one at a time per feed, so no
+// locking is needed. A zero limit means unlimited.
+type contentBudget struct {
+	limit    time.Duration
+	spent    time.Duration
+	deferred int // Articles saved without a content fetch because the budget ran out
+}
+
+func newContentBudget(limit time.Duration) *contentBudget {
+	return &contentBudget{limit: limit}
+}
+
+// fetchTimeout returns the timeout for the next content fetch: perFetch,
+// shortened to whatever budget remains. ok is false once the budget is spent
+// and the fetch should be skipped.
+func (b *contentBudget) fetchTimeout(perFetch time.Duration) (timeout time.Duration, ok bool) {
+	if b.limit <= 0 {
+		return perFetch, true
+	}
+	remaining := b.limit - b.spent
+	if remaining <= 0 {
+		return 0, false
+	}
+	return min(perFetch, remaining), true
+}
+
+// charge records time spent on a content fetch.
+func (b *contentBudget) charge(d time.Duration) {
+	b.spent += d
+}
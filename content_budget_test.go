@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+
+	"information-broker/config"
+)
+
+func TestContentBudgetFetchTimeout(t *testing.T) {
+	unlimited := newContentBudget(0)
+	unlimited.charge(time.Hour)
+	if timeout, ok := unlimited.fetchTimeout(30 * time.Second); !ok || timeout != 30*time.Second {
+		t.Errorf("unlimited budget: got (%v, %v), want (30s, true)", timeout, ok)
+	}
+
+	b := newContentBudget(time.Minute)
+	if timeout, ok := b.fetchTimeout(30 * time.Second); !ok || timeout != 30*time.Second {
+		t.Errorf("fresh budget: got (%v, %v), want (30s, true)", timeout, ok)
+	}
+	b.charge(50 * time.Second)
+	if timeout, ok := b.fetchTimeout(30 * time.Second); !ok || timeout != 10*time.Second {
+		t.Errorf("nearly spent budget should shorten the fetch: got (%v, %v), want (10s, true)", timeout, ok)
+	}
+	b.charge(10 * time.Second)
+	if _, ok := b.fetchTimeout(30 * time.Second); ok {
+		t.Errorf("spent budget should skip the fetch")
+	}
+}
+
+func TestLoadArticleContentDefersPastBudget(t *testing.T) {
+	const pageDelay = 40 * time.Millisecond
+
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		select {
+		case <-time.After(pageDelay):
+		case <-r.Context().Done():
+			return
+		}
+		io.WriteString(w, "<html><body><article>"+strings.Repeat("Full article body. ", 10)+"</article></body></html>")
+	}))
+	defer srv.Close()
+
+	m := &RSSMonitor{
+		httpClient: &http.Client{},
+		metrics:    testMetrics(),
+		config: &config.Config{
+			API:         config.APIConfig{Timeout: 5 * time.Second},
+			Performance: config.PerformanceConfig{MaxArticleContentLength: 10000},
+		},
+	}
+
+	// Room for roughly one and a half page fetches
+	budget := newContentBudget(pageDelay * 3 / 2)
+	items := []*gofeed.Item{
+		{Link: srv.URL + "/1", Description: "desc 1"},
+		{Link: srv.URL + "/2", Description: "desc 2"},
+		{Link: srv.URL + "/3", Description: "desc 3"},
+	}
+
+	var results []string
+	var deferredFlags []bool
+	for _, item := range items {
+		content, _, _, deferred := m.loadArticleContent(item, "", budget)
+		results = append(results, content)
+		deferredFlags = append(deferredFlags, deferred)
+	}
+
+	if !strings.Contains(results[0], "Full article body") || deferredFlags[0] {
+		t.Errorf("first article should be fetched in full, got %q (deferred=%v)", results[0], deferredFlags[0])
+	}
+	if results[2] != "desc 3" || !deferredFlags[2] {
+		t.Errorf("last article should fall back to its description and be deferred, got %q (deferred=%v)", results[2], deferredFlags[2])
+	}
+	if got := atomic.LoadInt32(&hits); got >= 3 {
+		t.Errorf("expected content fetches to stop once the budget ran out, server saw %d", got)
+	}
+	if budget.deferred < 1 {
+		t.Errorf("budget.deferred = %d, want at least 1", budget.deferred)
+	}
+}
+
+func TestFetchFullContentCapsRawRead(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "<html><body><article><p>Opening paragraph.</p>"+strings.Repeat("<p>filler</p>", 1000)+"<p>PAST THE CAP</p></article></body></html>")
+	}))
+	defer srv.Close()
+
+	m := &RSSMonitor{
+		httpClient: &http.Client{},
+		config: &config.Config{
+			Performance: config.PerformanceConfig{MaxArticleContentLength: 100000, MaxRawContentBytes: 4096},
+		},
+	}
+	content, err := m.fetchFullContent(context.Background(), srv.URL, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(content, "Opening paragraph.") || strings.Contains(content, "PAST THE CAP") {
+		t.Errorf("content (%d bytes) should keep the start of the page and stop at the raw cap", len(content))
+	}
+
+	m.config.Performance.MaxRawContentBytes = 0
+	if content, _ := m.fetchFullContent(context.Background(), srv.URL, ""); !strings.Contains(content, "PAST THE CAP") {
+		t.Error("a zero cap should read the whole page")
+	}
+}
+
+func TestFetchFullContentGlobalConcurrency(t *testing.T) {
+	var inFlight, peak int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+		io.WriteString(w, "<html><body><article>Body.</article></body></html>")
+	}))
+	defer srv.Close()
+
+	m := &RSSMonitor{
+		httpClient:   &http.Client{},
+		contentSlots: make(chan struct{}, 2),
+		config: &config.Config{
+			Performance: config.PerformanceConfig{MaxArticleContentLength: 10000},
+		},
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := m.fetchFullContent(context.Background(), srv.URL, ""); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := atomic.LoadInt32(&peak); got != 2 {
+		t.Errorf("peak concurrent content fetches = %d, want 2", got)
+	}
+
+	// A fetch waiting for a slot gives up with its context
+	m.contentSlots <- struct{}{}
+	m.contentSlots <- struct{}{}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := m.fetchFullContent(ctx, srv.URL, ""); err == nil {
+		t.Error("expected an error while every slot is taken")
+	}
+}
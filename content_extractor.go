@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"gopkg.in/yaml.v2"
+)
+
+// ContentExtractor extracts article text from fetched pages, consulting
+// per-domain selector rules before extractMainContent's generic selectors.
+// A nil *ContentExtractor has no rules.
+type ContentExtractor struct {
+	rules map[string][]string // Lowercased host -> selectors, in priority order
+}
+
+// LoadContentExtractor reads per-domain rules from a YAML or JSON file that
+// maps a host to a selector or a list of selectors:
+//
+//	example.com: .story__body
+//	news.example.org: [".article-text", "#main-story"]
+//
+// A rule for a host also covers its subdomains (example.com covers
+// www.example.com) unless a subdomain has a rule of its own. An empty path
+// returns an extractor with no rules.
+func LoadContentExtractor(path string) (*ContentExtractor, error) {
+	e := &ContentExtractor{rules: make(map[string][]string)}
+	if path == "" {
+		return e, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read content extraction rules: %w", err)
+	}
+	var raw map[string]interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse content extraction rules %s: %w", path, err)
+	}
+
+	for host, value := range raw {
+		var selectors []string
+		switch v := value.(type) {
+		case string:
+			selectors = []string{v}
+		case []interface{}:
+			for _, item := range v {
+				selector, ok := item.(string)
+				if !ok {
+					return nil, fmt.Errorf("content extraction rule for %s: selectors must be strings", host)
+				}
+				selectors = append(selectors, selector)
+			}
+		default:
+			return nil, fmt.Errorf("content extraction rule for %s: want a selector or a list of selectors", host)
+		}
+
+		var cleaned []string
+		for _, selector := range selectors {
+			if selector = strings.TrimSpace(selector); selector != "" {
+				cleaned = append(cleaned, selector)
+			}
+		}
+		if len(cleaned) == 0 {
+			return nil, fmt.Errorf("content extraction rule for %s has no selectors", host)
+		}
+		e.rules[strings.ToLower(strings.TrimSpace(host))] = cleaned
+	}
+	return e, nil
+}
+
+// Len returns the number of domains with rules.
+func (e *ContentExtractor) Len() int {
+	if e == nil {
+		return 0
+	}
+	return len(e.rules)
+}
+
+// selectorsFor returns the rule selectors for the first of urls whose host,
+// or a parent domain of it, has a rule.
+func (e *ContentExtractor) selectorsFor(urls ...string) []string {
+	if e.Len() == 0 {
+		return nil
+	}
+	for _, rawURL := range urls {
+		u, err := url.Parse(rawURL)
+		if err != nil {
+			continue
+		}
+		host := strings.ToLower(u.Hostname())
+		for host != "" {
+			if selectors, ok := e.rules[host]; ok {
+				return selectors
+			}
+			_, parent, found := strings.Cut(host, ".")
+			if !found {
+				break
+			}
+			host = parent
+		}
+	}
+	return nil
+}
+
+// Extract returns the article text of doc. If the article or feed URL's host
+// has a rule, the text of every element matching its first selector that
+// matches anything is used; otherwise, or if no rule selector matches, it
+// falls back to extractMainContent.
+func (e *ContentExtractor) Extract(doc *goquery.Document, articleURL, feedURL string) string {
+	selectors := e.selectorsFor(articleURL, feedURL)
+	if len(selectors) > 0 {
+		stripNonArticleElements(doc)
+		for _, selector := range selectors {
+			var parts []string
+			doc.Find(selector).Each(func(_ int, s *goquery.Selection) {
+				if text := strings.TrimSpace(s.Text()); text != "" {
+					parts = append(parts, text)
+				}
+			})
+			if len(parts) > 0 {
+				return strings.Join(parts, "\n\n")
+			}
+		}
+	}
+	return extractMainContent(doc)
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+const extractorTestPage = `<html><body>
+	<nav>Home | World | Sponsored offers</nav>
+	<article>
+		<div class="promo">Subscribe now for unlimited access to every story on the site.</div>
+		<div class="story__body"><p>The real story text.</p><script>track()</script></div>
+		<div class="story__body"><p>Its second half.</p></div>
+	</article>
+</body></html>`
+
+func writeExtractionRules(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestContentExtractorExtract(t *testing.T) {
+	path := writeExtractionRules(t, "rules.yaml", `
+example.com: .story__body
+feeds.example.org: [".missing", ".story__body"]
+nothing.example.net: .missing
+`)
+	extractor, err := LoadContentExtractor(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if extractor.Len() != 3 {
+		t.Errorf("Len() = %d, want 3", extractor.Len())
+	}
+
+	tests := []struct {
+		name       string
+		articleURL string
+		feedURL    string
+		wantRule   bool
+	}{
+		{"exact host", "https://example.com/story", "", true},
+		{"subdomain of a rule's host", "https://www.example.com/story", "", true},
+		{"feed host when the article host has no rule", "https://cdn.other.com/story", "https://feeds.example.org/rss", true},
+		{"later selector when the first matches nothing", "https://feeds.example.org/story", "", true},
+		{"no rule uses the default selectors", "https://unrelated.com/story", "https://unrelated.com/rss", false},
+		{"rule matching nothing falls back", "https://nothing.example.net/story", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(extractorTestPage))
+			if err != nil {
+				t.Fatalf("parse: %v", err)
+			}
+			got := extractor.Extract(doc, tt.articleURL, tt.feedURL)
+
+			if tt.wantRule {
+				if got != "The real story text.\n\nIts second half." {
+					t.Errorf("got %q, want just the .story__body text", got)
+				}
+			} else if !strings.Contains(got, "Subscribe now") {
+				t.Errorf("got %q, want the default <article> extraction", got)
+			}
+			if strings.Contains(got, "track()") {
+				t.Errorf("script text leaked into %q", got)
+			}
+		})
+	}
+}
+
+func TestLoadContentExtractor(t *testing.T) {
+	t.Run("JSON", func(t *testing.T) {
+		path := writeExtractionRules(t, "rules.json", `{"Example.com": [".story__body"], "other.com": "#main"}`)
+		extractor, err := LoadContentExtractor(path)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if got := extractor.selectorsFor("https://example.com/a"); len(got) != 1 || got[0] != ".story__body" {
+			t.Errorf("selectors for example.com = %v", got)
+		}
+	})
+
+	t.Run("no file", func(t *testing.T) {
+		extractor, err := LoadContentExtractor("")
+		if err != nil || extractor.Len() != 0 {
+			t.Errorf("got %v rules, error %v; want an empty extractor", extractor.Len(), err)
+		}
+	})
+
+	for name, content := range map[string]string{
+		"nested map":       "example.com:\n  selector: .story\n",
+		"non-string entry": "example.com: [1, 2]\n",
+		"empty selector":   "example.com: \"  \"\n",
+		"malformed":        "example.com: [.story\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			if _, err := LoadContentExtractor(writeExtractionRules(t, "rules.yaml", content)); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+	if _, err := LoadContentExtractor(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
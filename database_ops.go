@@ -24,6 +24,7 @@ type DatabaseArticle struct {
 	FeedURL         *string    `json:"feed_url,omitempty"`
 	ContentHash     *string    `json:"content_hash,omitempty"`
 	FetchDurationMs *int       `json:"fetch_duration_ms,omitempty"`
+	Language        *string    `json:"language,omitempty"`
 }
 
 // WebhookLog represents a webhook attempt log in the database
@@ -295,6 +296,29 @@ func (ops *DatabaseOperations) UpdateArticleDiscordStatusByURL(url string, poste
 	return nil
 }
 
+// SetArticleSummary stores the summary of the article with the given URL. An
+// article that doesn't exist, say because it was purged while being
+// summarized, is reported as an error wrapping sql.ErrNoRows.
+func (ops *DatabaseOperations) SetArticleSummary(url, summary string) error {
+	query := `UPDATE articles SET summary = $1, updated_at = NOW() WHERE url = $2`
+
+	result, err := ops.db.Exec(query, summary, url)
+	if err != nil {
+		return fmt.Errorf("failed to update summary: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get affected rows: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("article with URL %s not found: %w", url, sql.ErrNoRows)
+	}
+
+	return nil
+}
+
 // InsertWebhookLog inserts a new webhook log entry atomically
 func (ops *DatabaseOperations) InsertWebhookLog(log *WebhookLog) (*WebhookLog, error) {
 	tx, err := ops.db.Begin()
@@ -527,6 +551,121 @@ func (ops *DatabaseOperations) GetArticleByURL(url string) (*DatabaseArticle, er
 	return &article, nil
 }
 
+// GetArticleByID gets an article by its primary key. A missing article is
+// reported as an error wrapping sql.ErrNoRows.
+func (ops *DatabaseOperations) GetArticleByID(id int64) (*DatabaseArticle, error) {
+	query := `
+		SELECT id, title, url, publish_date, summary, full_content, 
+			   fetch_time, posted_to_discord, created_at, updated_at,
+			   feed_url, content_hash, fetch_duration_ms, language
+		FROM articles 
+		WHERE id = $1`
+
+	var article DatabaseArticle
+	err := ops.db.QueryRow(query, id).Scan(
+		&article.ID,
+		&article.Title,
+		&article.URL,
+		&article.PublishDate,
+		&article.Summary,
+		&article.FullContent,
+		&article.FetchTime,
+		&article.PostedToDiscord,
+		&article.CreatedAt,
+		&article.UpdatedAt,
+		&article.FeedURL,
+		&article.ContentHash,
+		&article.FetchDurationMs,
+		&article.Language,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, fmt.Errorf("article with ID %d not found: %w", id, err)
+		}
+		return nil, fmt.Errorf("failed to get article: %w", err)
+	}
+
+	return &article, nil
+}
+
+// CountWebhookLogsByArticle returns how many webhook attempts were logged for an article
+func (ops *DatabaseOperations) CountWebhookLogsByArticle(articleID int64) (int, error) {
+	var count int
+	err := ops.db.QueryRow("SELECT COUNT(*) FROM webhook_logs WHERE article_id = $1", articleID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count webhook logs: %w", err)
+	}
+	return count, nil
+}
+
+// PurgeResult reports how many rows DeleteArticlesOlderThan removed from each
+// table, plus the URLs of the deleted articles.
+type PurgeResult struct {
+	Articles    int64    `json:"articles"`
+	WebhookLogs int64    `json:"webhook_logs"`
+	SummaryLogs int64    `json:"summary_logs"`
+	URLs        []string `json:"-"`
+}
+
+// DeleteArticlesOlderThan deletes every article published before cutoff along
+// with its webhook and summary logs, in one transaction. Logs are deleted
+// explicitly rather than left to ON DELETE CASCADE so they can be counted, and
+// because summary_logs is keyed by URL with no foreign key at all.
+func (ops *DatabaseOperations) DeleteArticlesOlderThan(cutoff time.Time) (*PurgeResult, error) {
+	tx, err := ops.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result := &PurgeResult{}
+
+	res, err := tx.Exec(`
+		DELETE FROM webhook_logs
+		WHERE article_id IN (SELECT id FROM articles WHERE publish_date < $1)`, cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete webhook logs: %w", err)
+	}
+	if result.WebhookLogs, err = res.RowsAffected(); err != nil {
+		return nil, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	res, err = tx.Exec(`
+		DELETE FROM summary_logs
+		WHERE article_url IN (SELECT url FROM articles WHERE publish_date < $1)`, cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete summary logs: %w", err)
+	}
+	if result.SummaryLogs, err = res.RowsAffected(); err != nil {
+		return nil, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	rows, err := tx.Query(`DELETE FROM articles WHERE publish_date < $1 RETURNING url`, cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete articles: %w", err)
+	}
+	for rows.Next() {
+		var url string
+		if err := rows.Scan(&url); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan deleted article: %w", err)
+		}
+		result.URLs = append(result.URLs, url)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to delete articles: %w", err)
+	}
+	result.Articles = int64(len(result.URLs))
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return result, nil
+}
+
 // GetArticleCount returns the total number of articles in the database
 func (ops *DatabaseOperations) GetArticleCount() (int64, error) {
 	query := `SELECT COUNT(*) FROM articles`
@@ -539,3 +678,64 @@ func (ops *DatabaseOperations) GetArticleCount() (int64, error) {
 
 	return count, nil
 }
+
+// GetCrossFeedDuplicates reports pairs of feeds that published the same story
+// (matched by normalized title) since the given time, most overlapping first.
+// Only pairs sharing at least minSharedArticlesForOverlap articles are included.
+func (ops *DatabaseOperations) GetCrossFeedDuplicates(since time.Time) ([]FeedOverlap, error) {
+	query := `
+		SELECT feed_url, title
+		FROM articles
+		WHERE publish_date >= $1 AND feed_url IS NOT NULL AND feed_url <> ''`
+
+	rows, err := ops.db.Query(query, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query articles for duplicates: %w", err)
+	}
+	defer rows.Close()
+
+	var titles []feedTitle
+	for rows.Next() {
+		var ft feedTitle
+		if err := rows.Scan(&ft.FeedURL, &ft.Title); err != nil {
+			return nil, fmt.Errorf("failed to scan article: %w", err)
+		}
+		titles = append(titles, ft)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate articles: %w", err)
+	}
+
+	return findFeedOverlaps(titles, minSharedArticlesForOverlap), nil
+}
+
+// GetStaleFeeds reports feeds whose most recent article was published before
+// cutoff, stalest first.
+func (ops *DatabaseOperations) GetStaleFeeds(cutoff time.Time) ([]StaleFeed, error) {
+	query := `
+		SELECT feed_url, MAX(publish_date), COUNT(*)
+		FROM articles
+		WHERE feed_url IS NOT NULL AND feed_url <> '' AND publish_date IS NOT NULL
+		GROUP BY feed_url
+		HAVING MAX(publish_date) < $1`
+
+	rows, err := ops.db.Query(query, cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query stale feeds: %w", err)
+	}
+	defer rows.Close()
+
+	var feeds []StaleFeed
+	for rows.Next() {
+		var feed StaleFeed
+		if err := rows.Scan(&feed.FeedURL, &feed.LatestArticle, &feed.ArticleCount); err != nil {
+			return nil, fmt.Errorf("failed to scan stale feed: %w", err)
+		}
+		feeds = append(feeds, feed)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate stale feeds: %w", err)
+	}
+
+	return findStaleFeeds(feeds, cutoff, time.Now()), nil
+}
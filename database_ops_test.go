@@ -0,0 +1,25 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+)
+
+func TestSetArticleSummary(t *testing.T) {
+	db, recorder := openExecRecorder(t)
+	ops := NewDatabaseOperations(db)
+
+	if err := ops.SetArticleSummary("https://example.com/a", "A summary."); err != nil {
+		t.Fatalf("SetArticleSummary: %v", err)
+	}
+	writes := recorder.recordedWrites("UPDATE articles SET summary =")
+	if len(writes) != 1 || writes[0][0] != "A summary." || writes[0][1] != "https://example.com/a" {
+		t.Errorf("summary writes = %v", writes)
+	}
+
+	recorder.affectNothing("UPDATE articles SET summary =")
+	if err := ops.SetArticleSummary("https://example.com/purged", "A summary."); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("summary of a missing article: err = %v, want sql.ErrNoRows", err)
+	}
+}
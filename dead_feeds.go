@@ -0,0 +1,267 @@
+package main
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// feedStateChange is what one fetch outcome did to a feed's failure state.
+type feedStateChange int
+
+const (
+	feedStateUnchanged feedStateChange = iota
+	feedStateFailing                   // First failure after a success
+	feedStateRecovered                 // First success after failures
+	feedStateDisabled                  // Failing for longer than the threshold
+)
+
+// deadFeedTracker follows how long each feed has been failing, so a feed
+// that went away for good (an expired domain, say) can be disabled instead
+// of being polled against an open circuit breaker forever. The monitor
+// mirrors it in the feed_state table, so a restart neither re-enables a
+// disabled feed nor restarts a failing feed's clock.
+type deadFeedTracker struct {
+	mu           sync.Mutex
+	threshold    time.Duration
+	failingSince map[string]time.Time // Feed URL -> first failure since its last success
+	disabled     map[string]time.Time // Feed URL -> when it was disabled
+}
+
+func newDeadFeedTracker(threshold time.Duration) *deadFeedTracker {
+	return &deadFeedTracker{
+		threshold:    threshold,
+		failingSince: make(map[string]time.Time),
+		disabled:     make(map[string]time.Time),
+	}
+}
+
+// record notes the outcome of a fetch of feedURL at now and returns the
+// change it made. A feed is disabled by the first failure at least
+// threshold after its failures began; a zero threshold never disables.
+func (t *deadFeedTracker) record(feedURL string, ok bool, now time.Time) feedStateChange {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	since, failing := t.failingSince[feedURL]
+	if ok {
+		if !failing {
+			return feedStateUnchanged
+		}
+		delete(t.failingSince, feedURL)
+		return feedStateRecovered
+	}
+	if !failing {
+		t.failingSince[feedURL] = now
+		return feedStateFailing
+	}
+	if _, disabled := t.disabled[feedURL]; disabled || t.threshold <= 0 || now.Sub(since) < t.threshold {
+		return feedStateUnchanged
+	}
+	t.disabled[feedURL] = now
+	return feedStateDisabled
+}
+
+// restore sets a feed's state as loaded from feed_state; zero times are
+// left unset.
+func (t *deadFeedTracker) restore(feedURL string, failingSince, disabledAt time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !failingSince.IsZero() {
+		t.failingSince[feedURL] = failingSince
+	}
+	if !disabledAt.IsZero() {
+		t.disabled[feedURL] = disabledAt
+	}
+}
+
+// enable clears a feed's state and reports whether it was disabled.
+func (t *deadFeedTracker) enable(feedURL string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	_, disabled := t.disabled[feedURL]
+	delete(t.disabled, feedURL)
+	delete(t.failingSince, feedURL)
+	return disabled
+}
+
+// disabledAt reports when feedURL was disabled, if it is.
+func (t *deadFeedTracker) disabledAt(feedURL string) (time.Time, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	at, ok := t.disabled[feedURL]
+	return at, ok
+}
+
+// disabledCount returns how many feeds are disabled.
+func (t *deadFeedTracker) disabledCount() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.disabled)
+}
+
+// activeFeeds returns the feeds that aren't disabled.
+func (m *RSSMonitor) activeFeeds(feeds []Feed) []Feed {
+	active := make([]Feed, 0, len(feeds))
+	for _, feed := range feeds {
+		if _, disabled := m.deadFeeds.disabledAt(feed.URL); !disabled {
+			active = append(active, feed)
+		}
+	}
+	return active
+}
+
+// trackFeedHealth records the outcome of a fetch of feedURL, fetchErr nil
+// for a success, and disables the feed once it has failed for longer than
+// App.DeadFeedThreshold. It does nothing while the threshold is 0.
+func (m *RSSMonitor) trackFeedHealth(feedURL string, fetchErr error) {
+	if m.config.App.DeadFeedThreshold <= 0 {
+		return
+	}
+
+	now := time.Now().UTC()
+	var err error
+	switch m.deadFeeds.record(feedURL, fetchErr == nil, now) {
+	case feedStateFailing:
+		err = m.saveFeedState(`
+			INSERT INTO feed_state (feed_url, failing_since, updated_at)
+			VALUES ($1, $2, NOW())
+			ON CONFLICT (feed_url) DO UPDATE
+			SET failing_since = EXCLUDED.failing_since, updated_at = NOW()`, feedURL, now)
+	case feedStateRecovered:
+		err = m.saveFeedState(`
+			UPDATE feed_state SET failing_since = NULL, updated_at = NOW()
+			WHERE feed_url = $1`, feedURL)
+	case feedStateDisabled:
+		slog.Warn("Disabling dead feed", "feed_url", feedURL,
+			"threshold", m.config.App.DeadFeedThreshold.String(), "error", fetchErr)
+		m.metrics.RecordFeedDisabled(feedURL)
+		m.metrics.UpdateDisabledFeeds(m.deadFeeds.disabledCount())
+		err = m.saveFeedState(`
+			INSERT INTO feed_state (feed_url, disabled, disabled_at, last_error, updated_at)
+			VALUES ($1, TRUE, $2, $3, NOW())
+			ON CONFLICT (feed_url) DO UPDATE
+			SET disabled = TRUE, disabled_at = EXCLUDED.disabled_at, last_error = EXCLUDED.last_error, updated_at = NOW()`,
+			feedURL, now, fetchErr.Error())
+	}
+	if err != nil {
+		slog.Error("Failed to save feed state", "feed_url", feedURL, "error", err)
+	}
+}
+
+// saveFeedState runs one write to feed_state, if there is a database.
+func (m *RSSMonitor) saveFeedState(query string, args ...any) error {
+	if m.db == nil {
+		return nil
+	}
+	_, err := m.db.Exec(query, args...)
+	return err
+}
+
+// loadFeedState restores the failing and disabled feeds recorded by
+// previous runs. It does nothing while App.DeadFeedThreshold is 0, so
+// turning the feature off polls every feed again.
+func (m *RSSMonitor) loadFeedState() error {
+	if m.config.App.DeadFeedThreshold <= 0 || m.db == nil {
+		return nil
+	}
+
+	rows, err := m.db.Query(`
+		SELECT feed_url, failing_since, disabled_at
+		FROM feed_state
+		WHERE failing_since IS NOT NULL OR disabled`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var feedURL string
+		var failingSince, disabledAt *time.Time
+		if err := rows.Scan(&feedURL, &failingSince, &disabledAt); err != nil {
+			return err
+		}
+		var since, disabled time.Time
+		if failingSince != nil {
+			since = *failingSince
+		}
+		if disabledAt != nil {
+			disabled = *disabledAt
+		}
+		m.deadFeeds.restore(feedURL, since, disabled)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if count := m.deadFeeds.disabledCount(); count > 0 {
+		slog.Info("Skipping feeds disabled as dead; re-enable them through /admin/feeds/enable", "feeds", count)
+	}
+	m.metrics.UpdateDisabledFeeds(m.deadFeeds.disabledCount())
+	return nil
+}
+
+// EnableFeed re-enables a feed disabled as dead, clearing its failure
+// history, and reports whether it was disabled.
+func (m *RSSMonitor) EnableFeed(feedURL string) (bool, error) {
+	wasDisabled := m.deadFeeds.enable(feedURL)
+	m.metrics.UpdateDisabledFeeds(m.deadFeeds.disabledCount())
+	if err := m.saveFeedState(`DELETE FROM feed_state WHERE feed_url = $1`, feedURL); err != nil {
+		return wasDisabled, err
+	}
+	if wasDisabled {
+		slog.Info("Re-enabled dead feed", "feed_url", feedURL)
+	}
+	return wasDisabled, nil
+}
+
+// enableFeed re-enables the feed in ?url= after it was disabled as dead.
+// Feeds that aren't monitored get 404; enabling one that isn't disabled
+// just clears its failure history.
+func (s *APIServer) enableFeed(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !s.requireAdmin(w, r) {
+		return
+	}
+	if s.monitor == nil {
+		http.Error(w, "RSS monitor not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	feedURL := r.URL.Query().Get("url")
+	if feedURL == "" {
+		http.Error(w, "url is required", http.StatusBadRequest)
+		return
+	}
+	monitored := false
+	for _, feed := range s.monitor.Feeds() {
+		if feed.URL == feedURL {
+			monitored = true
+			break
+		}
+	}
+	if !monitored {
+		http.Error(w, "Feed not monitored", http.StatusNotFound)
+		return
+	}
+
+	wasDisabled, err := s.monitor.EnableFeed(feedURL)
+	if err != nil {
+		slog.Error("Failed to clear feed state", "feed_url", feedURL, "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"feed_url":     feedURL,
+		"was_disabled": wasDisabled,
+	})
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"information-broker/config"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDeadFeedTracker(t *testing.T) {
+	const feed = "https://dead.example/rss"
+	start := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	tracker := newDeadFeedTracker(72 * time.Hour)
+
+	steps := []struct {
+		at   time.Duration
+		ok   bool
+		want feedStateChange
+	}{
+		{0, true, feedStateUnchanged},
+		{time.Hour, false, feedStateFailing},
+		{24 * time.Hour, false, feedStateUnchanged},
+		{48 * time.Hour, true, feedStateRecovered}, // A success restarts the clock
+		{49 * time.Hour, false, feedStateFailing},
+		{120 * time.Hour, false, feedStateUnchanged},
+		{121 * time.Hour, false, feedStateDisabled},
+		{122 * time.Hour, false, feedStateUnchanged},
+	}
+	for i, step := range steps {
+		if got := tracker.record(feed, step.ok, start.Add(step.at)); got != step.want {
+			t.Errorf("step %d (+%v, ok=%v) = %v, want %v", i, step.at, step.ok, got, step.want)
+		}
+	}
+	if at, ok := tracker.disabledAt(feed); !ok || !at.Equal(start.Add(121*time.Hour)) {
+		t.Errorf("disabledAt = %v, %v; want disabled at +121h", at, ok)
+	}
+
+	if !tracker.enable(feed) || tracker.enable(feed) {
+		t.Error("enable should report the feed disabled once, then not")
+	}
+	if got := tracker.record(feed, false, start.Add(200*time.Hour)); got != feedStateFailing {
+		t.Errorf("first failure after re-enabling = %v, want a fresh failing period", got)
+	}
+
+	if got := newDeadFeedTracker(0).record(feed, false, start); got != feedStateFailing {
+		t.Errorf("record with no threshold = %v", got)
+	}
+	off := newDeadFeedTracker(0)
+	off.record(feed, false, start)
+	if got := off.record(feed, false, start.Add(1000*time.Hour)); got != feedStateUnchanged {
+		t.Errorf("a zero threshold disabled the feed: %v", got)
+	}
+}
+
+func TestTrackFeedHealthDisablesDeadFeeds(t *testing.T) {
+	db, recorder := openExecRecorder(t)
+	cfg := &config.Config{App: config.AppConfig{DeadFeedThreshold: time.Hour}}
+	feeds := []Feed{{URL: "https://dead.example/rss"}, {URL: "https://alive.example/rss"}}
+	m := NewRSSMonitor(db, feeds, testMetrics(), cfg, NewCircuitBreakerManager(), nil, nil)
+	m.deadFeeds.restore("https://dead.example/rss", time.Now().Add(-2*time.Hour), time.Time{})
+
+	m.trackFeedHealth("https://dead.example/rss", errors.New("no such host"))
+	if active := m.activeFeeds(feeds); len(active) != 1 || active[0].URL != "https://alive.example/rss" {
+		t.Fatalf("active feeds = %+v, want the dead feed left out", active)
+	}
+	if writes := recorder.recordedWrites("INSERT INTO feed_state"); len(writes) != 1 || writes[0][2] != "no such host" {
+		t.Errorf("feed_state writes = %v, want the feed disabled with its last error", writes)
+	}
+
+	s := &APIServer{monitor: m, config: cfg}
+	enable := func(target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		s.enableFeed(rec, httptest.NewRequest(http.MethodPost, target, nil))
+		return rec
+	}
+	cfg.Security.AdminSecret = "k3y" // Vouched for by requireSignature
+
+	if rec := enable("/admin/feeds/enable?url=https://unknown.example/rss"); rec.Code != http.StatusNotFound {
+		t.Errorf("enabling an unmonitored feed = %d, want 404", rec.Code)
+	}
+	rec := enable("/admin/feeds/enable?url=https://dead.example/rss")
+	var body struct {
+		WasDisabled bool `json:"was_disabled"`
+	}
+	if rec.Code != http.StatusOK || json.NewDecoder(rec.Body).Decode(&body) != nil || !body.WasDisabled {
+		t.Fatalf("enable = %d %s, want the feed reported as disabled before", rec.Code, rec.Body)
+	}
+	if len(m.activeFeeds(feeds)) != 2 || len(recorder.recordedWrites("DELETE FROM feed_state")) != 1 {
+		t.Error("re-enabled feed isn't polled again or its feed_state row wasn't cleared")
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"information-broker/config"
+	"log/slog"
+	"time"
+)
+
+// discordBreakerName names the breaker that guards the whole Discord post
+// step, as opposed to the per-feed and per-backend breakers.
+const discordBreakerName = "discord"
+
+// discordReplayBatchSize caps how many flagged posts one replay pass sends, so
+// a long outage's backlog drains gradually instead of tripping rate limits.
+const discordReplayBatchSize = 10
+
+// newDiscordBreaker returns the global Discord breaker, or nil if it is
+// disabled (a failure threshold of 0) or there is no breaker manager.
+func newDiscordBreaker(breakers *CircuitBreakerManager, cfg config.DiscordConfig) *CircuitBreaker {
+	if breakers == nil || cfg.BreakerFailureThreshold <= 0 {
+		return nil
+	}
+	return breakers.GetOrCreateBreaker(discordBreakerName, &CircuitBreakerConfig{
+		FailureThreshold:      cfg.BreakerFailureThreshold,
+		SuccessThreshold:      1,
+		Timeout:               cfg.BreakerTimeout,
+		ResetTimeout:          5 * time.Minute,
+		HalfOpenMaxConcurrent: 1,
+	})
+}
+
+// postArticleToDiscord sends the article to every webhook group through the
+// Discord breaker and returns how many groups accepted it. A post counts as a
+// failure for the breaker only when no group accepted it. While the breaker
+// is open the post isn't attempted at all: the article is flagged for replay
+// and skipped is true.
+func (s *SummarizationScheduler) postArticleToDiscord(request SummarizationRequest, articleMessage ArticleMessage, webhookGroups [][]string) (successCount int64, skipped bool) {
+	if s.discordBreaker == nil {
+		return s.sendToWebhookGroups(request, articleMessage, webhookGroups), false
+	}
+
+	err := s.discordBreaker.Execute(func() error {
+		successCount = s.sendToWebhookGroups(request, articleMessage, webhookGroups)
+		if successCount == 0 {
+			return fmt.Errorf("no Discord webhook accepted the post")
+		}
+		return nil
+	}, s.metrics)
+
+	if errors.Is(err, ErrCircuitBreakerOpen) {
+		slog.Warn("Skipping Discord notification: Discord circuit breaker open, flagged for replay", "article_url", request.ArticleURL, "request_id", request.RequestID)
+		s.metrics.RecordDiscordWebhookError("circuit_open")
+		if err := s.flagDiscordReplay(request.ArticleURL); err != nil {
+			slog.Error("Failed to flag article for Discord replay", "article_url", request.ArticleURL, "request_id", request.RequestID, "error", err)
+		}
+		return 0, true
+	}
+	return successCount, false
+}
+
+// flagDiscordReplay marks an article whose post the breaker skipped.
+func (s *SummarizationScheduler) flagDiscordReplay(articleURL string) error {
+	_, err := s.db.Exec(`UPDATE articles SET discord_replay_pending = TRUE WHERE url = $1`, articleURL)
+	return err
+}
+
+// discordReplayer periodically re-sends posts the Discord breaker skipped.
+func (s *SummarizationScheduler) discordReplayer(ctx context.Context) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.shutdown:
+			return
+		case <-ticker.C:
+			s.replayDiscordPosts()
+		}
+	}
+}
+
+// replayDiscordPosts runs one replay pass, oldest articles first. It waits
+// while the breaker is open and not yet due a probe; after that the first
+// replayed post is the probe, and if it fails the rest are flagged again. Each article's flag is cleared
+// before it is re-sent, so a post skipped again is simply re-flagged.
+func (s *SummarizationScheduler) replayDiscordPosts() {
+	// Not IsHealthy: an open breaker only turns half-open when a post goes
+	// through it, so on a quiet system the replay has to be that probe
+	if !s.discordBreaker.ReadyToTry() {
+		return
+	}
+
+	rows, err := s.db.Query(`
+		SELECT url, title, COALESCE(feed_url, ''), COALESCE(summary, '')
+		FROM articles
+		WHERE discord_replay_pending AND NOT COALESCE(posted_to_discord, FALSE)
+		ORDER BY publish_date
+		LIMIT $1`, discordReplayBatchSize)
+	if err != nil {
+		slog.Error("Failed to load Discord posts to replay", "error", err)
+		return
+	}
+
+	var requests []SummarizationRequest
+	var summaries []string
+	for rows.Next() {
+		var request SummarizationRequest
+		var summary string
+		if err := rows.Scan(&request.ArticleURL, &request.ArticleTitle, &request.FeedURL, &summary); err != nil {
+			slog.Error("Failed to scan Discord post to replay", "error", err)
+			continue
+		}
+		request.RequestID = newRequestID() // A replay is traced on its own
+		if s.feeds != nil {
+			request.Priority = s.feeds(request.FeedURL).Priority // Counts toward highlighting, as on the first try
+		}
+		requests = append(requests, s.withFeedDirectives(request))
+		summaries = append(summaries, summary)
+	}
+	rows.Close()
+
+	for i, request := range requests {
+		if _, err := s.db.Exec(`UPDATE articles SET discord_replay_pending = FALSE WHERE url = $1`, request.ArticleURL); err != nil {
+			slog.Error("Failed to clear Discord replay flag", "article_url", request.ArticleURL, "error", err)
+			continue
+		}
+		slog.Info("Replaying Discord notification", "article_url", request.ArticleURL, "request_id", request.RequestID)
+		s.sendDiscordNotification(request, summaries[i])
+	}
+}
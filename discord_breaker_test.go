@@ -0,0 +1,244 @@
+package main
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"information-broker/config"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// execRecorder is a database/sql driver that records the arguments of every
+// UPDATE, INSERT or DELETE it executes, for code whose database access is
+// fire-and-forget writes plus a few lookups, answered with the rows
+// registered by answer. Anything else fails.
+type execRecorder struct {
+	mu      sync.Mutex
+	writes  []recordedWrite
+	answers []recorderAnswer
+	misses  []string // Prefixes of writes that affect no rows
+}
+
+// recordedWrite is one statement executed by an execRecorder.
+type recordedWrite struct {
+	query string
+	args  []driver.Value
+}
+
+// recorderAnswer is the canned result of queries containing match.
+type recorderAnswer struct {
+	match string
+	rows  [][]driver.Value
+}
+
+var execRecorderCount int32
+
+// openExecRecorder returns a database whose UPDATEs are recorded by the
+// returned recorder.
+func openExecRecorder(t testing.TB) (*sql.DB, *execRecorder) {
+	t.Helper()
+	r := &execRecorder{}
+	name := fmt.Sprintf("execrecorder%d", atomic.AddInt32(&execRecorderCount, 1))
+	sql.Register(name, r)
+	db, err := sql.Open(name, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db, r
+}
+
+func (r *execRecorder) Open(string) (driver.Conn, error) { return recorderConn{r}, nil }
+
+// answer makes queries containing match return rows, each a slice of column
+// values. A match with no rows answers sql.ErrNoRows to QueryRow.
+func (r *execRecorder) answer(match string, rows ...[]driver.Value) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.answers = append(r.answers, recorderAnswer{match: match, rows: rows})
+}
+
+// affectNothing makes writes starting with prefix report no rows affected,
+// as if the row they target didn't exist.
+func (r *execRecorder) affectNothing(prefix string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.misses = append(r.misses, prefix)
+}
+
+// recorded returns the arguments of the UPDATEs executed so far.
+func (r *execRecorder) recorded() [][]driver.Value {
+	return r.recordedWrites("UPDATE")
+}
+
+// recordedWrites returns the arguments of the executed statements that start
+// with verb.
+func (r *execRecorder) recordedWrites(verb string) [][]driver.Value {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var args [][]driver.Value
+	for _, write := range r.writes {
+		if strings.HasPrefix(write.query, verb) {
+			args = append(args, write.args)
+		}
+	}
+	return args
+}
+
+type recorderConn struct{ r *execRecorder }
+
+func (c recorderConn) Prepare(query string) (driver.Stmt, error) {
+	return recorderStmt{c.r, query}, nil
+}
+func (c recorderConn) Close() error              { return nil }
+func (c recorderConn) Begin() (driver.Tx, error) { return nil, errors.New("not supported") }
+
+type recorderStmt struct {
+	r     *execRecorder
+	query string
+}
+
+func (s recorderStmt) Close() error  { return nil }
+func (s recorderStmt) NumInput() int { return -1 }
+func (s recorderStmt) Exec(args []driver.Value) (driver.Result, error) {
+	query := strings.TrimSpace(s.query)
+	if !strings.HasPrefix(query, "UPDATE") && !strings.HasPrefix(query, "INSERT") && !strings.HasPrefix(query, "DELETE") {
+		return nil, errors.New("not supported")
+	}
+	s.r.mu.Lock()
+	defer s.r.mu.Unlock()
+	s.r.writes = append(s.r.writes, recordedWrite{query: query, args: args})
+	for _, prefix := range s.r.misses {
+		if strings.HasPrefix(query, prefix) {
+			return driver.RowsAffected(0), nil
+		}
+	}
+	return driver.RowsAffected(1), nil
+}
+func (s recorderStmt) Query([]driver.Value) (driver.Rows, error) {
+	s.r.mu.Lock()
+	defer s.r.mu.Unlock()
+	for _, answer := range s.r.answers {
+		if strings.Contains(s.query, answer.match) {
+			return &recorderRows{rows: answer.rows}, nil
+		}
+	}
+	return nil, errors.New("not supported")
+}
+
+type recorderRows struct{ rows [][]driver.Value }
+
+func (r *recorderRows) Columns() []string {
+	if len(r.rows) == 0 {
+		return nil
+	}
+	return make([]string, len(r.rows[0]))
+}
+func (r *recorderRows) Close() error { return nil }
+func (r *recorderRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func TestPostArticleToDiscordTripsBreaker(t *testing.T) {
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	db, replayRecorder := openExecRecorder(t)
+
+	metrics := testMetrics()
+	cfg := config.DiscordConfig{Timeout: 5 * time.Second, BreakerFailureThreshold: 3, BreakerTimeout: time.Hour}
+	s := &SummarizationScheduler{
+		db:      db,
+		metrics: metrics,
+		discordSender: &DiscordWebhookSender{
+			httpClient: &http.Client{Timeout: 5 * time.Second},
+			metrics:    metrics,
+			config:     &cfg,
+		},
+		discordBreaker: newDiscordBreaker(NewCircuitBreakerManager(), cfg),
+	}
+
+	request := SummarizationRequest{ArticleURL: "https://example.com/a", ArticleTitle: "Article"}
+	article := ArticleMessage{Title: "Article", URL: "https://example.com/a", Summary: "A summary."}
+	groups := [][]string{{srv.URL}}
+	for i := 0; i < 3; i++ {
+		if sent, skipped := s.postArticleToDiscord(request, article, groups); sent != 0 || skipped {
+			t.Fatalf("post %d: sent %d, skipped %v; want an attempted, failed post", i+1, sent, skipped)
+		}
+	}
+	if got := atomic.LoadInt32(&hits); got != 3 {
+		t.Fatalf("webhook received %d posts before the breaker tripped, want 3", got)
+	}
+	if flagged := replayRecorder.recorded(); len(flagged) != 0 {
+		t.Fatalf("attempted posts were flagged for replay: %v", flagged)
+	}
+
+	before := counterValue(t, metrics.discordWebhookErrors.WithLabelValues("circuit_open"))
+	if _, skipped := s.postArticleToDiscord(request, article, groups); !skipped {
+		t.Error("post after the breaker tripped was not skipped")
+	}
+	if got := atomic.LoadInt32(&hits); got != 3 {
+		t.Errorf("webhook received %d posts, want none once the breaker is open", got-3)
+	}
+	if got := counterValue(t, metrics.discordWebhookErrors.WithLabelValues("circuit_open")) - before; got != 1 {
+		t.Errorf("circuit_open errors recorded = %v, want 1", got)
+	}
+	if flagged := replayRecorder.recorded(); len(flagged) != 1 || flagged[0][0] != request.ArticleURL {
+		t.Errorf("flagged for replay: %v, want just %s", flagged, request.ArticleURL)
+	}
+}
+
+func TestNewDiscordBreakerDisabled(t *testing.T) {
+	if cb := newDiscordBreaker(NewCircuitBreakerManager(), config.DiscordConfig{}); cb != nil {
+		t.Error("breaker created with a zero failure threshold")
+	}
+	if cb := newDiscordBreaker(nil, config.DiscordConfig{BreakerFailureThreshold: 5}); cb != nil {
+		t.Error("breaker created without a manager")
+	}
+}
+
+// With nothing new to post, the replay itself probes a breaker that has
+// been open for its timeout, rather than waiting for fresh traffic.
+func TestReplayDiscordPostsProbesOpenBreaker(t *testing.T) {
+	s, recorder, received := newGraceTestScheduler(t, "http://127.0.0.1:0")
+	s.config.Discord.BreakerFailureThreshold = 1
+	s.config.Discord.BreakerTimeout = 10 * time.Millisecond
+	s.discordBreaker = newDiscordBreaker(NewCircuitBreakerManager(), s.config.Discord)
+	recorder.answer("WHERE discord_replay_pending",
+		[]driver.Value{"https://important.example/a", "Held article", "https://important.example/feed", "A summary."})
+
+	s.discordBreaker.Execute(func() error { return errors.New("Discord down") }, s.metrics)
+	if s.discordBreaker.IsHealthy() {
+		t.Fatal("breaker didn't trip")
+	}
+	s.replayDiscordPosts()
+	if got := received(); len(got) != 0 {
+		t.Fatalf("replayed %d posts while the breaker was open", len(got))
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	s.replayDiscordPosts()
+	if got := waitForMessages(t, received, 1); len(got) != 1 {
+		t.Fatalf("replayed %d posts once the breaker timed out, want the held one as its probe", len(got))
+	}
+	if state := s.discordBreaker.GetStatus().State; state != StateClosed {
+		t.Errorf("breaker state = %v after a successful replay, want closed", state)
+	}
+}
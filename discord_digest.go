@@ -0,0 +1,210 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Discord message limits that digests have to respect.
+const (
+	maxDiscordContentChars     = 2000
+	maxDiscordEmbedsPerMessage = 10
+	maxDiscordEmbedChars       = 6000 // Across all embeds in one message
+)
+
+// embedLength counts the characters Discord counts against the total embed
+// limit: title, description, footer text, author name and field names and
+// values.
+func embedLength(embed DiscordEmbed) int {
+	n := utf8.RuneCountInString(embed.Title) + utf8.RuneCountInString(embed.Description)
+	if embed.Footer != nil {
+		n += utf8.RuneCountInString(embed.Footer.Text)
+	}
+	if embed.Author != nil {
+		n += utf8.RuneCountInString(embed.Author.Name)
+	}
+	for _, field := range embed.Fields {
+		n += utf8.RuneCountInString(field.Name) + utf8.RuneCountInString(field.Value)
+	}
+	return n
+}
+
+// embedsLength is the total embed length of a message.
+func embedsLength(embeds []DiscordEmbed) int {
+	n := 0
+	for _, embed := range embeds {
+		n += embedLength(embed)
+	}
+	return n
+}
+
+// SendArticleBatch sends several articles to one webhook as a single message
+// with one embed per article. The batch must fit in one message: at most 10
+// articles whose embeds total at most 6000 characters.
+func (d *DiscordWebhookSender) SendArticleBatch(ctx context.Context, webhookURL string, articles []ArticleMessage) error {
+	if strings.TrimSpace(webhookURL) == "" {
+		return fmt.Errorf("webhook URL cannot be empty")
+	}
+	if len(articles) == 0 {
+		return fmt.Errorf("article batch cannot be empty")
+	}
+	if len(articles) > maxDiscordEmbedsPerMessage {
+		return fmt.Errorf("article batch has %d articles (Discord limit: %d embeds)", len(articles), maxDiscordEmbedsPerMessage)
+	}
+
+	message := d.createDiscordMessage(articles[0])
+	message.Embeds = make([]DiscordEmbed, 0, len(articles))
+	for _, article := range articles {
+		if strings.TrimSpace(article.Title) == "" || strings.TrimSpace(article.URL) == "" {
+			return fmt.Errorf("article in batch is missing a title or URL")
+		}
+		message.Embeds = append(message.Embeds, d.createDiscordEmbed(article))
+	}
+	if n := embedsLength(message.Embeds); n > maxDiscordEmbedChars {
+		return fmt.Errorf("article batch embeds total %d characters (Discord limit: %d)", n, maxDiscordEmbedChars)
+	}
+
+	title := fmt.Sprintf("digest of %d articles", len(articles))
+	_, err := d.sendMessageWithRetry(ctx, http.MethodPost, webhookURL, message, title, articles[0].URL, "")
+	return err
+}
+
+// digestEntry is an article waiting in the digest buffer. done is called
+// with the outcome once the article's batch has been sent.
+type digestEntry struct {
+	article ArticleMessage
+	done    func(error)
+}
+
+// digestGroup is the buffered articles for one webhook failover group.
+type digestGroup struct {
+	urls    []string
+	entries []digestEntry
+}
+
+// discordDigest buffers articles per webhook group for the digest window.
+// The window opens with the first article queued after a flush, and the
+// flush timer sends everything buffered when it closes.
+type discordDigest struct {
+	mu     sync.Mutex
+	groups []*digestGroup
+	timer  *time.Timer
+}
+
+// DigestEnabled reports whether articles should go through QueueArticle
+// rather than being sent one message each.
+func (d *DiscordWebhookSender) DigestEnabled() bool {
+	return d.config != nil && d.config.DigestInterval > 0
+}
+
+// QueueArticle buffers an article for the next digest to group and starts
+// the flush timer if it isn't already running. done, if not nil, is called
+// from the flush with the result of sending the article's batch.
+func (d *DiscordWebhookSender) QueueArticle(group []string, article ArticleMessage, done func(error)) {
+	d.digest.mu.Lock()
+	defer d.digest.mu.Unlock()
+
+	key := strings.Join(group, "|")
+	var target *digestGroup
+	for _, g := range d.digest.groups {
+		if strings.Join(g.urls, "|") == key {
+			target = g
+			break
+		}
+	}
+	if target == nil {
+		target = &digestGroup{urls: group}
+		d.digest.groups = append(d.digest.groups, target)
+	}
+	target.entries = append(target.entries, digestEntry{article: article, done: done})
+
+	if d.digest.timer == nil {
+		d.digest.timer = time.AfterFunc(d.config.DigestInterval, func() {
+			d.FlushDigest(context.Background())
+		})
+	}
+}
+
+// FlushDigest sends everything buffered now, one message per batch of
+// articles that fits Discord's limits, and reports each article's outcome
+// to its done callback. It is safe to call while the timer is pending, e.g.
+// at shutdown.
+func (d *DiscordWebhookSender) FlushDigest(ctx context.Context) {
+	d.digest.mu.Lock()
+	groups := d.digest.groups
+	d.digest.groups = nil
+	if d.digest.timer != nil {
+		d.digest.timer.Stop()
+		d.digest.timer = nil
+	}
+	d.digest.mu.Unlock()
+
+	for _, group := range groups {
+		for i, batch := range d.splitDigest(group.entries) {
+			// Space out the messages to one webhook; a 429 is still retried
+			// by sendMessageWithRetry
+			if i > 0 && d.config.DigestMessageInterval > 0 {
+				select {
+				case <-ctx.Done():
+				case <-time.After(d.config.DigestMessageInterval):
+				}
+			}
+
+			articles := make([]ArticleMessage, len(batch))
+			for i, entry := range batch {
+				articles[i] = entry.article
+			}
+
+			title := fmt.Sprintf("digest of %d articles", len(articles))
+			_, err := d.sendWithFailover(ctx, group.urls, title, func(ctx context.Context, webhookURL string) error {
+				return d.SendArticleBatch(ctx, webhookURL, articles)
+			})
+			if err != nil {
+				slog.Error("Failed to send Discord digest", "articles", len(articles), "error", err)
+			}
+			for _, entry := range batch {
+				if entry.done != nil {
+					entry.done(err)
+				}
+			}
+		}
+	}
+}
+
+// digestMaxEmbeds is the number of articles per digest message:
+// DigestMaxEmbeds, or Discord's limit if that is unset or above it.
+func (d *DiscordWebhookSender) digestMaxEmbeds() int {
+	if n := d.config.DigestMaxEmbeds; n > 0 && n < maxDiscordEmbedsPerMessage {
+		return n
+	}
+	return maxDiscordEmbedsPerMessage
+}
+
+// splitDigest divides entries, in order, into batches that each fit in one
+// message: at most digestMaxEmbeds embeds totalling at most 6000
+// characters. Each article is one whole embed, never split between two.
+func (d *DiscordWebhookSender) splitDigest(entries []digestEntry) [][]digestEntry {
+	maxEmbeds := d.digestMaxEmbeds()
+	var batches [][]digestEntry
+	var current []digestEntry
+	chars := 0
+	for _, entry := range entries {
+		n := embedLength(d.createDiscordEmbed(entry.article))
+		if len(current) > 0 && (len(current) == maxEmbeds || chars+n > maxDiscordEmbedChars) {
+			batches = append(batches, current)
+			current, chars = nil, 0
+		}
+		current = append(current, entry)
+		chars += n
+	}
+	if len(current) > 0 {
+		batches = append(batches, current)
+	}
+	return batches
+}
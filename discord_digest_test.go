@@ -0,0 +1,253 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"information-broker/config"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func digestArticle(i int, summaryLen int) ArticleMessage {
+	return ArticleMessage{
+		Title:       fmt.Sprintf("Article %d", i),
+		URL:         fmt.Sprintf("https://example.com/%d", i),
+		Summary:     strings.Repeat("x", summaryLen),
+		PublishDate: time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC),
+		FeedTitle:   strings.Repeat("f", 250),
+	}
+}
+
+func TestSplitDigestRespectsLimits(t *testing.T) {
+	d := &DiscordWebhookSender{config: &config.DiscordConfig{}}
+
+	tests := []struct {
+		name       string
+		count      int
+		summaryLen int
+		wantSizes  []int
+	}{
+		{"small articles fill ten embeds", 23, 10, []int{10, 10, 3}},
+		{"ten ~580-character embeds still fit", 12, 300, []int{10, 2}},
+		{"empty", 0, 10, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var entries []digestEntry
+			for i := 0; i < tt.count; i++ {
+				entries = append(entries, digestEntry{article: digestArticle(i, tt.summaryLen)})
+			}
+
+			var sizes []int
+			next := 0
+			for _, batch := range d.splitDigest(entries) {
+				sizes = append(sizes, len(batch))
+				var embeds []DiscordEmbed
+				for _, entry := range batch {
+					if entry.article.URL != entries[next].article.URL {
+						t.Fatalf("batches out of order at %s", entry.article.URL)
+					}
+					next++
+					embeds = append(embeds, d.createDiscordEmbed(entry.article))
+				}
+				if n := embedsLength(embeds); n > maxDiscordEmbedChars {
+					t.Errorf("batch of %d embeds totals %d characters", len(batch), n)
+				}
+			}
+			if fmt.Sprint(sizes) != fmt.Sprint(tt.wantSizes) {
+				t.Errorf("batch sizes = %v, want %v", sizes, tt.wantSizes)
+			}
+		})
+	}
+
+	// Force the character limit: embeds that would total well past 6000
+	var entries []digestEntry
+	for i := 0; i < 10; i++ {
+		article := digestArticle(i, 300)
+		article.Title = strings.Repeat("t", 256)
+		entries = append(entries, digestEntry{article: article})
+	}
+	if batches := d.splitDigest(entries); len(batches) < 2 {
+		t.Errorf("ten ~830-character embeds fit in %d batch, want a split", len(batches))
+	}
+}
+
+func TestSendArticleBatch(t *testing.T) {
+	var mu sync.Mutex
+	var received []DiscordWebhookMessage
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg DiscordWebhookMessage
+		json.NewDecoder(r.Body).Decode(&msg)
+		mu.Lock()
+		received = append(received, msg)
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	d := &DiscordWebhookSender{
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+		metrics:    testMetrics(),
+		config:     &config.DiscordConfig{Timeout: 5 * time.Second},
+	}
+
+	articles := []ArticleMessage{digestArticle(1, 100), digestArticle(2, 100), digestArticle(3, 100)}
+	if err := d.SendArticleBatch(context.Background(), srv.URL, articles); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(received) != 1 || len(received[0].Embeds) != 3 || received[0].Embeds[2].URL != "https://example.com/3" {
+		t.Errorf("received %+v, want one message with the three articles' embeds", received)
+	}
+
+	var tooMany []ArticleMessage
+	for i := 0; i < 11; i++ {
+		tooMany = append(tooMany, digestArticle(i, 10))
+	}
+	if err := d.SendArticleBatch(context.Background(), srv.URL, tooMany); err == nil {
+		t.Error("expected an error for 11 articles")
+	}
+	if err := d.SendArticleBatch(context.Background(), srv.URL, nil); err == nil {
+		t.Error("expected an error for an empty batch")
+	}
+	if len(received) != 1 {
+		t.Errorf("rejected batches reached the webhook: %d messages", len(received))
+	}
+}
+
+func TestQueueArticleFlushesDigest(t *testing.T) {
+	var mu sync.Mutex
+	var embedCounts []int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg DiscordWebhookMessage
+		json.NewDecoder(r.Body).Decode(&msg)
+		mu.Lock()
+		embedCounts = append(embedCounts, len(msg.Embeds))
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	d := &DiscordWebhookSender{
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+		metrics:    testMetrics(),
+		config:     &config.DiscordConfig{Timeout: 5 * time.Second, DigestInterval: 50 * time.Millisecond},
+	}
+	if !d.DigestEnabled() {
+		t.Fatal("digest not enabled with a DigestInterval")
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 12; i++ {
+		wg.Add(1)
+		d.QueueArticle([]string{srv.URL}, digestArticle(i, 10), func(err error) {
+			if err != nil {
+				t.Errorf("digest send failed: %v", err)
+			}
+			wg.Done()
+		})
+	}
+
+	mu.Lock()
+	if len(embedCounts) != 0 {
+		t.Errorf("digest sent before the window closed")
+	}
+	mu.Unlock()
+
+	wg.Wait()
+	mu.Lock()
+	defer mu.Unlock()
+	if fmt.Sprint(embedCounts) != "[10 2]" {
+		t.Errorf("messages carried %v embeds, want [10 2]", embedCounts)
+	}
+}
+
+func TestFlushDigestPacksLongArticlesWithinLimits(t *testing.T) {
+	longArticle := func(i int) ArticleMessage {
+		article := digestArticle(i, 300)
+		article.Title = fmt.Sprintf("%03d %s", i, strings.Repeat("t", 252))
+		return article
+	}
+	embedChars := embedLength((&DiscordWebhookSender{config: &config.DiscordConfig{}}).createDiscordEmbed(longArticle(0)))
+	if maxDiscordEmbedChars/embedChars >= maxDiscordEmbedsPerMessage {
+		t.Fatalf("%d-character embeds are too short to hit the character cap", embedChars)
+	}
+
+	tests := []struct {
+		name      string
+		maxEmbeds int
+		perMsg    int // Articles per message the caps allow
+	}{
+		{"character cap binds", 0, maxDiscordEmbedChars / embedChars},
+		{"configured embed cap binds", 4, 4},
+		{"embed cap above Discord's is clamped", 25, maxDiscordEmbedChars / embedChars},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			const articles = 45
+			var mu sync.Mutex
+			var received []DiscordWebhookMessage
+			var sentAt []time.Time
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var msg DiscordWebhookMessage
+				json.NewDecoder(r.Body).Decode(&msg)
+				mu.Lock()
+				received = append(received, msg)
+				sentAt = append(sentAt, time.Now())
+				mu.Unlock()
+				w.WriteHeader(http.StatusNoContent)
+			}))
+			defer srv.Close()
+
+			const gap = 20 * time.Millisecond
+			d := &DiscordWebhookSender{
+				httpClient: &http.Client{Timeout: 5 * time.Second},
+				metrics:    testMetrics(),
+				config: &config.DiscordConfig{
+					Timeout:               5 * time.Second,
+					DigestInterval:        time.Hour,
+					DigestMaxEmbeds:       tt.maxEmbeds,
+					DigestMessageInterval: gap,
+				},
+			}
+			for i := 0; i < articles; i++ {
+				d.QueueArticle([]string{srv.URL}, longArticle(i), nil)
+			}
+			d.FlushDigest(context.Background())
+
+			mu.Lock()
+			defer mu.Unlock()
+			wantMessages := (articles + tt.perMsg - 1) / tt.perMsg
+			if len(received) != wantMessages {
+				t.Fatalf("sent %d messages, want %d (%d articles of %d characters, %d per message)",
+					len(received), wantMessages, articles, embedChars, tt.perMsg)
+			}
+
+			next := 0
+			for i, msg := range received {
+				if len(msg.Embeds) > tt.perMsg || len(msg.Embeds) > maxDiscordEmbedsPerMessage {
+					t.Errorf("message %d has %d embeds, over the cap of %d", i, len(msg.Embeds), tt.perMsg)
+				}
+				if n := embedsLength(msg.Embeds); n > maxDiscordEmbedChars {
+					t.Errorf("message %d embeds total %d characters", i, n)
+				}
+				for _, embed := range msg.Embeds {
+					if want := longArticle(next).URL; embed.URL != want || embed.Title != longArticle(next).Title {
+						t.Fatalf("message %d carries %s, want the whole of %s next", i, embed.URL, want)
+					}
+					next++
+				}
+				if i > 0 && sentAt[i].Sub(sentAt[i-1]) < gap {
+					t.Errorf("message %d sent %v after the previous one, want at least %v", i, sentAt[i].Sub(sentAt[i-1]), gap)
+				}
+			}
+			if next != articles {
+				t.Errorf("delivered %d of %d articles", next, articles)
+			}
+		})
+	}
+}
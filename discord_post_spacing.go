@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// discordPostSpacing keeps posts to the same webhook at least
+// Discord.MinPostSpacing apart. Each send reserves the webhook's next free
+// slot and waits for it, so a burst (catch-up after downtime, a backfill)
+// goes out in arrival order at a readable pace while the rest of the
+// pipeline carries on. The zero value is ready to use.
+type discordPostSpacing struct {
+	mu       sync.Mutex
+	nextSlot map[string]time.Time // Earliest time of the next post, per webhook URL
+}
+
+// reserve books the first slot for webhookURL at or after now and returns
+// it. A zero spacing books nothing and returns now.
+func (p *discordPostSpacing) reserve(webhookURL string, spacing time.Duration, now time.Time) time.Time {
+	if spacing <= 0 {
+		return now
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.nextSlot == nil {
+		p.nextSlot = make(map[string]time.Time)
+	}
+	slot := now
+	if next := p.nextSlot[webhookURL]; next.After(now) {
+		slot = next
+	}
+	p.nextSlot[webhookURL] = slot.Add(spacing)
+	return slot
+}
+
+// wait blocks until the post titled title may go to webhookURL, or ctx is
+// done.
+func (p *discordPostSpacing) wait(ctx context.Context, webhookURL string, spacing time.Duration, title string) error {
+	now := time.Now()
+	delay := p.reserve(webhookURL, spacing, now).Sub(now)
+	if delay <= 0 {
+		return nil
+	}
+
+	slog.Info("Holding Discord post to keep posts apart", "title", title, "delay", delay.Round(time.Second), "spacing", spacing)
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// minPostSpacing is the configured least time between posts to one webhook.
+func (d *DiscordWebhookSender) minPostSpacing() time.Duration {
+	if d.config == nil {
+		return 0
+	}
+	return d.config.MinPostSpacing
+}
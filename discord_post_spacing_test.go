@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"information-broker/config"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestDiscordPostSpacingReserve(t *testing.T) {
+	var p discordPostSpacing
+	start := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	const webhook = "https://discord.com/api/webhooks/1/a"
+
+	// A burst gets consecutive slots, 30s apart
+	for i := 0; i < 3; i++ {
+		if got, want := p.reserve(webhook, 30*time.Second, start), start.Add(time.Duration(i)*30*time.Second); !got.Equal(want) {
+			t.Errorf("burst post %d slot = %v, want %v", i, got, want)
+		}
+	}
+	// Another channel isn't held up by the first one's backlog
+	if got := p.reserve("https://discord.com/api/webhooks/2/b", 30*time.Second, start); !got.Equal(start) {
+		t.Errorf("other webhook slot = %v, want now", got)
+	}
+	// Once the backlog has drained a post goes straight out
+	later := start.Add(5 * time.Minute)
+	if got := p.reserve(webhook, 30*time.Second, later); !got.Equal(later) {
+		t.Errorf("slot after the backlog = %v, want now", got)
+	}
+	// No spacing, no waiting
+	if got := p.reserve(webhook, 0, start); !got.Equal(start) {
+		t.Errorf("slot without spacing = %v, want now", got)
+	}
+}
+
+func TestSendArticleSpacesBurst(t *testing.T) {
+	const spacing = 150 * time.Millisecond
+	var mu sync.Mutex
+	var arrivals []time.Time
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		mu.Lock()
+		arrivals = append(arrivals, time.Now())
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	d := &DiscordWebhookSender{
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+		metrics:    testMetrics(),
+		config:     &config.DiscordConfig{Timeout: 100 * time.Millisecond, MinPostSpacing: spacing},
+	}
+
+	// A burst of posts, each waiting longer than the send timeout
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			article := ArticleMessage{Title: fmt.Sprintf("Post %d", i), URL: fmt.Sprintf("https://example.com/%d", i), Summary: "A summary."}
+			if _, err := d.SendArticleWithFailover(context.Background(), []string{srv.URL}, article); err != nil {
+				t.Errorf("post %d: %v", i, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if len(arrivals) != 4 {
+		t.Fatalf("Discord received %d posts, want 4", len(arrivals))
+	}
+	sort.Slice(arrivals, func(i, j int) bool { return arrivals[i].Before(arrivals[j]) })
+	for i := 1; i < len(arrivals); i++ {
+		// Allow for the request latency varying between posts
+		if gap := arrivals[i].Sub(arrivals[i-1]); gap < spacing-20*time.Millisecond {
+			t.Errorf("posts %d and %d arrived %v apart, want at least %v", i-1, i, gap, spacing)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// deferredPost is a Discord notification held back because it would have
+// gone out during quiet hours.
+type deferredPost struct {
+	request    SummarizationRequest
+	summary    string
+	deferredAt time.Time
+}
+
+// deferredPostQueue holds quiet-hours posts in arrival order until they can
+// be released. Releasing is rate-limited so a night's backlog doesn't flood
+// the channel the moment quiet hours end, and posts that sat longer than the
+// TTL are dropped as stale rather than posted hours late.
+type deferredPostQueue struct {
+	mu    sync.Mutex
+	posts []deferredPost
+}
+
+// add appends a post to the back of the queue.
+func (q *deferredPostQueue) add(post deferredPost) {
+	q.mu.Lock()
+	q.posts = append(q.posts, post)
+	q.mu.Unlock()
+}
+
+// len returns the number of held posts.
+func (q *deferredPostQueue) len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.posts)
+}
+
+// release pops up to max posts that are still within ttl of when they were
+// deferred, oldest first, and separately returns every post that has
+// expired. Expired posts are removed regardless of max so they never block
+// fresh ones. A ttl of 0 disables expiry; a max <= 0 releases nothing.
+func (q *deferredPostQueue) release(now time.Time, ttl time.Duration, max int) (ready, expired []deferredPost) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	remaining := q.posts[:0]
+	for _, post := range q.posts {
+		switch {
+		case ttl > 0 && now.Sub(post.deferredAt) > ttl:
+			expired = append(expired, post)
+		case len(ready) < max:
+			ready = append(ready, post)
+		default:
+			remaining = append(remaining, post)
+		}
+	}
+	q.posts = remaining
+	return ready, expired
+}
+
+// deferredReleaser drains the quiet-hours queue once quiet hours are over,
+// releasing at most DeferredReleasePerMinute posts each minute.
+func (s *SummarizationScheduler) deferredReleaser(ctx context.Context) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.shutdown:
+			return
+		case <-ticker.C:
+			s.releaseDeferredPosts(time.Now())
+		}
+	}
+}
+
+// releaseDeferredPosts runs one release pass. It is a no-op while quiet hours
+// are still in effect.
+func (s *SummarizationScheduler) releaseDeferredPosts(now time.Time) {
+	if s.config.Discord.InQuietHours(now) || s.deferredPosts.len() == 0 {
+		return
+	}
+
+	ready, expired := s.deferredPosts.release(now, s.config.Discord.DeferredPostTTL, s.config.Discord.DeferredReleasePerMinute)
+	for _, post := range expired {
+		slog.Warn("Dropping deferred Discord post held longer than its TTL", "title", post.request.ArticleTitle,
+			"article_url", post.request.ArticleURL, "held", now.Sub(post.deferredAt).Round(time.Second), "ttl", s.config.Discord.DeferredPostTTL)
+		s.metrics.RecordDiscordDeferredPost("expired")
+	}
+	for _, post := range ready {
+		slog.Info("Releasing deferred Discord post", "title", post.request.ArticleTitle, "article_url", post.request.ArticleURL)
+		s.metrics.RecordDiscordDeferredPost("released")
+		s.sendDiscordNotification(post.request, post.summary)
+	}
+}
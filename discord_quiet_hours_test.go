@@ -0,0 +1,82 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDeferredPostQueueRelease(t *testing.T) {
+	now := time.Date(2026, 7, 15, 7, 0, 0, 0, time.UTC)
+	post := func(title string, age time.Duration) deferredPost {
+		return deferredPost{
+			request:    SummarizationRequest{ArticleTitle: title},
+			deferredAt: now.Add(-age),
+		}
+	}
+
+	t.Run("posts older than the TTL are dropped, not released", func(t *testing.T) {
+		q := &deferredPostQueue{}
+		q.add(post("stale-1", 8*time.Hour))
+		q.add(post("fresh-1", 30*time.Minute))
+		q.add(post("stale-2", 7*time.Hour))
+
+		ready, expired := q.release(now, 6*time.Hour, 10)
+		if len(expired) != 2 || expired[0].request.ArticleTitle != "stale-1" || expired[1].request.ArticleTitle != "stale-2" {
+			t.Fatalf("expected both stale posts to expire, got %+v", expired)
+		}
+		if len(ready) != 1 || ready[0].request.ArticleTitle != "fresh-1" {
+			t.Fatalf("expected only the fresh post to be released, got %+v", ready)
+		}
+		if q.len() != 0 {
+			t.Fatalf("expected empty queue, %d left", q.len())
+		}
+	})
+
+	t.Run("fresh posts are released oldest first within the rate limit", func(t *testing.T) {
+		q := &deferredPostQueue{}
+		for _, title := range []string{"a", "b", "c", "d", "e"} {
+			q.add(post(title, time.Hour))
+		}
+
+		ready, expired := q.release(now, 6*time.Hour, 2)
+		if len(expired) != 0 {
+			t.Fatalf("expected nothing expired, got %+v", expired)
+		}
+		if len(ready) != 2 || ready[0].request.ArticleTitle != "a" || ready[1].request.ArticleTitle != "b" {
+			t.Fatalf("expected first two posts released, got %+v", ready)
+		}
+		if q.len() != 3 {
+			t.Fatalf("expected 3 posts held for the next release, got %d", q.len())
+		}
+
+		ready, _ = q.release(now, 6*time.Hour, 2)
+		if len(ready) != 2 || ready[0].request.ArticleTitle != "c" {
+			t.Fatalf("expected next batch to continue in order, got %+v", ready)
+		}
+	})
+
+	t.Run("expired posts are removed even when the rate limit is exhausted", func(t *testing.T) {
+		q := &deferredPostQueue{}
+		q.add(post("fresh-1", time.Minute))
+		q.add(post("fresh-2", time.Minute))
+		q.add(post("stale", 10*time.Hour))
+
+		ready, expired := q.release(now, 6*time.Hour, 1)
+		if len(ready) != 1 || len(expired) != 1 {
+			t.Fatalf("expected 1 released and 1 expired, got %d and %d", len(ready), len(expired))
+		}
+		if q.len() != 1 {
+			t.Fatalf("expected only fresh-2 left, got %d", q.len())
+		}
+	})
+
+	t.Run("zero TTL never expires", func(t *testing.T) {
+		q := &deferredPostQueue{}
+		q.add(post("ancient", 72*time.Hour))
+
+		ready, expired := q.release(now, 0, 5)
+		if len(expired) != 0 || len(ready) != 1 {
+			t.Fatalf("expected post released with TTL disabled, got ready=%d expired=%d", len(ready), len(expired))
+		}
+	})
+}
@@ -5,13 +5,18 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"information-broker/config"
 	"io"
-	"log"
+	"log/slog"
 	"math"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 )
 
 // DiscordEmbed represents a Discord embed structure
@@ -52,15 +57,48 @@ type DiscordWebhookMessage struct {
 	Username  string         `json:"username,omitempty"`
 	AvatarURL string         `json:"avatar_url,omitempty"`
 	Embeds    []DiscordEmbed `json:"embeds,omitempty"`
+
+	// ThreadName creates a thread of that name for the message (forum
+	// channels only); ThreadID posts it into an existing thread, and goes
+	// in the URL rather than the body.
+	ThreadName string `json:"thread_name,omitempty"`
+	ThreadID   string `json:"-"`
+
+	AllowedMentions *DiscordAllowedMentions `json:"allowed_mentions,omitempty"`
 }
 
+// DiscordAllowedMentions limits which mentions in a message's content
+// notify anyone.
+type DiscordAllowedMentions struct {
+	Parse []string `json:"parse"`
+	Roles []string `json:"roles,omitempty"`
+}
+
+// importantEmbedColor marks the embeds of important articles (Discord red).
+const importantEmbedColor = 0xED4245
+
+// Defaults for posts from feeds without Discord.FeedBranding.
+const (
+	defaultEmbedColor       = 0x5865F2 // Discord's blurple color
+	defaultDiscordUsername  = "Information Broker"
+	defaultDiscordAvatarURL = "https://vignette.wikia.nocookie.net/es.starwars/images/e/e5/Information_broker_TotG.jpg"
+)
+
 // ArticleMessage represents an article to be sent to Discord
 type ArticleMessage struct {
+	RequestID   string // The summarization request that produced it, for logs; may be empty
 	Title       string
 	URL         string
 	Summary     string
 	PublishDate time.Time
 	FeedTitle   string
+	FeedURL     string // Picks the feed's Discord thread and branding, if configured
+	Category    string // The feed's category, which can also pick its branding
+	Priority    int    // The feed's priority, which counts toward the article's importance
+	// SummaryLimit is the limit Summary was written to; the embed cuts it
+	// by the same rule, so a summary within it is shown whole. Zero leaves
+	// only Discord's own limit.
+	SummaryLimit summaryLimit
 }
 
 // DiscordWebhookSender handles sending messages to Discord webhooks
@@ -69,6 +107,9 @@ type DiscordWebhookSender struct {
 	httpClient *http.Client
 	maxRetries int
 	metrics    *PrometheusMetrics
+	config     *config.DiscordConfig
+	digest     discordDigest // Articles buffered for the next digest, when DigestInterval is set
+	spacing    discordPostSpacing
 }
 
 // DiscordErrorLog represents logging structure for Discord webhook errors
@@ -83,7 +124,7 @@ type DiscordErrorLog struct {
 }
 
 // NewDiscordWebhookSender creates a new Discord webhook sender instance
-func NewDiscordWebhookSender(db *sql.DB, metrics *PrometheusMetrics) *DiscordWebhookSender {
+func NewDiscordWebhookSender(db *sql.DB, metrics *PrometheusMetrics, cfg *config.DiscordConfig) *DiscordWebhookSender {
 	return &DiscordWebhookSender{
 		db: db,
 		httpClient: &http.Client{
@@ -91,13 +132,12 @@ func NewDiscordWebhookSender(db *sql.DB, metrics *PrometheusMetrics) *DiscordWeb
 		},
 		maxRetries: 2, // Retry twice as specified
 		metrics:    metrics,
+		config:     cfg,
 	}
 }
 
 // SendArticleToDiscord sends a formatted article message to Discord webhook with embeds
 func (d *DiscordWebhookSender) SendArticleToDiscord(ctx context.Context, webhookURL string, article ArticleMessage) error {
-	startTime := time.Now()
-
 	// Validate inputs
 	if strings.TrimSpace(webhookURL) == "" {
 		return fmt.Errorf("webhook URL cannot be empty")
@@ -114,20 +154,38 @@ func (d *DiscordWebhookSender) SendArticleToDiscord(ctx context.Context, webhook
 	// Create the Discord message with embed
 	message := d.createDiscordMessage(article)
 
+	if !d.recordsMessageIDs() {
+		_, err := d.sendMessageWithRetry(ctx, http.MethodPost, webhookURL, message, article.Title, article.URL, article.RequestID)
+		return err
+	}
+	messageID, err := d.sendMessageWithRetry(ctx, http.MethodPost, waitForMessageURL(webhookURL), message, article.Title, article.URL, article.RequestID)
+	if err == nil {
+		d.recordMessageID(article.URL, webhookURL, messageID)
+	}
+	return err
+}
+
+// sendMessageWithRetry sends message to webhookURL with method (POST to
+// post it, PATCH to edit one), retrying Discord errors with backoff, and
+// returns the message id if Discord sent the message back. title, articleURL
+// and requestID identify the post in logs.
+func (d *DiscordWebhookSender) sendMessageWithRetry(ctx context.Context, method, webhookURL string, message DiscordWebhookMessage, title, articleURL, requestID string) (string, error) {
+	startTime := time.Now()
+
 	var lastErr error
 
 	// Retry logic - retry twice if Discord returns an error
 	for attempt := 1; attempt <= d.maxRetries+1; attempt++ { // +1 for initial attempt
 		attemptStart := time.Now()
 
-		err := d.sendWebhookMessage(ctx, webhookURL, message)
+		messageID, err := d.sendWebhookMessage(ctx, method, webhookURL, message)
 		attemptDuration := time.Since(attemptStart)
 
 		if err == nil {
 			// Success - record metrics
 			d.metrics.RecordDiscordWebhook("success", attemptDuration)
-			log.Printf("Successfully sent article to Discord: %s (attempt %d)", article.Title, attempt)
-			return nil
+			slog.Info("Sent article to Discord", "title", title, "article_url", articleURL, "request_id", requestID, "attempt", attempt)
+			return messageID, nil
 		}
 
 		lastErr = err
@@ -138,7 +196,9 @@ func (d *DiscordWebhookSender) SendArticleToDiscord(ctx context.Context, webhook
 		// Determine error type for metrics
 		errorType := "unknown"
 		if discordErr, ok := err.(*DiscordAPIError); ok {
-			if discordErr.StatusCode >= 400 && discordErr.StatusCode < 500 {
+			if discordErr.StatusCode == http.StatusTooManyRequests {
+				errorType = "rate_limited"
+			} else if discordErr.StatusCode >= 400 && discordErr.StatusCode < 500 {
 				errorType = "client_error"
 			} else if discordErr.StatusCode >= 500 {
 				errorType = "server_error"
@@ -151,7 +211,7 @@ func (d *DiscordWebhookSender) SendArticleToDiscord(ctx context.Context, webhook
 		// Log the error to PostgreSQL
 		d.logDiscordError(DiscordErrorLog{
 			WebhookURL:   d.sanitizeWebhookURL(webhookURL),
-			ArticleURL:   article.URL,
+			ArticleURL:   articleURL,
 			ErrorMessage: err.Error(),
 			StatusCode:   d.extractStatusCode(err),
 			RetryAttempt: attempt,
@@ -159,17 +219,25 @@ func (d *DiscordWebhookSender) SendArticleToDiscord(ctx context.Context, webhook
 			CreatedAt:    time.Now(),
 		})
 
-		log.Printf("Discord webhook attempt %d failed for article %s: %v", attempt, article.Title, err)
+		slog.Warn("Discord webhook attempt failed", "title", title, "article_url", articleURL, "request_id", requestID, "attempt", attempt, "error", err)
 
 		// Don't wait after the last attempt
 		if attempt <= d.maxRetries {
 			// Exponential backoff: 1s, 2s, 4s
 			backoffDuration := time.Duration(math.Pow(2, float64(attempt-1))) * time.Second
 
+			// When rate limited, Discord says exactly how long to wait
+			if discordErr, ok := err.(*DiscordAPIError); ok && discordErr.RetryAfter > 0 {
+				if discordErr.RetryAfter > maxDiscordRetryAfter {
+					return "", fmt.Errorf("rate limited for %v, longer than %v: %w", discordErr.RetryAfter, maxDiscordRetryAfter, err)
+				}
+				backoffDuration = discordErr.RetryAfter
+			}
+
 			select {
 			case <-ctx.Done():
 				d.metrics.RecordDiscordWebhookError("context_cancelled")
-				return fmt.Errorf("context cancelled during retry backoff: %w", ctx.Err())
+				return "", fmt.Errorf("context cancelled during retry backoff: %w", ctx.Err())
 			case <-time.After(backoffDuration):
 				// Continue to next attempt
 			}
@@ -179,21 +247,107 @@ func (d *DiscordWebhookSender) SendArticleToDiscord(ctx context.Context, webhook
 	// All attempts failed
 	totalDuration := time.Since(startTime)
 	d.metrics.RecordDiscordWebhookError("max_retries_exceeded")
-	log.Printf("Failed to send article to Discord after %d attempts (took %v): %s",
-		d.maxRetries+1, totalDuration, article.Title)
+	slog.Error("Failed to send article to Discord", "title", title, "article_url", articleURL, "request_id", requestID,
+		"attempts", d.maxRetries+1, "duration_ms", totalDuration.Milliseconds())
+
+	return "", fmt.Errorf("failed to send to Discord after %d attempts: %w", d.maxRetries+1, lastErr)
+}
+
+// SendArticleWithFailover sends an article to a webhook failover group: the
+// primary URL first, then each backup in turn, moving on only when the
+// previous webhook exhausted its retries with a retryable error. It returns
+// the URL that accepted the post. Each URL gets its own Timeout budget.
+func (d *DiscordWebhookSender) SendArticleWithFailover(ctx context.Context, group []string, article ArticleMessage) (string, error) {
+	return d.sendWithFailover(ctx, group, article.Title, func(ctx context.Context, webhookURL string) error {
+		return d.SendArticleToDiscord(ctx, webhookURL, article)
+	})
+}
+
+// sendWithFailover runs send against each URL of a failover group in turn,
+// as described on SendArticleWithFailover. title identifies the post in logs.
+func (d *DiscordWebhookSender) sendWithFailover(ctx context.Context, group []string, title string, send func(ctx context.Context, webhookURL string) error) (string, error) {
+	if len(group) == 0 {
+		return "", fmt.Errorf("webhook group cannot be empty")
+	}
+
+	var lastErr error
+	for i, webhookURL := range group {
+		if i > 0 {
+			slog.Warn("Failing over to backup Discord webhook", "webhook", i, "title", title, "error", lastErr)
+			d.metrics.RecordDiscordWebhookError("failover")
+		}
+
+		// Wait for this webhook's next slot before the send timeout starts
+		if err := d.spacing.wait(ctx, webhookURL, d.minPostSpacing(), title); err != nil {
+			return "", err
+		}
+
+		attemptCtx, cancel := context.WithTimeout(ctx, d.sendTimeout())
+		lastErr = send(attemptCtx, webhookURL)
+		cancel()
 
-	return fmt.Errorf("failed to send to Discord after %d attempts: %w", d.maxRetries+1, lastErr)
+		if lastErr == nil {
+			return webhookURL, nil
+		}
+		if ctx.Err() != nil || !isRetryableDiscordError(lastErr) {
+			break
+		}
+	}
+	return "", lastErr
+}
+
+// sendTimeout is the time budget for one webhook URL, retries included.
+func (d *DiscordWebhookSender) sendTimeout() time.Duration {
+	if d.config != nil && d.config.Timeout > 0 {
+		return d.config.Timeout
+	}
+	return d.httpClient.Timeout
+}
+
+// isRetryableDiscordError reports whether a failed send is worth handing to a
+// backup webhook: transport failures, timeouts, rate limiting and Discord
+// server errors. Other 4xx responses and validation errors would fail the
+// same way on any webhook.
+func isRetryableDiscordError(err error) bool {
+	var apiErr *DiscordAPIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusTooManyRequests || apiErr.StatusCode >= 500
+	}
+	var urlErr *url.Error
+	return errors.As(err, &urlErr) || errors.Is(err, context.DeadlineExceeded)
 }
 
 // createDiscordMessage creates a properly formatted Discord message with embed
 func (d *DiscordWebhookSender) createDiscordMessage(article ArticleMessage) DiscordWebhookMessage {
+	message := DiscordWebhookMessage{
+		Username:  defaultDiscordUsername,
+		AvatarURL: defaultDiscordAvatarURL,
+		Embeds:    []DiscordEmbed{d.createDiscordEmbed(article)},
+	}
+	if d.config != nil {
+		branding := d.config.Branding(article.FeedURL, article.Category)
+		if branding.Username != "" {
+			message.Username = branding.Username
+		}
+		if branding.AvatarURL != "" {
+			message.AvatarURL = branding.AvatarURL
+		}
+		message.ThreadID, message.ThreadName = d.config.FeedThread(article.FeedURL)
+		if roleID := d.config.ImportantRoleID; roleID != "" && d.isImportant(article) {
+			message.Content = "<@&" + roleID + ">"
+			message.AllowedMentions = &DiscordAllowedMentions{Parse: []string{}, Roles: []string{roleID}}
+		}
+	}
+	return message
+}
+
+// createDiscordEmbed creates the embed for one article
+func (d *DiscordWebhookSender) createDiscordEmbed(article ArticleMessage) DiscordEmbed {
 	// Truncate title to Discord's 256 character limit
 	title := d.truncateString(article.Title, 256)
 
-	// Ensure the entire message content stays within Discord's 2000 character limit
-	// Reserve space for title, formatting, timestamp, and other embed elements
-	maxSummaryLength := 300 // Conservative limit to ensure total message < 2000 chars
-	summary := d.truncateString(article.Summary, maxSummaryLength)
+	// Cut the summary the way the summarizer did, then to what an embed holds
+	summary := truncateAtWord(article.SummaryLimit.fit(article.Summary), maxDiscordDescriptionChars)
 
 	// Format timestamp to ISO 8601 format
 	timestamp := article.PublishDate.Format(time.RFC3339)
@@ -203,12 +357,21 @@ func (d *DiscordWebhookSender) createDiscordMessage(article ArticleMessage) Disc
 		Title:       title,
 		URL:         article.URL,
 		Description: summary,
-		Color:       0x5865F2, // Discord's blurple color
+		Color:       defaultEmbedColor,
 		Timestamp:   timestamp,
 		Footer: &DiscordEmbedFooter{
 			Text: "Information Broker",
 		},
 	}
+	if d.config != nil {
+		if color := d.config.Branding(article.FeedURL, article.Category).Color; color != 0 {
+			embed.Color = color
+		}
+	}
+	// Highlighting beats branding, so important articles stand out everywhere
+	if d.isImportant(article) {
+		embed.Color = importantEmbedColor
+	}
 
 	// Add feed title as author if available
 	if strings.TrimSpace(article.FeedTitle) != "" {
@@ -217,33 +380,51 @@ func (d *DiscordWebhookSender) createDiscordMessage(article ArticleMessage) Disc
 		}
 	}
 
-	// Create the webhook message
-	message := DiscordWebhookMessage{
-		Username:  "Information Broker",
-		AvatarURL: "https://vignette.wikia.nocookie.net/es.starwars/images/e/e5/Information_broker_TotG.jpg", // Default Discord avatar
-		Embeds:    []DiscordEmbed{embed},
+	// The embed timestamp is easy to miss, so optionally spell the date out
+	if d.config != nil && d.config.ShowPublishDateField && !article.PublishDate.IsZero() {
+		embed.Fields = append(embed.Fields, DiscordEmbedField{
+			Name:   "Published",
+			Value:  formatPublishDate(article.PublishDate, d.config.Location()),
+			Inline: true,
+		})
 	}
 
-	return message
+	return embed
 }
 
-// sendWebhookMessage sends the actual HTTP request to Discord
-func (d *DiscordWebhookSender) sendWebhookMessage(ctx context.Context, webhookURL string, message DiscordWebhookMessage) error {
+// isImportant reports whether the article scores high enough to be
+// highlighted (see DiscordConfig.IsImportant).
+func (d *DiscordWebhookSender) isImportant(article ArticleMessage) bool {
+	return d.config != nil && d.config.IsImportant(article.Title, article.Summary, article.Priority)
+}
+
+// formatPublishDate renders a publish date for the "Published" embed field.
+func formatPublishDate(t time.Time, loc *time.Location) string {
+	return t.In(loc).Format("Jan 2, 2006 15:04 MST")
+}
+
+// sendWebhookMessage sends the actual HTTP request to Discord and returns
+// the id of the message, when Discord answers with it (see
+// waitForMessageURL).
+func (d *DiscordWebhookSender) sendWebhookMessage(ctx context.Context, method, webhookURL string, message DiscordWebhookMessage) (string, error) {
 	// Marshal the message to JSON
 	jsonData, err := json.Marshal(message)
 	if err != nil {
-		return fmt.Errorf("failed to marshal Discord message: %w", err)
+		return "", fmt.Errorf("failed to marshal Discord message: %w", err)
 	}
 
-	// Verify total message size doesn't exceed Discord's limits
-	if len(jsonData) > 2000 {
-		return fmt.Errorf("message too large: %d characters (Discord limit: 2000)", len(jsonData))
+	// Verify the message doesn't exceed Discord's limits
+	if n := utf8.RuneCountInString(message.Content); n > maxDiscordContentChars {
+		return "", fmt.Errorf("message too large: %d content characters (Discord limit: %d)", n, maxDiscordContentChars)
+	}
+	if n := embedsLength(message.Embeds); n > maxDiscordEmbedChars {
+		return "", fmt.Errorf("message too large: %d embed characters (Discord limit: %d)", n, maxDiscordEmbedChars)
 	}
 
 	// Create HTTP request
-	req, err := http.NewRequestWithContext(ctx, "POST", webhookURL, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, method, threadURL(webhookURL, message.ThreadID), bytes.NewBuffer(jsonData))
 	if err != nil {
-		return fmt.Errorf("failed to create HTTP request: %w", err)
+		return "", fmt.Errorf("failed to create HTTP request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
@@ -252,7 +433,7 @@ func (d *DiscordWebhookSender) sendWebhookMessage(ctx context.Context, webhookUR
 	// Send the request
 	resp, err := d.httpClient.Do(req)
 	if err != nil {
-		return fmt.Errorf("HTTP request failed: %w", err)
+		return "", fmt.Errorf("HTTP request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
@@ -261,19 +442,54 @@ func (d *DiscordWebhookSender) sendWebhookMessage(ctx context.Context, webhookUR
 
 	// Check for Discord API errors
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return &DiscordAPIError{
+		apiErr := &DiscordAPIError{
 			StatusCode: resp.StatusCode,
 			Message:    string(body),
 		}
+		if resp.StatusCode == http.StatusTooManyRequests {
+			apiErr.RetryAfter = parseRetryAfter(resp.Header.Get("Retry-After"), body)
+		}
+		return "", apiErr
 	}
 
-	return nil
+	// Without ?wait=true Discord answers 204 and there is no id to read
+	var sent struct {
+		ID string `json:"id"`
+	}
+	json.Unmarshal(body, &sent)
+	return sent.ID, nil
+}
+
+// maxDiscordRetryAfter is the longest rate-limit wait SendArticleToDiscord
+// sits through before giving up; anything longer is a global or abuse limit
+// that a quick retry won't get past.
+const maxDiscordRetryAfter = time.Minute
+
+// discordRateLimitBody is the JSON body of a Discord 429 response.
+type discordRateLimitBody struct {
+	RetryAfter float64 `json:"retry_after"` // Seconds, may be fractional
+}
+
+// parseRetryAfter returns how long a 429 response asks to wait. The JSON
+// body's retry_after is preferred since it keeps sub-second precision; the
+// Retry-After header (seconds) is the fallback. It returns 0 if neither is
+// usable.
+func parseRetryAfter(header string, body []byte) time.Duration {
+	var limit discordRateLimitBody
+	if err := json.Unmarshal(body, &limit); err == nil && limit.RetryAfter > 0 {
+		return time.Duration(limit.RetryAfter * float64(time.Second))
+	}
+	if seconds, err := strconv.ParseFloat(strings.TrimSpace(header), 64); err == nil && seconds > 0 {
+		return time.Duration(seconds * float64(time.Second))
+	}
+	return 0
 }
 
 // DiscordAPIError represents an error from Discord's API
 type DiscordAPIError struct {
 	StatusCode int
 	Message    string
+	RetryAfter time.Duration // Wait requested by a 429 response; 0 otherwise
 }
 
 func (e *DiscordAPIError) Error() string {
@@ -319,6 +535,10 @@ func (d *DiscordWebhookSender) sanitizeWebhookURL(webhookURL string) string {
 
 // logDiscordError logs Discord webhook errors to PostgreSQL
 func (d *DiscordWebhookSender) logDiscordError(errorLog DiscordErrorLog) {
+	if d.db == nil {
+		return
+	}
+
 	query := `
 		INSERT INTO discord_error_logs (
 			webhook_url, article_url, error_message, status_code,
@@ -336,7 +556,7 @@ func (d *DiscordWebhookSender) logDiscordError(errorLog DiscordErrorLog) {
 	)
 
 	if err != nil {
-		log.Printf("Failed to log Discord error to database: %v", err)
+		slog.Error("Failed to log Discord error to database", "error", err)
 	}
 }
 
@@ -378,7 +598,7 @@ func InitializeDiscordTables(db *sql.DB) error {
 // SendArticleWithRetry is a convenience function that sends an article to Discord with proper retry logic
 // Usage example:
 //
-//	sender := NewDiscordWebhookSender(db)
+//	sender := NewDiscordWebhookSender(db, metrics, &cfg.Discord)
 //	article := ArticleMessage{
 //		Title:       "Breaking News: Important Update",
 //		URL:         "https://example.com/article",
@@ -0,0 +1,351 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"information-broker/config"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCreateDiscordMessagePublishedField(t *testing.T) {
+	article := ArticleMessage{
+		Title:       "Critical flaw in router firmware",
+		URL:         "https://example.com/article",
+		Summary:     "A summary.",
+		PublishDate: time.Date(2026, 7, 15, 23, 30, 0, 0, time.UTC),
+		FeedTitle:   "Example Security News",
+	}
+
+	findField := func(msg DiscordWebhookMessage, name string) *DiscordEmbedField {
+		for i := range msg.Embeds[0].Fields {
+			if msg.Embeds[0].Fields[i].Name == name {
+				return &msg.Embeds[0].Fields[i]
+			}
+		}
+		return nil
+	}
+
+	tests := []struct {
+		name     string
+		cfg      *config.DiscordConfig
+		wantNone bool
+		want     string
+	}{
+		{"disabled by default", &config.DiscordConfig{}, true, ""},
+		{"no config", nil, true, ""},
+		{"enabled in UTC", &config.DiscordConfig{ShowPublishDateField: true, DisplayTimezone: "UTC"}, false, "Jul 15, 2026 23:30 UTC"},
+		{"enabled in display timezone", &config.DiscordConfig{ShowPublishDateField: true, DisplayTimezone: "America/New_York"}, false, "Jul 15, 2026 19:30 EDT"},
+		{"invalid timezone falls back to UTC", &config.DiscordConfig{ShowPublishDateField: true, DisplayTimezone: "Mars/Olympus"}, false, "Jul 15, 2026 23:30 UTC"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &DiscordWebhookSender{config: tt.cfg}
+			msg := d.createDiscordMessage(article)
+
+			field := findField(msg, "Published")
+			if tt.wantNone {
+				if field != nil {
+					t.Fatalf("expected no Published field, got %+v", field)
+				}
+				return
+			}
+			if field == nil {
+				t.Fatal("expected a Published field")
+			}
+			if field.Value != tt.want {
+				t.Errorf("Published = %q, want %q", field.Value, tt.want)
+			}
+			// The feed still shows as the author alongside the new field
+			if msg.Embeds[0].Author == nil || msg.Embeds[0].Author.Name != article.FeedTitle {
+				t.Errorf("author = %+v, want feed title", msg.Embeds[0].Author)
+			}
+			if msg.Embeds[0].Timestamp != "2026-07-15T23:30:00Z" {
+				t.Errorf("embed timestamp changed: %q", msg.Embeds[0].Timestamp)
+			}
+		})
+	}
+}
+
+func TestSendArticleWithFailover(t *testing.T) {
+	article := ArticleMessage{Title: "Failover test", URL: "https://example.com/a", Summary: "A summary."}
+
+	// webhook returns a stub Discord endpoint answering with status and
+	// counting the posts it receives.
+	webhook := func(status int) (*httptest.Server, *int32) {
+		var hits int32
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			io.Copy(io.Discard, r.Body)
+			atomic.AddInt32(&hits, 1)
+			w.WriteHeader(status)
+		}))
+		t.Cleanup(srv.Close)
+		return srv, &hits
+	}
+
+	newSender := func() *DiscordWebhookSender {
+		return &DiscordWebhookSender{
+			httpClient: &http.Client{Timeout: 5 * time.Second},
+			metrics:    testMetrics(),
+			config:     &config.DiscordConfig{Timeout: 5 * time.Second},
+		}
+	}
+
+	tests := []struct {
+		name          string
+		primaryStatus int
+		wantBackup    int32
+		wantErr       bool
+	}{
+		{"succeeding primary doesn't touch the backup", http.StatusNoContent, 0, false},
+		{"server error fails over to the backup", http.StatusBadGateway, 1, false},
+		{"rate limiting fails over to the backup", http.StatusTooManyRequests, 1, false},
+		{"non-retryable client error does not fail over", http.StatusNotFound, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			primary, primaryHits := webhook(tt.primaryStatus)
+			backup, backupHits := webhook(http.StatusNoContent)
+
+			// No retries, so the test doesn't sit through the backoff
+			d := newSender()
+			d.maxRetries = 0
+			used, err := d.SendArticleWithFailover(context.Background(), []string{primary.URL, backup.URL}, article)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := atomic.LoadInt32(backupHits); got != tt.wantBackup {
+				t.Errorf("backup received %d posts, want %d", got, tt.wantBackup)
+			}
+			if got := atomic.LoadInt32(primaryHits); got != 1 {
+				t.Errorf("primary received %d posts, want 1", got)
+			}
+			if !tt.wantErr {
+				want := primary.URL
+				if tt.wantBackup > 0 {
+					want = backup.URL
+				}
+				if used != want {
+					t.Errorf("used webhook = %q, want %q", used, want)
+				}
+			}
+		})
+	}
+
+	t.Run("all webhooks failing returns the last error", func(t *testing.T) {
+		primary, _ := webhook(http.StatusServiceUnavailable)
+		backup, _ := webhook(http.StatusServiceUnavailable)
+
+		d := newSender()
+		d.maxRetries = 0
+		_, err := d.SendArticleWithFailover(context.Background(), []string{primary.URL, backup.URL}, article)
+		if !isRetryableDiscordError(err) {
+			t.Errorf("expected the backup's 503 to be returned, got %v", err)
+		}
+	})
+}
+
+func TestSendArticleToFeedThread(t *testing.T) {
+	type post struct {
+		threadID   string
+		threadName string
+	}
+	posts := make(chan post, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg DiscordWebhookMessage
+		json.NewDecoder(r.Body).Decode(&msg)
+		posts <- post{threadID: r.URL.Query().Get("thread_id"), threadName: msg.ThreadName}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	d := &DiscordWebhookSender{
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+		metrics:    testMetrics(),
+		config: &config.DiscordConfig{Timeout: 5 * time.Second, FeedThreads: []string{
+			"advisories.example=42",
+			"forum.example=name:Forum News",
+		}},
+	}
+
+	tests := []struct {
+		name    string
+		feedURL string
+		want    post
+	}{
+		{"feed with a thread id", "https://advisories.example/feed", post{threadID: "42"}},
+		{"feed with a thread name", "https://forum.example/rss", post{threadName: "Forum News"}},
+		{"other feeds post to the channel", "https://news.example/feed", post{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			article := ArticleMessage{Title: "Thread test", URL: "https://example.com/a", Summary: "A summary.", FeedURL: tt.feedURL}
+			if err := d.SendArticleToDiscord(context.Background(), srv.URL, article); err != nil {
+				t.Fatal(err)
+			}
+			if got := <-posts; got != tt.want {
+				t.Errorf("post = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCreateDiscordMessageBranding(t *testing.T) {
+	d := &DiscordWebhookSender{config: &config.DiscordConfig{
+		FeedBranding: []string{
+			"category:security=color:#E74C3C;username:Security Desk",
+			"releases.example=avatar:https://releases.example/logo.png",
+		},
+		ImportantKeywords:       []string{"zero-day"},
+		ImportanceKeywordWeight: 1,
+		ImportanceThreshold:     1,
+	}}
+
+	tests := []struct {
+		name         string
+		article      ArticleMessage
+		wantColor    int
+		wantUsername string
+		wantAvatar   string
+	}{
+		{"branded category", ArticleMessage{Title: "Breach", FeedURL: "https://news.example/feed", Category: "Security"}, 0xE74C3C, "Security Desk", defaultDiscordAvatarURL},
+		{"branded feed keeps the other defaults", ArticleMessage{Title: "v1.2", FeedURL: "https://releases.example/atom"}, defaultEmbedColor, defaultDiscordUsername, "https://releases.example/logo.png"},
+		{"unbranded feed", ArticleMessage{Title: "News", FeedURL: "https://news.example/feed"}, defaultEmbedColor, defaultDiscordUsername, defaultDiscordAvatarURL},
+		{"highlighting beats the branded color", ArticleMessage{Title: "Zero-day", FeedURL: "https://news.example/feed", Category: "security"}, importantEmbedColor, "Security Desk", defaultDiscordAvatarURL},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := d.createDiscordMessage(tt.article)
+			if msg.Embeds[0].Color != tt.wantColor || msg.Username != tt.wantUsername || msg.AvatarURL != tt.wantAvatar {
+				t.Errorf("color %#x, username %q, avatar %q; want %#x, %q, %q",
+					msg.Embeds[0].Color, msg.Username, msg.AvatarURL, tt.wantColor, tt.wantUsername, tt.wantAvatar)
+			}
+		})
+	}
+}
+
+func TestImportantArticleHighlight(t *testing.T) {
+	posts := make(chan DiscordWebhookMessage, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg DiscordWebhookMessage
+		json.NewDecoder(r.Body).Decode(&msg)
+		posts <- msg
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	d := &DiscordWebhookSender{
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+		metrics:    testMetrics(),
+		config: &config.DiscordConfig{
+			Timeout:                  5 * time.Second,
+			ImportantKeywords:        []string{"zero-day", "actively exploited"},
+			ImportanceKeywordWeight:  1,
+			ImportancePriorityWeight: 1,
+			ImportanceThreshold:      2,
+			ImportantRoleID:          "987654321",
+		},
+	}
+
+	tests := []struct {
+		name      string
+		article   ArticleMessage
+		important bool
+	}{
+		{"keyword hits", ArticleMessage{Title: "Zero-day in VPN", Summary: "It is actively exploited."}, true},
+		{"keyword and feed priority", ArticleMessage{Title: "Zero-day in VPN", Summary: "Patch soon.", Priority: 1}, true},
+		{"normal article", ArticleMessage{Title: "Zero-day in VPN", Summary: "Patch soon."}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.article.URL = "https://example.com/a"
+			if err := d.SendArticleToDiscord(context.Background(), srv.URL, tt.article); err != nil {
+				t.Fatal(err)
+			}
+			msg := <-posts
+			if !tt.important {
+				if msg.Content != "" || msg.AllowedMentions != nil || msg.Embeds[0].Color != 0x5865F2 {
+					t.Errorf("normal article was highlighted: content %q, mentions %+v, color %#x", msg.Content, msg.AllowedMentions, msg.Embeds[0].Color)
+				}
+				return
+			}
+			if msg.Embeds[0].Color != importantEmbedColor {
+				t.Errorf("embed color = %#x, want %#x", msg.Embeds[0].Color, importantEmbedColor)
+			}
+			if msg.Content != "<@&987654321>" || msg.AllowedMentions == nil || len(msg.AllowedMentions.Roles) != 1 || msg.AllowedMentions.Roles[0] != "987654321" {
+				t.Errorf("important article should mention the role: content %q, mentions %+v", msg.Content, msg.AllowedMentions)
+			}
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		body   string
+		want   time.Duration
+	}{
+		{"body seconds win over header", "2", `{"message": "You are being rate limited.", "retry_after": 0.25, "global": false}`, 250 * time.Millisecond},
+		{"header when body has none", "3", `{"message": "slow down"}`, 3 * time.Second},
+		{"header when body is not JSON", "1.5", `rate limited`, 1500 * time.Millisecond},
+		{"nothing usable", "soon", ``, 0},
+		{"negative is ignored", "-1", `{"retry_after": -2}`, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseRetryAfter(tt.header, []byte(tt.body)); got != tt.want {
+				t.Errorf("parseRetryAfter(%q, %q) = %v, want %v", tt.header, tt.body, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSendArticleHonorsRetryAfter(t *testing.T) {
+	article := ArticleMessage{Title: "Rate limit test", URL: "https://example.com/a", Summary: "A summary."}
+
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		if atomic.AddInt32(&hits, 1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			io.WriteString(w, `{"message": "You are being rate limited.", "retry_after": 0.05, "global": false}`)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	metrics := testMetrics()
+	before := counterValue(t, metrics.discordWebhookErrors.WithLabelValues("rate_limited"))
+
+	d := &DiscordWebhookSender{
+		httpClient: &http.Client{Timeout: 5 * time.Second},
+		maxRetries: 1,
+		metrics:    metrics,
+	}
+	start := time.Now()
+	if err := d.SendArticleToDiscord(context.Background(), srv.URL, article); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	elapsed := time.Since(start)
+
+	if hits != 2 {
+		t.Errorf("webhook received %d posts, want 2", hits)
+	}
+	// The exponential backoff would have waited a full second
+	if elapsed < 50*time.Millisecond || elapsed >= time.Second {
+		t.Errorf("retry waited %v, want about the 50ms Discord asked for", elapsed)
+	}
+	if got := counterValue(t, metrics.discordWebhookErrors.WithLabelValues("rate_limited")) - before; got != 1 {
+		t.Errorf("rate_limited errors recorded = %v, want 1", got)
+	}
+}
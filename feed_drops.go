@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Reasons an article from a feed doesn't reach Discord, the reason label of
+// articles_dropped_total. The first group is decided when the feed is read,
+// the rest when the summarized article would be posted.
+const (
+	dropNoLink           = "no_link"           // The feed item has no link
+	dropNoPublishDate    = "no_publish_date"   // The feed item has no publish date
+	dropBeforeCutoff     = "before_cutoff"     // Published before ARTICLE_CUTOFF_DATE
+	dropBeforeInitiation = "before_initiation" // Published before APP_INITIATION_DATE
+	dropPurged           = "purged"            // Published before the last purge
+	dropDuplicate        = "duplicate"         // Already stored, unchanged
+	dropSaveFailed       = "save_failed"       // Storing the article failed
+
+	dropExcludedFeed = "excluded_feed" // The feed is in DISCORD_EXCLUDED_FEEDS
+	dropPostFailed   = "post_failed"   // No webhook accepted the post
+)
+
+// FeedDrops is one feed's dropped articles since startup, by reason.
+type FeedDrops struct {
+	FeedURL string         `json:"feed_url"`
+	Total   int            `json:"total"`
+	Reasons map[string]int `json:"reasons"`
+}
+
+// feedHost returns the lower-cased host name of a feed URL.
+func feedHost(feedURL string) string {
+	u, err := url.Parse(feedURL)
+	if err != nil {
+		return ""
+	}
+	return strings.ToLower(u.Hostname())
+}
+
+// getFeedDrops reports, for the feeds served from the host in
+// /feeds/{host}/drops, how many articles were dropped since startup and
+// why, to tell why a feed isn't producing posts. Monitored feeds without
+// drops are listed with none.
+func (s *APIServer) getFeedDrops(w http.ResponseWriter, r *http.Request) {
+	host, ok := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/feeds/"), "/drops")
+	if !ok || host == "" || strings.Contains(host, "/") {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	host = strings.ToLower(host)
+
+	byFeed := make(map[string]FeedDrops)
+	if s.monitor != nil {
+		for _, feed := range s.monitor.Feeds() {
+			if feedHost(feed.URL) == host {
+				byFeed[feed.URL] = FeedDrops{FeedURL: feed.URL, Reasons: map[string]int{}}
+			}
+		}
+	}
+	for feedURL, reasons := range s.metrics.ArticleDrops() {
+		if feedHost(feedURL) != host {
+			continue
+		}
+		drops := FeedDrops{FeedURL: feedURL, Reasons: reasons}
+		for _, count := range reasons {
+			drops.Total += count
+		}
+		byFeed[feedURL] = drops
+	}
+
+	feeds := make([]FeedDrops, 0, len(byFeed))
+	total := 0
+	for _, drops := range byFeed {
+		feeds = append(feeds, drops)
+		total += drops.Total
+	}
+	sort.Slice(feeds, func(i, j int) bool { return feeds[i].FeedURL < feeds[j].FeedURL })
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"host":  host,
+		"feeds": feeds,
+		"total": total,
+	})
+}
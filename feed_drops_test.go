@@ -0,0 +1,171 @@
+package main
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"information-broker/config"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+)
+
+func TestProcessArticleDropReasons(t *testing.T) {
+	published := func(t time.Time) *time.Time { return &t }
+	cfg := &config.Config{App: config.AppConfig{
+		ArticleCutoffDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		InitiationDate:    time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+	}}
+
+	tests := []struct {
+		reason string
+		item   *gofeed.Item
+	}{
+		{dropNoLink, &gofeed.Item{Title: "No link", PublishedParsed: published(time.Now())}},
+		{dropNoPublishDate, &gofeed.Item{Title: "No date", Link: "https://drops.example/no-date"}},
+		{dropBeforeCutoff, &gofeed.Item{Title: "Old", Link: "https://drops.example/old", PublishedParsed: published(time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC))}},
+		{dropBeforeInitiation, &gofeed.Item{Title: "Early", Link: "https://drops.example/early", PublishedParsed: published(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC))}},
+		{dropPurged, &gofeed.Item{Title: "Purged", Link: "https://drops.example/purged", PublishedParsed: published(time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC))}},
+		{dropDuplicate, &gofeed.Item{Title: "Seen", Link: "https://drops.example/seen", PublishedParsed: published(time.Now())}},
+		{dropSaveFailed, &gofeed.Item{Title: "Unsaved", Link: "https://drops.example/unsaved", Description: "Body.", PublishedParsed: published(time.Now())}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.reason, func(t *testing.T) {
+			db, _ := openExecRecorder(t)
+			db.Close() // Every write fails
+			metrics := testMetrics()
+			m := NewRSSMonitor(db, nil, metrics, cfg, NewCircuitBreakerManager(), nil, nil)
+			m.purgedBefore = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+			m.seenArticles["https://drops.example/seen"] = true
+			budget := newContentBudget(time.Millisecond)
+			budget.charge(time.Second) // Use the feed's content rather than fetching the page
+
+			feedURL := "https://drops.example/" + tt.reason + ".xml"
+			if m.processArticle(tt.item, feedURL, budget) {
+				t.Fatal("processArticle() = true, want the article dropped")
+			}
+			for _, reason := range []string{dropNoLink, dropNoPublishDate, dropBeforeCutoff, dropBeforeInitiation, dropPurged, dropDuplicate, dropSaveFailed} {
+				want := 0.0
+				if reason == tt.reason {
+					want = 1
+				}
+				if got := counterValue(t, metrics.articlesDropped.WithLabelValues(feedURL, reason)); got != want {
+					t.Errorf("articles_dropped_total{reason=%q} = %v, want %v", reason, got, want)
+				}
+			}
+
+			// Articles that got past the date checks count as post-cutoff,
+			// only the cutoff itself counts as pre-cutoff
+			wantPre, wantPost := 0.0, 0.0
+			switch tt.reason {
+			case dropBeforeCutoff:
+				wantPre = 1
+			case dropPurged, dropDuplicate, dropSaveFailed:
+				wantPost = 1
+			}
+			if got := counterValue(t, metrics.articlesFilteredPreCutoff.WithLabelValues(feedURL)); got != wantPre {
+				t.Errorf("articles_filtered_pre_cutoff_total = %v, want %v", got, wantPre)
+			}
+			if got := counterValue(t, metrics.articlesProcessedPostCutoff.WithLabelValues(feedURL)); got != wantPost {
+				t.Errorf("articles_processed_post_cutoff_total = %v, want %v", got, wantPost)
+			}
+		})
+	}
+}
+
+func TestSendDiscordNotificationDropReasons(t *testing.T) {
+	discord := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer discord.Close()
+
+	tests := []struct {
+		reason    string
+		feedURL   string
+		published time.Time
+	}{
+		{dropExcludedFeed, "https://excluded.example/feed", time.Now()},
+		{dropBeforeCutoff, "https://post-cutoff.example/feed", time.Date(2023, 5, 1, 0, 0, 0, 0, time.UTC)},
+		{dropPostFailed, "https://rejected.example/feed", time.Now()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.reason, func(t *testing.T) {
+			db, recorder := openExecRecorder(t)
+			recorder.answer("SELECT posted_to_discord", []driver.Value{false})
+			recorder.answer("SELECT feed_url, publish_date", []driver.Value{tt.feedURL, tt.published})
+
+			metrics := testMetrics()
+			cfg := &config.Config{
+				App: config.AppConfig{ArticleCutoffDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+				Discord: config.DiscordConfig{
+					WebhookURL:    discord.URL,
+					Timeout:       5 * time.Second,
+					ExcludedFeeds: []string{"excluded.example"},
+				},
+			}
+			s := &SummarizationScheduler{
+				db:      db,
+				config:  cfg,
+				metrics: metrics,
+				discordSender: &DiscordWebhookSender{
+					httpClient: &http.Client{Timeout: 5 * time.Second},
+					metrics:    metrics,
+					config:     &cfg.Discord,
+				},
+				deferredPosts: &deferredPostQueue{},
+			}
+
+			before := counterValue(t, metrics.articlesDropped.WithLabelValues(tt.feedURL, tt.reason))
+			s.sendDiscordNotification(SummarizationRequest{ArticleURL: "https://a.example/1", ArticleTitle: "Article"}, "A summary.")
+			if got := counterValue(t, metrics.articlesDropped.WithLabelValues(tt.feedURL, tt.reason)) - before; got != 1 {
+				t.Errorf("articles_dropped_total{reason=%q} grew by %v, want 1", tt.reason, got)
+			}
+		})
+	}
+}
+
+func TestGetFeedDrops(t *testing.T) {
+	metrics := testMetrics()
+	metrics.RecordArticleDropped("https://report.example/feed.xml", dropDuplicate)
+	metrics.RecordArticleDropped("https://report.example/feed.xml", dropDuplicate)
+	metrics.RecordArticleDropped("https://report.example/feed.xml", dropSaveFailed)
+	metrics.RecordArticleDropped("https://other.example/feed.xml", dropDuplicate)
+
+	cfg := &config.Config{}
+	m := NewRSSMonitor(nil, []Feed{{URL: "https://report.example/feed.xml"}, {URL: "https://report.example/quiet.xml"}}, metrics, cfg, NewCircuitBreakerManager(), nil, nil)
+	s := &APIServer{metrics: metrics, monitor: m}
+
+	rec := httptest.NewRecorder()
+	s.getFeedDrops(rec, httptest.NewRequest(http.MethodGet, "/feeds/Report.Example/drops", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	var body struct {
+		Host  string      `json:"host"`
+		Feeds []FeedDrops `json:"feeds"`
+		Total int         `json:"total"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Host != "report.example" || body.Total != 3 || len(body.Feeds) != 2 {
+		t.Fatalf("report = %+v, want 3 drops over the 2 feeds of report.example", body)
+	}
+	if feed := body.Feeds[0]; feed.FeedURL != "https://report.example/feed.xml" || feed.Total != 3 ||
+		feed.Reasons[dropDuplicate] != 2 || feed.Reasons[dropSaveFailed] != 1 {
+		t.Errorf("feed.xml drops = %+v", feed)
+	}
+	if feed := body.Feeds[1]; feed.FeedURL != "https://report.example/quiet.xml" || feed.Total != 0 {
+		t.Errorf("quiet.xml drops = %+v, want none", feed)
+	}
+
+	for _, target := range []string{"/feeds/report.example", "/feeds//drops", "/feeds/a/b/drops"} {
+		rec := httptest.NewRecorder()
+		s.getFeedDrops(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("GET %s: status = %d, want 404", target, rec.Code)
+		}
+	}
+}
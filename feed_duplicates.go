@@ -0,0 +1,148 @@
+package main
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// minSharedArticlesForOverlap is the fewest shared stories for a feed pair to
+// show up in the duplicates report; one coincidental match isn't a signal.
+const minSharedArticlesForOverlap = 2
+
+// FeedOverlap describes how much two feeds duplicate each other in a window.
+// Overlap is Shared divided by the smaller feed's article count, so 1.0 means
+// everything the smaller feed published also appeared in the other one.
+type FeedOverlap struct {
+	FeedA   string  `json:"feed_a"`
+	FeedB   string  `json:"feed_b"`
+	Shared  int     `json:"shared_articles"`
+	TotalA  int     `json:"feed_a_articles"`
+	TotalB  int     `json:"feed_b_articles"`
+	Overlap float64 `json:"overlap"`
+}
+
+// feedTitle is one (feed, article title) row fed into findFeedOverlaps.
+type feedTitle struct {
+	FeedURL string
+	Title   string
+}
+
+// normalizeTitle lowercases a headline and collapses punctuation and
+// whitespace runs to single spaces, so syndicated copies that differ only in
+// quoting, dashes or casing compare equal.
+func normalizeTitle(title string) string {
+	fields := strings.FieldsFunc(strings.ToLower(title), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(fields, " ")
+}
+
+// findFeedOverlaps groups articles by normalized title and counts, for every
+// pair of feeds, how many distinct titles they share. Pairs below minShared
+// are dropped. Results are ordered by shared count, then overlap, then feed
+// names so the report is stable.
+func findFeedOverlaps(titles []feedTitle, minShared int) []FeedOverlap {
+	feedsByTitle := make(map[string]map[string]bool)
+	totals := make(map[string]int)
+	for _, t := range titles {
+		norm := normalizeTitle(t.Title)
+		if norm == "" {
+			continue
+		}
+		if feedsByTitle[norm] == nil {
+			feedsByTitle[norm] = make(map[string]bool)
+		}
+		if !feedsByTitle[norm][t.FeedURL] {
+			feedsByTitle[norm][t.FeedURL] = true
+			totals[t.FeedURL]++
+		}
+	}
+
+	type pair struct{ a, b string }
+	shared := make(map[pair]int)
+	for _, feeds := range feedsByTitle {
+		if len(feeds) < 2 {
+			continue
+		}
+		urls := make([]string, 0, len(feeds))
+		for u := range feeds {
+			urls = append(urls, u)
+		}
+		sort.Strings(urls)
+		for i := range urls {
+			for j := i + 1; j < len(urls); j++ {
+				shared[pair{urls[i], urls[j]}]++
+			}
+		}
+	}
+
+	overlaps := []FeedOverlap{}
+	for p, count := range shared {
+		if count < minShared {
+			continue
+		}
+		smaller := totals[p.a]
+		if totals[p.b] < smaller {
+			smaller = totals[p.b]
+		}
+		overlaps = append(overlaps, FeedOverlap{
+			FeedA:   p.a,
+			FeedB:   p.b,
+			Shared:  count,
+			TotalA:  totals[p.a],
+			TotalB:  totals[p.b],
+			Overlap: float64(count) / float64(smaller),
+		})
+	}
+
+	sort.Slice(overlaps, func(i, j int) bool {
+		if overlaps[i].Shared != overlaps[j].Shared {
+			return overlaps[i].Shared > overlaps[j].Shared
+		}
+		if overlaps[i].Overlap != overlaps[j].Overlap {
+			return overlaps[i].Overlap > overlaps[j].Overlap
+		}
+		if overlaps[i].FeedA != overlaps[j].FeedA {
+			return overlaps[i].FeedA < overlaps[j].FeedA
+		}
+		return overlaps[i].FeedB < overlaps[j].FeedB
+	})
+	return overlaps
+}
+
+// getFeedDuplicates reports feed pairs with heavily overlapping articles over
+// the last `days` days (default 7, max 90; anything else is a 400), to help
+// prune redundant feeds.
+func (s *APIServer) getFeedDuplicates(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	days, err := parseDays(r, 7, 90)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	since := time.Now().UTC().AddDate(0, 0, -days)
+
+	overlaps, err := NewDatabaseOperations(s.db).GetCrossFeedDuplicates(since)
+	if err != nil {
+		slog.Error("Database query error", "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"since": since,
+		"days":  days,
+		"pairs": overlaps,
+		"count": len(overlaps),
+	})
+}
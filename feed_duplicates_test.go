@@ -0,0 +1,93 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNormalizeTitle(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Critical Flaw in OpenSSH", "critical flaw in openssh"},
+		{"Critical flaw in OpenSSH!", "critical flaw in openssh"},
+		{"  “Critical” flaw — in   OpenSSH ", "critical flaw in openssh"},
+		{"CVE-2024-3094: xz backdoor", "cve 2024 3094 xz backdoor"},
+		{"!!!", ""},
+	}
+	for _, tt := range tests {
+		if got := normalizeTitle(tt.in); got != tt.want {
+			t.Errorf("normalizeTitle(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFindFeedOverlaps(t *testing.T) {
+	const (
+		feedA = "https://a.example/feed"
+		feedB = "https://b.example/feed"
+		feedC = "https://c.example/feed"
+	)
+	titles := []feedTitle{
+		// A and B share three stories (one with different punctuation/casing)
+		{feedA, "Ransomware hits hospital chain"},
+		{feedB, "Ransomware Hits Hospital Chain!"},
+		{feedA, "New Chrome zero-day patched"},
+		{feedB, "New Chrome zero-day patched"},
+		{feedA, "Botnet takedown in Europe"},
+		{feedB, "Botnet takedown in Europe"},
+		{feedA, "Only on A"},
+		// C shares a single story with A -- below the threshold
+		{feedC, "Only on A"},
+		{feedC, "Only on C"},
+		// A repeat of the same story within one feed counts once
+		{feedB, "New Chrome zero-day patched"},
+	}
+
+	got := findFeedOverlaps(titles, 2)
+	if len(got) != 1 {
+		t.Fatalf("expected 1 overlapping pair, got %d: %+v", len(got), got)
+	}
+
+	o := got[0]
+	if o.FeedA != feedA || o.FeedB != feedB {
+		t.Errorf("pair = %s/%s, want %s/%s", o.FeedA, o.FeedB, feedA, feedB)
+	}
+	if o.Shared != 3 {
+		t.Errorf("shared = %d, want 3", o.Shared)
+	}
+	if o.TotalA != 4 || o.TotalB != 3 {
+		t.Errorf("totals = %d/%d, want 4/3", o.TotalA, o.TotalB)
+	}
+	if o.Overlap != 1.0 {
+		t.Errorf("overlap = %v, want 1.0 (every B story also on A)", o.Overlap)
+	}
+
+	t.Run("threshold of one includes the single shared story", func(t *testing.T) {
+		got := findFeedOverlaps(titles, 1)
+		if len(got) != 2 {
+			t.Fatalf("expected 2 pairs, got %+v", got)
+		}
+		if got[1].FeedA != feedA || got[1].FeedB != feedC || got[1].Shared != 1 {
+			t.Errorf("second pair = %+v, want A/C sharing 1", got[1])
+		}
+	})
+
+	t.Run("no data", func(t *testing.T) {
+		if got := findFeedOverlaps(nil, 2); len(got) != 0 {
+			t.Errorf("expected no pairs, got %+v", got)
+		}
+	})
+}
+
+func TestGetFeedDuplicatesRejectsMalformedDays(t *testing.T) {
+	s := &APIServer{}
+	for _, target := range []string{"/feeds/duplicates?days=abc", "/feeds/duplicates?days=0", "/feeds/duplicates?days=91", "/feeds/duplicates?days=2.5"} {
+		rec := httptest.NewRecorder()
+		s.getFeedDuplicates(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("GET %s: status = %d, want 400", target, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// feedFetchGuard enforces a minimum gap between fetches of the same feed, so
+// overlapping triggers (startup fetch, interval tickers, on-demand fetches,
+// reloads) don't hit a publisher twice in quick succession.
+type feedFetchGuard struct {
+	mu            sync.Mutex
+	minInterval   time.Duration
+	lastFetchedAt map[string]time.Time
+}
+
+func newFeedFetchGuard(minInterval time.Duration) *feedFetchGuard {
+	return &feedFetchGuard{minInterval: minInterval, lastFetchedAt: make(map[string]time.Time)}
+}
+
+// claim reports whether feedURL may be fetched at now, and if so records now
+// as its last fetch. A zero minInterval never blocks.
+func (g *feedFetchGuard) claim(feedURL string, now time.Time) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if last, ok := g.lastFetchedAt[feedURL]; ok && g.minInterval > 0 && now.Sub(last) < g.minInterval {
+		return false
+	}
+	g.lastFetchedAt[feedURL] = now
+	return true
+}
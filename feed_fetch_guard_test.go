@@ -0,0 +1,62 @@
+package main
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFeedFetchGuard(t *testing.T) {
+	const feed = "https://example.com/feed"
+	start := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	g := newFeedFetchGuard(time.Minute)
+
+	if !g.claim(feed, start) {
+		t.Fatal("first fetch should be allowed")
+	}
+	if g.claim(feed, start.Add(20*time.Second)) {
+		t.Error("second trigger within the minimum interval should be skipped")
+	}
+	if !g.claim("https://other.example/rss", start.Add(20*time.Second)) {
+		t.Error("the guard is per feed; another feed should not be blocked")
+	}
+	if !g.claim(feed, start.Add(time.Minute)) {
+		t.Error("fetch after the minimum interval should be allowed")
+	}
+	// A skipped trigger doesn't push the window forward
+	if g.claim(feed, start.Add(90*time.Second)) || !g.claim(feed, start.Add(2*time.Minute)) {
+		t.Error("window should be measured from the last allowed fetch")
+	}
+}
+
+func TestFeedFetchGuardConcurrentTriggers(t *testing.T) {
+	g := newFeedFetchGuard(time.Minute)
+	now := time.Now()
+
+	// Startup fetch, ticker and on-demand trigger racing for the same feed
+	var fetches int32
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if g.claim("https://example.com/feed", now) {
+				atomic.AddInt32(&fetches, 1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if fetches != 1 {
+		t.Errorf("got %d fetches, want exactly 1", fetches)
+	}
+}
+
+func TestFeedFetchGuardDisabled(t *testing.T) {
+	g := newFeedFetchGuard(0)
+	now := time.Now()
+	if !g.claim("f", now) || !g.claim("f", now) {
+		t.Error("a zero minimum interval should never skip fetches")
+	}
+}
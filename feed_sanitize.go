@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"log/slog"
+	"regexp"
+
+	"github.com/mmcdole/gofeed"
+)
+
+var (
+	// xmlControlChars are the control characters XML 1.0 doesn't allow,
+	// raw or as numeric character references (&#11;, &#x1F;).
+	xmlControlChars = regexp.MustCompile(`[\x00-\x08\x0B\x0C\x0E-\x1F]`)
+	xmlControlRefs  = regexp.MustCompile(`&#(?:0*(?:[0-8]|1[1-24-9]|2[0-9]|3[01])|[xX]0*(?:[0-8bBcCeEfF]|1[0-9a-fA-F]));`)
+
+	// xmlEncodingDecl matches the encoding in the XML declaration.
+	xmlEncodingDecl = regexp.MustCompile(`^(<\?xml[^>]*?\sencoding\s*=\s*)(?:"[^"]*"|'[^']*')`)
+)
+
+// sanitizeFeedBody repairs the damage that most often makes an otherwise
+// readable feed fail to parse: junk before the first tag, bytes that aren't
+// valid UTF-8 (usually a Latin-1 feed declared as UTF-8), control
+// characters XML forbids, and an encoding declaration the parser doesn't
+// know. The result is UTF-8 and declared as such. It reports whether
+// anything changed.
+func sanitizeFeedBody(body []byte) ([]byte, bool) {
+	clean := body
+	if start := bytes.IndexByte(clean, '<'); start > 0 {
+		clean = clean[start:]
+	}
+	clean = bytes.ToValidUTF8(clean, nil)
+	clean = xmlControlChars.ReplaceAll(clean, nil)
+	clean = xmlControlRefs.ReplaceAll(clean, nil)
+	clean = xmlEncodingDecl.ReplaceAll(clean, []byte(`${1}"UTF-8"`))
+	return clean, !bytes.Equal(clean, body)
+}
+
+// parseFeed parses a feed body. If that fails, it parses a sanitized copy
+// once before giving up, so one bad byte doesn't lose every article in the
+// feed; the first error is returned when the retry fails too.
+func (m *RSSMonitor) parseFeed(feedURL string, body []byte) (*gofeed.Feed, error) {
+	feed, err := m.parser.Parse(bytes.NewReader(body))
+	if err == nil {
+		return feed, nil
+	}
+
+	sanitized, changed := sanitizeFeedBody(body)
+	if !changed {
+		return nil, err
+	}
+	feed, retryErr := m.parser.Parse(bytes.NewReader(sanitized))
+	if retryErr != nil {
+		m.metrics.RecordFeedSanitized(feedURL, "failed")
+		slog.Warn("Feed still fails to parse after sanitizing it", "feed_url", feedURL, "error", err, "sanitized_error", retryErr)
+		return nil, err
+	}
+	m.metrics.RecordFeedSanitized(feedURL, "recovered")
+	slog.Warn("Parsed malformed feed after sanitizing it", "feed_url", feedURL, "error", err)
+	return feed, nil
+}
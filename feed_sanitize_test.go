@@ -0,0 +1,59 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/mmcdole/gofeed"
+)
+
+func TestParseFeedSanitizesMalformedFeeds(t *testing.T) {
+	const item = `<item><title>Article</title><link>https://a.example/1</link></item>`
+	feed := func(decl, title string) string {
+		return `<?xml version="1.0" encoding="` + decl + `"?><rss version="2.0"><channel><title>` + title + `</title>` + item + `</channel></rss>`
+	}
+	tests := []struct {
+		name      string
+		body      string
+		wantTitle string
+	}{
+		{"Latin-1 bytes declared as UTF-8", feed("UTF-8", "Caf\xe9 news"), "Caf news"},
+		{"raw control character", feed("UTF-8", "Tabs\x0b and more"), "Tabs and more"},
+		{"control character reference", feed("UTF-8", "Tabs&#11; and &#x1F;more"), "Tabs and more"},
+		{"junk before the declaration", "\n\nHTTP/1.1 200 OK\n" + feed("UTF-8", "Junk"), "Junk"},
+		{"unknown encoding", feed("x-made-up", "Unknown"), "Unknown"},
+	}
+	metrics := testMetrics()
+	m := &RSSMonitor{parser: gofeed.NewParser(), metrics: metrics}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			feedURL := "https://sanitize.example/" + tt.name
+			if _, err := gofeed.NewParser().ParseString(tt.body); err == nil {
+				t.Fatal("the raw body parses; the case tests nothing")
+			}
+			parsed, err := m.parseFeed(feedURL, []byte(tt.body))
+			if err != nil {
+				t.Fatalf("parseFeed: %v", err)
+			}
+			if parsed.Title != tt.wantTitle || len(parsed.Items) != 1 {
+				t.Errorf("parsed title %q with %d items, want %q with 1", parsed.Title, len(parsed.Items), tt.wantTitle)
+			}
+			if got := counterValue(t, metrics.feedsSanitized.WithLabelValues(feedURL, "recovered")); got != 1 {
+				t.Errorf("recovered count = %v, want 1", got)
+			}
+		})
+	}
+
+	t.Run("unrepairable feed returns the original error", func(t *testing.T) {
+		const feedURL = "https://sanitize.example/truncated"
+		if _, err := m.parseFeed(feedURL, []byte("\x00"+feed("UTF-8", "Cut")[:60])); err == nil {
+			t.Fatal("parseFeed accepted a truncated feed")
+		}
+		if got := counterValue(t, metrics.feedsSanitized.WithLabelValues(feedURL, "failed")); got != 1 {
+			t.Errorf("failed count = %v, want 1", got)
+		}
+	})
+
+	if _, changed := sanitizeFeedBody([]byte(feed("UTF-8", "Clean &amp; valid"))); changed {
+		t.Error("sanitizeFeedBody changed a valid feed")
+	}
+}
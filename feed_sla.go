@@ -0,0 +1,228 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"information-broker/config"
+	"log/slog"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// FeedSLA is one feed's standing against the configured SLAs over the
+// window. A measure with no data in the window (no fetches, summaries or
+// posts) is null and doesn't fail the feed.
+type FeedSLA struct {
+	FeedURL string `json:"feed_url"`
+
+	Fetches          int      `json:"fetches"`
+	FetchSuccessRate *float64 `json:"fetch_success_rate"`
+	FetchOK          bool     `json:"fetch_ok"`
+
+	Summaries            int      `json:"summaries"`
+	MedianSummarySeconds *float64 `json:"median_summary_seconds"`
+	SummaryOK            bool     `json:"summary_ok"`
+
+	Posted           int      `json:"posted"`
+	PostedOnTimeRate *float64 `json:"posted_on_time_rate"`
+	PostingOK        bool     `json:"posting_ok"`
+
+	Pass bool `json:"pass"`
+}
+
+// feedFetchStats counts a feed's fetches in the SLA window.
+type feedFetchStats struct {
+	total, succeeded int
+}
+
+// feedSummaryStats is a feed's successful summarizations in the SLA window.
+type feedSummaryStats struct {
+	count  int
+	median time.Duration
+}
+
+// feedPostingStats counts a feed's articles posted in the SLA window, and
+// those posted within SLAPostWithin of their publish date.
+type feedPostingStats struct {
+	posted, onTime int
+}
+
+// evaluateFeedSLAs judges every feed seen in any of the stats against the
+// thresholds in cfg, ordered by feed URL.
+func evaluateFeedSLAs(fetches map[string]feedFetchStats, summaries map[string]feedSummaryStats, posts map[string]feedPostingStats, cfg *config.AppConfig) []FeedSLA {
+	feedURLs := make(map[string]bool)
+	for feedURL := range fetches {
+		feedURLs[feedURL] = true
+	}
+	for feedURL := range summaries {
+		feedURLs[feedURL] = true
+	}
+	for feedURL := range posts {
+		feedURLs[feedURL] = true
+	}
+
+	slas := []FeedSLA{}
+	for feedURL := range feedURLs {
+		sla := FeedSLA{FeedURL: feedURL, FetchOK: true, SummaryOK: true, PostingOK: true}
+		if fetch := fetches[feedURL]; fetch.total > 0 {
+			rate := float64(fetch.succeeded) / float64(fetch.total)
+			sla.Fetches, sla.FetchSuccessRate = fetch.total, &rate
+			sla.FetchOK = rate >= cfg.SLAFetchSuccessRate
+		}
+		if summary := summaries[feedURL]; summary.count > 0 {
+			seconds := summary.median.Seconds()
+			sla.Summaries, sla.MedianSummarySeconds = summary.count, &seconds
+			sla.SummaryOK = summary.median <= cfg.SLASummaryLatency
+		}
+		if post := posts[feedURL]; post.posted > 0 {
+			rate := float64(post.onTime) / float64(post.posted)
+			sla.Posted, sla.PostedOnTimeRate = post.posted, &rate
+			sla.PostingOK = rate >= cfg.SLAPostedOnTimeRate
+		}
+		sla.Pass = sla.FetchOK && sla.SummaryOK && sla.PostingOK
+		slas = append(slas, sla)
+	}
+
+	sort.Slice(slas, func(i, j int) bool { return slas[i].FeedURL < slas[j].FeedURL })
+	return slas
+}
+
+// GetFeedFetchStats counts each feed's fetches since the given time, and
+// how many succeeded (a 304 Not Modified counts as a success).
+func (ops *DatabaseOperations) GetFeedFetchStats(since time.Time) (map[string]feedFetchStats, error) {
+	rows, err := ops.db.Query(`
+		SELECT feed_url, COUNT(*), COUNT(*) FILTER (WHERE status IN ('success', 'not_modified'))
+		FROM fetch_logs
+		WHERE created_at >= $1
+		GROUP BY feed_url`, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query fetch stats: %w", err)
+	}
+	defer rows.Close()
+
+	stats := make(map[string]feedFetchStats)
+	for rows.Next() {
+		var feedURL string
+		var fetch feedFetchStats
+		if err := rows.Scan(&feedURL, &fetch.total, &fetch.succeeded); err != nil {
+			return nil, fmt.Errorf("failed to scan fetch stats: %w", err)
+		}
+		stats[feedURL] = fetch
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate fetch stats: %w", err)
+	}
+	return stats, nil
+}
+
+// GetFeedSummaryLatencies returns the count and median duration of each
+// feed's successful summarizations since the given time, going by the
+// summary_logs of its articles.
+func (ops *DatabaseOperations) GetFeedSummaryLatencies(since time.Time) (map[string]feedSummaryStats, error) {
+	rows, err := ops.db.Query(`
+		SELECT a.feed_url, COUNT(*), percentile_cont(0.5) WITHIN GROUP (ORDER BY l.duration_ms)
+		FROM summary_logs l
+		JOIN articles a ON a.url = l.article_url
+		WHERE l.created_at >= $1 AND l.status = 'success' AND a.feed_url IS NOT NULL AND a.feed_url <> ''
+		GROUP BY a.feed_url`, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query summary latencies: %w", err)
+	}
+	defer rows.Close()
+
+	stats := make(map[string]feedSummaryStats)
+	for rows.Next() {
+		var feedURL string
+		var summary feedSummaryStats
+		var medianMs float64
+		if err := rows.Scan(&feedURL, &summary.count, &medianMs); err != nil {
+			return nil, fmt.Errorf("failed to scan summary latencies: %w", err)
+		}
+		summary.median = time.Duration(medianMs * float64(time.Millisecond))
+		stats[feedURL] = summary
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate summary latencies: %w", err)
+	}
+	return stats, nil
+}
+
+// GetFeedPostingStats counts each feed's articles posted to Discord since
+// the given time, and how many of them were posted within `within` of their
+// publish date.
+func (ops *DatabaseOperations) GetFeedPostingStats(since time.Time, within time.Duration) (map[string]feedPostingStats, error) {
+	rows, err := ops.db.Query(`
+		SELECT feed_url, COUNT(*), COUNT(*) FILTER (WHERE discord_posted_at <= publish_date + $2 * INTERVAL '1 second')
+		FROM articles
+		WHERE discord_posted_at >= $1 AND publish_date IS NOT NULL AND feed_url IS NOT NULL AND feed_url <> ''
+		GROUP BY feed_url`, since, within.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query posting stats: %w", err)
+	}
+	defer rows.Close()
+
+	stats := make(map[string]feedPostingStats)
+	for rows.Next() {
+		var feedURL string
+		var post feedPostingStats
+		if err := rows.Scan(&feedURL, &post.posted, &post.onTime); err != nil {
+			return nil, fmt.Errorf("failed to scan posting stats: %w", err)
+		}
+		stats[feedURL] = post
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate posting stats: %w", err)
+	}
+	return stats, nil
+}
+
+// getFeedSLAs reports, per feed, whether it met the configured SLAs over
+// the last SLA_WINDOW: fetch success rate, median summarization latency and
+// the share of posted articles that reached Discord in time.
+func (s *APIServer) getFeedSLAs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	cfg := &s.config.App
+	since := time.Now().UTC().Add(-cfg.SLAWindow)
+	ops := NewDatabaseOperations(s.db)
+	fetches, err := ops.GetFeedFetchStats(since)
+	var summaries map[string]feedSummaryStats
+	if err == nil {
+		summaries, err = ops.GetFeedSummaryLatencies(since)
+	}
+	var posts map[string]feedPostingStats
+	if err == nil {
+		posts, err = ops.GetFeedPostingStats(since, cfg.SLAPostWithin)
+	}
+	if err != nil {
+		slog.Error("Database query error", "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	feeds := evaluateFeedSLAs(fetches, summaries, posts, cfg)
+	failing := 0
+	for _, feed := range feeds {
+		if !feed.Pass {
+			failing++
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"since": since,
+		"thresholds": map[string]interface{}{
+			"fetch_success_rate":     cfg.SLAFetchSuccessRate,
+			"median_summary_seconds": cfg.SLASummaryLatency.Seconds(),
+			"post_within_seconds":    cfg.SLAPostWithin.Seconds(),
+			"posted_on_time_rate":    cfg.SLAPostedOnTimeRate,
+		},
+		"feeds":   feeds,
+		"count":   len(feeds),
+		"failing": failing,
+	})
+}
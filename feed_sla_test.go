@@ -0,0 +1,77 @@
+package main
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"information-broker/config"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestGetFeedSLAs(t *testing.T) {
+	db, recorder := openExecRecorder(t)
+	recorder.answer("FROM fetch_logs",
+		[]driver.Value{"https://healthy.example/feed", int64(96), int64(95)},
+		[]driver.Value{"https://flaky.example/feed", int64(96), int64(60)},
+		[]driver.Value{"https://slow.example/feed", int64(48), int64(48)},
+		[]driver.Value{"https://new.example/feed", int64(2), int64(2)},
+	)
+	recorder.answer("FROM summary_logs",
+		[]driver.Value{"https://healthy.example/feed", int64(20), 45000.0},
+		[]driver.Value{"https://flaky.example/feed", int64(5), 30000.0},
+		[]driver.Value{"https://slow.example/feed", int64(12), 300000.0},
+	)
+	recorder.answer("discord_posted_at <=",
+		[]driver.Value{"https://healthy.example/feed", int64(20), int64(19)},
+		[]driver.Value{"https://flaky.example/feed", int64(5), int64(5)},
+		[]driver.Value{"https://slow.example/feed", int64(12), int64(3)},
+	)
+	s := &APIServer{db: db, config: &config.Config{App: config.AppConfig{
+		SLAWindow:           24 * time.Hour,
+		SLAFetchSuccessRate: 0.95,
+		SLASummaryLatency:   2 * time.Minute,
+		SLAPostWithin:       30 * time.Minute,
+		SLAPostedOnTimeRate: 0.9,
+	}}}
+
+	rec := httptest.NewRecorder()
+	s.getFeedSLAs(rec, httptest.NewRequest(http.MethodGet, "/feeds/sla", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET /feeds/sla = %d: %s", rec.Code, rec.Body)
+	}
+	var got struct {
+		Feeds   []FeedSLA `json:"feeds"`
+		Failing int       `json:"failing"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		feedURL                     string
+		fetchOK, summaryOK, postsOK bool
+	}{
+		{"https://flaky.example/feed", false, true, true},
+		{"https://healthy.example/feed", true, true, true},
+		{"https://new.example/feed", true, true, true}, // Nothing summarized or posted yet
+		{"https://slow.example/feed", true, false, false},
+	}
+	if len(got.Feeds) != len(want) || got.Failing != 2 {
+		t.Fatalf("got %d feeds with %d failing: %+v; want %d with 2 failing", len(got.Feeds), got.Failing, got.Feeds, len(want))
+	}
+	for i, w := range want {
+		feed := got.Feeds[i]
+		if feed.FeedURL != w.feedURL || feed.FetchOK != w.fetchOK || feed.SummaryOK != w.summaryOK || feed.PostingOK != w.postsOK ||
+			feed.Pass != (w.fetchOK && w.summaryOK && w.postsOK) {
+			t.Errorf("feeds[%d] = %+v, want %s fetch=%v summary=%v posting=%v", i, feed, w.feedURL, w.fetchOK, w.summaryOK, w.postsOK)
+		}
+	}
+	if healthy := got.Feeds[1]; healthy.MedianSummarySeconds == nil || *healthy.MedianSummarySeconds != 45 {
+		t.Errorf("median summary seconds = %v, want 45", healthy.MedianSummarySeconds)
+	}
+	if fresh := got.Feeds[2]; fresh.MedianSummarySeconds != nil || fresh.PostedOnTimeRate != nil {
+		t.Errorf("a feed without summaries or posts should report null, got %+v", fresh)
+	}
+}
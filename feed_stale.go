@@ -0,0 +1,73 @@
+package main
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// StaleFeed is a feed whose most recent article is older than the staleness
+// threshold, a candidate for removal from the feed list.
+type StaleFeed struct {
+	FeedURL       string    `json:"feed_url"`
+	LatestArticle time.Time `json:"latest_article"`
+	ArticleCount  int       `json:"article_count"`
+	DaysSilent    int       `json:"days_silent"`
+}
+
+// findStaleFeeds keeps the feeds whose latest article is before cutoff,
+// stalest first (ties broken by URL so the report is stable). DaysSilent is
+// counted from now.
+func findStaleFeeds(feeds []StaleFeed, cutoff, now time.Time) []StaleFeed {
+	stale := []StaleFeed{}
+	for _, feed := range feeds {
+		if !feed.LatestArticle.Before(cutoff) {
+			continue
+		}
+		feed.DaysSilent = int(now.Sub(feed.LatestArticle).Hours() / 24)
+		stale = append(stale, feed)
+	}
+
+	sort.Slice(stale, func(i, j int) bool {
+		if !stale[i].LatestArticle.Equal(stale[j].LatestArticle) {
+			return stale[i].LatestArticle.Before(stale[j].LatestArticle)
+		}
+		return stale[i].FeedURL < stale[j].FeedURL
+	})
+	return stale
+}
+
+// getStaleFeeds lists feeds that haven't published anything in the last
+// `days` days (default 30, max 3650; anything else is a 400), to help curate
+// the feed list. Feeds that never produced an article don't appear, as they
+// have no row in articles to judge by.
+func (s *APIServer) getStaleFeeds(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	days, err := parseDays(r, 30, 3650)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	cutoff := time.Now().UTC().AddDate(0, 0, -days)
+
+	feeds, err := NewDatabaseOperations(s.db).GetStaleFeeds(cutoff)
+	if err != nil {
+		slog.Error("Database query error", "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"cutoff": cutoff,
+		"days":   days,
+		"feeds":  feeds,
+		"count":  len(feeds),
+	})
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestFindStaleFeeds(t *testing.T) {
+	now := time.Date(2024, 6, 30, 12, 0, 0, 0, time.UTC)
+	cutoff := now.AddDate(0, 0, -30)
+
+	feeds := []StaleFeed{
+		{FeedURL: "https://active.example/feed", LatestArticle: now.Add(-2 * time.Hour), ArticleCount: 120},
+		{FeedURL: "https://quiet.example/feed", LatestArticle: now.AddDate(0, 0, -45), ArticleCount: 8},
+		{FeedURL: "https://dead.example/feed", LatestArticle: now.AddDate(-1, 0, 0), ArticleCount: 40},
+		{FeedURL: "https://borderline.example/feed", LatestArticle: cutoff, ArticleCount: 3},
+		{FeedURL: "https://also-quiet.example/feed", LatestArticle: now.AddDate(0, 0, -45), ArticleCount: 2},
+	}
+
+	stale := findStaleFeeds(feeds, cutoff, now)
+
+	want := []struct {
+		url  string
+		days int
+	}{
+		{"https://dead.example/feed", 366},
+		{"https://also-quiet.example/feed", 45},
+		{"https://quiet.example/feed", 45},
+	}
+	if len(stale) != len(want) {
+		t.Fatalf("got %d stale feeds %+v, want %d", len(stale), stale, len(want))
+	}
+	for i, w := range want {
+		if stale[i].FeedURL != w.url || stale[i].DaysSilent != w.days {
+			t.Errorf("stale[%d] = %s (%d days), want %s (%d days)", i, stale[i].FeedURL, stale[i].DaysSilent, w.url, w.days)
+		}
+	}
+
+	if got := findStaleFeeds(nil, cutoff, now); got == nil || len(got) != 0 {
+		t.Errorf("no feeds gave %v, want an empty (non-nil) list", got)
+	}
+}
+
+func TestGetStaleFeedsRejectsMalformedDays(t *testing.T) {
+	s := &APIServer{}
+	for _, target := range []string{"/feeds/stale?days=abc", "/feeds/stale?days=0", "/feeds/stale?days=-7", "/feeds/stale?days=3651", "/feeds/stale?days=1.5"} {
+		rec := httptest.NewRecorder()
+		s.getStaleFeeds(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("GET %s: status = %d, want 400", target, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"net/http"
+	"sync"
+)
+
+// feedValidator holds the cache validators a feed server sent with its last
+// successfully parsed response.
+type feedValidator struct {
+	etag         string
+	lastModified string
+}
+
+// feedValidatorCache remembers ETag/Last-Modified per feed URL so repeat
+// fetches can be made conditional. It lives in memory only: after a restart
+// the first fetch of each feed is unconditional, which is harmless.
+type feedValidatorCache struct {
+	mu         sync.RWMutex
+	validators map[string]feedValidator
+}
+
+func newFeedValidatorCache() *feedValidatorCache {
+	return &feedValidatorCache{validators: make(map[string]feedValidator)}
+}
+
+// apply adds If-None-Match/If-Modified-Since headers to req for any
+// validators stored for feedURL.
+func (c *feedValidatorCache) apply(req *http.Request, feedURL string) {
+	c.mu.RLock()
+	v, ok := c.validators[feedURL]
+	c.mu.RUnlock()
+	if !ok {
+		return
+	}
+
+	if v.etag != "" {
+		req.Header.Set("If-None-Match", v.etag)
+	}
+	if v.lastModified != "" {
+		req.Header.Set("If-Modified-Since", v.lastModified)
+	}
+}
+
+// store records the validators from resp for feedURL. A response carrying
+// neither header clears what was stored, since the server has stopped
+// supporting conditional requests for this feed.
+func (c *feedValidatorCache) store(feedURL string, resp *http.Response) {
+	v := feedValidator{
+		etag:         resp.Header.Get("ETag"),
+		lastModified: resp.Header.Get("Last-Modified"),
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if v.etag == "" && v.lastModified == "" {
+		delete(c.validators, feedURL)
+		return
+	}
+	c.validators[feedURL] = v
+}
+
+// forget drops the validators stored for feedURL, so its next fetch is
+// unconditional.
+func (c *feedValidatorCache) forget(feedURL string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.validators, feedURL)
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestFeedValidatorCache(t *testing.T) {
+	const feedURL = "https://example.com/feed.xml"
+	c := newFeedValidatorCache()
+
+	newRequest := func() *http.Request {
+		req, err := http.NewRequest("GET", feedURL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return req
+	}
+	response := func(etag, lastModified string) *http.Response {
+		resp := &http.Response{Header: http.Header{}}
+		if etag != "" {
+			resp.Header.Set("ETag", etag)
+		}
+		if lastModified != "" {
+			resp.Header.Set("Last-Modified", lastModified)
+		}
+		return resp
+	}
+
+	req := newRequest()
+	c.apply(req, feedURL)
+	if req.Header.Get("If-None-Match") != "" || req.Header.Get("If-Modified-Since") != "" {
+		t.Fatalf("unknown feed should get an unconditional request, got %v", req.Header)
+	}
+
+	c.store(feedURL, response(`"abc123"`, "Wed, 14 Oct 2026 08:00:00 GMT"))
+	req = newRequest()
+	c.apply(req, feedURL)
+	if got := req.Header.Get("If-None-Match"); got != `"abc123"` {
+		t.Errorf("If-None-Match = %q", got)
+	}
+	if got := req.Header.Get("If-Modified-Since"); got != "Wed, 14 Oct 2026 08:00:00 GMT" {
+		t.Errorf("If-Modified-Since = %q", got)
+	}
+
+	// Validators are per feed
+	other, _ := http.NewRequest("GET", "https://example.org/rss", nil)
+	c.apply(other, "https://example.org/rss")
+	if other.Header.Get("If-None-Match") != "" {
+		t.Errorf("validators leaked to another feed")
+	}
+
+	// Only one validator sent: only that header goes out
+	c.store(feedURL, response("", "Thu, 15 Oct 2026 08:00:00 GMT"))
+	req = newRequest()
+	c.apply(req, feedURL)
+	if req.Header.Get("If-None-Match") != "" || req.Header.Get("If-Modified-Since") != "Thu, 15 Oct 2026 08:00:00 GMT" {
+		t.Errorf("unexpected headers after Last-Modified-only response: %v", req.Header)
+	}
+
+	// Server stopped sending validators: go back to unconditional fetches
+	c.store(feedURL, response("", ""))
+	req = newRequest()
+	c.apply(req, feedURL)
+	if req.Header.Get("If-Modified-Since") != "" {
+		t.Errorf("stale validator still applied: %v", req.Header)
+	}
+}
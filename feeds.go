@@ -0,0 +1,254 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"log/slog"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Feed is one entry from the feeds file. Lines are a bare URL optionally
+// followed by pipe-separated directives: key=value pairs, or a bare duration
+// as shorthand for interval=, e.g.
+//
+//	https://www.krebsonsecurity.com/feed/|priority=10
+//	https://isc.sans.edu/rssfeed.xml|2m
+//	https://curated.example/feed|use_feed_summary=true
+type Feed struct {
+	URL string
+	// Priority orders feeds within a fetch cycle: higher values are dispatched
+	// first, so they get a concurrency slot ahead of lower ones. Default 0.
+	Priority int
+	// Interval overrides the global RSS fetch interval for this feed. Zero
+	// means use the global default.
+	Interval time.Duration
+	// UseFeedSummary takes the feed's own item description as the summary
+	// instead of running the model, for feeds that already carry a good one.
+	UseFeedSummary bool
+	// Title is the feed's display name, when the feeds file gives one (OPML
+	// outlines do).
+	Title string
+	// ContentType selects the summarization prompt written for this kind of
+	// article: news, advisory, release or blog. Empty uses the generic one.
+	ContentType string
+	// SummaryWords overrides Content.MaxSummaryLength for this feed's
+	// summaries, in words even when SUMMARY_LIMIT_UNIT is characters. Zero
+	// means use the global length.
+	SummaryWords int
+	// Rewrite is a sed-style s/regex/replacement/ rule applied to URL to
+	// get the URL actually requested, for servers with quirks like
+	// insisting on a trailing slash. URL itself, as stored and shown, is
+	// left alone.
+	Rewrite string
+	// Category groups feeds for Discord branding (see
+	// DiscordConfig.Branding). OPML feeds take the folder they are in.
+	Category string
+}
+
+// loadFeeds reads the feeds file: an OPML export from another reader (by
+// .opml extension or an <opml> root element), or otherwise one feed line
+// per line. A feed listed more than once is loaded once, with its first
+// entry.
+func loadFeeds(filename string) ([]Feed, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var feeds []Feed
+	if isOPML(filename, data) {
+		feeds, err = parseOPML(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", filename, err)
+		}
+	} else {
+		feeds, err = parseFeedLines(filename, data)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return dedupeFeeds(feeds), nil
+}
+
+// parseFeedLines parses a text feeds file, skipping blank lines and #
+// comments.
+func parseFeedLines(filename string, data []byte) ([]Feed, error) {
+	var feeds []Feed
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		feed, err := parseFeedLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s line %d: %w", filename, lineNum, err)
+		}
+		feeds = append(feeds, feed)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return feeds, nil
+}
+
+// dedupeFeeds drops feeds whose URL came up earlier in the list.
+func dedupeFeeds(feeds []Feed) []Feed {
+	seen := make(map[string]bool, len(feeds))
+	unique := feeds[:0]
+	for _, feed := range feeds {
+		if seen[feed.URL] {
+			slog.Warn("Skipping duplicate feed", "feed_url", feed.URL)
+			continue
+		}
+		seen[feed.URL] = true
+		unique = append(unique, feed)
+	}
+	return unique
+}
+
+// parseFeedLine parses a single non-comment feeds file line.
+func parseFeedLine(line string) (Feed, error) {
+	parts := strings.Split(line, "|")
+	feed := Feed{URL: strings.TrimSpace(parts[0])}
+	if feed.URL == "" {
+		return Feed{}, fmt.Errorf("missing feed URL")
+	}
+
+	for _, directive := range parts[1:] {
+		directive = strings.TrimSpace(directive)
+		if directive == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(directive, "=")
+		if !ok {
+			// A bare token is an interval shorthand: url|2m
+			if _, err := time.ParseDuration(directive); err != nil {
+				return Feed{}, fmt.Errorf("invalid directive %q for %s: expected key=value or an interval", directive, feed.URL)
+			}
+			key, value = "interval", directive
+		}
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+
+		switch key {
+		case "priority":
+			priority, err := strconv.Atoi(value)
+			if err != nil {
+				return Feed{}, fmt.Errorf("invalid priority %q for %s: %w", value, feed.URL, err)
+			}
+			feed.Priority = priority
+		case "interval":
+			interval, err := time.ParseDuration(value)
+			if err != nil || interval <= 0 {
+				return Feed{}, fmt.Errorf("invalid interval %q for %s: must be a positive duration", value, feed.URL)
+			}
+			feed.Interval = interval
+		case "use_feed_summary":
+			use, err := strconv.ParseBool(value)
+			if err != nil {
+				return Feed{}, fmt.Errorf("invalid use_feed_summary %q for %s: must be true or false", value, feed.URL)
+			}
+			feed.UseFeedSummary = use
+		case "content_type":
+			if !isContentType(value) {
+				return Feed{}, fmt.Errorf("invalid content_type %q for %s: use news, advisory, release or blog", value, feed.URL)
+			}
+			feed.ContentType = value
+		case "summary_words":
+			words, err := strconv.Atoi(value)
+			if err != nil || words <= 0 {
+				return Feed{}, fmt.Errorf("invalid summary_words %q for %s: must be a positive number", value, feed.URL)
+			}
+			feed.SummaryWords = words
+		case "rewrite":
+			if _, _, err := parseRewriteRule(value); err != nil {
+				return Feed{}, fmt.Errorf("invalid rewrite %q for %s: %w", value, feed.URL, err)
+			}
+			feed.Rewrite = value
+		case "category":
+			if value == "" {
+				return Feed{}, fmt.Errorf("empty category for %s", feed.URL)
+			}
+			feed.Category = value
+		default:
+			return Feed{}, fmt.Errorf("unknown directive %q for %s", key, feed.URL)
+		}
+	}
+
+	return feed, nil
+}
+
+// parseRewriteRule splits a sed-style rewrite rule, s/regex/replacement/,
+// where any character can stand in for the slash so rules about slashes
+// read better: s#([^/])$#$1/#. The replacement uses regexp's $1 syntax.
+func parseRewriteRule(rule string) (*regexp.Regexp, string, error) {
+	if len(rule) < 4 || rule[0] != 's' {
+		return nil, "", fmt.Errorf("expected s/regex/replacement/")
+	}
+	delimiter := rule[1:2]
+	parts := strings.Split(rule[2:], delimiter)
+	if len(parts) != 3 || parts[2] != "" {
+		return nil, "", fmt.Errorf("expected s%[1]sregex%[1]sreplacement%[1]s", delimiter)
+	}
+	pattern, err := regexp.Compile(parts[0])
+	if err != nil {
+		return nil, "", err
+	}
+	return pattern, parts[1], nil
+}
+
+// requestURL returns the URL to fetch the feed from: URL with the Rewrite
+// rule applied, if it has one.
+func (f Feed) requestURL() string {
+	if f.Rewrite == "" {
+		return f.URL
+	}
+	pattern, replacement, err := parseRewriteRule(f.Rewrite)
+	if err != nil {
+		return f.URL // Checked when the feeds file was loaded
+	}
+	return pattern.ReplaceAllString(f.URL, replacement)
+}
+
+// applyDirectives sets the fields of request that follow the feed's
+// directives: the prompt template, summary length and category.
+func (f Feed) applyDirectives(request *SummarizationRequest) {
+	request.ContentType = f.ContentType
+	request.SummaryWords = f.SummaryWords
+	request.Category = f.Category
+}
+
+// feedsByPriority returns a copy of feeds ordered highest priority first.
+// Feeds with equal priority keep their order from the feeds file.
+func feedsByPriority(feeds []Feed) []Feed {
+	ordered := make([]Feed, len(feeds))
+	copy(ordered, feeds)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].Priority > ordered[j].Priority
+	})
+	return ordered
+}
+
+// feedsByInterval groups feeds by their effective fetch interval, with
+// defaultInterval standing in for feeds that don't override it.
+func feedsByInterval(feeds []Feed, defaultInterval time.Duration) map[time.Duration][]Feed {
+	groups := make(map[time.Duration][]Feed)
+	for _, feed := range feeds {
+		interval := feed.Interval
+		if interval <= 0 {
+			interval = defaultInterval
+		}
+		groups[interval] = append(groups[interval], feed)
+	}
+	return groups
+}
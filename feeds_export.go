@@ -0,0 +1,168 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// FeedExport is one monitored feed as exported by /feeds/export.
+type FeedExport struct {
+	URL            string     `json:"url"`
+	Title          string     `json:"title,omitempty"`
+	Priority       int        `json:"priority,omitempty"`
+	Interval       string     `json:"interval,omitempty"`
+	UseFeedSummary bool       `json:"use_feed_summary,omitempty"`
+	ContentType    string     `json:"content_type,omitempty"`
+	SummaryWords   int        `json:"summary_words,omitempty"`
+	Category       string     `json:"category,omitempty"`
+	ArticleCount   int        `json:"article_count"`
+	LatestArticle  *time.Time `json:"latest_article,omitempty"`
+}
+
+// opmlFeedOutline is an exported feed's OPML outline. articleCount is not
+// part of OPML; readers importing the file ignore it.
+type opmlFeedOutline struct {
+	XMLName      xml.Name `xml:"outline"`
+	Type         string   `xml:"type,attr"`
+	Text         string   `xml:"text,attr"`
+	Title        string   `xml:"title,attr,omitempty"`
+	XMLURL       string   `xml:"xmlUrl,attr"`
+	ArticleCount int      `xml:"articleCount,attr"`
+}
+
+// exportFeeds returns the monitored feeds with their article counts, as
+// OPML for other readers (the default) or as JSON with ?format=json or an
+// Accept header asking for application/json.
+func (s *APIServer) exportFeeds(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "opml"
+		if strings.Contains(r.Header.Get("Accept"), "application/json") {
+			format = "json"
+		}
+	}
+	if format != "opml" && format != "json" {
+		http.Error(w, "format must be opml or json", http.StatusBadRequest)
+		return
+	}
+
+	stats, err := s.loadFeedStats()
+	if err != nil {
+		slog.Error("Database query error", "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	feeds := s.exportedFeeds(stats)
+
+	if format == "json" {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"feeds": feeds,
+			"count": len(feeds),
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/x-opml; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="feeds.opml"`)
+	if err := writeFeedsOPML(w, feeds, time.Now()); err != nil {
+		slog.Error("Failed to write OPML feed export", "error", err)
+	}
+}
+
+// exportedFeeds joins the monitored feeds with their stats. Without a
+// monitor, the feeds that have stored articles are exported instead.
+func (s *APIServer) exportedFeeds(stats []FeedStats) []FeedExport {
+	statsByURL := make(map[string]FeedStats, len(stats))
+	for _, stat := range stats {
+		statsByURL[stat.FeedURL] = stat
+	}
+
+	var monitored []Feed
+	if s.monitor != nil {
+		monitored = s.monitor.Feeds()
+	} else {
+		for _, stat := range stats {
+			monitored = append(monitored, Feed{URL: stat.FeedURL})
+		}
+	}
+
+	feeds := make([]FeedExport, 0, len(monitored))
+	for _, feed := range monitored {
+		export := FeedExport{
+			URL:            feed.URL,
+			Title:          feed.Title,
+			Priority:       feed.Priority,
+			UseFeedSummary: feed.UseFeedSummary,
+			ContentType:    feed.ContentType,
+			SummaryWords:   feed.SummaryWords,
+			Category:       feed.Category,
+			ArticleCount:   statsByURL[feed.URL].ArticleCount,
+			LatestArticle:  statsByURL[feed.URL].LatestArticle,
+		}
+		if feed.Interval > 0 {
+			export.Interval = feed.Interval.String()
+		}
+		feeds = append(feeds, export)
+	}
+	return feeds
+}
+
+// writeFeedsOPML writes feeds as an OPML 2.0 subscription list, one outline
+// at a time so long lists aren't built up in memory.
+func writeFeedsOPML(w io.Writer, feeds []FeedExport, created time.Time) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+
+	opml := xml.StartElement{Name: xml.Name{Local: "opml"}, Attr: []xml.Attr{{Name: xml.Name{Local: "version"}, Value: "2.0"}}}
+	body := xml.StartElement{Name: xml.Name{Local: "body"}}
+	head := struct {
+		XMLName     xml.Name `xml:"head"`
+		Title       string   `xml:"title"`
+		DateCreated string   `xml:"dateCreated"`
+	}{Title: "Information Broker feeds", DateCreated: created.UTC().Format(time.RFC1123Z)}
+
+	if err := encoder.EncodeToken(opml); err != nil {
+		return err
+	}
+	if err := encoder.Encode(head); err != nil {
+		return err
+	}
+	if err := encoder.EncodeToken(body); err != nil {
+		return err
+	}
+	for _, feed := range feeds {
+		text := feed.Title
+		if text == "" {
+			text = feed.URL
+		}
+		outline := opmlFeedOutline{Type: "rss", Text: text, Title: feed.Title, XMLURL: feed.URL, ArticleCount: feed.ArticleCount}
+		if err := encoder.Encode(outline); err != nil {
+			return err
+		}
+	}
+	if err := encoder.EncodeToken(body.End()); err != nil {
+		return err
+	}
+	if err := encoder.EncodeToken(opml.End()); err != nil {
+		return err
+	}
+	if err := encoder.Flush(); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExportFeeds(t *testing.T) {
+	latest := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	newExportServer := func(t *testing.T) *APIServer {
+		db, recorder := openExecRecorder(t)
+		recorder.answer("GROUP BY feed_url",
+			[]driver.Value{"https://a.example/feed", int64(12), latest, latest.Add(-48 * time.Hour), 150.0, "success", nil, latest},
+			[]driver.Value{"https://gone.example/feed", int64(3), latest, latest, nil, nil, nil, nil},
+		)
+		monitor := &RSSMonitor{feeds: []Feed{
+			{URL: "https://a.example/feed", Title: "A & Co", Priority: 2},
+			{URL: "https://b.example/feed", Interval: 10 * time.Minute},
+		}}
+		return &APIServer{db: db, monitor: monitor}
+	}
+
+	t.Run("opml", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		newExportServer(t).exportFeeds(rec, httptest.NewRequest(http.MethodGet, "/feeds/export", nil))
+		if rec.Code != http.StatusOK || !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/x-opml") {
+			t.Fatalf("GET /feeds/export = %d %q", rec.Code, rec.Header().Get("Content-Type"))
+		}
+		body := rec.Body.Bytes()
+		feeds, err := parseOPML(body)
+		if err != nil {
+			t.Fatalf("exported OPML doesn't import: %v\n%s", err, body)
+		}
+		want := []Feed{{URL: "https://a.example/feed", Title: "A & Co"}, {URL: "https://b.example/feed", Title: "https://b.example/feed"}}
+		if len(feeds) != len(want) || feeds[0] != want[0] || feeds[1] != want[1] {
+			t.Errorf("imported %+v, want %+v", feeds, want)
+		}
+		if !strings.Contains(string(body), `articleCount="12"`) || strings.Contains(string(body), "gone.example") {
+			t.Errorf("export should count the monitored feeds' articles and leave out the others:\n%s", body)
+		}
+	})
+
+	for _, tt := range []struct{ name, target, accept string }{
+		{"json by query", "/feeds/export?format=json", ""},
+		{"json by accept", "/feeds/export", "application/json"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			req.Header.Set("Accept", tt.accept)
+			rec := httptest.NewRecorder()
+			newExportServer(t).exportFeeds(rec, req)
+
+			var got struct {
+				Feeds []FeedExport `json:"feeds"`
+				Count int          `json:"count"`
+			}
+			if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if got.Count != 2 || got.Feeds[0].ArticleCount != 12 || got.Feeds[0].Priority != 2 || !got.Feeds[0].LatestArticle.Equal(latest) ||
+				got.Feeds[1].ArticleCount != 0 || got.Feeds[1].Interval != "10m0s" {
+				t.Errorf("exported %+v", got)
+			}
+		})
+	}
+
+	t.Run("unknown format", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		newExportServer(t).exportFeeds(rec, httptest.NewRequest(http.MethodGet, "/feeds/export?format=csv", nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("GET ?format=csv = %d, want 400", rec.Code)
+		}
+	})
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// opmlDocument is the part of an OPML subscription list that matters here.
+// Readers export feeds as outlines with an xmlUrl, grouped into folders
+// that are outlines themselves.
+type opmlDocument struct {
+	XMLName  xml.Name      `xml:"opml"`
+	Outlines []opmlOutline `xml:"body>outline"`
+}
+
+type opmlOutline struct {
+	Text     string        `xml:"text,attr"`
+	Title    string        `xml:"title,attr"`
+	XMLURL   string        `xml:"xmlUrl,attr"`
+	Outlines []opmlOutline `xml:"outline"`
+}
+
+// isOPML reports whether a feeds file is OPML: named *.opml, or starting
+// (after any XML declaration and comments) with an <opml> element.
+func isOPML(filename string, data []byte) bool {
+	if strings.EqualFold(filepath.Ext(filename), ".opml") {
+		return true
+	}
+	decoder := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return false
+		}
+		switch token := token.(type) {
+		case xml.StartElement:
+			return strings.EqualFold(token.Name.Local, "opml")
+		case xml.CharData:
+			if len(bytes.TrimSpace(token)) > 0 {
+				return false // Plain text, such as a feed URL line
+			}
+		}
+	}
+}
+
+// parseOPML returns a feed for every outline with an xmlUrl, through any
+// depth of folders, titled by the outline's title or else its text. The
+// folder a feed sits in, named the same way, becomes its category.
+func parseOPML(data []byte) ([]Feed, error) {
+	var doc opmlDocument
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("invalid OPML: %w", err)
+	}
+
+	var feeds []Feed
+	var walk func(outlines []opmlOutline, folder string)
+	walk = func(outlines []opmlOutline, folder string) {
+		for _, outline := range outlines {
+			title := strings.TrimSpace(outline.Title)
+			if title == "" {
+				title = strings.TrimSpace(outline.Text)
+			}
+			if url := strings.TrimSpace(outline.XMLURL); url != "" {
+				feeds = append(feeds, Feed{URL: url, Title: title, Category: folder})
+			}
+			walk(outline.Outlines, title)
+		}
+	}
+	walk(doc.Outlines, "")
+
+	if len(feeds) == 0 {
+		return nil, fmt.Errorf("OPML lists no feeds (no outline has an xmlUrl)")
+	}
+	return feeds, nil
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"log/slog"
+	"os"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// startPollers starts a pollFeeds schedule, tracked by wg, for every feed
+// interval that doesn't have one running yet.
+func (m *RSSMonitor) startPollers(ctx context.Context, wg *sync.WaitGroup) {
+	m.feedsMu.Lock()
+	defer m.feedsMu.Unlock()
+
+	for interval, feeds := range feedsByInterval(m.feeds, m.fetchInterval) {
+		if m.pollers[interval] {
+			continue
+		}
+		m.pollers[interval] = true
+		if interval != m.fetchInterval {
+			slog.Info("Polling feeds", "feeds", len(feeds), "interval", interval.String())
+		}
+
+		wg.Add(1)
+		go func(interval time.Duration) {
+			defer wg.Done()
+			m.pollFeeds(ctx, interval)
+		}(interval)
+	}
+}
+
+// polledFeeds returns the feeds polled every interval. Once a reload has
+// left none on it, it retires the interval's schedule and reports false;
+// startPollers starts a fresh one if feeds come back to it.
+func (m *RSSMonitor) polledFeeds(interval time.Duration) ([]Feed, bool) {
+	m.feedsMu.Lock()
+	defer m.feedsMu.Unlock()
+
+	feeds := feedsByInterval(m.feeds, m.fetchInterval)[interval]
+	if len(feeds) == 0 {
+		delete(m.pollers, interval)
+		return nil, false
+	}
+	return feeds, true
+}
+
+// reloadFeeds replaces the monitored feeds and returns the ones that
+// weren't monitored before and the ones no longer monitored. Per-feed state
+// (seen articles, circuit breakers, validators) is kept, so a feed removed
+// and added back picks up where it left off.
+func (m *RSSMonitor) reloadFeeds(feeds []Feed) (added, removed []Feed) {
+	m.feedsMu.Lock()
+	previous := m.feeds
+	m.feeds = feeds
+	m.feedsMu.Unlock()
+
+	listed := make(map[string]bool, len(feeds))
+	for _, feed := range feeds {
+		listed[feed.URL] = true
+	}
+	wasListed := make(map[string]bool, len(previous))
+	for _, feed := range previous {
+		wasListed[feed.URL] = true
+		if !listed[feed.URL] {
+			removed = append(removed, feed)
+		}
+	}
+	for _, feed := range feeds {
+		if !wasListed[feed.URL] {
+			added = append(added, feed)
+		}
+	}
+	return added, removed
+}
+
+// watchFeedsFile checks App.RSSFeedsFile every App.RSSFeedsReload until ctx
+// is cancelled and applies any change to its feeds: new feeds are fetched at
+// once and join the polling schedules (tracked by wg), removed feeds stop
+// being polled. A file that can't be loaded or lists no feeds leaves the
+// current feeds in place.
+func (m *RSSMonitor) watchFeedsFile(ctx context.Context, wg *sync.WaitGroup) {
+	path := m.config.App.RSSFeedsFile
+	// The first check always loads the file, catching edits made since
+	// startup read it
+	var last [sha256.Size]byte
+
+	ticker := time.NewTicker(m.config.App.RSSFeedsReload)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		digest, err := fileDigest(path)
+		if err != nil {
+			slog.Warn("Failed to read feeds file", "file", path, "error", err)
+			continue
+		}
+		if digest == last {
+			continue
+		}
+		last = digest // A broken edit is reported once, not on every check
+
+		feeds, err := loadFeeds(path)
+		if err != nil {
+			slog.Error("Feeds file changed but can't be loaded; keeping the current feeds", "file", path, "error", err)
+			continue
+		}
+		if len(feeds) == 0 {
+			slog.Error("Feeds file changed but lists no feeds; keeping the current feeds", "file", path)
+			continue
+		}
+		if reflect.DeepEqual(feeds, m.Feeds()) {
+			continue // Only comments or blank lines changed
+		}
+
+		added, removed := m.reloadFeeds(feeds)
+		for _, feed := range added {
+			slog.Info("Feed added", "feed_url", feed.URL)
+		}
+		for _, feed := range removed {
+			slog.Info("Feed removed", "feed_url", feed.URL)
+		}
+		slog.Info("Reloaded feeds file", "file", path, "feeds", len(feeds), "added", len(added), "removed", len(removed))
+
+		m.startPollers(ctx, wg)
+		dispatchFeeds(ctx, m.activeFeeds(added), m.fetchSlots, func(ctx context.Context, feedURL string) {
+			m.fetchFeed(ctx, feedURL)
+		})
+	}
+}
+
+// fileDigest returns the SHA-256 of a file's contents.
+func fileDigest(path string) ([sha256.Size]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return [sha256.Size]byte{}, err
+	}
+	return sha256.Sum256(data), nil
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"information-broker/config"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWatchFeedsFile(t *testing.T) {
+	var mu sync.Mutex
+	fetched := make(map[string]int)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		fetched[r.URL.Path]++
+		mu.Unlock()
+		io.WriteString(w, `<?xml version="1.0"?><rss version="2.0"><channel><title>Test</title></channel></rss>`)
+	}))
+	defer srv.Close()
+	fetches := func(path string) int {
+		mu.Lock()
+		defer mu.Unlock()
+		return fetched[path]
+	}
+
+	path := filepath.Join(t.TempDir(), "feeds.txt")
+	write := func(content string) {
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(srv.URL + "/a\n" + srv.URL + "/b|2h\n")
+	feeds, err := loadFeeds(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, _ := openExecRecorder(t)
+	cfg := &config.Config{
+		App:         config.AppConfig{RSSFetchInterval: time.Hour, RSSFeedsFile: path, RSSFeedsReload: 10 * time.Millisecond},
+		API:         config.APIConfig{Timeout: 5 * time.Second},
+		Performance: config.PerformanceConfig{MaxConcurrentFeeds: 1},
+	}
+	m := NewRSSMonitor(db, feeds, testMetrics(), cfg, NewCircuitBreakerManager(), nil, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	m.startPollers(ctx, &wg)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		m.watchFeedsFile(ctx, &wg)
+	}()
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	waitFor := func(what string, done func() bool) {
+		t.Helper()
+		for deadline := time.Now().Add(5 * time.Second); !done(); time.Sleep(5 * time.Millisecond) {
+			if time.Now().After(deadline) {
+				t.Fatalf("timed out waiting for %s", what)
+			}
+		}
+	}
+
+	write(srv.URL + "/a\n" + srv.URL + "/c\n")
+	waitFor("the added feed to be fetched", func() bool { return fetches("/c") == 1 })
+	if got := m.Feeds(); len(got) != 2 || got[0].URL != srv.URL+"/a" || got[1].URL != srv.URL+"/c" {
+		t.Errorf("feeds after reload = %+v, want /a and /c", got)
+	}
+	if fetches("/a") != 0 {
+		t.Error("a feed listed before the reload was fetched by it")
+	}
+	if _, ok := m.polledFeeds(2 * time.Hour); ok {
+		t.Error("the removed feed's interval is still polled")
+	}
+
+	// A broken edit keeps the feeds in place
+	write(srv.URL + "/d|priority=high\n")
+	time.Sleep(50 * time.Millisecond)
+	if got := m.Feeds(); len(got) != 2 || fetches("/d") != 0 {
+		t.Errorf("feeds after a broken edit = %+v, want /a and /c kept", got)
+	}
+
+	m.feedsMu.Lock()
+	polled := len(m.pollers)
+	m.feedsMu.Unlock()
+	if polled != 1 {
+		t.Errorf("%d intervals polled, want only the default", polled)
+	}
+}
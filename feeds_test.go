@@ -0,0 +1,261 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestParseFeedLine(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		want    Feed
+		wantErr bool
+	}{
+		{"bare url", "https://example.com/feed", Feed{URL: "https://example.com/feed"}, false},
+		{"priority directive", "https://example.com/feed|priority=10", Feed{URL: "https://example.com/feed", Priority: 10}, false},
+		{"spaces around directives", "https://example.com/feed | priority = -2 ", Feed{URL: "https://example.com/feed", Priority: -2}, false},
+		{"empty directive ignored", "https://example.com/feed||priority=1", Feed{URL: "https://example.com/feed", Priority: 1}, false},
+		{"non-numeric priority", "https://example.com/feed|priority=high", Feed{}, true},
+		{"directive without value", "https://example.com/feed|priority", Feed{}, true},
+		{"unknown directive", "https://example.com/feed|color=red", Feed{}, true},
+		{"missing url", "|priority=1", Feed{}, true},
+		{"category", "https://example.com/feed|category=Security|priority=1", Feed{URL: "https://example.com/feed", Category: "Security", Priority: 1}, false},
+		{"empty category", "https://example.com/feed|category=", Feed{}, true},
+		{"interval shorthand", "https://example.com/feed|2m", Feed{URL: "https://example.com/feed", Interval: 2 * time.Minute}, false},
+		{"interval directive", "https://example.com/feed|interval=1h", Feed{URL: "https://example.com/feed", Interval: time.Hour}, false},
+		{"interval with priority", "https://example.com/feed|30s|priority=3", Feed{URL: "https://example.com/feed", Interval: 30 * time.Second, Priority: 3}, false},
+		{"non-positive interval", "https://example.com/feed|0s", Feed{}, true},
+		{"garbage bare token", "https://example.com/feed|soon", Feed{}, true},
+		{"use feed summary", "https://example.com/feed|use_feed_summary=true", Feed{URL: "https://example.com/feed", UseFeedSummary: true}, false},
+		{"use feed summary off", "https://example.com/feed|use_feed_summary=false|priority=2", Feed{URL: "https://example.com/feed", Priority: 2}, false},
+		{"invalid use feed summary", "https://example.com/feed|use_feed_summary=sometimes", Feed{}, true},
+		{"content type", "https://example.com/feed|content_type=advisory", Feed{URL: "https://example.com/feed", ContentType: "advisory"}, false},
+		{"unknown content type", "https://example.com/feed|content_type=podcast", Feed{}, true},
+		{"summary words", "https://example.com/feed|summary_words=250|priority=1", Feed{URL: "https://example.com/feed", SummaryWords: 250, Priority: 1}, false},
+		{"non-positive summary words", "https://example.com/feed|summary_words=0", Feed{}, true},
+		{"garbage summary words", "https://example.com/feed|summary_words=long", Feed{}, true},
+		{"rewrite", "https://example.com/feed|rewrite=s#([^/])$#$1/#", Feed{URL: "https://example.com/feed", Rewrite: "s#([^/])$#$1/#"}, false},
+		{"rewrite without replacement part", "https://example.com/feed|rewrite=s#feed$#", Feed{}, true},
+		{"rewrite with a bad regex", "https://example.com/feed|rewrite=s/(feed/x/", Feed{}, true},
+		{"rewrite not in s form", "https://example.com/feed|rewrite=feed/", Feed{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseFeedLine(tt.line)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseFeedLine(%q) error = %v, wantErr %v", tt.line, err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("parseFeedLine(%q) = %+v, want %+v", tt.line, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadFeeds(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "feeds.txt")
+	content := "# security feeds\n\nhttps://a.example/feed\n  https://b.example/feed|5m  \n# https://disabled.example/feed\nhttps://c.example/feed|priority=2\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	feeds, err := loadFeeds(path)
+	if err != nil {
+		t.Fatalf("loadFeeds: %v", err)
+	}
+	want := []Feed{
+		{URL: "https://a.example/feed"},
+		{URL: "https://b.example/feed", Interval: 5 * time.Minute},
+		{URL: "https://c.example/feed", Priority: 2},
+	}
+	if len(feeds) != len(want) {
+		t.Fatalf("loadFeeds = %+v, want %+v", feeds, want)
+	}
+	for i := range want {
+		if feeds[i] != want[i] {
+			t.Errorf("feed %d = %+v, want %+v", i, feeds[i], want[i])
+		}
+	}
+
+	bad := filepath.Join(t.TempDir(), "bad.txt")
+	os.WriteFile(bad, []byte("https://a.example/feed\nhttps://b.example/feed|often\n"), 0o600)
+	if _, err := loadFeeds(bad); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("expected an error pointing at line 2, got %v", err)
+	}
+}
+
+func TestLoadFeedsOPML(t *testing.T) {
+	const opml = `<?xml version="1.0" encoding="UTF-8"?>
+<opml version="2.0">
+  <head><title>Subscriptions</title></head>
+  <body>
+    <outline text="Krebs on Security" type="rss" xmlUrl="https://krebsonsecurity.com/feed/"/>
+    <outline text="Security" title="Security">
+      <outline text="SANS ISC" title="SANS Internet Storm Center" xmlUrl="https://isc.sans.edu/rssfeed.xml"/>
+      <outline text="Vendors">
+        <outline text="Vendor advisories" xmlUrl="https://vendor.example/advisories.xml"/>
+        <outline text="Krebs again" xmlUrl="https://krebsonsecurity.com/feed/"/>
+      </outline>
+    </outline>
+  </body>
+</opml>`
+	want := []Feed{
+		{URL: "https://krebsonsecurity.com/feed/", Title: "Krebs on Security"},
+		{URL: "https://isc.sans.edu/rssfeed.xml", Title: "SANS Internet Storm Center", Category: "Security"},
+		{URL: "https://vendor.example/advisories.xml", Title: "Vendor advisories", Category: "Vendors"},
+	}
+
+	// Detected by extension, and by content under any other name
+	for _, name := range []string{"subscriptions.opml", "feeds.txt"} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			if err := os.WriteFile(path, []byte(opml), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			feeds, err := loadFeeds(path)
+			if err != nil {
+				t.Fatalf("loadFeeds: %v", err)
+			}
+			if !reflect.DeepEqual(feeds, want) {
+				t.Errorf("loadFeeds = %+v, want %+v", feeds, want)
+			}
+		})
+	}
+
+	empty := filepath.Join(t.TempDir(), "empty.opml")
+	os.WriteFile(empty, []byte(`<opml version="2.0"><body><outline text="Folder"/></body></opml>`), 0o600)
+	if _, err := loadFeeds(empty); err == nil {
+		t.Error("an OPML file without feeds should fail to load")
+	}
+}
+
+func TestLoadFeedsDeduplicates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "feeds.txt")
+	os.WriteFile(path, []byte("https://a.example/feed|priority=2\nhttps://b.example/feed\nhttps://a.example/feed\n"), 0o600)
+	feeds, err := loadFeeds(path)
+	if err != nil {
+		t.Fatalf("loadFeeds: %v", err)
+	}
+	want := []Feed{{URL: "https://a.example/feed", Priority: 2}, {URL: "https://b.example/feed"}}
+	if !reflect.DeepEqual(feeds, want) {
+		t.Errorf("loadFeeds = %+v, want %+v", feeds, want)
+	}
+}
+
+func TestFeedsByInterval(t *testing.T) {
+	feeds := []Feed{
+		{URL: "default-a"},
+		{URL: "fast", Interval: 2 * time.Minute},
+		{URL: "default-b", Priority: 5},
+		{URL: "hourly", Interval: time.Hour},
+		{URL: "also-default", Interval: 5 * time.Minute},
+	}
+
+	groups := feedsByInterval(feeds, 5*time.Minute)
+	if len(groups) != 3 {
+		t.Fatalf("expected 3 interval groups, got %d: %+v", len(groups), groups)
+	}
+	if got := groups[5*time.Minute]; len(got) != 3 || got[0].URL != "default-a" || got[1].URL != "default-b" || got[2].URL != "also-default" {
+		t.Errorf("default group = %+v", got)
+	}
+	if got := groups[2*time.Minute]; len(got) != 1 || got[0].URL != "fast" {
+		t.Errorf("2m group = %+v", got)
+	}
+	if got := groups[time.Hour]; len(got) != 1 || got[0].URL != "hourly" {
+		t.Errorf("1h group = %+v", got)
+	}
+}
+
+func TestDispatchFeedsByPriority(t *testing.T) {
+	feeds := []Feed{
+		{URL: "low-a", Priority: 0},
+		{URL: "high", Priority: 10},
+		{URL: "low-b", Priority: 0},
+		{URL: "mid", Priority: 5},
+		{URL: "negative", Priority: -1},
+	}
+
+	t.Run("single slot fetches strictly by priority, file order within a tier", func(t *testing.T) {
+		var mu sync.Mutex
+		var started []string
+		dispatchFeeds(context.Background(), feeds, make(chan struct{}, 1), func(ctx context.Context, url string) {
+			mu.Lock()
+			started = append(started, url)
+			mu.Unlock()
+			time.Sleep(time.Millisecond) // hold the slot so later feeds must queue
+		})
+
+		want := []string{"high", "mid", "low-a", "low-b", "negative"}
+		if len(started) != len(want) {
+			t.Fatalf("started %v, want %v", started, want)
+		}
+		for i := range want {
+			if started[i] != want[i] {
+				t.Fatalf("started %v, want %v", started, want)
+			}
+		}
+	})
+
+	t.Run("high-priority feeds take the constrained slots first", func(t *testing.T) {
+		release := make(chan struct{})
+		var mu sync.Mutex
+		var firstWave []string
+		done := make(chan struct{})
+
+		go func() {
+			dispatchFeeds(context.Background(), feeds, make(chan struct{}, 2), func(ctx context.Context, url string) {
+				mu.Lock()
+				firstWave = append(firstWave, url)
+				mu.Unlock()
+				<-release
+			})
+			close(done)
+		}()
+
+		// Both slots are held until release is closed, so only the first two
+		// dispatched feeds can have started.
+		time.Sleep(50 * time.Millisecond)
+		mu.Lock()
+		got := append([]string(nil), firstWave...)
+		mu.Unlock()
+		close(release)
+		<-done
+
+		if len(got) != 2 {
+			t.Fatalf("expected 2 feeds in flight, got %v", got)
+		}
+		for _, url := range got {
+			if url != "high" && url != "mid" {
+				t.Errorf("low-priority feed %q got a slot ahead of higher-priority feeds (in flight: %v)", url, got)
+			}
+		}
+	})
+
+}
+
+func TestFeedRequestURL(t *testing.T) {
+	tests := []struct {
+		name string
+		feed Feed
+		want string
+	}{
+		{"no rule", Feed{URL: "https://example.com/feed"}, "https://example.com/feed"},
+		{"trailing slash", Feed{URL: "https://example.com/feed", Rewrite: "s#([^/])$#$1/#"}, "https://example.com/feed/"},
+		{"trailing slash already there", Feed{URL: "https://example.com/feed/", Rewrite: "s#([^/])$#$1/#"}, "https://example.com/feed/"},
+		{"path and query", Feed{URL: "http://example.com/rss.php?cat=1", Rewrite: "s,^http://(.*)/rss\\.php,https://$1/feed.xml,"}, "https://example.com/feed.xml?cat=1"},
+		{"no match", Feed{URL: "https://example.com/atom", Rewrite: "s/rss$/rss.xml/"}, "https://example.com/atom"},
+	}
+	for _, tt := range tests {
+		if got := tt.feed.requestURL(); got != tt.want {
+			t.Errorf("%s: requestURL() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"sync"
+	"time"
+)
+
+// fetchIntervalScaler stretches the feed polling intervals while fetching is
+// broadly failing or the database is unhealthy, so a network outage or a
+// struggling database isn't hammered on every tick, and shrinks them back
+// as things recover. The multiplier doubles on each unhealthy check up to
+// maxMultiplier and halves on each healthy one.
+type fetchIntervalScaler struct {
+	mu            sync.Mutex
+	failureRatio  float64 // Fraction of feeds failing that counts as unhealthy
+	maxMultiplier int
+	multiplier    int
+	failingRatio  float64 // Last observed fraction of failing feeds
+	dbHealthy     bool    // Last observed database health
+}
+
+func newFetchIntervalScaler(failureRatio float64, maxMultiplier int) *fetchIntervalScaler {
+	return &fetchIntervalScaler{
+		failureRatio:  failureRatio,
+		maxMultiplier: max(maxMultiplier, 1),
+		multiplier:    1,
+		dbHealthy:     true,
+	}
+}
+
+// observe records one health check and returns the resulting multiplier.
+func (s *fetchIntervalScaler) observe(failingRatio float64, dbHealthy bool) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.failingRatio = failingRatio
+	s.dbHealthy = dbHealthy
+	if !dbHealthy || (s.failureRatio > 0 && failingRatio >= s.failureRatio) {
+		s.multiplier = min(s.multiplier*2, s.maxMultiplier)
+	} else {
+		s.multiplier = max(s.multiplier/2, 1)
+	}
+	return s.multiplier
+}
+
+// scale returns interval stretched by the current multiplier.
+func (s *fetchIntervalScaler) scale(interval time.Duration) time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return interval * time.Duration(s.multiplier)
+}
+
+// FetchIntervalStatus is the polling interval state reported by /health.
+type FetchIntervalStatus struct {
+	BaseInterval      string  `json:"base_interval"`
+	EffectiveInterval string  `json:"effective_interval"`
+	Multiplier        int     `json:"multiplier"`
+	FailingFeedRatio  float64 `json:"failing_feed_ratio"`
+	DatabaseHealthy   bool    `json:"database_healthy"`
+}
+
+// FetchIntervalStatus returns the current base and effective polling
+// interval of the monitor.
+func (m *RSSMonitor) FetchIntervalStatus() FetchIntervalStatus {
+	m.intervalScaler.mu.Lock()
+	defer m.intervalScaler.mu.Unlock()
+	return FetchIntervalStatus{
+		BaseInterval:      m.fetchInterval.String(),
+		EffectiveInterval: (m.fetchInterval * time.Duration(m.intervalScaler.multiplier)).String(),
+		Multiplier:        m.intervalScaler.multiplier,
+		FailingFeedRatio:  m.intervalScaler.failingRatio,
+		DatabaseHealthy:   m.intervalScaler.dbHealthy,
+	}
+}
+
+// adaptFetchInterval re-checks feed and database health every base fetch
+// interval and updates the polling multiplier until ctx is cancelled.
+func (m *RSSMonitor) adaptFetchInterval(ctx context.Context) {
+	ticker := time.NewTicker(m.fetchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			m.checkFetchHealth(ctx)
+		}
+	}
+}
+
+// checkFetchHealth feeds the current fraction of failing feeds and the
+// database health to the interval scaler, logging any change.
+func (m *RSSMonitor) checkFetchHealth(ctx context.Context) {
+	pingCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	dbHealthy := m.db.PingContext(pingCtx) == nil
+
+	failing := m.failingFeedRatio()
+	before := m.intervalScaler.scale(m.fetchInterval)
+	after := m.fetchInterval * time.Duration(m.intervalScaler.observe(failing, dbHealthy))
+	if after != before {
+		slog.Info("Feed polling interval changed", "interval", after, "base_interval", m.fetchInterval,
+			"failing_feeds_percent", math.Round(failing*100), "database_healthy", dbHealthy)
+	}
+}
+
+// failingFeedRatio returns the fraction of feeds whose circuit breaker is
+// open.
+func (m *RSSMonitor) failingFeedRatio() float64 {
+	feeds := m.Feeds()
+	if len(feeds) == 0 {
+		return 0
+	}
+	status := m.circuitBreakers.GetStatus()
+	failing := 0
+	for _, feed := range feeds {
+		if cb, ok := status["rss_feed_"+feed.URL]; ok && cb.State == StateOpen {
+			failing++
+		}
+	}
+	return float64(failing) / float64(len(feeds))
+}
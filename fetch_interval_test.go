@@ -0,0 +1,77 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFetchIntervalScaler(t *testing.T) {
+	s := newFetchIntervalScaler(0.5, 4)
+	base := 10 * time.Minute
+
+	// Widespread failures stretch the interval, doubling up to the cap
+	for i, want := range []int{2, 4, 4} {
+		if got := s.observe(0.6, true); got != want {
+			t.Fatalf("failing check %d: multiplier = %d, want %d", i+1, got, want)
+		}
+	}
+	if got := s.scale(base); got != 40*time.Minute {
+		t.Errorf("scaled interval = %v, want 40m", got)
+	}
+
+	// Recovery shrinks it back step by step
+	if got := s.observe(0.1, true); got != 2 {
+		t.Errorf("first healthy check: multiplier = %d, want 2", got)
+	}
+	if got := s.observe(0, true); got != 1 {
+		t.Errorf("second healthy check: multiplier = %d, want 1", got)
+	}
+	if got := s.scale(base); got != base {
+		t.Errorf("recovered interval = %v, want %v", got, base)
+	}
+
+	// An unhealthy database stretches it regardless of the feeds
+	if got := s.observe(0, false); got != 2 {
+		t.Errorf("database unhealthy: multiplier = %d, want 2", got)
+	}
+}
+
+func TestCheckFetchHealthFollowsFeedBreakers(t *testing.T) {
+	db, _ := openExecRecorder(t)
+	feeds := []Feed{{URL: "https://a.example/feed"}, {URL: "https://b.example/feed"}, {URL: "https://c.example/feed"}}
+	m := &RSSMonitor{
+		db:              db,
+		feeds:           feeds,
+		fetchInterval:   5 * time.Minute,
+		circuitBreakers: NewCircuitBreakerManager(),
+		intervalScaler:  newFetchIntervalScaler(0.5, 8),
+	}
+
+	// Open the breakers of two of the three feeds
+	for _, feed := range feeds[:2] {
+		cb := m.circuitBreakers.GetOrCreateBreaker("rss_feed_"+feed.URL, &CircuitBreakerConfig{
+			FailureThreshold: 1, SuccessThreshold: 1, Timeout: time.Minute, ResetTimeout: time.Minute, HalfOpenMaxConcurrent: 1,
+		})
+		cb.Execute(func() error { return errors.New("feed down") }, nil)
+	}
+
+	m.checkFetchHealth(context.Background())
+	m.checkFetchHealth(context.Background())
+	status := m.FetchIntervalStatus()
+	if status.Multiplier != 4 || status.EffectiveInterval != "20m0s" || status.BaseInterval != "5m0s" {
+		t.Errorf("status = %+v, want the interval stretched 4x to 20m", status)
+	}
+	if !status.DatabaseHealthy || status.FailingFeedRatio < 0.66 || status.FailingFeedRatio > 0.67 {
+		t.Errorf("status = %+v, want 2/3 feeds failing and a healthy database", status)
+	}
+
+	// The feeds recover
+	m.circuitBreakers = NewCircuitBreakerManager()
+	m.checkFetchHealth(context.Background())
+	m.checkFetchHealth(context.Background())
+	if status := m.FetchIntervalStatus(); status.Multiplier != 1 || status.EffectiveInterval != "5m0s" {
+		t.Errorf("status = %+v, want the interval back to normal after recovery", status)
+	}
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// FetchLog is one fetch_logs row: the outcome of one fetch of a feed.
+type FetchLog struct {
+	ID            int64     `json:"id"`
+	FeedURL       string    `json:"feed_url"`
+	Status        string    `json:"status"`
+	Message       *string   `json:"message"`
+	DurationMs    *int64    `json:"duration_ms"`
+	ArticlesFound int       `json:"articles_found"`
+	NewArticles   int       `json:"new_articles"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// fetchLogFilter narrows /fetch-logs; zero fields don't filter.
+type fetchLogFilter struct {
+	feedURL      string
+	status       string
+	since, until time.Time
+}
+
+// buildFetchLogsQuery builds the paginated fetch_logs query for filter,
+// newest first. since is inclusive and until exclusive.
+func buildFetchLogsQuery(filter fetchLogFilter, limit, offset int) (string, []interface{}) {
+	query := `SELECT id, feed_url, status, message, duration_ms, COALESCE(articles_found, 0), COALESCE(new_articles, 0), created_at
+		FROM fetch_logs`
+	var conds []string
+	var args []interface{}
+	i := 1
+	if filter.feedURL != "" {
+		conds = append(conds, fmt.Sprintf("feed_url = $%d", i))
+		args = append(args, filter.feedURL)
+		i++
+	}
+	if filter.status != "" {
+		conds = append(conds, fmt.Sprintf("status = $%d", i))
+		args = append(args, filter.status)
+		i++
+	}
+	if !filter.since.IsZero() {
+		conds = append(conds, fmt.Sprintf("created_at >= $%d", i))
+		args = append(args, filter.since)
+		i++
+	}
+	if !filter.until.IsZero() {
+		conds = append(conds, fmt.Sprintf("created_at < $%d", i))
+		args = append(args, filter.until)
+		i++
+	}
+	if len(conds) > 0 {
+		query += " WHERE " + strings.Join(conds, " AND ")
+	}
+	query += fmt.Sprintf(" ORDER BY created_at DESC, id DESC LIMIT $%d OFFSET $%d", i, i+1)
+	args = append(args, limit, offset)
+	return query, args
+}
+
+// parseTimeParam reads a time range bound given as an RFC 3339 timestamp or
+// a plain date (midnight UTC). An empty value is the zero time.
+func parseTimeParam(name, value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC(), nil
+	}
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid %s %q: must be an RFC 3339 timestamp or a date like 2024-01-31", name, value)
+}
+
+// getFetchLogs returns recent fetch_logs rows, newest first, filtered by
+// feed_url, status (success, error, not_modified) and a since/until time
+// range, with the limit/offset pagination of /articles. It is the place to
+// look when a feed stops producing articles.
+func (s *APIServer) getFetchLogs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	limit, offset, err := parsePagination(r, 50, 100)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	query := r.URL.Query()
+	filter := fetchLogFilter{feedURL: query.Get("feed_url"), status: query.Get("status")}
+	if filter.since, err = parseTimeParam("since", query.Get("since")); err == nil {
+		filter.until, err = parseTimeParam("until", query.Get("until"))
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !filter.since.IsZero() && !filter.until.IsZero() && !filter.since.Before(filter.until) {
+		http.Error(w, "since must be before until", http.StatusBadRequest)
+		return
+	}
+
+	sqlQuery, args := buildFetchLogsQuery(filter, limit, offset)
+	rows, err := s.db.Query(sqlQuery, args...)
+	if err != nil {
+		slog.Error("Database query error", "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	logs := []FetchLog{}
+	for rows.Next() {
+		var entry FetchLog
+		if err := rows.Scan(&entry.ID, &entry.FeedURL, &entry.Status, &entry.Message, &entry.DurationMs,
+			&entry.ArticlesFound, &entry.NewArticles, &entry.CreatedAt); err != nil {
+			slog.Error("Row scan error", "error", err)
+			continue
+		}
+		logs = append(logs, entry)
+	}
+	if err := rows.Err(); err != nil {
+		slog.Error("Database query error", "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"fetch_logs": logs,
+		"count":      len(logs),
+		"limit":      limit,
+		"offset":     offset,
+	})
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBuildFetchLogsQuery(t *testing.T) {
+	since := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	until := since.AddDate(0, 0, 1)
+
+	query, args := buildFetchLogsQuery(fetchLogFilter{}, 50, 0)
+	want := `SELECT id, feed_url, status, message, duration_ms, COALESCE(articles_found, 0), COALESCE(new_articles, 0), created_at
+		FROM fetch_logs ORDER BY created_at DESC, id DESC LIMIT $1 OFFSET $2`
+	if query != want || !reflect.DeepEqual(args, []interface{}{50, 0}) {
+		t.Errorf("unfiltered query = %q %v", query, args)
+	}
+
+	query, args = buildFetchLogsQuery(fetchLogFilter{feedURL: "https://a/rss", status: "error", since: since, until: until}, 10, 20)
+	wantWhere := " WHERE feed_url = $1 AND status = $2 AND created_at >= $3 AND created_at < $4 ORDER BY created_at DESC, id DESC LIMIT $5 OFFSET $6"
+	if query[len(query)-len(wantWhere):] != wantWhere {
+		t.Errorf("filtered query = %q, want it to end in %q", query, wantWhere)
+	}
+	if !reflect.DeepEqual(args, []interface{}{"https://a/rss", "error", since, until, 10, 20}) {
+		t.Errorf("filtered args = %v", args)
+	}
+}
+
+func TestGetFetchLogs(t *testing.T) {
+	db, recorder := openExecRecorder(t)
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	recorder.answer("FROM fetch_logs",
+		[]driver.Value{int64(7), "https://a/rss", "error", "HTTP 503", int64(1200), int64(0), int64(0), at},
+		[]driver.Value{int64(6), "https://a/rss", "success", nil, int64(300), int64(25), int64(3), at.Add(-time.Hour)})
+	s := &APIServer{db: db}
+
+	rec := httptest.NewRecorder()
+	s.getFetchLogs(rec, httptest.NewRequest(http.MethodGet, "/fetch-logs?feed_url=https://a/rss&since=2024-05-01&limit=2", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d: %s", rec.Code, rec.Body)
+	}
+	var body struct {
+		FetchLogs []FetchLog `json:"fetch_logs"`
+		Count     int        `json:"count"`
+		Limit     int        `json:"limit"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Count != 2 || body.Limit != 2 {
+		t.Fatalf("count = %d, limit = %d; want 2 and 2", body.Count, body.Limit)
+	}
+	if first := body.FetchLogs[0]; first.Status != "error" || first.Message == nil || *first.Message != "HTTP 503" || *first.DurationMs != 1200 {
+		t.Errorf("first row = %+v", first)
+	}
+	if second := body.FetchLogs[1]; second.Message != nil || second.ArticlesFound != 25 || second.NewArticles != 3 {
+		t.Errorf("second row = %+v", second)
+	}
+
+	for _, target := range []string{
+		"/fetch-logs?limit=0",
+		"/fetch-logs?since=yesterday",
+		"/fetch-logs?since=2024-05-02&until=2024-05-01",
+	} {
+		rec := httptest.NewRecorder()
+		s.getFetchLogs(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("GET %s: status = %d, want 400", target, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"strings"
+	"unicode"
+)
+
+// languageStopwords are short, frequent function words that are distinctive
+// enough to tell the supported languages apart. Detection is a vote over
+// these; it only has to be good enough to notice a summary written in a
+// different language than its article.
+var languageStopwords = map[string][]string{
+	"en": {"the", "and", "of", "to", "is", "that", "it", "for", "with", "was", "are", "on", "this", "be", "have", "from", "by", "has", "they", "which", "not", "an", "will", "were"},
+	"fr": {"le", "la", "les", "des", "et", "est", "une", "du", "dans", "qui", "pour", "pas", "sur", "au", "avec", "sont", "ce", "cette", "ont", "aux", "leur", "mais", "nous", "été"},
+	"de": {"der", "die", "das", "und", "ist", "nicht", "ein", "eine", "mit", "den", "von", "zu", "auf", "für", "sich", "dem", "auch", "wird", "wurde", "sind", "bei", "nach", "oder", "werden"},
+	"es": {"el", "los", "las", "y", "una", "por", "con", "para", "se", "como", "más", "pero", "sus", "está", "son", "fue", "al", "este", "esta", "han", "sobre", "entre", "también", "hay"},
+	"it": {"il", "gli", "della", "di", "che", "è", "per", "non", "sono", "anche", "come", "nel", "alla", "questo", "più", "ha", "dei", "delle", "degli", "nella", "stato", "essere", "loro", "ma"},
+	"pt": {"o", "os", "da", "do", "em", "não", "uma", "dos", "das", "mais", "ao", "pelo", "pela", "foi", "são", "à", "seu", "sua", "também", "já", "ser", "quando", "muito", "nos"},
+	"nl": {"het", "een", "en", "van", "niet", "op", "dat", "met", "voor", "zijn", "ook", "wordt", "aan", "bij", "er", "naar", "om", "door", "werd", "maar", "worden", "deze", "nog", "heeft"},
+}
+
+// languageNames maps the codes above to the names used in prompt instructions.
+var languageNames = map[string]string{
+	"en": "English", "fr": "French", "de": "German", "es": "Spanish",
+	"it": "Italian", "pt": "Portuguese", "nl": "Dutch",
+}
+
+// minLanguageHits is how many stopwords the winning language needs before a
+// text is considered detected at all.
+const minLanguageHits = 3
+
+var stopwordLanguages = buildStopwordIndex()
+
+func buildStopwordIndex() map[string][]string {
+	index := make(map[string][]string)
+	for lang, words := range languageStopwords {
+		for _, w := range words {
+			index[w] = append(index[w], lang)
+		}
+	}
+	return index
+}
+
+// detectLanguage returns the most likely language code for text. ok is false
+// when the text is too short or too mixed to call: the winner needs at least
+// minLanguageHits stopword hits and a clear lead over the runner-up.
+func detectLanguage(text string) (lang string, ok bool) {
+	scores := make(map[string]int)
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	for _, w := range words {
+		for _, l := range stopwordLanguages[w] {
+			scores[l]++
+		}
+	}
+
+	best, second := 0, 0
+	for l, score := range scores {
+		if score > best {
+			second = best
+			best, lang = score, l
+		} else if score > second {
+			second = score
+		}
+	}
+
+	// A tie for first lands in second, so it fails the lead check below
+	if best < minLanguageHits || best*2 < second*3 {
+		return "", false
+	}
+	return lang, true
+}
+
+// languageName returns the name of a language code for prompts, or the code
+// itself if it isn't one of the detected languages.
+func languageName(lang string) string {
+	if name, known := languageNames[lang]; known {
+		return name
+	}
+	return lang
+}
+
+// withLanguageInstruction adds an explicit instruction to write in lang, the
+// article's language, just before the prompt's trailing "Summary:" cue.
+func withLanguageInstruction(prompt, lang string) string {
+	return withPromptInstruction(prompt, "Write the summary in "+languageName(lang)+", the language of the article.")
+}
+
+// withPromptInstruction inserts instruction just before the prompt's
+// trailing "Summary:" cue.
+func withPromptInstruction(prompt, instruction string) string {
+	instruction += "\n\n"
+	if i := strings.LastIndex(prompt, "Summary:"); i >= 0 {
+		return prompt[:i] + instruction + prompt[i:]
+	}
+	return prompt + "\n\n" + instruction
+}
+
+// summaryLanguageInstruction returns the output-language instruction the
+// SummaryLanguage setting calls for on an article with the given body, or
+// "" for none:
+//   - "": none, leaving the language to the model
+//   - "auto": the article's language, when it is detected and isn't
+//     English; the plain prompt already gets English right
+//   - "source": the article's language, named when detected
+//   - a language code such as "en": always that language
+func summaryLanguageInstruction(setting, body string) string {
+	switch setting = strings.ToLower(strings.TrimSpace(setting)); setting {
+	case "":
+		return ""
+	case "auto":
+		if lang, ok := detectLanguage(body); ok && lang != "en" {
+			return "Write the summary in " + languageName(lang) + ", the language of the article."
+		}
+		return ""
+	case "source":
+		if lang, ok := detectLanguage(body); ok {
+			return "Write the summary in " + languageName(lang) + ", the language of the article."
+		}
+		return "Write the summary in the same language as the article."
+	default:
+		return "Write the summary in " + languageName(setting) + "."
+	}
+}
+
+// fixedSummaryLanguage returns the language code SummaryLanguage pins
+// summaries to, or "" when it follows the article.
+func fixedSummaryLanguage(setting string) string {
+	switch setting = strings.ToLower(strings.TrimSpace(setting)); setting {
+	case "", "auto", "source":
+		return ""
+	default:
+		return setting
+	}
+}
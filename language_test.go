@@ -0,0 +1,74 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		name   string
+		text   string
+		want   string
+		wantOK bool
+	}{
+		{"english", "Attackers exploited a flaw in the gateway, and the vendor has released a patch for it. This is the second incident this year.", "en", true},
+		{"french", "Les attaquants ont exploité une faille dans la passerelle et le fournisseur a publié un correctif pour les clients qui sont concernés.", "fr", true},
+		{"german", "Die Angreifer haben eine Lücke in dem Gateway ausgenutzt und der Hersteller hat ein Update für die betroffenen Kunden veröffentlicht, das auch sich selbst installiert.", "de", true},
+		{"spanish", "Los atacantes explotaron una falla en el sistema y el proveedor publicó una corrección para los clientes que están afectados por este problema.", "es", true},
+		{"too short to call", "Patch now.", "", false},
+		{"no stopwords", "CVE-2026-1234 RCE PoC XSS SQLi", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := detectLanguage(tt.text)
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("detectLanguage() = (%q, %v), want (%q, %v)", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestWithLanguageInstruction(t *testing.T) {
+	prompt := "Summarize this.\n\nArticle text:\nBonjour\n\nSummary:"
+	got := withLanguageInstruction(prompt, "fr")
+
+	if !strings.Contains(got, "Write the summary in French") {
+		t.Fatalf("missing language instruction: %q", got)
+	}
+	if !strings.HasSuffix(got, "Summary:") {
+		t.Errorf("instruction should come before the trailing Summary: cue: %q", got)
+	}
+	if strings.Index(got, "Write the summary") < strings.Index(got, "Bonjour") {
+		t.Errorf("instruction should follow the article text: %q", got)
+	}
+}
+
+func TestSummaryLanguageInstruction(t *testing.T) {
+	english := "Attackers exploited a flaw in the gateway, and the vendor has released a patch for it. This is the second incident this year."
+	french := "Les attaquants ont exploité une faille dans la passerelle et le fournisseur a publié un correctif pour les clients qui sont concernés."
+
+	tests := []struct {
+		name    string
+		setting string
+		body    string
+		want    string
+	}{
+		{"auto leaves English alone", "auto", english, ""},
+		{"auto names a detected language", "auto", french, "Write the summary in French, the language of the article."},
+		{"unset leaves it to the model", "", french, ""},
+		{"source names the language", "source", english, "Write the summary in English, the language of the article."},
+		{"source without detection", "source", "Patch now.", "Write the summary in the same language as the article."},
+		{"fixed language", "en", french, "Write the summary in English."},
+		{"unknown code passed through", "xx", english, "Write the summary in xx."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := summaryLanguageInstruction(tt.setting, tt.body); got != tt.want {
+				t.Errorf("summaryLanguageInstruction(%q) = %q, want %q", tt.setting, got, tt.want)
+			}
+		})
+	}
+}
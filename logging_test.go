@@ -0,0 +1,39 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestNewLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newLogger(&buf, slog.LevelInfo)
+	logger.Debug("Fetching feed", "feed_url", "https://example.com/rss")
+	logger.Warn("Failed to fetch article content", "feed_url", "https://example.com/rss", "article_url", "https://example.com/a")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("logged %d lines, want only the warning: %q", len(lines), buf.String())
+	}
+	var record map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &record); err != nil {
+		t.Fatalf("log line isn't JSON: %v: %s", err, lines[0])
+	}
+	want := map[string]any{
+		"level":       "warn",
+		"msg":         "Failed to fetch article content",
+		"feed_url":    "https://example.com/rss",
+		"article_url": "https://example.com/a",
+	}
+	for key, value := range want {
+		if record[key] != value {
+			t.Errorf("%s = %v, want %v", key, record[key], value)
+		}
+	}
+	if _, ok := record["timestamp"]; !ok {
+		t.Errorf("record has no timestamp: %v", record)
+	}
+}
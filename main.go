@@ -1,12 +1,12 @@
 package main
 
 import (
-	"bufio"
 	"context"
 	"database/sql"
 	"fmt"
 	"information-broker/config"
 	"log"
+	"log/slog"
 	"os"
 	"os/signal"
 	"strings"
@@ -21,8 +21,32 @@ func main() {
 	// Load configuration
 	cfg := config.Load()
 
+	// Structured JSON logging at LOG_LEVEL; the log package's output goes
+	// through it too, at info level. An invalid level is reported by Validate.
+	logLevel, _ := config.ParseLogLevel(cfg.App.LogLevel)
+	slog.SetDefault(newLogger(os.Stderr, logLevel))
+
+	// Pre-deploy smoke test: `information-broker --validate [--check-feeds]`
+	// (or VALIDATE_ONLY=true) checks the configuration, database and feeds,
+	// prints a report and exits non-zero on failure, without starting anything.
+	if validate, checkFeeds := validateRequested(os.Args[1:]); validate || cfg.App.ValidateOnly {
+		checkFeeds = checkFeeds || cfg.App.ValidateCheckFeeds
+		if err := runValidation(context.Background(), cfg, checkFeeds, initDatabase, os.Stdout); err != nil {
+			log.Print(err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	if err := cfg.Validate(); err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+
 	// One-off maintenance: `information-broker backfill <url-substring>` re-fetches
 	// and re-extracts matching articles with the current extractor, then exits.
+	// `backfill --deferred` instead targets articles whose content fetch was
+	// deferred by PER_FEED_CONTENT_BUDGET, and `backfill --previews` fills in
+	// the preview of articles saved before there was one or with markup in it.
 	if len(os.Args) > 1 && os.Args[1] == "backfill" {
 		pattern := "theregister.com"
 		if len(os.Args) > 2 {
@@ -39,9 +63,7 @@ func main() {
 		return
 	}
 
-	// Setup logging
-	log.SetFlags(log.LstdFlags | log.Lshortfile)
-	log.Println("Starting Information Broker RSS Monitor")
+	slog.Info("Starting Information Broker RSS Monitor", "log_level", strings.ToLower(logLevel.String()))
 
 	// Initialize Prometheus metrics
 	metrics := NewPrometheusMetrics()
@@ -64,21 +86,51 @@ func main() {
 	}
 	log.Printf("Loaded %d RSS feeds", len(feeds))
 
+	// Load per-domain content extraction rules
+	extractor, err := LoadContentExtractor(cfg.Content.ExtractionRulesFile)
+	if err != nil {
+		log.Fatalf("Failed to load content extraction rules: %v", err)
+	}
+	if extractor.Len() > 0 {
+		log.Printf("Loaded content extraction rules for %d domains", extractor.Len())
+	}
+
 	// Create circuit breaker manager
 	circuitBreakers := NewCircuitBreakerManager()
 	circuitBreakers.SetMetrics(metrics)
+	if cfg.App.PersistCircuitBreakers {
+		// Not fatal: breakers just start closed, as they would without persistence
+		if err := circuitBreakers.LoadState(db); err != nil {
+			slog.Warn("Circuit breaker state not restored", "error", err)
+		}
+	}
+
+	// A NATS server that rejects our credentials or TLS settings won't
+	// start accepting them later, so stop here rather than drop every event
+	events, err := NewEventPublisher(cfg.Events)
+	if err != nil {
+		log.Fatalf("Failed to set up pipeline events: %v", err)
+	}
 
 	// Create summarization scheduler
-	summarizationScheduler := NewSummarizationScheduler(db, cfg, metrics)
+	summarizationScheduler := NewSummarizationScheduler(db, cfg, metrics, circuitBreakers, events)
+
+	// Catch misconfiguration now rather than hours into a silently failing pipeline
+	if cfg.App.StartupSelfTest {
+		checks := startupSelfTestChecks(db, cfg, summarizationScheduler.summarizer, summarizationScheduler.discordSender)
+		if err := runStartupSelfTest(context.Background(), checks, cfg.App.SelfTestAbort); err != nil {
+			log.Fatalf("Aborting startup: %v", err)
+		}
+	}
 
 	// Create story-clustering scheduler (backs the digest feature's "important" bucket)
 	clusteringScheduler := NewClusteringScheduler(db, cfg, summarizationScheduler)
 
 	// Create monitor with metrics and circuit breakers
-	monitor := NewRSSMonitor(db, feeds, metrics, cfg, circuitBreakers, summarizationScheduler)
+	monitor := NewRSSMonitor(db, feeds, metrics, cfg, circuitBreakers, summarizationScheduler, extractor)
 
 	// Create API server with metrics and circuit breakers
-	apiServer := NewAPIServer(db, cfg.App.Port, metrics, cfg, circuitBreakers, summarizationScheduler)
+	apiServer := NewAPIServer(db, cfg.App.Port, metrics, cfg, circuitBreakers, summarizationScheduler, monitor)
 
 	// Setup graceful shutdown
 	ctx, cancel := context.WithCancel(context.Background())
@@ -118,7 +170,7 @@ func main() {
 		apiServer.Start()
 	}()
 
-	// Start database metrics updater
+	// Start database and runtime metrics updater
 	go func() {
 		ticker := time.NewTicker(30 * time.Second)
 		defer ticker.Stop()
@@ -130,6 +182,7 @@ func main() {
 			case <-ticker.C:
 				stats := db.Stats()
 				metrics.UpdateDBConnections(stats.OpenConnections, stats.InUse, stats.Idle)
+				collectSystemMetrics(metrics)
 			}
 		}
 	}()
@@ -172,30 +225,10 @@ func main() {
 
 	cancel()
 	wg.Wait()
-	log.Println("All services stopped successfully")
-}
 
-func loadFeeds(filename string) ([]string, error) {
-	file, err := os.Open(filename)
-	if err != nil {
-		return nil, err
-	}
-	defer file.Close()
-
-	var feeds []string
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line != "" && !strings.HasPrefix(line, "#") {
-			feeds = append(feeds, line)
-		}
-	}
-
-	if err := scanner.Err(); err != nil {
-		return nil, err
-	}
-
-	return feeds, nil
+	// Send the pipeline events still buffered
+	summarizationScheduler.events.Close()
+	log.Println("All services stopped successfully")
 }
 
 func initDatabase(cfg *config.Config) (*sql.DB, error) {
@@ -205,6 +238,7 @@ func initDatabase(cfg *config.Config) (*sql.DB, error) {
 	if err != nil {
 		return nil, err
 	}
+	configureDBPool(db, cfg.Database)
 
 	// Test connection
 	if err := db.Ping(); err != nil {
@@ -237,6 +271,20 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+// configureDBPool bounds the connection pool as configured, so load can't
+// exhaust Postgres connections and long-lived ones get recycled.
+func configureDBPool(db *sql.DB, cfg config.DatabaseConfig) {
+	if cfg.MaxOpenConns > 0 {
+		db.SetMaxOpenConns(cfg.MaxOpenConns)
+	}
+	if cfg.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(cfg.MaxIdleConns)
+	}
+	if cfg.ConnMaxLifetime > 0 {
+		db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	}
+}
+
 func createTables(db *sql.DB) error {
 	queries := []string{
 		`CREATE TABLE IF NOT EXISTS articles (
@@ -269,12 +317,87 @@ func createTables(db *sql.DB) error {
 		`CREATE INDEX IF NOT EXISTS idx_articles_title_trgm ON articles USING GIN (title gin_trgm_ops)`,
 		`CREATE INDEX IF NOT EXISTS idx_articles_summary_trgm ON articles USING GIN (summary gin_trgm_ops)`,
 		`CREATE INDEX IF NOT EXISTS idx_articles_full_content_trgm ON articles USING GIN (full_content gin_trgm_ops)`,
+		// Full-text index backing /search; the expression must match articleSearchVector exactly.
+		`CREATE INDEX IF NOT EXISTS idx_articles_search_fts ON articles USING GIN (` + articleSearchVector + `)`,
 		// Story-clustering columns: summary_embedding backs the precomputed clustering job's
 		// similarity comparisons (no pgvector -- plain Postgres array, compared in Go);
 		// story_cluster_id is self-referencing (a cluster's seed article's own id).
 		`ALTER TABLE articles ADD COLUMN IF NOT EXISTS summary_embedding real[]`,
 		`ALTER TABLE articles ADD COLUMN IF NOT EXISTS story_cluster_id BIGINT`,
 		`CREATE INDEX IF NOT EXISTS idx_articles_story_cluster_id ON articles(story_cluster_id)`,
+		// preview is a short plain-text excerpt for list views, so /articles doesn't
+		// ship full_content. New rows get one from processArticle; rows saved
+		// before the column existed get theirs from `backfill --previews`.
+		`ALTER TABLE articles ADD COLUMN IF NOT EXISTS preview TEXT`,
+		// needs_content_refetch marks articles saved with the feed description
+		// because their feed ran out of content-fetch budget; `backfill --deferred`
+		// picks them up.
+		`ALTER TABLE articles ADD COLUMN IF NOT EXISTS needs_content_refetch BOOLEAN NOT NULL DEFAULT FALSE`,
+		`CREATE INDEX IF NOT EXISTS idx_articles_needs_content_refetch ON articles(id) WHERE needs_content_refetch`,
+		// discord_replay_pending marks articles whose Discord post was skipped
+		// while the Discord circuit breaker was open; the scheduler re-sends them.
+		`ALTER TABLE articles ADD COLUMN IF NOT EXISTS discord_replay_pending BOOLEAN NOT NULL DEFAULT FALSE`,
+		`CREATE INDEX IF NOT EXISTS idx_articles_discord_replay_pending ON articles(publish_date) WHERE discord_replay_pending`,
+		// original_url is the link as the feed gave it, kept when url was normalized
+		// (tracking parameters, fragment or trailing slashes removed).
+		`ALTER TABLE articles ADD COLUMN IF NOT EXISTS original_url TEXT`,
+		// summary_grace_until is set when an article's summarization fails in a
+		// feed with a summary grace period; the scheduler retries it until then
+		// and posts it without a summary afterwards.
+		`ALTER TABLE articles ADD COLUMN IF NOT EXISTS summary_grace_until TIMESTAMP WITH TIME ZONE`,
+		`CREATE INDEX IF NOT EXISTS idx_articles_summary_grace_until ON articles(summary_grace_until) WHERE summary_grace_until IS NOT NULL`,
+		// language is the article's detected language code; NULL when the
+		// text was too short or mixed to tell.
+		`ALTER TABLE articles ADD COLUMN IF NOT EXISTS language TEXT`,
+		// raw_item is the feed item the article came from, as JSON, when
+		// CONTENT_STORE_RAW_ITEM is on.
+		`ALTER TABLE articles ADD COLUMN IF NOT EXISTS raw_item JSONB`,
+		// How often a failed summarization was replayed, and whether the
+		// replays gave up on it (SUMMARIZATION_DEAD_LETTER_REPLAY_INTERVAL)
+		`ALTER TABLE articles ADD COLUMN IF NOT EXISTS summary_replay_attempts INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE articles ADD COLUMN IF NOT EXISTS summary_abandoned BOOLEAN NOT NULL DEFAULT FALSE`,
+		// simhash is the content's SimHash fingerprint; simhash_bands holds its
+		// bands so near-duplicates are looked up through the GIN index
+		// (CONTENT_NEAR_DUPLICATE_INDEX).
+		`ALTER TABLE articles ADD COLUMN IF NOT EXISTS simhash BIGINT`,
+		`ALTER TABLE articles ADD COLUMN IF NOT EXISTS simhash_bands INTEGER[]`,
+		`CREATE INDEX IF NOT EXISTS idx_articles_simhash_bands ON articles USING GIN (simhash_bands)`,
+		// discord_posted_at is when the article first reached Discord, for
+		// the posting SLA of /feeds/sla.
+		`ALTER TABLE articles ADD COLUMN IF NOT EXISTS discord_posted_at TIMESTAMP WITH TIME ZONE`,
+		// The feed item's author name(s) and its categories as lowercased
+		// tags, filtered by /articles?tag=
+		`ALTER TABLE articles ADD COLUMN IF NOT EXISTS author TEXT`,
+		`ALTER TABLE articles ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}'`,
+		`CREATE INDEX IF NOT EXISTS idx_articles_tags ON articles USING GIN (tags)`,
+		// Summarization requests that are queued or being processed, reloaded
+		// into the queue at startup so a restart doesn't lose them.
+		`CREATE TABLE IF NOT EXISTS pending_summarizations (
+			article_url TEXT PRIMARY KEY,
+			article_title TEXT NOT NULL,
+			feed_url TEXT NOT NULL DEFAULT '',
+			lead TEXT NOT NULL DEFAULT '',
+			content TEXT NOT NULL DEFAULT '',
+			model TEXT NOT NULL,
+			content_source TEXT NOT NULL DEFAULT '',
+			priority INTEGER NOT NULL DEFAULT 0,
+			enqueued_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+		)`,
+		// A restored request keeps the ID its log lines were written under
+		`ALTER TABLE pending_summarizations ADD COLUMN IF NOT EXISTS request_id TEXT NOT NULL DEFAULT ''`,
+		// ...and whether it updates an article already posted, and its feed's
+		// directives as they were when it was queued
+		`ALTER TABLE pending_summarizations ADD COLUMN IF NOT EXISTS is_update BOOLEAN NOT NULL DEFAULT FALSE`,
+		`ALTER TABLE pending_summarizations ADD COLUMN IF NOT EXISTS content_type TEXT NOT NULL DEFAULT ''`,
+		`ALTER TABLE pending_summarizations ADD COLUMN IF NOT EXISTS summary_words INTEGER NOT NULL DEFAULT 0`,
+		`ALTER TABLE pending_summarizations ADD COLUMN IF NOT EXISTS category TEXT NOT NULL DEFAULT ''`,
+		`CREATE TABLE IF NOT EXISTS discord_messages (
+			article_url TEXT NOT NULL,
+			webhook_url TEXT NOT NULL,
+			message_id TEXT NOT NULL,
+			posted_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+			PRIMARY KEY (article_url, webhook_url)
+		)`,
 		`CREATE TABLE IF NOT EXISTS fetch_logs (
 			id SERIAL PRIMARY KEY,
 			feed_url TEXT NOT NULL,
@@ -287,6 +410,26 @@ func createTables(db *sql.DB) error {
 		)`,
 		`CREATE INDEX IF NOT EXISTS idx_fetch_logs_feed_url ON fetch_logs(feed_url)`,
 		`CREATE INDEX IF NOT EXISTS idx_fetch_logs_created_at ON fetch_logs(created_at)`,
+		// Feeds failing since failing_since, and those disabled for failing
+		// longer than DEAD_FEED_THRESHOLD; restored at startup.
+		`CREATE TABLE IF NOT EXISTS feed_state (
+			feed_url TEXT PRIMARY KEY,
+			failing_since TIMESTAMP WITH TIME ZONE,
+			disabled BOOLEAN NOT NULL DEFAULT FALSE,
+			disabled_at TIMESTAMP WITH TIME ZONE,
+			last_error TEXT,
+			updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		)`,
+		// Last known state of each circuit breaker, restored at startup when
+		// CIRCUIT_BREAKER_PERSIST is on.
+		`CREATE TABLE IF NOT EXISTS circuit_breaker_state (
+			name TEXT PRIMARY KEY,
+			state TEXT NOT NULL,
+			failure_count INTEGER NOT NULL DEFAULT 0,
+			success_count INTEGER NOT NULL DEFAULT 0,
+			last_failure_time TIMESTAMP WITH TIME ZONE,
+			updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+		)`,
 	}
 
 	for _, query := range queries {
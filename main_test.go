@@ -0,0 +1,22 @@
+package main
+
+import (
+	"information-broker/config"
+	"testing"
+	"time"
+)
+
+func TestConfigureDBPool(t *testing.T) {
+	db, _ := openExecRecorder(t)
+	configureDBPool(db, config.DatabaseConfig{MaxOpenConns: 8, MaxIdleConns: 3, ConnMaxLifetime: time.Minute})
+	if got := db.Stats().MaxOpenConnections; got != 8 {
+		t.Errorf("MaxOpenConnections = %d, want 8", got)
+	}
+
+	// Zero leaves database/sql's defaults alone
+	db, _ = openExecRecorder(t)
+	configureDBPool(db, config.DatabaseConfig{})
+	if got := db.Stats().MaxOpenConnections; got != 0 {
+		t.Errorf("MaxOpenConnections = %d, want 0 (unlimited)", got)
+	}
+}
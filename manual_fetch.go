@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sync"
+)
+
+// FetchNow fetches the given feeds straight away, or every active feed when
+// feedURLs is empty, and returns one result per feed in the order given.
+// It goes through the same path as the polling schedules: fetches share
+// their slots, each feed's circuit breaker applies, and a feed fetched
+// within App.MinFeedRefetch is reported as skipped rather than fetched
+// twice, so it is safe to call while a scheduled cycle is running.
+func (m *RSSMonitor) FetchNow(ctx context.Context, feedURLs []string) []FeedFetchResult {
+	var feeds []Feed
+	if len(feedURLs) == 0 {
+		feeds = m.activeFeeds(m.Feeds())
+	} else {
+		for _, feedURL := range feedURLs {
+			feeds = append(feeds, m.feed(feedURL))
+		}
+	}
+
+	var mu sync.Mutex
+	byURL := make(map[string]FeedFetchResult, len(feeds))
+	dispatchFeeds(ctx, feeds, m.fetchSlots, func(ctx context.Context, feedURL string) {
+		result := m.fetchFeed(ctx, feedURL)
+		mu.Lock()
+		byURL[feedURL] = result
+		mu.Unlock()
+	})
+
+	results := make([]FeedFetchResult, 0, len(feeds))
+	for _, feed := range feeds {
+		result, ok := byURL[feed.URL]
+		if !ok {
+			// Never dispatched: the context ended while waiting for a slot
+			result = FeedFetchResult{FeedURL: feed.URL, Status: fetchStatusError, Error: ctx.Err().Error()}
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// triggerFeedFetch fetches the feed in ?url=, or every active feed without
+// it, out of band of the polling schedule and reports how many new articles
+// each fetch found. Feeds that aren't monitored get 404 and feeds disabled
+// as dead get 409; re-enable those through /admin/feeds/enable first.
+func (s *APIServer) triggerFeedFetch(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !s.requireAdmin(w, r) {
+		return
+	}
+	if s.monitor == nil {
+		http.Error(w, "RSS monitor not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	var feedURLs []string
+	if feedURL := r.URL.Query().Get("url"); feedURL != "" {
+		monitored := false
+		for _, feed := range s.monitor.Feeds() {
+			if feed.URL == feedURL {
+				monitored = true
+				break
+			}
+		}
+		if !monitored {
+			http.Error(w, "Feed not monitored", http.StatusNotFound)
+			return
+		}
+		if _, disabled := s.monitor.deadFeeds.disabledAt(feedURL); disabled {
+			http.Error(w, "Feed is disabled as dead; re-enable it through /admin/feeds/enable", http.StatusConflict)
+			return
+		}
+		feedURLs = []string{feedURL}
+	}
+
+	results := s.monitor.FetchNow(r.Context(), feedURLs)
+	total := 0
+	for _, result := range results {
+		total += result.NewArticles
+	}
+	slog.Info("Manual feed fetch finished", "feeds", len(results), "new", total)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"feeds":        results,
+		"new_articles": total,
+	})
+}
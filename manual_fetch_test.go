@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"information-broker/config"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTriggerFeedFetch(t *testing.T) {
+	mux := http.NewServeMux()
+	var srvURL string
+	mux.HandleFunc("/feed", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `<?xml version="1.0"?><rss version="2.0"><channel><title>Test</title>
+			<item><title>One</title><link>%[1]s/article/1</link><pubDate>%[2]s</pubDate></item>
+			<item><title>Two</title><link>%[1]s/article/2</link><pubDate>%[2]s</pubDate></item>
+			</channel></rss>`, srvURL, time.Now().UTC().Format(time.RFC1123Z))
+	})
+	mux.HandleFunc("/article/", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "<html><body><article>"+strings.Repeat("Article body. ", 20)+"</article></body></html>")
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	srvURL = srv.URL
+	feedURL, goneURL := srv.URL+"/feed", srv.URL+"/gone"
+
+	db, _ := openExecRecorder(t)
+	cfg := &config.Config{
+		App:         config.AppConfig{MinFeedRefetch: time.Hour},
+		API:         config.APIConfig{Timeout: 5 * time.Second},
+		Performance: config.PerformanceConfig{MaxConcurrentFeeds: 1, MaxArticleContentLength: 10000},
+	}
+	scheduler := &SummarizationScheduler{config: cfg, metrics: testMetrics(), queueCap: 10, queueReady: make(chan struct{}, 1)}
+	m := NewRSSMonitor(db, []Feed{{URL: feedURL}, {URL: goneURL}}, testMetrics(), cfg, NewCircuitBreakerManager(), scheduler, nil)
+	s := &APIServer{monitor: m, config: cfg}
+	cfg.Security.AdminSecret = "k3y" // Vouched for by requireSignature
+
+	trigger := func(method, target string) (*httptest.ResponseRecorder, []FeedFetchResult, int) {
+		rec := httptest.NewRecorder()
+		s.triggerFeedFetch(rec, httptest.NewRequest(method, target, nil))
+		var body struct {
+			Feeds       []FeedFetchResult `json:"feeds"`
+			NewArticles int               `json:"new_articles"`
+		}
+		if rec.Code == http.StatusOK {
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return rec, body.Feeds, body.NewArticles
+	}
+
+	if rec, _, _ := trigger(http.MethodGet, "/feeds/fetch"); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET = %d, want 405", rec.Code)
+	}
+	if rec, _, _ := trigger(http.MethodPost, "/feeds/fetch?url=https://unknown.example/rss"); rec.Code != http.StatusNotFound {
+		t.Errorf("fetching an unmonitored feed = %d, want 404", rec.Code)
+	}
+
+	rec, results, total := trigger(http.MethodPost, "/feeds/fetch?url="+feedURL)
+	if rec.Code != http.StatusOK || total != 2 || len(results) != 1 {
+		t.Fatalf("fetch = %d, %d new over %+v; want 2 new articles from one feed", rec.Code, total, results)
+	}
+	if results[0].FeedURL != feedURL || results[0].Status != fetchStatusFetched || results[0].NewArticles != 2 {
+		t.Errorf("result = %+v", results[0])
+	}
+
+	// The feed was just fetched, so only the other one goes out
+	_, results, total = trigger(http.MethodPost, "/feeds/fetch")
+	if total != 0 || len(results) != 2 {
+		t.Fatalf("fetching all feeds = %d new over %+v, want two results and nothing new", total, results)
+	}
+	if results[0].Status != fetchStatusSkipped {
+		t.Errorf("refetched feed = %+v, want it skipped", results[0])
+	}
+	if results[1].FeedURL != goneURL || results[1].Status != fetchStatusError || results[1].Error != "HTTP 404" {
+		t.Errorf("missing feed = %+v, want the HTTP 404 reported", results[1])
+	}
+}
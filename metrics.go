@@ -6,6 +6,7 @@ import (
 
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
+	dto "github.com/prometheus/client_model/go"
 )
 
 // PrometheusMetrics holds all the Prometheus metrics for the application
@@ -14,20 +15,31 @@ type PrometheusMetrics struct {
 	rssFetchTotal    *prometheus.CounterVec
 	rssFetchDuration *prometheus.HistogramVec
 	rssFetchErrors   *prometheus.CounterVec
+	feedsSanitized   *prometheus.CounterVec
+	feedsDisabled    *prometheus.CounterVec
+	feedsDisabledNow *prometheus.GaugeVec
 
 	// Article processing metrics
-	articlesProcessed *prometheus.CounterVec
-	newArticlesFound  *prometheus.CounterVec
+	articlesProcessed           *prometheus.CounterVec
+	articlesDropped             *prometheus.CounterVec
+	newArticlesFound            *prometheus.CounterVec
+	articleContentFetchDuration *prometheus.HistogramVec
+	articleContentFetchSkipped  *prometheus.CounterVec
+	articlesDeferredThroughput  *prometheus.CounterVec
 
 	// Summarization API metrics
 	summaryAPILatency *prometheus.HistogramVec
 	summaryAPITotal   *prometheus.CounterVec
 	summaryAPIErrors  *prometheus.CounterVec
+	summaryInjection  *prometheus.CounterVec
+	summaryBackend    *prometheus.CounterVec
+	summaryLangMiss   *prometheus.CounterVec
 
 	// Discord webhook metrics
 	discordWebhookLatency *prometheus.HistogramVec
 	discordWebhookTotal   *prometheus.CounterVec
 	discordWebhookErrors  *prometheus.CounterVec
+	discordDeferredPosts  *prometheus.CounterVec
 
 	// HTTP API metrics
 	httpRequestDuration *prometheus.HistogramVec
@@ -35,6 +47,8 @@ type PrometheusMetrics struct {
 
 	// System metrics
 	dbConnections *prometheus.GaugeVec
+	goroutines    prometheus.Gauge
+	memoryBytes   *prometheus.GaugeVec
 
 	// Circuit breaker metrics
 	circuitBreakerState *prometheus.GaugeVec
@@ -82,8 +96,29 @@ func NewPrometheusMetrics() *PrometheusMetrics {
 			},
 			[]string{"feed_url", "error_type"},
 		),
+		feedsSanitized: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "rss_feed_sanitized_total",
+				Help: "Total number of feeds re-parsed after sanitizing a body that failed to parse, by outcome (recovered, failed)",
+			},
+			[]string{"feed_url", "outcome"},
+		),
 
 		// Article processing metrics
+		feedsDisabled: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "feeds_disabled_total",
+				Help: "Total number of times a feed was disabled for failing longer than DEAD_FEED_THRESHOLD",
+			},
+			[]string{"feed_url"},
+		),
+		feedsDisabledNow: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "feeds_disabled",
+				Help: "Number of feeds currently disabled as dead",
+			},
+			[]string{},
+		),
 		articlesProcessed: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
 				Name: "articles_processed_by_feed_total",
@@ -91,6 +126,13 @@ func NewPrometheusMetrics() *PrometheusMetrics {
 			},
 			[]string{"feed_url", "status"},
 		),
+		articlesDropped: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "articles_dropped_total",
+				Help: "Total number of articles that didn't make it to Discord, by feed and the step that dropped them",
+			},
+			[]string{"feed_url", "reason"},
+		),
 		newArticlesFound: prometheus.NewCounterVec(
 			prometheus.CounterOpts{
 				Name: "new_articles_found_total",
@@ -98,6 +140,28 @@ func NewPrometheusMetrics() *PrometheusMetrics {
 			},
 			[]string{"feed_url"},
 		),
+		articleContentFetchDuration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Name:    "article_content_fetch_duration_seconds",
+				Help:    "Time spent fetching article pages for their full content, by outcome (success, fallback, error)",
+				Buckets: []float64{0.1, 0.25, 0.5, 1.0, 2.5, 5.0, 10.0, 15.0, 30.0},
+			},
+			[]string{"feed_url", "outcome"},
+		),
+		articleContentFetchSkipped: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "article_content_fetch_skipped_total",
+				Help: "Total number of article page fetches skipped because the feed carried enough content",
+			},
+			[]string{"feed_url"},
+		),
+		articlesDeferredThroughput: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "articles_deferred_throughput_total",
+				Help: "Total number of new articles left for a later fetch because MAX_ARTICLES_PER_HOUR was reached",
+			},
+			[]string{"feed_url"},
+		),
 
 		// Summarization API metrics
 		summaryAPILatency: prometheus.NewHistogramVec(
@@ -122,6 +186,27 @@ func NewPrometheusMetrics() *PrometheusMetrics {
 			},
 			[]string{"model", "error_type"},
 		),
+		summaryInjection: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "summary_injection_suspected_total",
+				Help: "Total number of suspected prompt injections, by where they were detected (content, summary)",
+			},
+			[]string{"stage"},
+		),
+		summaryBackend: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "summary_backend_served_total",
+				Help: "Total number of summaries served, by summarization backend host",
+			},
+			[]string{"backend"},
+		),
+		summaryLangMiss: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "summary_language_mismatch_total",
+				Help: "Total number of summaries rejected for being in a different language than the article",
+			},
+			[]string{"expected", "detected"},
+		),
 
 		// Discord webhook metrics
 		discordWebhookLatency: prometheus.NewHistogramVec(
@@ -146,6 +231,13 @@ func NewPrometheusMetrics() *PrometheusMetrics {
 			},
 			[]string{"error_type"},
 		),
+		discordDeferredPosts: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Name: "discord_deferred_posts_total",
+				Help: "Total number of Discord posts held during quiet hours, by outcome (deferred, released, expired)",
+			},
+			[]string{"outcome"},
+		),
 
 		// HTTP API metrics
 		httpRequestDuration: prometheus.NewHistogramVec(
@@ -172,6 +264,19 @@ func NewPrometheusMetrics() *PrometheusMetrics {
 			},
 			[]string{"state"},
 		),
+		goroutines: prometheus.NewGauge(
+			prometheus.GaugeOpts{
+				Name: "goroutines",
+				Help: "Current number of goroutines, as of the last /health check or 30s update",
+			},
+		),
+		memoryBytes: prometheus.NewGaugeVec(
+			prometheus.GaugeOpts{
+				Name: "memory_bytes",
+				Help: "Go heap bytes allocated (alloc) and memory obtained from the OS (sys)",
+			},
+			[]string{"type"},
+		),
 
 		// Circuit breaker metrics
 		circuitBreakerState: prometheus.NewGaugeVec(
@@ -210,7 +315,7 @@ func NewPrometheusMetrics() *PrometheusMetrics {
 				Help:    "Time spent processing summarization requests end-to-end",
 				Buckets: []float64{0.5, 1.0, 2.5, 5.0, 10.0, 30.0, 60.0, 120.0, 300.0},
 			},
-			[]string{"model", "status"},
+			[]string{"model", "status", "content_source"},
 		),
 		summarizationQueueWaitTime: prometheus.NewHistogramVec(
 			prometheus.HistogramOpts{
@@ -225,7 +330,7 @@ func NewPrometheusMetrics() *PrometheusMetrics {
 				Name: "summarization_requests_processed_total",
 				Help: "Total number of summarization requests processed by the scheduler",
 			},
-			[]string{"model", "status"},
+			[]string{"model", "status", "content_source"},
 		),
 
 		// Article date filtering metrics
@@ -266,17 +371,30 @@ func NewPrometheusMetrics() *PrometheusMetrics {
 		metrics.rssFetchTotal,
 		metrics.rssFetchDuration,
 		metrics.rssFetchErrors,
+		metrics.feedsSanitized,
+		metrics.feedsDisabled,
+		metrics.feedsDisabledNow,
 		metrics.articlesProcessed,
+		metrics.articlesDropped,
 		metrics.newArticlesFound,
+		metrics.articleContentFetchDuration,
+		metrics.articleContentFetchSkipped,
+		metrics.articlesDeferredThroughput,
 		metrics.summaryAPILatency,
 		metrics.summaryAPITotal,
 		metrics.summaryAPIErrors,
+		metrics.summaryInjection,
+		metrics.summaryBackend,
+		metrics.summaryLangMiss,
 		metrics.discordWebhookLatency,
 		metrics.discordWebhookTotal,
 		metrics.discordWebhookErrors,
+		metrics.discordDeferredPosts,
 		metrics.httpRequestDuration,
 		metrics.httpRequestsTotal,
 		metrics.dbConnections,
+		metrics.goroutines,
+		metrics.memoryBytes,
 		metrics.circuitBreakerState,
 		metrics.circuitBreakerTrips,
 		metrics.summarizationQueueDepth,
@@ -304,11 +422,74 @@ func (m *PrometheusMetrics) RecordRSSFetchError(feedURL, errorType string) {
 	m.rssFetchErrors.WithLabelValues(feedURL, errorType).Inc()
 }
 
+// RecordFeedSanitized records a sanitized re-parse of a malformed feed;
+// outcome is recovered or failed.
+func (m *PrometheusMetrics) RecordFeedSanitized(feedURL, outcome string) {
+	m.feedsSanitized.WithLabelValues(feedURL, outcome).Inc()
+}
+
+// RecordContentFetch records the duration of an article page fetch. outcome
+// is success, fallback (the page failed and the feed's own content was used)
+// or error (the page failed and the feed had nothing to fall back on).
+func (m *PrometheusMetrics) RecordContentFetch(feedURL, outcome string, duration time.Duration) {
+	m.articleContentFetchDuration.WithLabelValues(feedURL, outcome).Observe(duration.Seconds())
+}
+
+// RecordContentFetchSkipped records an article whose page wasn't fetched
+// because the feed's own content was long enough.
+func (m *PrometheusMetrics) RecordContentFetchSkipped(feedURL string) {
+	m.articleContentFetchSkipped.WithLabelValues(feedURL).Inc()
+}
+
+// RecordArticleDeferredThroughput records a new article left for a later
+// fetch by the hourly throughput cap.
+func (m *PrometheusMetrics) RecordArticleDeferredThroughput(feedURL string) {
+	m.articlesDeferredThroughput.WithLabelValues(feedURL).Inc()
+}
+
 // RecordArticleProcessed records article processing metrics
 func (m *PrometheusMetrics) RecordArticleProcessed(feedURL, status string) {
 	m.articlesProcessed.WithLabelValues(feedURL, status).Inc()
 }
 
+// RecordFeedDisabled records a feed disabled as dead.
+func (m *PrometheusMetrics) RecordFeedDisabled(feedURL string) {
+	m.feedsDisabled.WithLabelValues(feedURL).Inc()
+}
+
+// UpdateDisabledFeeds sets the number of feeds currently disabled as dead.
+func (m *PrometheusMetrics) UpdateDisabledFeeds(count int) {
+	m.feedsDisabledNow.WithLabelValues().Set(float64(count))
+}
+
+// RecordArticleDropped records an article from feedURL that stopped short of
+// Discord, for one of the drop reasons in feed_drops.go
+func (m *PrometheusMetrics) RecordArticleDropped(feedURL, reason string) {
+	m.articlesDropped.WithLabelValues(feedURL, reason).Inc()
+}
+
+// ArticleDrops returns the articles dropped since startup, by feed URL and
+// then by reason.
+func (m *PrometheusMetrics) ArticleDrops() map[string]map[string]int {
+	drops := make(map[string]map[string]int)
+	forEachSeries(m.articlesDropped, func(pb *dto.Metric) {
+		var feedURL, reason string
+		for _, label := range pb.GetLabel() {
+			switch label.GetName() {
+			case "feed_url":
+				feedURL = label.GetValue()
+			case "reason":
+				reason = label.GetValue()
+			}
+		}
+		if drops[feedURL] == nil {
+			drops[feedURL] = make(map[string]int)
+		}
+		drops[feedURL][reason] = int(pb.GetCounter().GetValue())
+	})
+	return drops
+}
+
 // RecordNewArticles records new articles found metrics
 func (m *PrometheusMetrics) RecordNewArticles(feedURL string, count int) {
 	m.newArticlesFound.WithLabelValues(feedURL).Add(float64(count))
@@ -325,6 +506,21 @@ func (m *PrometheusMetrics) RecordSummaryAPIError(model, errorType string) {
 	m.summaryAPIErrors.WithLabelValues(model, errorType).Inc()
 }
 
+// RecordSummaryInjectionSuspected records a suspected prompt injection
+func (m *PrometheusMetrics) RecordSummaryInjectionSuspected(stage string) {
+	m.summaryInjection.WithLabelValues(stage).Inc()
+}
+
+// RecordSummaryBackendServed records which backend served a summary
+func (m *PrometheusMetrics) RecordSummaryBackendServed(backend string) {
+	m.summaryBackend.WithLabelValues(backend).Inc()
+}
+
+// RecordSummaryLanguageMismatch records a summary rejected for its language
+func (m *PrometheusMetrics) RecordSummaryLanguageMismatch(expected, detected string) {
+	m.summaryLangMiss.WithLabelValues(expected, detected).Inc()
+}
+
 // RecordDiscordWebhook records Discord webhook metrics
 func (m *PrometheusMetrics) RecordDiscordWebhook(status string, duration time.Duration) {
 	m.discordWebhookTotal.WithLabelValues(status).Inc()
@@ -336,6 +532,11 @@ func (m *PrometheusMetrics) RecordDiscordWebhookError(errorType string) {
 	m.discordWebhookErrors.WithLabelValues(errorType).Inc()
 }
 
+// RecordDiscordDeferredPost records a quiet-hours deferral outcome
+func (m *PrometheusMetrics) RecordDiscordDeferredPost(outcome string) {
+	m.discordDeferredPosts.WithLabelValues(outcome).Inc()
+}
+
 // RecordHTTPRequest records HTTP request metrics
 func (m *PrometheusMetrics) RecordHTTPRequest(method, endpoint, statusCode string, duration time.Duration) {
 	m.httpRequestsTotal.WithLabelValues(method, endpoint, statusCode).Inc()
@@ -349,6 +550,13 @@ func (m *PrometheusMetrics) UpdateDBConnections(open, inUse, idle int) {
 	m.dbConnections.WithLabelValues("idle").Set(float64(idle))
 }
 
+// UpdateRuntimeStats updates the goroutine and memory gauges
+func (m *PrometheusMetrics) UpdateRuntimeStats(goroutines int, allocBytes, sysBytes uint64) {
+	m.goroutines.Set(float64(goroutines))
+	m.memoryBytes.WithLabelValues("alloc").Set(float64(allocBytes))
+	m.memoryBytes.WithLabelValues("sys").Set(float64(sysBytes))
+}
+
 // HTTPMetricsMiddleware creates a middleware for recording HTTP metrics
 func (m *PrometheusMetrics) HTTPMetricsMiddleware(next http.HandlerFunc, endpoint string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -378,6 +586,70 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.ResponseWriter.WriteHeader(code)
 }
 
+// MetricsSummary is a snapshot of the key counters and gauges, served as
+// JSON by /metrics/summary for those not running Prometheus.
+type MetricsSummary struct {
+	RSSFetches           float64 `json:"rss_fetches_total"`
+	RSSFetchErrors       float64 `json:"rss_fetch_errors_total"`
+	ArticlesProcessed    float64 `json:"articles_processed_total"`
+	ArticlesFailed       float64 `json:"articles_failed_total"`
+	SummariesSucceeded   float64 `json:"summaries_succeeded_total"`
+	SummariesFailed      float64 `json:"summaries_failed_total"`
+	QueueDepth           float64 `json:"summarization_queue_depth"`
+	OpenCircuitBreakers  float64 `json:"circuit_breakers_open"`
+	DiscordWebhookErrors float64 `json:"discord_webhook_errors_total"`
+}
+
+// Summary reads the current values of the metrics in MetricsSummary, each
+// summed over its labels.
+func (m *PrometheusMetrics) Summary() MetricsSummary {
+	return MetricsSummary{
+		RSSFetches:           sumMetric(m.rssFetchTotal, nil),
+		RSSFetchErrors:       sumMetric(m.rssFetchErrors, nil),
+		ArticlesProcessed:    sumMetric(m.articlesProcessedTotal, map[string]string{"status": "success"}),
+		ArticlesFailed:       sumMetric(m.articlesProcessedTotal, map[string]string{"status": "failed"}),
+		SummariesSucceeded:   sumMetric(m.summarizationTotalProcessed, map[string]string{"status": "success"}),
+		SummariesFailed:      sumMetric(m.summarizationTotalProcessed, map[string]string{"status": "error"}),
+		QueueDepth:           sumMetric(m.summarizationQueueDepth, nil),
+		OpenCircuitBreakers:  sumMetric(m.circuitBreakerState, map[string]string{"state": "open"}),
+		DiscordWebhookErrors: sumMetric(m.discordWebhookErrors, nil),
+	}
+}
+
+// sumMetric adds up the counter and gauge values of the series collected
+// from c whose labels include all of labels.
+func sumMetric(c prometheus.Collector, labels map[string]string) float64 {
+	var total float64
+	forEachSeries(c, func(pb *dto.Metric) {
+		matched := 0
+		for _, label := range pb.GetLabel() {
+			if value, ok := labels[label.GetName()]; ok && value == label.GetValue() {
+				matched++
+			}
+		}
+		if matched == len(labels) {
+			total += pb.GetCounter().GetValue() + pb.GetGauge().GetValue()
+		}
+	})
+	return total
+}
+
+// forEachSeries calls fn with the current value of every series collected
+// from c.
+func forEachSeries(c prometheus.Collector, fn func(*dto.Metric)) {
+	ch := make(chan prometheus.Metric)
+	go func() {
+		c.Collect(ch)
+		close(ch)
+	}()
+	for metric := range ch {
+		var pb dto.Metric
+		if err := metric.Write(&pb); err == nil {
+			fn(&pb)
+		}
+	}
+}
+
 // UpdateCircuitBreakerState updates circuit breaker state metrics
 func (m *PrometheusMetrics) UpdateCircuitBreakerState(name string, state CircuitBreakerState) {
 	// Reset all state gauges for this circuit breaker
@@ -404,10 +676,14 @@ func (m *PrometheusMetrics) UpdateSummarizationQueueCapacity(capacity int) {
 	m.summarizationQueueCapacity.WithLabelValues().Set(float64(capacity))
 }
 
-// RecordSummarizationProcessing records end-to-end summarization processing metrics
-func (m *PrometheusMetrics) RecordSummarizationProcessing(model, status string, duration time.Duration) {
-	m.summarizationProcessingTime.WithLabelValues(model, status).Observe(duration.Seconds())
-	m.summarizationTotalProcessed.WithLabelValues(model, status).Inc()
+// RecordSummarizationProcessing records end-to-end summarization processing
+// metrics, labelled with where the summarized content came from
+func (m *PrometheusMetrics) RecordSummarizationProcessing(model, status, contentSource string, duration time.Duration) {
+	if contentSource == "" {
+		contentSource = "unknown"
+	}
+	m.summarizationProcessingTime.WithLabelValues(model, status, contentSource).Observe(duration.Seconds())
+	m.summarizationTotalProcessed.WithLabelValues(model, status, contentSource).Inc()
 }
 
 // RecordSummarizationQueueWait records time spent waiting in queue
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestMetricsSummary(t *testing.T) {
+	metrics := testMetrics()
+	before := metrics.Summary()
+
+	metrics.RecordRSSFetch("https://summary.test/rss", "success", time.Second)
+	metrics.RecordRSSFetch("https://summary.test/rss", "error", time.Second)
+	metrics.RecordRSSFetchError("https://summary.test/rss", "timeout")
+	metrics.RecordArticleProcessedTotal("success")
+	metrics.RecordArticleProcessedTotal("success")
+	metrics.RecordArticleProcessedTotal("failed")
+	metrics.RecordSummarizationProcessing("m", "success", "article", time.Second)
+	metrics.RecordSummarizationProcessing("m", "error", "article", time.Second)
+	metrics.UpdateCircuitBreakerState("summary-test-open", StateOpen)
+	metrics.UpdateCircuitBreakerState("summary-test-closed", StateClosed)
+	metrics.UpdateSummarizationQueueDepth(7)
+
+	rec := httptest.NewRecorder()
+	(&APIServer{metrics: metrics}).getMetricsSummary(rec, httptest.NewRequest(http.MethodGet, "/metrics/summary", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	var after MetricsSummary
+	if err := json.NewDecoder(rec.Body).Decode(&after); err != nil {
+		t.Fatal(err)
+	}
+
+	deltas := map[string][2]float64{
+		"rss_fetches_total":         {after.RSSFetches - before.RSSFetches, 2},
+		"rss_fetch_errors_total":    {after.RSSFetchErrors - before.RSSFetchErrors, 1},
+		"articles_processed_total":  {after.ArticlesProcessed - before.ArticlesProcessed, 2},
+		"articles_failed_total":     {after.ArticlesFailed - before.ArticlesFailed, 1},
+		"summaries_succeeded_total": {after.SummariesSucceeded - before.SummariesSucceeded, 1},
+		"summaries_failed_total":    {after.SummariesFailed - before.SummariesFailed, 1},
+		"circuit_breakers_open":     {after.OpenCircuitBreakers - before.OpenCircuitBreakers, 1},
+	}
+	for name, delta := range deltas {
+		if delta[0] != delta[1] {
+			t.Errorf("%s grew by %v, want %v", name, delta[0], delta[1])
+		}
+	}
+	if after.QueueDepth != 7 {
+		t.Errorf("summarization_queue_depth = %v, want 7", after.QueueDepth)
+	}
+}
@@ -7,18 +7,22 @@ import (
 	"database/sql"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"information-broker/config"
 	"io"
-	"log"
+	"log/slog"
 	"net"
 	"net/http"
 	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+	"unicode/utf8"
 
 	"github.com/PuerkitoBio/goquery"
+	"github.com/lib/pq"
 	"github.com/mmcdole/gofeed"
 )
 
@@ -28,85 +32,225 @@ type Article struct {
 	URL           string        `json:"url"`
 	PublishedAt   time.Time     `json:"published_at"`
 	Content       string        `json:"content"`
+	Preview       string        `json:"preview"`
 	FetchDuration time.Duration `json:"fetch_duration"`
 	FeedURL       string        `json:"feed_url"`
 	ContentHash   string        `json:"content_hash"`
+
+	// ContentSource says where Content came from: one of the contentSource
+	// constants.
+	ContentSource string `json:"content_source"`
+
+	// Lead is the feed's description when it is separate from Content, passed
+	// to the summarizer as the article's standfirst. It isn't stored.
+	Lead string `json:"-"`
+
+	// ContentDeferred is set when the feed's content-fetch budget ran out and
+	// Content came from the feed itself rather than the fetched page.
+	ContentDeferred bool `json:"content_deferred"`
+
+	// FeedSummary is the item's cleaned description, set for feeds with the
+	// use_feed_summary directive; it is published in place of a model summary.
+	FeedSummary string `json:"-"`
+
+	// OriginalURL is the link as the feed gave it, when normalizeURL changed
+	// it to produce URL.
+	OriginalURL string `json:"original_url,omitempty"`
+
+	// Language is the detected language code of Content (e.g. "fr"), or
+	// empty when it couldn't be told.
+	Language string `json:"language,omitempty"`
+
+	// Updated marks an already-posted article whose content the feed
+	// changed; its new summary goes out per Discord.OnArticleUpdate.
+	Updated bool `json:"-"`
+
+	// Author is the item's author name(s), comma-separated; Tags are its
+	// categories, normalized by feedItemTags.
+	Author string   `json:"author,omitempty"`
+	Tags   []string `json:"tags,omitempty"`
+
+	// RawItem is the feed item as JSON, set when Content.StoreRawItem is on.
+	RawItem []byte `json:"-"`
+	// SimHash fingerprints Content for near-duplicate lookup; 0 when
+	// Content.NearDuplicateIndex is off.
+	SimHash uint64 `json:"-"`
 }
 
+// Where an article's content came from, in order of preference: the scraped
+// page, the feed's full content element, or the feed's description.
+const (
+	contentSourceScraped     = "scraped"
+	contentSourceFeedContent = "feed_content"
+	contentSourceDescription = "description"
+)
+
 // RSSMonitor manages the monitoring of RSS feeds
 type RSSMonitor struct {
 	db              *sql.DB
-	feeds           []string
-	seenArticles    map[string]bool // URL -> bool for deduplication
+	feeds           []Feed                 // Replaced when the feeds file is reloaded; guarded by feedsMu
+	feedsMu         sync.Mutex             // Guards feeds and pollers
+	pollers         map[time.Duration]bool // Intervals with a running pollFeeds schedule
+	seenArticles    map[string]bool        // URL -> bool for deduplication
+	purgedBefore    time.Time              // Articles published before this were purged; don't re-ingest them
 	mutex           sync.RWMutex
 	fetchInterval   time.Duration
+	fetchSlots      chan struct{} // Bounds concurrent fetches across all polling schedules
+	contentSlots    chan struct{} // Bounds concurrent full-content fetches across all feeds; nil = unbounded
+	validators      *feedValidatorCache
+	fetchGuard      *feedFetchGuard
 	httpClient      *http.Client
 	parser          *gofeed.Parser
 	metrics         *PrometheusMetrics
 	config          *config.Config
 	circuitBreakers *CircuitBreakerManager
 	scheduler       *SummarizationScheduler
+	extractor       *ContentExtractor // Per-domain extraction rules; nil uses only the generic selectors
+	intervalScaler  *fetchIntervalScaler
+	events          EventPublisher // Pipeline event stream; nil drops events
+
+	// itemVersions maps article URLs to a fingerprint of the feed item they
+	// were last seen as, for spotting updates (see articleChanged). Guarded
+	// by mutex.
+	itemVersions map[string]string
+
+	// userAgentTurn picks the next of API.UserAgents (see userAgent).
+	userAgentTurn atomic.Uint64
+
+	throughput *throughputLimiter // App.MaxArticlesPerHour; nil = no cap
+	deadFeeds  *deadFeedTracker   // App.DeadFeedThreshold; disabled feeds aren't polled
+	robots     *RobotsChecker     // Content.RespectRobotsTxt; nil fetches every page
 }
 
 // NewRSSMonitor creates a new RSS monitor instance
-func NewRSSMonitor(db *sql.DB, feeds []string, metrics *PrometheusMetrics, cfg *config.Config, circuitBreakers *CircuitBreakerManager, scheduler *SummarizationScheduler) *RSSMonitor {
-	return &RSSMonitor{
+func NewRSSMonitor(db *sql.DB, feeds []Feed, metrics *PrometheusMetrics, cfg *config.Config, circuitBreakers *CircuitBreakerManager, scheduler *SummarizationScheduler, extractor *ContentExtractor) *RSSMonitor {
+	m := &RSSMonitor{
 		db:            db,
 		feeds:         feeds,
 		seenArticles:  make(map[string]bool),
+		pollers:       make(map[time.Duration]bool),
 		fetchInterval: cfg.App.RSSFetchInterval,
+		fetchSlots:    make(chan struct{}, max(cfg.Performance.MaxConcurrentFeeds, 1)),
+		contentSlots:  make(chan struct{}, max(cfg.Performance.MaxConcurrentContentFetches, 1)),
+		validators:    newFeedValidatorCache(),
+		fetchGuard:    newFeedFetchGuard(cfg.App.MinFeedRefetch),
+		throughput:    newThroughputLimiter(cfg.App.MaxArticlesPerHour),
+		deadFeeds:     newDeadFeedTracker(cfg.App.DeadFeedThreshold),
 		httpClient: &http.Client{
-			Timeout: cfg.API.Timeout,
-			Transport: &http.Transport{
-				MaxIdleConns:        100,
-				MaxIdleConnsPerHost: 5,
-				MaxConnsPerHost:     10,
-				IdleConnTimeout:     90 * time.Second,
-				DialContext: (&net.Dialer{
-					Timeout:   10 * time.Second,
-					KeepAlive: 30 * time.Second,
-				}).DialContext,
-			},
+			Timeout:   cfg.API.Timeout,
+			Transport: newFeedTransport(cfg.Performance),
 		},
 		parser:          gofeed.NewParser(),
 		metrics:         metrics,
 		config:          cfg,
 		circuitBreakers: circuitBreakers,
 		scheduler:       scheduler,
+		extractor:       extractor,
+		intervalScaler:  newFetchIntervalScaler(cfg.App.AdaptiveFetchFailureRatio, cfg.App.AdaptiveFetchMaxMultiplier),
+	}
+	// Share the scheduler's event stream, so one connection carries them
+	// all, and let it look up the directives of the feeds it requeues for
+	if scheduler != nil {
+		m.events = scheduler.events
+		scheduler.feeds = m.feed
+	}
+	if cfg.Content.RespectRobotsTxt {
+		m.robots = NewRobotsChecker(m.httpClient, cfg.API.UserAgent, cfg.Content.RobotsTxtCacheTTL)
+	}
+	return m
+}
+
+// newFeedTransport builds the transport shared by feed and article page
+// fetches. Idle connections are pooled per host across fetch cycles, so
+// feeds served from the same CDN reuse warm connections instead of
+// re-dialing and re-negotiating TLS every cycle.
+func newFeedTransport(perf config.PerformanceConfig) *http.Transport {
+	return &http.Transport{
+		Proxy:               http.ProxyFromEnvironment,
+		MaxIdleConns:        perf.FeedMaxIdleConns,
+		MaxIdleConnsPerHost: perf.FeedMaxIdleConnsPerHost,
+		MaxConnsPerHost:     perf.FeedMaxConnsPerHost,
+		IdleConnTimeout:     perf.FeedIdleConnTimeout,
+		TLSHandshakeTimeout: 10 * time.Second,
+		ForceAttemptHTTP2:   true,
+		DialContext: (&net.Dialer{
+			Timeout:   10 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
 	}
 }
 
 // Start begins monitoring RSS feeds
 func (m *RSSMonitor) Start(ctx context.Context) {
-	log.Println("Starting RSS monitor")
+	slog.Info("Starting RSS monitor")
 
 	// Load existing articles from database to populate seen articles
 	if err := m.loadExistingArticles(); err != nil {
-		log.Printf("Error loading existing articles: %v", err)
+		slog.Error("Error loading existing articles", "error", err)
+	}
+	if err := m.loadFeedState(); err != nil {
+		slog.Error("Error loading feed state", "error", err)
 	}
-
-	// Create a ticker for periodic checks
-	ticker := time.NewTicker(m.fetchInterval)
-	defer ticker.Stop()
 
 	// Initial fetch
 	m.fetchAllFeeds(ctx)
 
-	// Periodic fetching
+	// Periodic fetching: one ticker per distinct interval, so feeds with an
+	// interval override poll on their own schedule while feeds sharing an
+	// interval still go out together in priority order. All schedules draw
+	// from the same fetch slots.
+	var wg sync.WaitGroup
+	if m.config.App.AdaptiveFetchMaxMultiplier > 1 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m.adaptFetchInterval(ctx)
+		}()
+	}
+	m.startPollers(ctx, &wg)
+	if m.config.App.RSSFeedsReload > 0 && m.config.App.RSSFeedsFile != "" {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m.watchFeedsFile(ctx, &wg)
+		}()
+	}
+	wg.Wait()
+	slog.Info("RSS monitor stopping")
+}
+
+// pollFeeds fetches the feeds with the given interval every interval,
+// stretched while fetching is broadly unhealthy, until ctx is cancelled or
+// a reload of the feeds file leaves no feed on it.
+func (m *RSSMonitor) pollFeeds(ctx context.Context, interval time.Duration) {
+	current := m.intervalScaler.scale(interval)
+	ticker := time.NewTicker(current)
+	defer ticker.Stop()
+
 	for {
 		select {
 		case <-ctx.Done():
-			log.Println("RSS monitor stopping...")
 			return
 		case <-ticker.C:
-			m.fetchAllFeeds(ctx)
+			feeds, ok := m.polledFeeds(interval)
+			if !ok {
+				slog.Info("Stopped polling interval with no feeds left", "interval", interval.String())
+				return
+			}
+			dispatchFeeds(ctx, m.activeFeeds(feeds), m.fetchSlots, func(ctx context.Context, feedURL string) {
+				m.fetchFeed(ctx, feedURL)
+			})
+			if next := m.intervalScaler.scale(interval); next != current {
+				ticker.Reset(next)
+				current = next
+			}
 		}
 	}
 }
 
 // loadExistingArticles populates the seen articles map from database
 func (m *RSSMonitor) loadExistingArticles() error {
-	log.Println("Loading existing articles from database...")
+	slog.Debug("Loading existing articles from database")
 
 	rows, err := m.db.Query("SELECT url FROM articles")
 	if err != nil {
@@ -119,7 +263,7 @@ func (m *RSSMonitor) loadExistingArticles() error {
 	for rows.Next() {
 		var url string
 		if err := rows.Scan(&url); err != nil {
-			log.Printf("Error scanning article URL: %v", err)
+			slog.Warn("Error scanning article URL", "error", err)
 			continue
 		}
 		urls = append(urls, url)
@@ -132,83 +276,205 @@ func (m *RSSMonitor) loadExistingArticles() error {
 	m.mutex.Lock()
 	for _, url := range urls {
 		m.seenArticles[url] = true
+		// Rows saved before links were normalized may still carry tracking parameters
+		m.seenArticles[normalizeURL(url, m.config.Content.TrackingParams)] = true
 	}
 	m.mutex.Unlock()
 
-	log.Printf("Loaded %d existing articles for deduplication", len(urls))
+	slog.Info("Loaded existing articles for deduplication", "articles", len(urls))
 	return nil
 }
 
+// ForgetArticles drops purged articles from the deduplication set so it
+// doesn't grow without bound. Their URLs could then be ingested again if a
+// feed still lists them, so items published before cutoff are skipped from
+// now on as well.
+func (m *RSSMonitor) ForgetArticles(urls []string, cutoff time.Time) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for _, url := range urls {
+		delete(m.seenArticles, url)
+		delete(m.itemVersions, url)
+	}
+	if cutoff.After(m.purgedBefore) {
+		m.purgedBefore = cutoff
+	}
+}
+
 // fetchAllFeeds fetches all RSS feeds concurrently
 func (m *RSSMonitor) fetchAllFeeds(ctx context.Context) {
-	log.Printf("Fetching %d RSS feeds...", len(m.feeds))
+	feeds := m.Feeds()
+	slog.Debug("Fetching RSS feeds", "feeds", len(feeds))
+
+	dispatchFeeds(ctx, m.activeFeeds(feeds), m.fetchSlots, func(ctx context.Context, feedURL string) {
+		m.fetchFeed(ctx, feedURL)
+	})
 
+	slog.Debug("Completed fetching all feeds")
+}
+
+// dispatchFeeds runs fetch for every feed, holding a slot from semaphore for
+// each fetch in flight and handing slots out in priority order. Slots are
+// acquired here rather than inside each goroutine so a high-priority feed
+// can't lose the race for a slot to a low-priority one. It returns once every
+// started fetch has finished.
+func dispatchFeeds(ctx context.Context, feeds []Feed, semaphore chan struct{}, fetch func(ctx context.Context, feedURL string)) {
 	var wg sync.WaitGroup
-	semaphore := make(chan struct{}, m.config.Performance.MaxConcurrentFeeds) // Limit concurrent fetches
 
-	for _, feedURL := range m.feeds {
+dispatch:
+	for _, feed := range feedsByPriority(feeds) {
+		select {
+		case semaphore <- struct{}{}:
+		case <-ctx.Done():
+			break dispatch
+		}
+
 		wg.Add(1)
 		go func(url string) {
 			defer wg.Done()
-
-			select {
-			case semaphore <- struct{}{}:
-				defer func() { <-semaphore }()
-				m.fetchFeed(ctx, url)
-			case <-ctx.Done():
-				return
-			}
-		}(feedURL)
+			defer func() { <-semaphore }()
+			fetch(ctx, url)
+		}(feed.URL)
 	}
 
 	wg.Wait()
-	log.Println("Completed fetching all feeds")
+}
+
+// Outcomes of one fetchFeed call, as reported by FeedFetchResult.Status.
+const (
+	fetchStatusFetched     = "fetched"
+	fetchStatusSkipped     = "skipped_recent_fetch"
+	fetchStatusCircuitOpen = "circuit_breaker_open"
+	fetchStatusError       = "error"
+)
+
+// FeedFetchResult is the outcome of one fetch of a feed.
+type FeedFetchResult struct {
+	FeedURL     string `json:"feed_url"`
+	Status      string `json:"status"`
+	NewArticles int    `json:"new_articles"`
+	Error       string `json:"error,omitempty"`
 }
 
 // fetchFeed fetches and processes a single RSS feed with circuit breaker protection
-func (m *RSSMonitor) fetchFeed(ctx context.Context, feedURL string) {
+func (m *RSSMonitor) fetchFeed(ctx context.Context, feedURL string) FeedFetchResult {
 	startTime := time.Now()
+	result := FeedFetchResult{FeedURL: feedURL, Status: fetchStatusFetched}
+
+	// Every trigger funnels through here, so this is where redundant
+	// back-to-back fetches of the same feed get dropped
+	if !m.fetchGuard.claim(feedURL, startTime) {
+		slog.Debug("Skipping feed fetched recently", "feed_url", feedURL, "min_refetch", m.config.App.MinFeedRefetch.String())
+		result.Status = fetchStatusSkipped
+		return result
+	}
 
-	log.Printf("Fetching feed: %s", feedURL)
+	slog.Debug("Fetching feed", "feed_url", feedURL)
 
 	// Get or create circuit breaker for this feed
 	cb := m.circuitBreakers.GetOrCreateBreaker("rss_feed_"+feedURL, &CircuitBreakerConfig{
-		FailureThreshold: 3,
-		SuccessThreshold: 2,
-		Timeout:          time.Minute * 2,
-		ResetTimeout:     time.Minute * 5,
+		FailureThreshold:      3,
+		SuccessThreshold:      2,
+		Timeout:               time.Minute * 2,
+		ResetTimeout:          time.Minute * 5,
+		HalfOpenMaxConcurrent: 1,
 	})
 
 	// Execute feed fetch with circuit breaker protection
 	err := cb.Execute(func() error {
-		return m.doFetchFeed(ctx, feedURL, startTime)
+		return m.fetchFeedWithRetry(ctx, feedURL, func(lastAttempt bool) error {
+			var err error
+			result.NewArticles, err = m.doFetchFeed(ctx, feedURL, startTime, lastAttempt)
+			return err
+		})
 	}, m.metrics)
 
 	if err != nil {
+		result.Status, result.Error = fetchStatusError, err.Error()
 		if err == ErrCircuitBreakerOpen {
+			result.Status = fetchStatusCircuitOpen
 			duration := time.Since(startTime)
 			m.logFetch(feedURL, "error", "Circuit breaker is open", duration, 0, 0)
 			m.metrics.RecordRSSFetch(feedURL, "circuit_breaker_open", duration)
 			m.metrics.RecordRSSFetchError(feedURL, "circuit_breaker_open")
 		}
 		// Other errors are already handled in doFetchFeed
+		publishEvent(m.events, PipelineEvent{Type: eventFetchFailed, FeedURL: feedURL, Error: err.Error()})
 	}
+	m.trackFeedHealth(feedURL, err)
+	return result
+}
+
+// transientFetchError marks a feed fetch failure that may well succeed if
+// the fetch is repeated straight away, such as a truncated body or a 5xx
+// from a server under load.
+type transientFetchError struct {
+	err error
+}
+
+func (e *transientFetchError) Error() string { return e.err.Error() }
+func (e *transientFetchError) Unwrap() error { return e.err }
+
+// isTransientFetchError reports whether err is worth an immediate retry.
+func isTransientFetchError(err error) bool {
+	var transient *transientFetchError
+	return errors.As(err, &transient)
 }
 
-// doFetchFeed performs the actual feed fetching logic
-func (m *RSSMonitor) doFetchFeed(ctx context.Context, feedURL string, startTime time.Time) error {
+// fetchFeedWithRetry calls fetch, then retries it up to FeedFetchRetries
+// times while it fails with a transient error, so only a failure that
+// survives the retries reaches the circuit breaker. Permanent errors (a 404,
+// say) are returned at once. fetch is told whether it is the last attempt,
+// so it records a transient failure only when no retry follows.
+func (m *RSSMonitor) fetchFeedWithRetry(ctx context.Context, feedURL string, fetch func(lastAttempt bool) error) error {
+	retries := m.config.App.FeedFetchRetries
+	err := fetch(retries == 0)
+	for attempt := 1; attempt <= retries && isTransientFetchError(err); attempt++ {
+		backoff := m.config.App.FeedFetchRetryBackoff << (attempt - 1)
+		slog.Warn("Transient feed fetch failure, retrying", "feed_url", feedURL, "attempt", attempt, "retries", retries, "backoff", backoff.String(), "error", err)
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		err = fetch(attempt == retries)
+	}
+	return err
+}
+
+// recordFetchFailure logs a failed fetch to fetch_logs and counts it in the
+// metrics, unless err is transient and another attempt follows: only the
+// outcome of the last attempt is recorded, so a feed that recovers on a
+// retry doesn't show up as failing in the SLA and fetch logs.
+func (m *RSSMonitor) recordFetchFailure(feedURL, message, reason string, startTime time.Time, err error, lastAttempt bool) {
+	if !lastAttempt && isTransientFetchError(err) {
+		return
+	}
+	duration := time.Since(startTime)
+	m.logFetch(feedURL, "error", message, duration, 0, 0)
+	m.metrics.RecordRSSFetch(feedURL, "error", duration)
+	m.metrics.RecordRSSFetchError(feedURL, reason)
+}
+
+// doFetchFeed performs the actual feed fetching logic and returns how many
+// new articles it found. Failures that may clear up on an immediate retry
+// are returned as *transientFetchError, and recorded only on the last
+// attempt.
+func (m *RSSMonitor) doFetchFeed(ctx context.Context, feedURL string, startTime time.Time, lastAttempt bool) (int, error) {
 	// Create request with context
-	req, err := http.NewRequestWithContext(ctx, "GET", feedURL, nil)
+	req, err := http.NewRequestWithContext(ctx, "GET", m.feed(feedURL).requestURL(), nil)
 	if err != nil {
-		duration := time.Since(startTime)
-		m.logFetch(feedURL, "error", fmt.Sprintf("Failed to create request: %v", err), duration, 0, 0)
-		m.metrics.RecordRSSFetch(feedURL, "error", duration)
-		m.metrics.RecordRSSFetchError(feedURL, "request_creation_failed")
-		return err
+		m.recordFetchFailure(feedURL, fmt.Sprintf("Failed to create request: %v", err), "request_creation_failed", startTime, err, lastAttempt)
+		return 0, err
 	}
 
 	// Set user agent
-	req.Header.Set("User-Agent", m.config.API.UserAgent)
+	req.Header.Set("User-Agent", m.userAgent())
+
+	// Make the request conditional if the server gave us validators last time
+	m.validators.apply(req, feedURL)
 
 	// Fetch the feed
 	resp, err := m.httpClient.Do(req)
@@ -222,13 +488,21 @@ func (m *RSSMonitor) doFetchFeed(ctx context.Context, feedURL string, startTime
 		if m.config.FlareSolverr.URL != "" && ctx.Err() == nil {
 			return m.fetchViaFlareSolverr(ctx, feedURL, startTime)
 		}
+		if ctx.Err() == nil {
+			err = &transientFetchError{err}
+		}
+		m.recordFetchFailure(feedURL, fmt.Sprintf("Failed to fetch feed: %v", err), "http_request_failed", startTime, err, lastAttempt)
+		return 0, err
+	}
+	defer drainAndClose(resp.Body)
+
+	// Unchanged since the last fetch: nothing to parse
+	if resp.StatusCode == http.StatusNotModified {
 		duration := time.Since(startTime)
-		m.logFetch(feedURL, "error", fmt.Sprintf("Failed to fetch feed: %v", err), duration, 0, 0)
-		m.metrics.RecordRSSFetch(feedURL, "error", duration)
-		m.metrics.RecordRSSFetchError(feedURL, "http_request_failed")
-		return err
+		m.logFetch(feedURL, "not_modified", "", duration, 0, 0)
+		m.metrics.RecordRSSFetch(feedURL, "not_modified", duration)
+		return 0, nil
 	}
-	defer resp.Body.Close()
 
 	if resp.StatusCode != http.StatusOK {
 		// Cloudflare and similar WAFs answer with a challenge status (403, and
@@ -238,24 +512,36 @@ func (m *RSSMonitor) doFetchFeed(ctx context.Context, feedURL string, startTime
 		if m.config.FlareSolverr.URL != "" && isChallengeStatus(resp.StatusCode) {
 			return m.fetchViaFlareSolverr(ctx, feedURL, startTime)
 		}
-		duration := time.Since(startTime)
 		err := fmt.Errorf("HTTP %d", resp.StatusCode)
-		m.logFetch(feedURL, "error", err.Error(), duration, 0, 0)
-		m.metrics.RecordRSSFetch(feedURL, "error", duration)
-		m.metrics.RecordRSSFetchError(feedURL, "http_error")
-		return err
+		if resp.StatusCode >= 500 {
+			err = &transientFetchError{err}
+		}
+		m.recordFetchFailure(feedURL, err.Error(), "http_error", startTime, err, lastAttempt)
+		return 0, err
 	}
 
-	// Parse the feed
-	feed, err := m.parser.Parse(resp.Body)
+	// Parse the feed, reading no more than MaxResponseBytes of it
+	body := newCappedReader(resp.Body, m.config.Performance.MaxResponseBytes)
+	raw, err := io.ReadAll(body)
+	var feed *gofeed.Feed
+	if err == nil {
+		feed, err = m.parseFeed(feedURL, raw)
+	}
+	if body.truncated {
+		slog.Warn("Feed is larger than the response limit; parsing only the start of it", "feed_url", feedURL, "max_bytes", m.config.Performance.MaxResponseBytes)
+	}
 	if err != nil {
-		duration := time.Since(startTime)
-		m.logFetch(feedURL, "error", fmt.Sprintf("Failed to parse feed: %v", err), duration, 0, 0)
-		m.metrics.RecordRSSFetch(feedURL, "error", duration)
-		m.metrics.RecordRSSFetchError(feedURL, "parse_failed")
-		return err
+		if ctx.Err() == nil {
+			err = &transientFetchError{err}
+		}
+		m.recordFetchFailure(feedURL, fmt.Sprintf("Failed to parse feed: %v", err), "parse_failed", startTime, err, lastAttempt)
+		return 0, err
 	}
 
+	// Only remember validators for a body we could parse, so a broken
+	// response isn't pinned in place by 304s
+	m.validators.store(feedURL, resp)
+
 	return m.processFeedItems(ctx, feedURL, feed, startTime)
 }
 
@@ -273,9 +559,10 @@ func isChallengeStatus(code int) bool {
 	return code >= 520 && code <= 527
 }
 
-// processFeedItems sorts and processes a parsed feed's items and records success
-// metrics. It is shared by the direct fetch path and the FlareSolverr fallback.
-func (m *RSSMonitor) processFeedItems(ctx context.Context, feedURL string, feed *gofeed.Feed, startTime time.Time) error {
+// processFeedItems sorts and processes a parsed feed's items, records success
+// metrics and returns how many new articles it saved. It is shared by the
+// direct fetch path and the FlareSolverr fallback.
+func (m *RSSMonitor) processFeedItems(ctx context.Context, feedURL string, feed *gofeed.Feed, startTime time.Time) (int, error) {
 	// Process articles
 	newArticles := 0
 	totalArticles := len(feed.Items)
@@ -312,16 +599,22 @@ func (m *RSSMonitor) processFeedItems(ctx context.Context, feedURL string, feed
 		return timeI.Before(timeJ)
 	})
 
+	budget := newContentBudget(m.config.Performance.PerFeedContentBudget)
 	for _, item := range sortedItems {
 		if ctx.Err() != nil {
-			return ctx.Err() // Context cancelled
+			return newArticles, ctx.Err() // Context cancelled
 		}
 
-		if m.processArticle(item, feedURL) {
+		if m.processArticle(item, feedURL, budget) {
 			newArticles++
 		}
 	}
 
+	if budget.deferred > 0 {
+		slog.Warn("Content-fetch budget exhausted; articles saved with description for later re-fetch",
+			"feed_url", feedURL, "budget", budget.limit.String(), "deferred", budget.deferred)
+	}
+
 	duration := time.Since(startTime)
 	m.logFetch(feedURL, "success", "", duration, totalArticles, newArticles)
 
@@ -330,10 +623,10 @@ func (m *RSSMonitor) processFeedItems(ctx context.Context, feedURL string, feed
 	m.metrics.RecordNewArticles(feedURL, newArticles)
 
 	if newArticles > 0 {
-		log.Printf("Feed %s: Found %d new articles out of %d total", feedURL, newArticles, totalArticles)
+		slog.Info("Found new articles", "feed_url", feedURL, "new", newArticles, "total", totalArticles)
 	}
 
-	return nil
+	return newArticles, nil
 }
 
 // flareSolverrResponse models the subset of the FlareSolverr v1 API response we use.
@@ -349,8 +642,8 @@ type flareSolverrResponse struct {
 // fetchViaFlareSolverr retries a blocked feed through a FlareSolverr instance,
 // which uses a headless browser to pass Cloudflare/WAF challenges. The browser
 // returns a rendered DOM, so the raw feed XML is extracted before parsing.
-func (m *RSSMonitor) fetchViaFlareSolverr(ctx context.Context, feedURL string, startTime time.Time) error {
-	log.Printf("Feed %s: HTTP 403, retrying via FlareSolverr", feedURL)
+func (m *RSSMonitor) fetchViaFlareSolverr(ctx context.Context, feedURL string, startTime time.Time) (int, error) {
+	slog.Info("Feed answered HTTP 403, retrying via FlareSolverr", "feed_url", feedURL)
 
 	payload, err := json.Marshal(map[string]interface{}{
 		"cmd":        "request.get",
@@ -358,7 +651,7 @@ func (m *RSSMonitor) fetchViaFlareSolverr(ctx context.Context, feedURL string, s
 		"maxTimeout": int(m.config.FlareSolverr.Timeout / time.Millisecond),
 	})
 	if err != nil {
-		return m.flareError(feedURL, startTime, fmt.Sprintf("marshal request: %v", err))
+		return 0, m.flareError(feedURL, startTime, fmt.Sprintf("marshal request: %v", err))
 	}
 
 	// Allow the solver its full maxTimeout plus headroom for browser startup.
@@ -367,39 +660,39 @@ func (m *RSSMonitor) fetchViaFlareSolverr(ctx context.Context, feedURL string, s
 
 	req, err := http.NewRequestWithContext(reqCtx, "POST", m.config.FlareSolverr.URL, bytes.NewReader(payload))
 	if err != nil {
-		return m.flareError(feedURL, startTime, fmt.Sprintf("create request: %v", err))
+		return 0, m.flareError(feedURL, startTime, fmt.Sprintf("create request: %v", err))
 	}
 	req.Header.Set("Content-Type", "application/json")
 
 	client := &http.Client{Timeout: m.config.FlareSolverr.Timeout + 30*time.Second}
 	resp, err := client.Do(req)
 	if err != nil {
-		return m.flareError(feedURL, startTime, fmt.Sprintf("request failed: %v", err))
+		return 0, m.flareError(feedURL, startTime, fmt.Sprintf("request failed: %v", err))
 	}
 	defer resp.Body.Close()
 
 	raw, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return m.flareError(feedURL, startTime, fmt.Sprintf("read response: %v", err))
+		return 0, m.flareError(feedURL, startTime, fmt.Sprintf("read response: %v", err))
 	}
 
 	var fsResp flareSolverrResponse
 	if err := json.Unmarshal(raw, &fsResp); err != nil {
-		return m.flareError(feedURL, startTime, fmt.Sprintf("decode response: %v", err))
+		return 0, m.flareError(feedURL, startTime, fmt.Sprintf("decode response: %v", err))
 	}
 	if fsResp.Status != "ok" {
-		return m.flareError(feedURL, startTime, fmt.Sprintf("solver status %q: %s", fsResp.Status, fsResp.Message))
+		return 0, m.flareError(feedURL, startTime, fmt.Sprintf("solver status %q: %s", fsResp.Status, fsResp.Message))
 	}
 	if fsResp.Solution.Status != http.StatusOK {
-		return m.flareError(feedURL, startTime, fmt.Sprintf("solved with HTTP %d", fsResp.Solution.Status))
+		return 0, m.flareError(feedURL, startTime, fmt.Sprintf("solved with HTTP %d", fsResp.Solution.Status))
 	}
 
-	feed, err := m.parser.ParseString(extractFeedXML(fsResp.Solution.Response))
+	feed, err := m.parseFeed(feedURL, []byte(extractFeedXML(fsResp.Solution.Response)))
 	if err != nil {
-		return m.flareError(feedURL, startTime, fmt.Sprintf("parse solved feed: %v", err))
+		return 0, m.flareError(feedURL, startTime, fmt.Sprintf("parse solved feed: %v", err))
 	}
 
-	log.Printf("Feed %s: solved via FlareSolverr (%d items)", feedURL, len(feed.Items))
+	slog.Info("Feed solved via FlareSolverr", "feed_url", feedURL, "items", len(feed.Items))
 	return m.processFeedItems(ctx, feedURL, feed, startTime)
 }
 
@@ -453,88 +746,144 @@ func extractFeedXML(s string) string {
 	return trimmed
 }
 
-// processArticle processes a single article from an RSS feed
-func (m *RSSMonitor) processArticle(item *gofeed.Item, feedURL string) bool {
-	if item.Link == "" {
-		m.metrics.RecordArticleProcessed(feedURL, "skipped_no_link")
-		return false
+// articleDateDropReason returns the item's publish date in UTC and, when
+// its date keeps it out, the drop reason: dropNoPublishDate without one,
+// dropBeforeCutoff before App.ArticleCutoffDate, dropBeforeInitiation before
+// App.InitiationDate. Dates compare as instants, whatever their time zone.
+func articleDateDropReason(item *gofeed.Item, app config.AppConfig) (time.Time, string) {
+	if item.PublishedParsed == nil {
+		return time.Time{}, dropNoPublishDate
 	}
-
-	// Parse and normalize the publish date to UTC
-	var publishDate time.Time
-	if item.PublishedParsed != nil {
-		publishDate = item.PublishedParsed.UTC()
-	} else {
-		// If no publish date is available, skip the article as per requirements
-		log.Printf("Skipping article with missing publish date: %s", item.Title)
-		m.metrics.RecordArticleProcessed(feedURL, "skipped_no_publish_date")
-		return false
+	publishDate := item.PublishedParsed.UTC()
+	if publishDate.Before(app.ArticleCutoffDate.UTC()) {
+		return publishDate, dropBeforeCutoff
+	}
+	if publishDate.Before(app.InitiationDate) {
+		return publishDate, dropBeforeInitiation
 	}
+	return publishDate, ""
+}
 
-	// Check publication date against the cutoff date — skip silently (metrics track these)
-	cutoffDate := m.config.App.ArticleCutoffDate.UTC()
-	if publishDate.Before(cutoffDate) {
-		m.metrics.RecordArticleFilteredPreCutoff(feedURL)
-		m.metrics.RecordArticleProcessed(feedURL, "skipped_before_cutoff")
+// processArticle processes a single article from an RSS feed
+func (m *RSSMonitor) processArticle(item *gofeed.Item, feedURL string, budget *contentBudget) bool {
+	if item.Link == "" {
+		m.metrics.RecordArticleProcessed(feedURL, "skipped_no_link")
+		m.metrics.RecordArticleDropped(feedURL, dropNoLink)
 		return false
 	}
 
-	// Check publication date against initiation date
-	if publishDate.Before(m.config.App.InitiationDate) {
-		m.metrics.RecordArticleProcessed(feedURL, "skipped_before_initiation")
+	// Skip articles without a publish date or published too long ago —
+	// silently, metrics track these
+	publishDate, dateDrop := articleDateDropReason(item, m.config.App)
+	if dateDrop != "" {
+		switch dateDrop {
+		case dropNoPublishDate:
+			slog.Debug("Skipping article with missing publish date", "feed_url", feedURL, "title", item.Title)
+		case dropBeforeCutoff:
+			m.metrics.RecordArticleFilteredPreCutoff(feedURL)
+		}
+		m.metrics.RecordArticleProcessed(feedURL, "skipped_"+dateDrop)
+		m.metrics.RecordArticleDropped(feedURL, dateDrop)
 		return false
 	}
 
 	// Article passed the cutoff date filter
 	m.metrics.RecordArticleProcessedPostCutoff(feedURL)
 
+	// Deduplicate and store on the normalized link, so tracking-parameter
+	// variants of one article don't become separate rows
+	articleURL := normalizeURL(item.Link, m.config.Content.TrackingParams)
+
 	// Check-and-set under write lock to prevent concurrent goroutines
 	// from processing the same URL simultaneously
 	m.mutex.Lock()
-	if m.seenArticles[item.Link] {
+	if publishDate.Before(m.purgedBefore) {
 		m.mutex.Unlock()
-		m.metrics.RecordArticleProcessed(feedURL, "skipped_duplicate")
+		m.metrics.RecordArticleProcessed(feedURL, "skipped_purged")
+		m.metrics.RecordArticleDropped(feedURL, dropPurged)
+		return false
+	}
+	if m.seenArticles[articleURL] {
+		m.mutex.Unlock()
+		status := "skipped_duplicate"
+		if m.articleChanged(item, articleURL) && m.processArticleUpdate(item, articleURL, feedURL, budget) {
+			status = "updated"
+		} else {
+			m.metrics.RecordArticleDropped(feedURL, dropDuplicate)
+		}
+		m.metrics.RecordArticleProcessed(feedURL, status)
 		return false // Already processed
 	}
+	if ok, reached := m.throughput.allow(); !ok {
+		// Left unseen, so a fetch in a later hour picks it up. That fetch
+		// must not be answered with a 304 for the response just parsed, so
+		// the validators stored for it are dropped.
+		m.mutex.Unlock()
+		m.validators.forget(feedURL)
+		if reached {
+			slog.Warn("Reached MAX_ARTICLES_PER_HOUR; leaving new articles for later fetches", "max_articles_per_hour", m.config.App.MaxArticlesPerHour)
+		}
+		m.metrics.RecordArticleDeferredThroughput(feedURL)
+		m.metrics.RecordArticleProcessed(feedURL, "deferred_throughput")
+		return false
+	}
 	// Mark as seen immediately to prevent duplicate processing by concurrent goroutines
-	m.seenArticles[item.Link] = true
+	m.seenArticles[articleURL] = true
 	m.mutex.Unlock()
+	m.articleChanged(item, articleURL) // The baseline later versions are compared to
 
-	// Fetch full content with context for graceful shutdown
-	fetchCtx, fetchCancel := context.WithTimeout(context.Background(), m.config.API.Timeout)
-	defer fetchCancel()
-	startTime := time.Now()
-	content, err := m.fetchFullContent(fetchCtx, item.Link)
-	fetchDuration := time.Since(startTime)
-
-	if err != nil {
-		log.Printf("Failed to fetch content for %s: %v", item.Link, err)
-		content = item.Description // Fallback to description
-	}
+	content, source, fetchDuration, deferred := m.loadArticleContent(item, feedURL, budget)
 
 	// Create article struct
 	article := Article{
-		Title:         item.Title,
-		URL:           item.Link,
-		Content:       content,
-		FetchDuration: fetchDuration,
-		FeedURL:       feedURL,
+		Title:           item.Title,
+		URL:             articleURL,
+		Content:         content,
+		ContentSource:   source,
+		Lead:            articleLead(item, content, source),
+		Preview:         buildArticlePreview(content, m.config.Content.PreviewLength),
+		FetchDuration:   fetchDuration,
+		FeedURL:         feedURL,
+		ContentDeferred: deferred,
+		Author:          feedItemAuthor(item),
+		Tags:            feedItemTags(item),
 	}
 
 	// Set published time (we already validated it exists above)
 	article.PublishedAt = publishDate
 
+	article.FeedSummary = m.feedSummary(item, feedURL)
+
+	// Keep the link as the feed gave it in case normalizing broke it
+	if item.Link != articleURL {
+		article.OriginalURL = item.Link
+	}
+
+	article.Language, _ = detectLanguage(article.Content)
+
+	if m.config.Content.StoreRawItem {
+		raw, err := json.Marshal(item)
+		if err != nil {
+			slog.Warn("Failed to encode feed item", "article_url", articleURL, "error", err)
+		}
+		article.RawItem = raw
+	}
+
 	// Generate content hash for deduplication
 	article.ContentHash = m.generateContentHash(article.Title, article.URL, article.Content)
+	if m.config.Content.NearDuplicateIndex {
+		article.SimHash = contentSimHash(article.Content)
+	}
 
 	// Save to database
 	if err := m.saveArticle(article); err != nil {
-		log.Printf("Failed to save article %s: %v", article.URL, err)
+		slog.Error("Failed to save article", "feed_url", feedURL, "article_url", article.URL, "error", err)
 		m.metrics.RecordArticleProcessed(feedURL, "save_failed")
+		m.metrics.RecordArticleDropped(feedURL, dropSaveFailed)
 		m.metrics.RecordArticleProcessedTotal("failed")
 		// Unmark on failure so it can be retried next cycle
 		m.mutex.Lock()
-		delete(m.seenArticles, item.Link)
+		delete(m.seenArticles, articleURL)
 		m.mutex.Unlock()
 		return false
 	}
@@ -543,7 +892,13 @@ func (m *RSSMonitor) processArticle(item *gofeed.Item, feedURL string) bool {
 	m.metrics.RecordArticleProcessed(feedURL, "processed")
 	m.metrics.RecordArticleProcessedTotal("success")
 
-	log.Printf("New article saved: %s", article.Title)
+	slog.Info("New article saved", "feed_url", feedURL, "article_url", article.URL, "title", article.Title)
+	publishEvent(m.events, PipelineEvent{
+		Type:       eventArticleIngested,
+		ArticleURL: article.URL,
+		Title:      article.Title,
+		FeedURL:    feedURL,
+	})
 
 	// Try to generate summary for the new article
 	go m.generateSummaryAsync(article)
@@ -551,6 +906,83 @@ func (m *RSSMonitor) processArticle(item *gofeed.Item, feedURL string) bool {
 	return true
 }
 
+// loadArticleContent fetches an item's full content within what is left of
+// the feed's content budget, falling back to what the feed itself carries on
+// failure. deferred reports that the budget, not the page, is why the
+// fallback was used, so the article should be re-fetched later.
+func (m *RSSMonitor) loadArticleContent(item *gofeed.Item, feedURL string, budget *contentBudget) (content, source string, fetchDuration time.Duration, deferred bool) {
+	if content, ok := m.sufficientFeedContent(item); ok {
+		m.metrics.RecordContentFetchSkipped(feedURL)
+		return content, contentSourceFeedContent, 0, false
+	}
+
+	timeout, ok := budget.fetchTimeout(m.config.API.Timeout)
+	if !ok {
+		budget.deferred++
+		content, source = feedItemContent(item)
+		return content, source, 0, true
+	}
+
+	// Fetch full content with context for graceful shutdown
+	fetchCtx, fetchCancel := context.WithTimeout(context.Background(), timeout)
+	defer fetchCancel()
+	if m.robots != nil && !m.robots.Allowed(fetchCtx, item.Link) {
+		slog.Info("robots.txt disallows the article, using the feed's content", "feed_url", feedURL, "article_url", item.Link)
+		content, source = feedItemContent(item)
+		return content, source, 0, false
+	}
+	startTime := time.Now()
+	content, err := m.fetchFullContent(fetchCtx, item.Link, feedURL)
+	fetchDuration = time.Since(startTime)
+	budget.charge(fetchDuration)
+
+	if err != nil {
+		slog.Warn("Failed to fetch article content", "feed_url", feedURL, "article_url", item.Link, "error", err)
+		content, source = feedItemContent(item) // Fallback to the feed's own content
+		outcome := "fallback"
+		if strings.TrimSpace(content) == "" {
+			outcome = "error"
+		}
+		m.metrics.RecordContentFetch(feedURL, outcome, fetchDuration)
+		// A fetch cut short by the budget deserves another try later
+		deferred = errors.Is(err, context.DeadlineExceeded) && timeout < m.config.API.Timeout
+		if deferred {
+			budget.deferred++
+		}
+		return content, source, fetchDuration, deferred
+	}
+	m.metrics.RecordContentFetch(feedURL, "success", fetchDuration)
+	return content, contentSourceScraped, fetchDuration, false
+}
+
+// sufficientFeedContent returns the plain text of the item's full content
+// element (RSS content:encoded, Atom <content>, JSON Feed content_html or
+// content_text) when it is at least Content.FeedContentMinLength characters,
+// so the article page needn't be fetched. A threshold of 0 always fetches.
+func (m *RSSMonitor) sufficientFeedContent(item *gofeed.Item) (string, bool) {
+	minLength := m.config.Content.FeedContentMinLength
+	if minLength <= 0 || strings.TrimSpace(item.Content) == "" {
+		return "", false
+	}
+	text := plainText(item.Content)
+	if utf8.RuneCountInString(text) < minLength {
+		return "", false
+	}
+	if len(text) > m.config.Performance.MaxArticleContentLength {
+		text = safeTruncate(text, m.config.Performance.MaxArticleContentLength) + "..."
+	}
+	return text, true
+}
+
+// feedItemContent returns the best content a feed item carries on its own:
+// its full content element if present, otherwise its description.
+func feedItemContent(item *gofeed.Item) (content, source string) {
+	if strings.TrimSpace(item.Content) != "" {
+		return item.Content, contentSourceFeedContent
+	}
+	return item.Description, contentSourceDescription
+}
+
 // extractMainContent picks the best-matching element's text from a page.
 // Pages that include "related posts"/"latest articles" widgets often have
 // several elements matching a content-area selector (e.g. multiple <article>
@@ -569,21 +1001,7 @@ func (m *RSSMonitor) processArticle(item *gofeed.Item, feedURL string) bool {
 // in header/nav chrome (a search-box placeholder, a "Pricing" nav link)
 // ahead of the real per-page content.
 func extractMainContent(doc *goquery.Document) string {
-	// goquery's .Text() returns the raw source text of <script>/<style>
-	// elements too (they're unrendered but still DOM text nodes) — strip
-	// them first so CSS rules or JS don't leak into the stored article text.
-	doc.Find("script, style").Remove()
-
-	// Strip known non-article widgets before measuring/using any selector's
-	// text — e.g. "#ar-widget" is a common "Listen to this article"
-	// audio-player plugin embedded inside the real content container, whose
-	// own playback/voice-selector controls would otherwise be measured (and
-	// stored) as if they were article prose.
-	doc.Find("#ar-widget").Remove()
-
-	// theregister.com labels every ad slot with <span class="ad-label">REG AD</span>;
-	// strip them so ad-slot labels don't pollute the extracted article text.
-	doc.Find(".ad-label").Remove()
+	stripNonArticleElements(doc)
 
 	// Precise, high-confidence article-body selectors. Within this tier the
 	// longest match wins (a real post body dwarfs a related-post teaser card;
@@ -634,32 +1052,68 @@ func extractMainContent(doc *goquery.Document) string {
 	return content
 }
 
-// fetchFullContent attempts to fetch the full content of an article
-func (m *RSSMonitor) fetchFullContent(ctx context.Context, url string) (string, error) {
+// stripNonArticleElements removes elements whose text is never article
+// prose, before any selector's text is measured or used.
+func stripNonArticleElements(doc *goquery.Document) {
+	// goquery's .Text() returns the raw source text of <script>/<style>
+	// elements too (they're unrendered but still DOM text nodes) — strip
+	// them first so CSS rules or JS don't leak into the stored article text.
+	doc.Find("script, style").Remove()
+
+	// Strip known non-article widgets before measuring/using any selector's
+	// text — e.g. "#ar-widget" is a common "Listen to this article"
+	// audio-player plugin embedded inside the real content container, whose
+	// own playback/voice-selector controls would otherwise be measured (and
+	// stored) as if they were article prose.
+	doc.Find("#ar-widget").Remove()
+
+	// theregister.com labels every ad slot with <span class="ad-label">REG AD</span>;
+	// strip them so ad-slot labels don't pollute the extracted article text.
+	doc.Find(".ad-label").Remove()
+}
+
+// fetchFullContent attempts to fetch the full content of an article,
+// extracted by the domain rules for the article or its feed if there are any
+func (m *RSSMonitor) fetchFullContent(ctx context.Context, url, feedURL string) (string, error) {
+	// Wait for a global content-fetch slot so concurrent page bodies stay bounded
+	if m.contentSlots != nil {
+		select {
+		case m.contentSlots <- struct{}{}:
+			defer func() { <-m.contentSlots }()
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return "", err
 	}
 
-	req.Header.Set("User-Agent", m.config.API.UserAgent)
+	req.Header.Set("User-Agent", m.userAgent())
 
 	resp, err := m.httpClient.Do(req)
 	if err != nil {
 		return "", err
 	}
-	defer resp.Body.Close()
+	defer drainAndClose(resp.Body)
 
 	if resp.StatusCode != http.StatusOK {
 		return "", fmt.Errorf("HTTP %d", resp.StatusCode)
 	}
 
-	// Parse HTML and extract text content
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	// Parse HTML and extract text content. Oversized pages are cut off at
+	// MaxRawContentBytes; the article text is almost always near the top.
+	body := newCappedReader(resp.Body, m.config.Performance.MaxRawContentBytes)
+	doc, err := goquery.NewDocumentFromReader(body)
 	if err != nil {
 		return "", err
 	}
+	if body.truncated {
+		slog.Warn("Article page is larger than the content limit; using only the start of it", "article_url", url, "max_bytes", m.config.Performance.MaxRawContentBytes)
+	}
 
-	content := strings.TrimSpace(extractMainContent(doc))
+	content := strings.TrimSpace(m.extractor.Extract(doc, url, feedURL))
 	if len(content) > m.config.Performance.MaxArticleContentLength { // Limit content length
 		// Truncate on a rune boundary; byte-slicing can split a multi-byte
 		// character and leave invalid UTF-8 that PostgreSQL rejects on save.
@@ -681,9 +1135,10 @@ func (m *RSSMonitor) generateContentHash(title, url, content string) string {
 // saveArticle saves an article to the database
 func (m *RSSMonitor) saveArticle(article Article) error {
 	query := `
-		INSERT INTO articles (title, url, full_content, publish_date, fetch_duration_ms, feed_url, content_hash, fetch_time, posted_to_discord)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, NOW(), FALSE)
+		INSERT INTO articles (title, url, full_content, preview, publish_date, fetch_duration_ms, feed_url, content_hash, needs_content_refetch, original_url, language, raw_item, simhash, simhash_bands, author, tags, fetch_time, posted_to_discord)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, NULLIF($10, ''), NULLIF($11, ''), NULLIF($12, '')::jsonb, $13, $14, NULLIF($15, ''), $16, NOW(), FALSE)
 		ON CONFLICT (url) DO NOTHING`
+	simHash, simHashBands := simHashColumns(article.SimHash)
 
 	// Strip any invalid UTF-8 before insert: a single bad byte makes PostgreSQL
 	// reject the whole row ("invalid byte sequence for encoding UTF8"), silently
@@ -692,10 +1147,19 @@ func (m *RSSMonitor) saveArticle(article Article) error {
 		sanitizeUTF8(article.Title),
 		sanitizeUTF8(article.URL),
 		sanitizeUTF8(article.Content),
+		sanitizeUTF8(article.Preview),
 		article.PublishedAt,
 		article.FetchDuration.Milliseconds(),
 		sanitizeUTF8(article.FeedURL),
 		article.ContentHash,
+		article.ContentDeferred,
+		sanitizeUTF8(article.OriginalURL),
+		article.Language,
+		string(article.RawItem),
+		simHash,
+		simHashBands,
+		sanitizeUTF8(article.Author),
+		pq.StringArray(sanitizeTags(article.Tags)),
 	)
 
 	return err
@@ -704,14 +1168,15 @@ func (m *RSSMonitor) saveArticle(article Article) error {
 // logFetch logs fetch operations to database and stdout
 func (m *RSSMonitor) logFetch(feedURL, status, message string, duration time.Duration, articlesFound, newArticles int) {
 	// Log to stdout
-	logMsg := fmt.Sprintf("Feed: %s | Status: %s | Duration: %v | Articles: %d | New: %d",
-		feedURL, status, duration, articlesFound, newArticles)
-
+	attrs := []any{"feed_url", feedURL, "status", status, "duration_ms", duration.Milliseconds(), "articles", articlesFound, "new", newArticles}
 	if message != "" {
-		logMsg += fmt.Sprintf(" | Message: %s", message)
+		attrs = append(attrs, "message", message)
+	}
+	if status == "error" {
+		slog.Warn("Feed fetched", attrs...)
+	} else {
+		slog.Info("Feed fetched", attrs...)
 	}
-
-	log.Println(logMsg)
 
 	// Log to database
 	query := `
@@ -720,45 +1185,115 @@ func (m *RSSMonitor) logFetch(feedURL, status, message string, duration time.Dur
 
 	_, err := m.db.Exec(query, feedURL, status, message, duration.Milliseconds(), articlesFound, newArticles)
 	if err != nil {
-		log.Printf("Failed to log fetch to database: %v", err)
+		slog.Error("Failed to log fetch to database", "feed_url", feedURL, "error", err)
+	}
+}
+
+// Feeds returns the monitored feeds, in feeds file order.
+func (m *RSSMonitor) Feeds() []Feed {
+	m.feedsMu.Lock()
+	defer m.feedsMu.Unlock()
+	return append([]Feed(nil), m.feeds...)
+}
+
+// userAgent returns the User-Agent for the next feed or article request:
+// API.UserAgents in turn, or API.UserAgent when there are none.
+func (m *RSSMonitor) userAgent() string {
+	agents := m.config.API.UserAgents
+	if len(agents) == 0 {
+		return m.config.API.UserAgent
+	}
+	turn := m.userAgentTurn.Add(1) - 1
+	return agents[turn%uint64(len(agents))]
+}
+
+// feed returns the configured feed with the given URL, or a Feed with no
+// directives if there is none.
+func (m *RSSMonitor) feed(feedURL string) Feed {
+	m.feedsMu.Lock()
+	defer m.feedsMu.Unlock()
+	for _, feed := range m.feeds {
+		if feed.URL == feedURL {
+			return feed
+		}
+	}
+	return Feed{URL: feedURL}
+}
+
+// feedSummary returns the item's cleaned description if its feed has the
+// use_feed_summary directive, and "" otherwise.
+func (m *RSSMonitor) feedSummary(item *gofeed.Item, feedURL string) string {
+	if !m.feed(feedURL).UseFeedSummary {
+		return ""
 	}
+	return plainText(item.Description)
+}
+
+// feedPriority returns the priority directive of the feed with the given
+// URL, or 0 if it has none.
+func (m *RSSMonitor) feedPriority(feedURL string) int {
+	return m.feed(feedURL).Priority
+}
+
+// summarizationRequest builds the request that carries article through the
+// scheduler, with its feed's priority and directives. Feed summaries go out
+// with the same request, so they are branded, highlighted and cut like
+// model summaries.
+func (m *RSSMonitor) summarizationRequest(article Article, requestID string) SummarizationRequest {
+	feed := m.feed(article.FeedURL)
+	request := SummarizationRequest{
+		RequestID:     requestID,
+		ArticleURL:    article.URL,
+		ArticleTitle:  article.Title,
+		FeedURL:       article.FeedURL,
+		Lead:          article.Lead,
+		Content:       article.Content,
+		Model:         m.config.OLLAMA.Model,
+		ContentSource: article.ContentSource,
+		Priority:      feed.Priority, // Urgent feeds get summarized first
+		EnqueuedAt:    time.Now(),
+		Update:        article.Updated,
+		ResponseChan:  nil, // No response channel needed for async processing
+	}
+	feed.applyDirectives(&request)
+	return request
 }
 
 // generateSummaryAsync generates a summary for an article by enqueuing it to the scheduler
 func (m *RSSMonitor) generateSummaryAsync(article Article) {
 	// Check if article has content worth summarizing
 	if strings.TrimSpace(article.Content) == "" {
-		log.Printf("Skipping summarization for article %s: no content", article.URL)
+		slog.Info("Skipping summarization of article without content", "feed_url", article.FeedURL, "article_url", article.URL)
 		return
 	}
 
-	// Create summarization request
-	request := SummarizationRequest{
-		ArticleURL:   article.URL,
-		ArticleTitle: article.Title,
-		Content:      article.Content,
-		Model:        m.config.OLLAMA.Model,
-		Priority:     1, // Normal priority for RSS articles
-		EnqueuedAt:   time.Now(),
-		ResponseChan: nil, // No response channel needed for async processing
+	// Every log line and summary_logs row of the article's trip through the
+	// pipeline carries this ID
+	requestID := newRequestID()
+
+	request := m.summarizationRequest(article, requestID)
+
+	// Feeds that carry their own summary skip the model entirely
+	if article.FeedSummary != "" {
+		slog.Debug("Using the feed's own summary", "feed_url", article.FeedURL, "article_url", article.URL, "request_id", requestID)
+		request.Model = "" // No model wrote it
+		m.scheduler.PublishFeedSummary(request, article.FeedSummary)
+		return
 	}
 
 	// Enqueue to the centralized scheduler
 	if err := m.scheduler.EnqueueSummarization(request); err != nil {
-		log.Printf("Failed to enqueue summarization for article %s: %v", article.URL, err)
+		slog.Warn("Failed to enqueue summarization", "feed_url", article.FeedURL, "article_url", article.URL, "request_id", requestID, "error", err)
+		if article.Updated {
+			return // The article keeps the summary of its previous version
+		}
 
 		// Fallback: save a placeholder summary to the database
-		if err := m.updateArticleSummary(article.URL, "summary unavailable"); err != nil {
-			log.Printf("Failed to save fallback summary for article %s: %v", article.URL, err)
+		if err := NewDatabaseOperations(m.db).SetArticleSummary(article.URL, "summary unavailable"); err != nil {
+			slog.Error("Failed to save fallback summary", "article_url", article.URL, "request_id", requestID, "error", err)
 		}
+		m.scheduler.startSummaryGrace(request)
 	} else {
-		log.Printf("Successfully enqueued summarization for article: %s", article.Title)
+		slog.Debug("Enqueued summarization", "feed_url", article.FeedURL, "article_url", article.URL, "request_id", requestID)
 	}
 }
-
-// updateArticleSummary updates the summary field for an article in the database
-func (m *RSSMonitor) updateArticleSummary(articleURL, summary string) error {
-	query := `UPDATE articles SET summary = $1, updated_at = NOW() WHERE url = $2`
-	_, err := m.db.Exec(query, summary, articleURL)
-	return err
-}
@@ -1,10 +1,22 @@
 package main
 
 import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/PuerkitoBio/goquery"
+	"github.com/mmcdole/gofeed"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	"information-broker/config"
 )
 
 func TestExtractMainContentPrefersLongestArticleMatch(t *testing.T) {
@@ -196,3 +208,323 @@ func TestExtractMainContentTheRegisterK5aArticle(t *testing.T) {
 		t.Fatalf("extracted a teaser card instead of the article body: %.120q", got)
 	}
 }
+
+func TestLoadArticleContentSource(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/broken" {
+			http.Error(w, "boom", http.StatusInternalServerError)
+			return
+		}
+		io.WriteString(w, "<html><body><article>"+strings.Repeat("Scraped page body. ", 10)+"</article></body></html>")
+	}))
+	defer srv.Close()
+
+	m := &RSSMonitor{
+		httpClient: &http.Client{},
+		metrics:    testMetrics(),
+		config: &config.Config{
+			API:         config.APIConfig{Timeout: 5 * time.Second},
+			Performance: config.PerformanceConfig{MaxArticleContentLength: 10000},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		item        *gofeed.Item
+		wantSource  string
+		wantContent string
+		wantOutcome string
+	}{
+		{"scraped page", &gofeed.Item{Link: srv.URL + "/ok", Content: "feed body", Description: "desc"},
+			contentSourceScraped, "Scraped page body.", "success"},
+		{"feed content when the scrape fails", &gofeed.Item{Link: srv.URL + "/broken", Content: "feed body", Description: "desc"},
+			contentSourceFeedContent, "feed body", "fallback"},
+		{"description as last resort", &gofeed.Item{Link: srv.URL + "/broken", Content: "  ", Description: "desc"},
+			contentSourceDescription, "desc", "fallback"},
+		{"nothing to fall back on", &gofeed.Item{Link: srv.URL + "/broken"},
+			contentSourceDescription, "", "error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetches := m.metrics.articleContentFetchDuration.WithLabelValues("", tt.wantOutcome).(prometheus.Histogram)
+			fetchesBefore := histogramCount(t, fetches)
+			content, source, _, _ := m.loadArticleContent(tt.item, "", newContentBudget(0))
+			if got := histogramCount(t, fetches); got != fetchesBefore+1 {
+				t.Errorf("%s content fetches went %d -> %d, want +1", tt.wantOutcome, fetchesBefore, got)
+			}
+			if source != tt.wantSource {
+				t.Errorf("source = %q, want %q", source, tt.wantSource)
+			}
+			if !strings.Contains(content, tt.wantContent) {
+				t.Errorf("content = %q, want it to contain %q", content, tt.wantContent)
+			}
+
+			if content == "" {
+				return // Nothing to summarize
+			}
+
+			// The source rides along on the summarization request...
+			s := &SummarizationScheduler{
+				config:     m.config,
+				metrics:    testMetrics(),
+				queueCap:   1,
+				queueReady: make(chan struct{}, 1),
+			}
+			m.scheduler = s
+			m.generateSummaryAsync(Article{Title: "t", URL: tt.item.Link, Content: content, ContentSource: source})
+			request, ok := s.dequeue()
+			if !ok || request.ContentSource != tt.wantSource {
+				t.Fatalf("queued request ContentSource = %q, want %q", request.ContentSource, tt.wantSource)
+			}
+
+			// ...and ends up as the content_source metric label
+			before := counterValue(t, s.metrics.summarizationTotalProcessed.WithLabelValues("m", "success", tt.wantSource))
+			s.metrics.RecordSummarizationProcessing("m", "success", request.ContentSource, time.Second)
+			after := counterValue(t, s.metrics.summarizationTotalProcessed.WithLabelValues("m", "success", tt.wantSource))
+			if after != before+1 {
+				t.Errorf("content_source=%q counter went %v -> %v, want +1", tt.wantSource, before, after)
+			}
+		})
+	}
+}
+
+func TestLoadArticleContentPrefersFeedContent(t *testing.T) {
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		io.WriteString(w, "<html><body><article>"+strings.Repeat("Scraped page body. ", 10)+"</article></body></html>")
+	}))
+	defer srv.Close()
+
+	body := strings.Repeat("Feed body sentence. ", 10)
+	formats := map[string]string{
+		"RSS content:encoded": `<?xml version="1.0"?><rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/"><channel><title>F</title>
+			<item><title>A</title><link>` + srv.URL + `/a</link><description>Teaser</description><content:encoded><![CDATA[<p>` + body + `</p>]]></content:encoded></item></channel></rss>`,
+		"Atom content": `<?xml version="1.0"?><feed xmlns="http://www.w3.org/2005/Atom"><title>F</title>
+			<entry><title>A</title><link href="` + srv.URL + `/a"/><summary>Teaser</summary><content type="html">&lt;p&gt;` + body + `&lt;/p&gt;</content></entry></feed>`,
+		"JSON Feed content_html": `{"version": "https://jsonfeed.org/version/1.1", "title": "F",
+			"items": [{"id": "1", "url": "` + srv.URL + `/a", "summary": "Teaser", "content_html": "<p>` + body + `</p>"}]}`,
+	}
+
+	m := &RSSMonitor{
+		httpClient: &http.Client{},
+		metrics:    testMetrics(),
+		config: &config.Config{
+			API:         config.APIConfig{Timeout: 5 * time.Second},
+			Performance: config.PerformanceConfig{MaxArticleContentLength: 10000},
+			Content:     config.ContentConfig{FeedContentMinLength: 150},
+		},
+	}
+	for name, raw := range formats {
+		t.Run(name, func(t *testing.T) {
+			feed, err := gofeed.NewParser().ParseString(raw)
+			if err != nil {
+				t.Fatal(err)
+			}
+			atomic.StoreInt32(&hits, 0)
+			feedURL := "https://content.example/" + name
+			content, source, _, _ := m.loadArticleContent(feed.Items[0], feedURL, newContentBudget(0))
+			if fetched := atomic.LoadInt32(&hits); fetched != 0 || source != contentSourceFeedContent || content != strings.TrimSpace(body) {
+				t.Errorf("got %q from %s after %d page fetches, want the feed's plain-text content and no fetch", content, source, fetched)
+			}
+			if got := counterValue(t, m.metrics.articleContentFetchSkipped.WithLabelValues(feedURL)); got != 1 {
+				t.Errorf("skipped fetches = %v, want 1", got)
+			}
+		})
+	}
+
+	t.Run("short feed content still fetches the page", func(t *testing.T) {
+		atomic.StoreInt32(&hits, 0)
+		item := &gofeed.Item{Link: srv.URL + "/a", Content: "<p>Just a teaser.</p>"}
+		if _, source, _, _ := m.loadArticleContent(item, "", newContentBudget(0)); source != contentSourceScraped || atomic.LoadInt32(&hits) != 1 {
+			t.Errorf("source = %s after %d page fetches, want the scraped page", source, atomic.LoadInt32(&hits))
+		}
+	})
+}
+
+// histogramCount reads the number of observations of a Prometheus histogram.
+func histogramCount(t *testing.T, h prometheus.Histogram) uint64 {
+	t.Helper()
+	var m dto.Metric
+	if err := h.Write(&m); err != nil {
+		t.Fatalf("read histogram: %v", err)
+	}
+	return m.GetHistogram().GetSampleCount()
+}
+
+// counterValue reads the current value of a Prometheus counter.
+func counterValue(t *testing.T, c prometheus.Counter) float64 {
+	t.Helper()
+	var m dto.Metric
+	if err := c.Write(&m); err != nil {
+		t.Fatalf("read counter: %v", err)
+	}
+	return m.GetCounter().GetValue()
+}
+
+// gaugeValue reads the current value of a Prometheus gauge.
+func gaugeValue(t *testing.T, g prometheus.Gauge) float64 {
+	t.Helper()
+	var m dto.Metric
+	if err := g.Write(&m); err != nil {
+		t.Fatalf("read gauge: %v", err)
+	}
+	return m.GetGauge().GetValue()
+}
+
+func TestFetchFeedWithRetry(t *testing.T) {
+	const validFeed = `<?xml version="1.0"?><rss version="2.0"><channel><title>Test</title></channel></rss>`
+
+	tests := []struct {
+		name      string
+		retries   int
+		responses []int // Status per request; 0 serves a truncated body with 200
+		wantHits  int32
+		wantErr   bool
+	}{
+		{"garbled body then a valid feed", 1, []int{0}, 2, false},
+		{"5xx then a valid feed", 2, []int{503, 502}, 3, false},
+		{"5xx that outlasts the retries", 1, []int{500, 500}, 2, true},
+		{"404 is not retried", 3, []int{404}, 1, true},
+		{"retries disabled", 0, []int{0}, 1, true},
+	}
+	// Only the last attempt's outcome reaches fetch_logs
+	errorLogs := func(recorder *execRecorder) int {
+		n := 0
+		for _, args := range recorder.recordedWrites("INSERT INTO fetch_logs") {
+			if args[1] == "error" {
+				n++
+			}
+		}
+		return n
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var hits int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := int(atomic.AddInt32(&hits, 1)) - 1
+				switch {
+				case n >= len(tt.responses):
+					io.WriteString(w, validFeed)
+				case tt.responses[n] == 0:
+					io.WriteString(w, validFeed[:40])
+				default:
+					w.WriteHeader(tt.responses[n])
+				}
+			}))
+			defer srv.Close()
+
+			db, recorder := openExecRecorder(t)
+			m := &RSSMonitor{
+				db:         db,
+				httpClient: srv.Client(),
+				parser:     gofeed.NewParser(),
+				validators: newFeedValidatorCache(),
+				metrics:    testMetrics(),
+				config: &config.Config{
+					App: config.AppConfig{FeedFetchRetries: tt.retries, FeedFetchRetryBackoff: time.Millisecond},
+				},
+			}
+
+			ctx := context.Background()
+			err := m.fetchFeedWithRetry(ctx, srv.URL, func(lastAttempt bool) error {
+				_, err := m.doFetchFeed(ctx, srv.URL, time.Now(), lastAttempt)
+				return err
+			})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if want := map[bool]int{false: 0, true: 1}[tt.wantErr]; errorLogs(recorder) != want {
+				t.Errorf("%d error rows in fetch_logs, want %d", errorLogs(recorder), want)
+			}
+			if got := atomic.LoadInt32(&hits); got != tt.wantHits {
+				t.Errorf("server got %d requests, want %d", got, tt.wantHits)
+			}
+		})
+	}
+}
+
+func TestFetchesRotateUserAgents(t *testing.T) {
+	var mu sync.Mutex
+	var agents []string
+	mux := http.NewServeMux()
+	var articleURL string
+	mux.HandleFunc("/feed", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		agents = append(agents, "feed:"+r.UserAgent())
+		mu.Unlock()
+		io.WriteString(w, `<?xml version="1.0"?><rss version="2.0"><channel><title>Test</title>
+			<item><title>Article</title><link>`+articleURL+`</link><pubDate>`+time.Now().UTC().Format(time.RFC1123Z)+`</pubDate></item>
+			</channel></rss>`)
+	})
+	mux.HandleFunc("/article", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		agents = append(agents, "page:"+r.UserAgent())
+		mu.Unlock()
+		io.WriteString(w, "<html><body><article>"+strings.Repeat("Article body. ", 20)+"</article></body></html>")
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	articleURL = srv.URL + "/article"
+
+	tests := []struct {
+		name       string
+		userAgents []string
+		want       []string
+	}{
+		{"single user agent", nil, []string{"feed:Information-Broker/1.0", "page:Information-Broker/1.0", "feed:Information-Broker/1.0"}},
+		{"pool", []string{"Mozilla/5.0 (X11, Linux)", "Bot/2"}, []string{"feed:Mozilla/5.0 (X11, Linux)", "page:Bot/2", "feed:Mozilla/5.0 (X11, Linux)"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			agents = nil
+			db, _ := openExecRecorder(t)
+			cfg := &config.Config{
+				API:         config.APIConfig{Timeout: 5 * time.Second, UserAgent: "Information-Broker/1.0", UserAgents: tt.userAgents},
+				Performance: config.PerformanceConfig{MaxConcurrentFeeds: 1, MaxArticleContentLength: 10000},
+			}
+			scheduler := &SummarizationScheduler{config: cfg, metrics: testMetrics(), queueCap: 10, queueReady: make(chan struct{}, 1)}
+			m := NewRSSMonitor(db, []Feed{{URL: srv.URL + "/feed"}}, testMetrics(), cfg, NewCircuitBreakerManager(), scheduler, nil)
+
+			m.fetchFeed(context.Background(), srv.URL+"/feed")
+			m.fetchFeed(context.Background(), srv.URL+"/feed") // The article is seen by now; only the feed is fetched
+
+			mu.Lock()
+			defer mu.Unlock()
+			if strings.Join(agents, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("requests carried %q, want %q", agents, tt.want)
+			}
+		})
+	}
+}
+
+func TestFetchAppliesFeedRewrite(t *testing.T) {
+	var requested atomic.Value
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested.Store(r.URL.Path)
+		if r.URL.Path != "/feed/" {
+			http.NotFound(w, r)
+			return
+		}
+		io.WriteString(w, `<?xml version="1.0"?><rss version="2.0"><channel><title>Test</title></channel></rss>`)
+	}))
+	defer srv.Close()
+
+	feedURL := srv.URL + "/feed"
+	db, recorder := openExecRecorder(t)
+	cfg := &config.Config{
+		API:         config.APIConfig{Timeout: 5 * time.Second},
+		Performance: config.PerformanceConfig{MaxConcurrentFeeds: 1, MaxArticleContentLength: 10000},
+	}
+	m := NewRSSMonitor(db, []Feed{{URL: feedURL, Rewrite: "s#([^/])$#$1/#"}}, testMetrics(), cfg, NewCircuitBreakerManager(), nil, nil)
+	m.fetchFeed(context.Background(), feedURL)
+
+	if got := requested.Load(); got != "/feed/" {
+		t.Errorf("requested %v, want the rewritten /feed/", got)
+	}
+	logs := recorder.recordedWrites("INSERT INTO fetch_logs")
+	if len(logs) != 1 || logs[0][0] != feedURL || logs[0][1] != "success" {
+		t.Errorf("fetch logs = %v, want one success under the configured URL %s", logs, feedURL)
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"information-broker/config"
+	"io"
+	"net/http"
+	"time"
+)
+
+// Notification payload formats (NOTIFICATION_FORMAT).
+const (
+	notificationFormatRaw         = "raw"
+	notificationFormatCloudEvents = "cloudevents"
+)
+
+// articleEventType is the CloudEvents type attribute of summarized-article
+// events.
+const articleEventType = "io.information-broker.article.summarized"
+
+// ArticleEvent is the article as delivered to generic notification webhooks;
+// in the cloudevents format it is the event's data.
+type ArticleEvent struct {
+	Title       string    `json:"title"`
+	URL         string    `json:"url"`
+	Summary     string    `json:"summary"`
+	FeedURL     string    `json:"feed_url,omitempty"`
+	PublishedAt time.Time `json:"published_at"`
+}
+
+// cloudEvent is a CloudEvents 1.0 structured-mode JSON envelope.
+type cloudEvent struct {
+	SpecVersion     string       `json:"specversion"`
+	Type            string       `json:"type"`
+	Source          string       `json:"source"`
+	ID              string       `json:"id"`
+	Time            time.Time    `json:"time"`
+	DataContentType string       `json:"datacontenttype"`
+	Data            ArticleEvent `json:"data"`
+}
+
+// articleEventID derives the event id from the article URL, so a resend of
+// the same article carries the same id and consumers can deduplicate on it.
+func articleEventID(articleURL string) string {
+	sum := sha256.Sum256([]byte(articleURL))
+	return hex.EncodeToString(sum[:16])
+}
+
+// buildNotificationPayload encodes event in the given format and returns the
+// body along with its Content-Type. An empty format means raw.
+func buildNotificationPayload(format, source string, event ArticleEvent, now time.Time) ([]byte, string, error) {
+	switch format {
+	case "", notificationFormatRaw:
+		body, err := json.Marshal(event)
+		return body, "application/json", err
+	case notificationFormatCloudEvents:
+		body, err := json.Marshal(cloudEvent{
+			SpecVersion:     "1.0",
+			Type:            articleEventType,
+			Source:          source,
+			ID:              articleEventID(event.URL),
+			Time:            now.UTC(),
+			DataContentType: "application/json",
+			Data:            event,
+		})
+		return body, "application/cloudevents+json", err
+	default:
+		return nil, "", fmt.Errorf("unsupported notification format %q", format)
+	}
+}
+
+// NotificationWebhookSender posts summarized articles to the generic
+// notification webhooks in the configured format.
+type NotificationWebhookSender struct {
+	httpClient *http.Client
+	config     *config.NotificationsConfig
+	userAgent  string
+}
+
+// NewNotificationWebhookSender creates a sender for cfg's webhooks.
+func NewNotificationWebhookSender(cfg *config.NotificationsConfig, userAgent string) *NotificationWebhookSender {
+	return &NotificationWebhookSender{
+		httpClient: &http.Client{Timeout: cfg.Timeout},
+		config:     cfg,
+		userAgent:  userAgent,
+	}
+}
+
+// Send delivers event to webhookURL. Any 2xx response is success.
+func (n *NotificationWebhookSender) Send(ctx context.Context, webhookURL string, event ArticleEvent) error {
+	body, contentType, err := buildNotificationPayload(n.config.Format, n.config.Source, event, time.Now())
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	if n.userAgent != "" {
+		req.Header.Set("User-Agent", n.userAgent)
+	}
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send notification: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("notification webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"information-broker/config"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func testArticleEvent() ArticleEvent {
+	return ArticleEvent{
+		Title:       "Gateway zero-day exploited",
+		URL:         "https://example.com/a",
+		Summary:     "Attackers are exploiting an unpatched gateway.",
+		FeedURL:     "https://example.com/feed",
+		PublishedAt: time.Date(2024, 5, 1, 8, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestBuildNotificationPayloadCloudEvents(t *testing.T) {
+	now := time.Date(2024, 5, 1, 9, 30, 0, 0, time.FixedZone("CEST", 2*3600))
+	body, contentType, err := buildNotificationPayload(notificationFormatCloudEvents, "/information-broker", testArticleEvent(), now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if contentType != "application/cloudevents+json" {
+		t.Errorf("content type = %q, want application/cloudevents+json", contentType)
+	}
+
+	var envelope map[string]interface{}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		t.Fatalf("payload is not JSON: %v", err)
+	}
+	want := map[string]string{
+		"specversion":     "1.0",
+		"type":            articleEventType,
+		"source":          "/information-broker",
+		"id":              articleEventID("https://example.com/a"),
+		"time":            "2024-05-01T07:30:00Z",
+		"datacontenttype": "application/json",
+	}
+	for attr, value := range want {
+		if got, _ := envelope[attr].(string); got != value {
+			t.Errorf("%s = %q, want %q", attr, got, value)
+		}
+	}
+	data, ok := envelope["data"].(map[string]interface{})
+	if !ok || data["url"] != "https://example.com/a" || data["summary"] != "Attackers are exploiting an unpatched gateway." {
+		t.Errorf("data = %v, want the article", envelope["data"])
+	}
+
+	// The same article always gets the same id; another article doesn't
+	if articleEventID("https://example.com/a") == articleEventID("https://example.com/b") {
+		t.Error("different articles share an event id")
+	}
+}
+
+func TestBuildNotificationPayloadRawIsDefault(t *testing.T) {
+	if format := config.Load().Notifications.Format; format != notificationFormatRaw {
+		t.Errorf("default format = %q, want raw", format)
+	}
+
+	for _, format := range []string{"", notificationFormatRaw} {
+		body, contentType, err := buildNotificationPayload(format, "/information-broker", testArticleEvent(), time.Now())
+		if err != nil {
+			t.Fatalf("format %q: unexpected error: %v", format, err)
+		}
+		var got ArticleEvent
+		if err := json.Unmarshal(body, &got); err != nil || got != testArticleEvent() {
+			t.Errorf("format %q: payload %s, want the bare article", format, body)
+		}
+		if contentType != "application/json" {
+			t.Errorf("format %q: content type = %q, want application/json", format, contentType)
+		}
+	}
+
+	if _, _, err := buildNotificationPayload("xml", "", testArticleEvent(), time.Now()); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestNotificationWebhookSenderSend(t *testing.T) {
+	var gotContentType string
+	var gotEvent cloudEvent
+	status := http.StatusAccepted
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotContentType = r.Header.Get("Content-Type")
+		json.NewDecoder(r.Body).Decode(&gotEvent)
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	cfg := &config.NotificationsConfig{Format: notificationFormatCloudEvents, Source: "/test", Timeout: 5 * time.Second}
+	sender := NewNotificationWebhookSender(cfg, "Information-Broker/1.0")
+
+	if err := sender.Send(context.Background(), srv.URL, testArticleEvent()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gotContentType != "application/cloudevents+json" || gotEvent.Source != "/test" || gotEvent.Data.Title != "Gateway zero-day exploited" {
+		t.Errorf("received %q %+v", gotContentType, gotEvent)
+	}
+
+	status = http.StatusInternalServerError
+	if err := sender.Send(context.Background(), srv.URL, testArticleEvent()); err == nil {
+		t.Error("expected an error for a 500 response")
+	}
+}
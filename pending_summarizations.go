@@ -0,0 +1,110 @@
+package main
+
+import "log/slog"
+
+// Every queued summarization is mirrored in pending_summarizations until it
+// has been handled, so requests queued when the process stops are picked up
+// again on the next start instead of leaving their article without a
+// summary for good.
+
+// persistRequest records a request about to be queued and reports whether
+// it added a row. An article that already has a row keeps it, so re-queuing
+// it is harmless.
+func (s *SummarizationScheduler) persistRequest(request SummarizationRequest) bool {
+	if s.db == nil {
+		return false
+	}
+	result, err := s.db.Exec(`
+		INSERT INTO pending_summarizations
+			(article_url, article_title, feed_url, lead, content, model, content_source, priority, enqueued_at, request_id,
+			 is_update, content_type, summary_words, category)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		ON CONFLICT (article_url) DO NOTHING`,
+		request.ArticleURL, request.ArticleTitle, request.FeedURL, request.Lead, request.Content,
+		request.Model, request.ContentSource, request.Priority, request.EnqueuedAt, request.RequestID,
+		request.Update, request.ContentType, request.SummaryWords, request.Category)
+	if err != nil {
+		slog.Error("Failed to persist summarization request", "article_url", request.ArticleURL, "request_id", request.RequestID, "error", err)
+		return false
+	}
+	inserted, err := result.RowsAffected()
+	return err == nil && inserted > 0
+}
+
+// forgetRequest deletes the row of a handled request.
+func (s *SummarizationScheduler) forgetRequest(articleURL string) {
+	if s.db == nil {
+		return
+	}
+	if _, err := s.db.Exec(`DELETE FROM pending_summarizations WHERE article_url = $1`, articleURL); err != nil {
+		slog.Error("Failed to delete pending summarization", "article_url", articleURL, "error", err)
+	}
+}
+
+// restorePendingRequests queues the requests left over from a previous run
+// and returns how many it queued. Rows whose article got its summary after
+// all (the process stopped between saving it and deleting the row) or no
+// longer exists are dropped rather than summarized again, and articles
+// already queued are skipped.
+func (s *SummarizationScheduler) restorePendingRequests() (int, error) {
+	if s.db == nil {
+		return 0, nil
+	}
+	if _, err := s.db.Exec(`
+		DELETE FROM pending_summarizations
+		WHERE article_url NOT IN (
+			SELECT url FROM articles WHERE summary IS NULL OR summary = 'summary unavailable'
+		)`); err != nil {
+		return 0, err
+	}
+
+	rows, err := s.db.Query(`
+		SELECT article_url, article_title, feed_url, lead, content, model, content_source, priority, enqueued_at, request_id,
+			is_update, content_type, summary_words, category
+		FROM pending_summarizations
+		ORDER BY enqueued_at`)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var requests []SummarizationRequest
+	for rows.Next() {
+		var request SummarizationRequest
+		if err := rows.Scan(&request.ArticleURL, &request.ArticleTitle, &request.FeedURL, &request.Lead, &request.Content,
+			&request.Model, &request.ContentSource, &request.Priority, &request.EnqueuedAt, &request.RequestID,
+			&request.Update, &request.ContentType, &request.SummaryWords, &request.Category); err != nil {
+			return 0, err
+		}
+		requests = append(requests, request)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	// Restored requests were admitted before, so they don't count against
+	// the queue capacity
+	s.mu.Lock()
+	restored := 0
+	for _, request := range requests {
+		if s.queue.contains(request.ArticleURL) {
+			continue
+		}
+		if request.RequestID == "" {
+			request.RequestID = newRequestID() // Persisted before requests had IDs
+		}
+		s.queue.push(request)
+		restored++
+	}
+	depth := s.queue.len()
+	s.mu.Unlock()
+
+	if restored > 0 {
+		select {
+		case s.queueReady <- struct{}{}:
+		default:
+		}
+		s.metrics.UpdateSummarizationQueueDepth(depth)
+	}
+	return restored, nil
+}
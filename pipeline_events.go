@@ -0,0 +1,235 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"information-broker/config"
+	"log/slog"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/nats-io/nats.go"
+)
+
+// Pipeline event types.
+const (
+	eventArticleIngested   = "article_ingested"
+	eventArticleSummarized = "article_summarized"
+	eventArticlePosted     = "article_posted"
+	eventFetchFailed       = "fetch_failed"
+)
+
+// natsDialTimeout bounds connecting to a NATS server and the final flush.
+const natsDialTimeout = 5 * time.Second
+
+// natsReconnectDelay is how long the client waits between attempts to
+// reconnect to a NATS server.
+const natsReconnectDelay = 5 * time.Second
+
+// PipelineEvent is one step of an article's way through the pipeline, or a
+// failed feed fetch, as published to the event stream.
+type PipelineEvent struct {
+	Type       string    `json:"type"`
+	Time       time.Time `json:"time"`
+	ArticleURL string    `json:"article_url,omitempty"`
+	Title      string    `json:"title,omitempty"`
+	FeedURL    string    `json:"feed_url,omitempty"`
+	Model      string    `json:"model,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// EventPublisher publishes pipeline events. Publish must not block the
+// pipeline; Close flushes what is still buffered.
+type EventPublisher interface {
+	Publish(event PipelineEvent)
+	Close()
+}
+
+// NewEventPublisher returns a NATS publisher for the configured brokers, or
+// a no-op publisher when there are none. It fails if a server refuses the
+// connection outright, for credentials or TLS; servers that can't be reached
+// yet are retried in the background.
+func NewEventPublisher(cfg config.EventsConfig) (EventPublisher, error) {
+	if len(cfg.Brokers) == 0 {
+		return noopPublisher{}, nil
+	}
+	p, err := newNATSPublisher(cfg)
+	if err != nil {
+		return nil, err
+	}
+	slog.Info("Publishing pipeline events to NATS", "topic", cfg.Topic, "servers", len(cfg.Brokers))
+	return p, nil
+}
+
+// publishEvent stamps event with the current time and hands it to p. A nil
+// publisher drops it.
+func publishEvent(p EventPublisher, event PipelineEvent) {
+	if p == nil {
+		return
+	}
+	event.Time = time.Now().UTC()
+	p.Publish(event)
+}
+
+// noopPublisher drops every event.
+type noopPublisher struct{}
+
+func (noopPublisher) Publish(PipelineEvent) {}
+func (noopPublisher) Close()                {}
+
+// natsPublisher publishes events to a NATS subject through the NATS client
+// library, from a background goroutine. The client reconnects on its own and
+// holds what is published while it does; events that overflow the buffer,
+// or that the client refuses, are dropped rather than holding up the
+// pipeline.
+type natsPublisher struct {
+	conn    *nats.Conn
+	subject string
+	events  chan []byte
+	done    chan struct{}
+
+	mu      sync.RWMutex // Guards closed against a Publish racing Close
+	closed  bool
+	dropped atomic.Int64
+}
+
+func newNATSPublisher(cfg config.EventsConfig) (*natsPublisher, error) {
+	opts, err := natsOptions(cfg)
+	if err != nil {
+		return nil, err
+	}
+	servers := strings.Join(cfg.Brokers, ",")
+	conn, err := nats.Connect(servers, opts...)
+	if err != nil && natsUnreachable(err) {
+		slog.Warn("No NATS server reachable yet, retrying in the background", "error", err)
+		conn, err = nats.Connect(servers, append(opts, nats.RetryOnFailedConnect(true))...)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("connect to NATS: %w", err)
+	}
+
+	p := &natsPublisher{
+		conn:    conn,
+		subject: cfg.Topic,
+		events:  make(chan []byte, max(cfg.BufferSize, 1)),
+		done:    make(chan struct{}),
+	}
+	go p.run()
+	return p, nil
+}
+
+// natsOptions returns the client options for cfg: credentials and TLS
+// settings, plus reconnecting for as long as the process runs.
+func natsOptions(cfg config.EventsConfig) ([]nats.Option, error) {
+	opts := []nats.Option{
+		nats.Name("information-broker"),
+		nats.Timeout(natsDialTimeout),
+		nats.MaxReconnects(-1),
+		nats.ReconnectWait(natsReconnectDelay),
+		nats.DisconnectErrHandler(func(_ *nats.Conn, err error) {
+			if err != nil {
+				slog.Warn("Disconnected from NATS", "error", err)
+			}
+		}),
+		nats.ReconnectHandler(func(nc *nats.Conn) {
+			slog.Info("Reconnected to NATS", "server", nc.ConnectedUrlRedacted())
+		}),
+		nats.ErrorHandler(func(_ *nats.Conn, _ *nats.Subscription, err error) {
+			slog.Error("NATS error", "error", err)
+		}),
+	}
+
+	switch {
+	case cfg.CredsFile != "":
+		opts = append(opts, nats.UserCredentials(cfg.CredsFile))
+	case cfg.NKeySeedFile != "":
+		opt, err := nats.NkeyOptionFromSeed(cfg.NKeySeedFile)
+		if err != nil {
+			return nil, fmt.Errorf("EVENTS_NATS_NKEY_SEED_FILE: %w", err)
+		}
+		opts = append(opts, opt)
+	case cfg.Token != "":
+		opts = append(opts, nats.Token(cfg.Token))
+	case cfg.User != "":
+		opts = append(opts, nats.UserInfo(cfg.User, cfg.Password))
+	}
+
+	if cfg.TLSCAFile != "" {
+		opts = append(opts, nats.RootCAs(cfg.TLSCAFile))
+	}
+	if cfg.TLSCertFile != "" {
+		opts = append(opts, nats.ClientCert(cfg.TLSCertFile, cfg.TLSKeyFile))
+	}
+	return opts, nil
+}
+
+// natsUnreachable reports whether a failed connect found no server to talk
+// to, as opposed to one that refused us (credentials, TLS), which retrying
+// won't fix.
+func natsUnreachable(err error) bool {
+	var opErr *net.OpError
+	return errors.Is(err, nats.ErrNoServers) || (errors.As(err, &opErr) && opErr.Op == "dial")
+}
+
+// Publish queues event for sending.
+func (p *natsPublisher) Publish(event PipelineEvent) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		slog.Error("Failed to encode pipeline event", "event_type", event.Type, "error", err)
+		return
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.closed {
+		return
+	}
+	select {
+	case p.events <- payload:
+	default:
+		p.drop()
+	}
+}
+
+// Close sends the buffered events and disconnects.
+func (p *natsPublisher) Close() {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return
+	}
+	p.closed = true
+	close(p.events)
+	p.mu.Unlock()
+	<-p.done
+}
+
+// drop counts events that couldn't be sent, logging every hundredth so an
+// outage doesn't flood the log.
+func (p *natsPublisher) drop() {
+	if dropped := p.dropped.Add(1); dropped%100 == 1 {
+		slog.Warn("Dropping pipeline events: event buffer full or NATS unreachable", "dropped_total", dropped)
+	}
+}
+
+// run hands queued events to the client until Close, then flushes them
+// and disconnects.
+func (p *natsPublisher) run() {
+	defer close(p.done)
+
+	for payload := range p.events {
+		if err := p.conn.Publish(p.subject, payload); err != nil {
+			p.drop()
+		}
+	}
+	if p.conn.IsConnected() {
+		if err := p.conn.FlushTimeout(natsDialTimeout); err != nil {
+			slog.Error("Failed to flush pipeline events to NATS", "error", err)
+		}
+	}
+	p.conn.Close()
+}
@@ -0,0 +1,262 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"information-broker/config"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakePublisher records the events published to it.
+type fakePublisher struct {
+	mu     sync.Mutex
+	events []PipelineEvent
+}
+
+func (p *fakePublisher) Publish(event PipelineEvent) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.events = append(p.events, event)
+}
+
+func (p *fakePublisher) Close() {}
+
+// ofType returns the recorded events of the given type.
+func (p *fakePublisher) ofType(eventType string) []PipelineEvent {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var events []PipelineEvent
+	for _, event := range p.events {
+		if event.Type == eventType {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+// waitForEvents waits for the publisher to have n events of eventType.
+func (p *fakePublisher) waitForEvents(eventType string, n int) []PipelineEvent {
+	deadline := time.Now().Add(5 * time.Second)
+	for len(p.ofType(eventType)) < n && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	return p.ofType(eventType)
+}
+
+func TestFeedFetchEvents(t *testing.T) {
+	mux := http.NewServeMux()
+	var feedURL, articleURL string
+	mux.HandleFunc("/feed", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `<?xml version="1.0"?><rss version="2.0"><channel><title>Test</title>
+			<item><title>Fresh article</title><link>%s</link><pubDate>%s</pubDate><description>desc</description></item>
+			</channel></rss>`, articleURL, time.Now().UTC().Format(time.RFC1123Z))
+	})
+	mux.HandleFunc("/article", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "<html><body><article>"+strings.Repeat("Article body. ", 20)+"</article></body></html>")
+	})
+	mux.HandleFunc("/gone", func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	feedURL, articleURL = srv.URL+"/feed", srv.URL+"/article"
+
+	db, _ := openExecRecorder(t)
+	cfg := &config.Config{
+		API:         config.APIConfig{Timeout: 5 * time.Second},
+		OLLAMA:      config.OLLAMAConfig{Model: "llama3"},
+		Performance: config.PerformanceConfig{MaxConcurrentFeeds: 1, MaxArticleContentLength: 10000},
+	}
+	events := &fakePublisher{}
+	scheduler := &SummarizationScheduler{
+		config:     cfg,
+		metrics:    testMetrics(),
+		queueCap:   10,
+		queueReady: make(chan struct{}, 1),
+		events:     events,
+	}
+	m := NewRSSMonitor(db, []Feed{{URL: feedURL}}, testMetrics(), cfg, NewCircuitBreakerManager(), scheduler, nil)
+
+	m.fetchFeed(context.Background(), feedURL)
+	ingested := events.ofType(eventArticleIngested)
+	if len(ingested) != 1 || ingested[0].ArticleURL != articleURL || ingested[0].FeedURL != feedURL || ingested[0].Time.IsZero() {
+		t.Errorf("article_ingested events = %+v, want one for %s", ingested, articleURL)
+	}
+
+	m.fetchFeed(context.Background(), srv.URL+"/gone")
+	failed := events.ofType(eventFetchFailed)
+	if len(failed) != 1 || failed[0].FeedURL != srv.URL+"/gone" || failed[0].Error != "HTTP 404" {
+		t.Errorf("fetch_failed events = %+v, want one for the 404 feed", failed)
+	}
+}
+
+func TestSummaryEvents(t *testing.T) {
+	var hits int32
+	ollama := newOllamaStub(t, http.StatusOK, "A summary.", &hits)
+	s, _, received := newGraceTestScheduler(t, ollama.URL)
+	events := &fakePublisher{}
+	s.events = events
+
+	request := SummarizationRequest{
+		ArticleURL:   "https://important.example/a",
+		ArticleTitle: "Important article",
+		FeedURL:      "https://important.example/feed",
+		Content:      "The article body.",
+		Model:        "llama3",
+	}
+	s.handleRequest(context.Background(), request, SummarizationSchedulerConfig{MaxRetries: 1, WorkerTimeout: 5 * time.Second})
+
+	summarized := events.ofType(eventArticleSummarized)
+	if len(summarized) != 1 || summarized[0].ArticleURL != request.ArticleURL || summarized[0].Model != "llama3" {
+		t.Errorf("article_summarized events = %+v, want one for %s", summarized, request.ArticleURL)
+	}
+	waitForMessages(t, received, 1)
+	if posted := events.waitForEvents(eventArticlePosted, 1); len(posted) != 1 || posted[0].ArticleURL != request.ArticleURL {
+		t.Errorf("article_posted events = %+v, want one once Discord accepted the post", posted)
+	}
+}
+
+// fakeNATSServer accepts one client on a local port, greets it with info
+// and answers its pings; when reject is set it answers CONNECT with that
+// error instead. It returns the server's address and the protocol lines
+// the client sent, other than pings, closed once the client disconnects.
+func fakeNATSServer(t *testing.T, info, reject string) (string, <-chan string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	lines := make(chan string, 10)
+	go func() {
+		defer close(lines)
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		io.WriteString(conn, "INFO "+info+"\r\n")
+		reader := bufio.NewReader(conn)
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimSpace(line)
+			switch {
+			case line == "PING":
+				io.WriteString(conn, "PONG\r\n")
+				continue
+			case strings.HasPrefix(line, "CONNECT ") && reject != "":
+				lines <- line
+				io.WriteString(conn, "-ERR '"+reject+"'\r\n")
+				return
+			}
+			lines <- line
+		}
+	}()
+	return ln.Addr().String(), lines
+}
+
+func TestNATSPublisher(t *testing.T) {
+	addr, lines := fakeNATSServer(t, `{"server_id":"test","max_payload":1048576}`, "")
+
+	p, err := NewEventPublisher(config.EventsConfig{Brokers: []string{"nats://" + addr}, Topic: "ib.events", BufferSize: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	publishEvent(p, PipelineEvent{Type: eventFetchFailed, FeedURL: "https://example.com/feed", Error: "HTTP 500"})
+	p.Close()
+
+	var got []string
+	for line := range lines {
+		got = append(got, line)
+	}
+	if len(got) != 3 || !strings.HasPrefix(got[0], "CONNECT ") || !strings.HasPrefix(got[1], "PUB ib.events ") {
+		t.Fatalf("server received %q, want CONNECT then one PUB to ib.events", got)
+	}
+	var event PipelineEvent
+	if err := json.Unmarshal([]byte(got[2]), &event); err != nil {
+		t.Fatalf("payload %q: %v", got[2], err)
+	}
+	if event.Type != eventFetchFailed || event.FeedURL != "https://example.com/feed" || event.Error != "HTTP 500" {
+		t.Errorf("event = %+v", event)
+	}
+	if want := fmt.Sprintf("PUB ib.events %d", len(got[2])); got[1] != want {
+		t.Errorf("PUB line = %q, want %q", got[1], want)
+	}
+}
+
+func TestNATSPublisherRejectedCredentials(t *testing.T) {
+	addr, lines := fakeNATSServer(t, `{"server_id":"test","auth_required":true}`, "Authorization Violation")
+
+	_, err := NewEventPublisher(config.EventsConfig{Brokers: []string{"nats://" + addr}, Topic: "ib.events", Token: "s3cret"})
+	if err == nil {
+		t.Fatal("a server rejecting the credentials should fail setting up the publisher")
+	}
+	if connect := <-lines; !strings.Contains(connect, `"auth_token":"s3cret"`) {
+		t.Errorf("CONNECT = %q, want the token sent", connect)
+	}
+}
+
+func TestNATSPublisherTLSRequired(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	// A server that wants TLS and hangs up on the handshake, as one would
+	// on a client certificate it doesn't trust
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		io.WriteString(conn, "INFO {\"server_id\":\"test\",\"tls_required\":true}\r\n")
+		conn.Read(make([]byte, 1))
+	}()
+
+	if _, err := NewEventPublisher(config.EventsConfig{Brokers: []string{"nats://" + ln.Addr().String()}, Topic: "ib.events"}); err == nil {
+		t.Fatal("a server requiring TLS should fail setting up the publisher")
+	}
+}
+
+func TestNATSPublisherUnreachable(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	// Nothing listening yet is no reason to stop startup; the client keeps
+	// trying in the background
+	p, err := NewEventPublisher(config.EventsConfig{Brokers: []string{"nats://" + addr}, Topic: "ib.events", BufferSize: 10})
+	if err != nil {
+		t.Fatalf("unreachable server: %v, want the publisher to retry", err)
+	}
+	publishEvent(p, PipelineEvent{Type: eventFetchFailed, FeedURL: "https://example.com/feed"})
+	p.Close()
+}
+
+func TestNewEventPublisherUnconfigured(t *testing.T) {
+	p, err := NewEventPublisher(config.EventsConfig{Topic: "ib.events"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := p.(noopPublisher); !ok {
+		t.Error("no brokers should give the no-op publisher")
+	}
+}
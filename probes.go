@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// readinessPingTimeout bounds the database ping behind /readyz, so a hung
+// connection fails the probe instead of outlasting its timeout.
+const readinessPingTimeout = 2 * time.Second
+
+// ProbeStatus is the body of /livez and /readyz.
+type ProbeStatus struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+// checkWorker reports why the worker goroutine can't be trusted to make
+// progress: it exited, or it has been on one request for longer than
+// stuckAfter, which no request should outlast (0 skips that check). A
+// scheduler that hasn't started yet passes.
+func (s *SummarizationScheduler) checkWorker(stuckAfter time.Duration) error {
+	select {
+	case <-s.done:
+		return errors.New("summarization worker has exited")
+	default:
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.currentRequest != nil && stuckAfter > 0 {
+		if busy := time.Since(s.requestStartTime); busy > stuckAfter {
+			return fmt.Errorf("summarization worker stuck on %s for %s", s.currentRequest.ArticleURL, busy.Round(time.Second))
+		}
+	}
+	return nil
+}
+
+// livez is the liveness probe: 200 while the process can serve HTTP and the
+// summarization worker is running, 503 once the worker has exited or hangs
+// on a request for twice Summarization.WorkerTimeout, which only a restart
+// fixes. Dependencies are left to /readyz, so an outage of the database
+// takes the pod out of rotation instead of restarting it.
+func (s *APIServer) livez(w http.ResponseWriter, r *http.Request) {
+	probe := ProbeStatus{Status: "alive", Checks: map[string]string{"http": "ok"}}
+	if s.scheduler != nil {
+		probe.Checks["summarization_worker"] = "ok"
+		if err := s.scheduler.checkWorker(2 * s.config.Summarization.WorkerTimeout); err != nil {
+			probe.Status = "dead"
+			probe.Checks["summarization_worker"] = err.Error()
+		}
+	}
+	writeProbe(w, probe, probe.Status == "alive")
+}
+
+// readyz is the readiness probe: 200 when the database answers a ping and
+// at least one circuit breaker lets calls through, 503 otherwise. It runs
+// the same checks as /health without the details.
+func (s *APIServer) readyz(w http.ResponseWriter, r *http.Request) {
+	probe := ProbeStatus{Status: "ready", Checks: map[string]string{"database": "ok", "circuit_breakers": "ok"}}
+
+	ctx, cancel := context.WithTimeout(r.Context(), readinessPingTimeout)
+	defer cancel()
+	if err := s.db.PingContext(ctx); err != nil {
+		probe.Status = "not_ready"
+		probe.Checks["database"] = err.Error()
+	}
+
+	if s.circuitBreakers != nil {
+		breakers := s.circuitBreakers.GetStatus()
+		open := 0
+		for _, cb := range breakers {
+			if cb.State == StateOpen {
+				open++
+			}
+		}
+		if len(breakers) > 0 && open == len(breakers) {
+			probe.Status = "not_ready"
+			probe.Checks["circuit_breakers"] = fmt.Sprintf("all %d circuit breakers are open", open)
+		}
+	}
+
+	writeProbe(w, probe, probe.Status == "ready")
+}
+
+// writeProbe writes a probe result as JSON, 200 if ok and 503 otherwise.
+func writeProbe(w http.ResponseWriter, probe ProbeStatus, ok bool) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if !ok {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(probe)
+}
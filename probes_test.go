@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"information-broker/config"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestLivez(t *testing.T) {
+	cfg := &config.Config{Summarization: config.SummarizationConfig{WorkerTimeout: time.Minute}}
+	scheduler := &SummarizationScheduler{done: make(chan struct{})}
+	s := &APIServer{config: cfg, scheduler: scheduler}
+	probe := func() (int, ProbeStatus) {
+		rec := httptest.NewRecorder()
+		s.livez(rec, httptest.NewRequest(http.MethodGet, "/livez", nil))
+		var body ProbeStatus
+		json.NewDecoder(rec.Body).Decode(&body)
+		return rec.Code, body
+	}
+
+	if code, body := probe(); code != http.StatusOK || body.Status != "alive" {
+		t.Errorf("idle worker: %d %+v, want 200 alive", code, body)
+	}
+
+	scheduler.currentRequest = &SummarizationRequest{ArticleURL: "https://a.example/1"}
+	scheduler.requestStartTime = time.Now().Add(-90 * time.Second)
+	if code, _ := probe(); code != http.StatusOK {
+		t.Errorf("worker busy within twice its timeout: %d, want 200", code)
+	}
+	scheduler.requestStartTime = time.Now().Add(-3 * time.Minute)
+	if code, body := probe(); code != http.StatusServiceUnavailable || body.Checks["summarization_worker"] == "ok" {
+		t.Errorf("stuck worker: %d %+v, want 503", code, body)
+	}
+
+	scheduler.currentRequest = nil
+	close(scheduler.done)
+	if code, _ := probe(); code != http.StatusServiceUnavailable {
+		t.Errorf("exited worker: %d, want 503", code)
+	}
+}
+
+func TestReadyz(t *testing.T) {
+	db, _ := openExecRecorder(t)
+	breakers := NewCircuitBreakerManager()
+	s := &APIServer{db: db, circuitBreakers: breakers}
+	probe := func() (int, ProbeStatus) {
+		rec := httptest.NewRecorder()
+		s.readyz(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		var body ProbeStatus
+		json.NewDecoder(rec.Body).Decode(&body)
+		return rec.Code, body
+	}
+
+	open := func(name string) {
+		breaker := breakers.GetOrCreateBreaker(name, &CircuitBreakerConfig{FailureThreshold: 1, Timeout: time.Hour})
+		breaker.Execute(func() error { return errors.New("down") }, nil)
+	}
+	open("ollama")
+	breakers.GetOrCreateBreaker("discord", &CircuitBreakerConfig{FailureThreshold: 1, Timeout: time.Hour})
+	if code, body := probe(); code != http.StatusOK || body.Status != "ready" {
+		t.Errorf("one breaker open: %d %+v, want 200 ready", code, body)
+	}
+
+	open("discord")
+	if code, body := probe(); code != http.StatusServiceUnavailable || body.Checks["database"] != "ok" {
+		t.Errorf("all breakers open: %d %+v, want 503 with the database fine", code, body)
+	}
+
+	s.circuitBreakers = NewCircuitBreakerManager()
+	db.Close()
+	if code, body := probe(); code != http.StatusServiceUnavailable || body.Checks["database"] == "ok" {
+		t.Errorf("database down: %d %+v, want 503", code, body)
+	}
+}
+
+func TestCollectSystemMetrics(t *testing.T) {
+	metrics := testMetrics()
+	stats := collectSystemMetrics(metrics)
+	if stats.GoRoutines < 1 || stats.MemorySysMB < 1 || stats.MemorySysMB < stats.MemoryMB {
+		t.Errorf("system metrics = %+v, want goroutines and memory filled in", stats)
+	}
+	if got := gaugeValue(t, metrics.goroutines); got < 1 {
+		t.Errorf("goroutines gauge = %v, want it updated", got)
+	}
+	if alloc, sys := gaugeValue(t, metrics.memoryBytes.WithLabelValues("alloc")), gaugeValue(t, metrics.memoryBytes.WithLabelValues("sys")); alloc <= 0 || sys < alloc {
+		t.Errorf("memory_bytes gauges = alloc %v, sys %v", alloc, sys)
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Article text is pasted straight into the summarization prompt, so a page
+// containing "ignore previous instructions and ..." can steer the model. When
+// CONTENT.PromptInjectionGuard is on, instruction-like phrases are blanked out
+// before the prompt is built, the article is fenced in delimiters the prompt
+// tells the model to treat as data, and summaries that read like the model
+// followed injected instructions are rejected.
+
+const (
+	articleOpenDelimiter  = "<article>"
+	articleCloseDelimiter = "</article>"
+	injectionPlaceholder  = "[removed]"
+)
+
+// injectionPatterns match imperative phrases aimed at the model rather than
+// the reader. They are deliberately narrow: an article that merely discusses
+// prompt injection should come through mostly intact.
+var injectionPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)\b(ignore|disregard|forget|override)\s+(all\s+|any\s+)?(of\s+)?(the\s+|your\s+)?(previous|prior|above|earlier|preceding|original)\s+(instructions|prompts?|directions|rules|context)\b`),
+	regexp.MustCompile(`(?i)\b(new|updated|revised)\s+(system\s+)?instructions\s*:`),
+	regexp.MustCompile(`(?i)\b(reveal|print|output|repeat|show)\s+(me\s+)?(your|the)\s+(system\s+prompt|instructions)\b`),
+	regexp.MustCompile(`(?i)\byou\s+are\s+now\s+(a|an|in)\s+\w+\s+(mode|assistant|bot|model)\b`),
+	regexp.MustCompile(`(?im)^\s*(system|assistant)\s*:`),
+	// Attempts to close our delimiters early and continue as instructions.
+	regexp.MustCompile(`(?i)</?\s*(article|system|instructions?)\s*>`),
+}
+
+// injectedSummaryPatterns match summaries where the model talks about its
+// instructions or itself instead of summarizing the article.
+var injectedSummaryPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)\bas an ai( language model)?\b`),
+	regexp.MustCompile(`(?i)\bmy (previous|prior|original) instructions\b`),
+	regexp.MustCompile(`(?i)\bi (have been|was|am) (instructed|told) to\b`),
+	regexp.MustCompile(`(?i)\b(my|the) system prompt\b`),
+}
+
+// neutralizeInjection replaces instruction-like phrases in article text with
+// a placeholder and reports whether anything was replaced.
+func neutralizeInjection(text string) (string, bool) {
+	suspected := false
+	for _, pattern := range injectionPatterns {
+		if pattern.MatchString(text) {
+			suspected = true
+			text = pattern.ReplaceAllString(text, injectionPlaceholder)
+		}
+	}
+	return text, suspected
+}
+
+// summaryLooksInjected reports whether a generated summary matches a
+// signature of the model obeying injected instructions.
+func summaryLooksInjected(summary string) bool {
+	for _, pattern := range injectionPatterns {
+		if pattern.MatchString(summary) {
+			return true
+		}
+	}
+	for _, pattern := range injectedSummaryPatterns {
+		if pattern.MatchString(summary) {
+			return true
+		}
+	}
+	return false
+}
+
+// wrapArticleText fences article text in delimiters so the prompt can tell
+// the model where untrusted content starts and ends.
+func wrapArticleText(text string) string {
+	return articleOpenDelimiter + "\n" + strings.TrimSpace(text) + "\n" + articleCloseDelimiter
+}
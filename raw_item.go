@@ -0,0 +1,39 @@
+package main
+
+import (
+	"database/sql"
+	"errors"
+	"log/slog"
+	"net/http"
+)
+
+// getArticleRawItem serves the feed item an article was built from, as
+// stored when Content.StoreRawItem is on, for debugging parsing problems.
+// Articles saved without one are reported as not found.
+func (s *APIServer) getArticleRawItem(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	id, err := parseArticleID(r.URL.Query().Get("id"))
+	if err != nil {
+		http.Error(w, "Invalid id", http.StatusBadRequest)
+		return
+	}
+
+	var raw sql.NullString
+	err = s.db.QueryRow(`SELECT raw_item FROM articles WHERE id = $1`, id).Scan(&raw)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !raw.Valid) {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		slog.Error("Failed to load raw item of article", "article_id", id, "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte(raw.String))
+}
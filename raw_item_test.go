@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"information-broker/config"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+)
+
+func TestStoreRawItem(t *testing.T) {
+	for _, store := range []bool{false, true} {
+		t.Run(fmt.Sprintf("store=%v", store), func(t *testing.T) {
+			mux := http.NewServeMux()
+			var articleURL string
+			mux.HandleFunc("/feed", func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprintf(w, `<?xml version="1.0"?><rss version="2.0"><channel><title>Test</title>
+					<item><title>Raw article</title><link>%s</link><pubDate>%s</pubDate><description>desc</description><category>cve</category></item>
+					</channel></rss>`, articleURL, time.Now().UTC().Format(time.RFC1123Z))
+			})
+			mux.HandleFunc("/article", func(w http.ResponseWriter, r *http.Request) {
+				io.WriteString(w, "<html><body><article>"+strings.Repeat("Article body. ", 20)+"</article></body></html>")
+			})
+			srv := httptest.NewServer(mux)
+			defer srv.Close()
+			articleURL = srv.URL + "/article"
+
+			db, recorder := openExecRecorder(t)
+			cfg := &config.Config{
+				API:         config.APIConfig{Timeout: 5 * time.Second},
+				OLLAMA:      config.OLLAMAConfig{Model: "llama3"},
+				Content:     config.ContentConfig{StoreRawItem: store},
+				Performance: config.PerformanceConfig{MaxConcurrentFeeds: 1, MaxArticleContentLength: 10000},
+			}
+			scheduler := &SummarizationScheduler{config: cfg, metrics: testMetrics(), queueCap: 10, queueReady: make(chan struct{}, 1)}
+			m := NewRSSMonitor(db, []Feed{{URL: srv.URL + "/feed"}}, testMetrics(), cfg, NewCircuitBreakerManager(), scheduler, nil)
+			m.fetchFeed(context.Background(), srv.URL+"/feed")
+
+			inserts := recorder.recordedWrites("INSERT INTO articles")
+			if len(inserts) != 1 {
+				t.Fatalf("recorded %d article inserts, want 1", len(inserts))
+			}
+			raw, _ := inserts[0][11].(string)
+			if !store {
+				if raw != "" {
+					t.Errorf("raw item stored while disabled: %s", raw)
+				}
+				return
+			}
+			var item gofeed.Item
+			if err := json.Unmarshal([]byte(raw), &item); err != nil {
+				t.Fatalf("stored raw item %q: %v", raw, err)
+			}
+			if item.Title != "Raw article" || item.Link != articleURL || len(item.Categories) != 1 || item.Categories[0] != "cve" {
+				t.Errorf("raw item round-tripped to %+v", item)
+			}
+		})
+	}
+}
+
+func TestGetArticleRawItem(t *testing.T) {
+	const raw = `{"title":"Raw article","link":"https://example.com/a"}`
+	tests := []struct {
+		name     string
+		target   string
+		answer   []driver.Value // nil: no such article
+		wantCode int
+		wantBody string
+	}{
+		{"stored", "/articles/raw?id=1", []driver.Value{raw}, http.StatusOK, raw},
+		{"not stored", "/articles/raw?id=1", []driver.Value{nil}, http.StatusNotFound, ""},
+		{"no such article", "/articles/raw?id=1", nil, http.StatusNotFound, ""},
+		{"invalid id", "/articles/raw?id=abc", nil, http.StatusBadRequest, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, recorder := openExecRecorder(t)
+			if tt.answer != nil {
+				recorder.answer("SELECT raw_item", tt.answer)
+			} else {
+				recorder.answer("SELECT raw_item")
+			}
+			s := &APIServer{db: db}
+
+			rec := httptest.NewRecorder()
+			s.getArticleRawItem(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+			if rec.Code != tt.wantCode {
+				t.Fatalf("GET %s = %d, want %d", tt.target, rec.Code, tt.wantCode)
+			}
+			if tt.wantBody != "" {
+				if got := rec.Body.String(); got != tt.wantBody {
+					t.Errorf("body = %q, want %q", got, tt.wantBody)
+				}
+				if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+					t.Errorf("Content-Type = %q", ct)
+				}
+			}
+		})
+	}
+}
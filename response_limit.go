@@ -0,0 +1,54 @@
+package main
+
+import "io"
+
+// maxDrainBytes is how much of an unread response body drainAndClose reads
+// to keep the connection; past it, closing the connection is cheaper.
+const maxDrainBytes = 64 << 10
+
+// cappedReader reads at most limit bytes of a response body, so a huge or
+// endless response can't exhaust memory before it is parsed, and remembers
+// whether the body went on past the cap. A limit of 0 or less reads
+// everything.
+type cappedReader struct {
+	r         io.Reader
+	remaining int64
+	limited   bool
+	truncated bool
+}
+
+func newCappedReader(r io.Reader, limit int64) *cappedReader {
+	return &cappedReader{r: r, remaining: limit, limited: limit > 0}
+}
+
+func (c *cappedReader) Read(p []byte) (int, error) {
+	if !c.limited {
+		return c.r.Read(p)
+	}
+	if c.remaining <= 0 {
+		// Probe for one more byte to tell a body that ends exactly at the
+		// cap from one that was cut off
+		if !c.truncated {
+			var probe [1]byte
+			if n, _ := c.r.Read(probe[:]); n > 0 {
+				c.truncated = true
+			}
+		}
+		return 0, io.EOF
+	}
+	if int64(len(p)) > c.remaining {
+		p = p[:c.remaining]
+	}
+	n, err := c.r.Read(p)
+	c.remaining -= int64(n)
+	return n, err
+}
+
+// drainAndClose reads what is left of a short response body before closing
+// it. The transport only returns a connection to the idle pool once its body
+// was read to the end; error pages and feeds the parser stopped reading
+// would otherwise cost a new connection on the next fetch.
+func drainAndClose(body io.ReadCloser) {
+	io.CopyN(io.Discard, body, maxDrainBytes)
+	body.Close()
+}
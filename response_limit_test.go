@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"information-broker/config"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCappedReader(t *testing.T) {
+	tests := []struct {
+		name          string
+		body          string
+		limit         int64
+		want          string
+		wantTruncated bool
+	}{
+		{"under the cap", "short", 10, "short", false},
+		{"exactly the cap", "0123456789", 10, "0123456789", false},
+		{"over the cap", "0123456789abc", 10, "0123456789", true},
+		{"no cap", "0123456789abc", 0, "0123456789abc", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newCappedReader(strings.NewReader(tt.body), tt.limit)
+			got, err := io.ReadAll(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want || r.truncated != tt.wantTruncated {
+				t.Errorf("read %q (truncated %v), want %q (truncated %v)", got, r.truncated, tt.want, tt.wantTruncated)
+			}
+		})
+	}
+}
+
+func TestFetchFeedCapsEndlessResponse(t *testing.T) {
+	// A feed that never stops sending
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `<?xml version="1.0"?><rss version="2.0"><channel><title>Endless</title><description>`)
+		chunk := strings.Repeat("x", 4096)
+		for r.Context().Err() == nil {
+			if _, err := io.WriteString(w, chunk); err != nil {
+				return
+			}
+		}
+	}))
+	defer srv.Close()
+
+	db, _ := openExecRecorder(t)
+	cfg := &config.Config{
+		API:         config.APIConfig{Timeout: 30 * time.Second},
+		Performance: config.PerformanceConfig{MaxConcurrentFeeds: 1, MaxResponseBytes: 64 * 1024},
+	}
+	m := NewRSSMonitor(db, []Feed{{URL: srv.URL}}, testMetrics(), cfg, NewCircuitBreakerManager(), nil, nil)
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := m.doFetchFeed(context.Background(), srv.URL, time.Now(), true)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("a feed cut off at the cap should fail to parse")
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("fetch kept reading past MaxResponseBytes")
+	}
+}
+
+func TestFeedFetchesReuseConnections(t *testing.T) {
+	var conns int32
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound) // An error page the fetcher never reads
+		io.WriteString(w, strings.Repeat("not here ", 500))
+	}))
+	srv.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&conns, 1)
+		}
+	}
+	srv.Start()
+	defer srv.Close()
+
+	db, _ := openExecRecorder(t)
+	cfg := &config.Config{
+		API:         config.APIConfig{Timeout: 5 * time.Second},
+		Performance: config.PerformanceConfig{MaxConcurrentFeeds: 1, FeedMaxIdleConnsPerHost: 2, FeedIdleConnTimeout: time.Minute},
+	}
+	m := NewRSSMonitor(db, nil, testMetrics(), cfg, NewCircuitBreakerManager(), nil, nil)
+	for i := 0; i < 3; i++ {
+		m.doFetchFeed(context.Background(), srv.URL+"/feed", time.Now(), true)
+	}
+	if got := atomic.LoadInt32(&conns); got != 1 {
+		t.Errorf("3 fetches opened %d connections, want 1 reused", got)
+	}
+}
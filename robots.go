@@ -0,0 +1,194 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxRobotsTxtBytes is how much of a robots.txt is read; crawlers commonly
+// ignore anything past 500 KiB.
+const maxRobotsTxtBytes = 500 << 10
+
+// RobotsChecker answers whether robots.txt lets us fetch an article page,
+// caching each host's rules for ttl. A host without a robots.txt allows
+// everything; so does one whose robots.txt can't be fetched, until the next
+// page of that host is checked.
+type RobotsChecker struct {
+	client    *http.Client
+	userAgent string // Sent when fetching robots.txt, and matched against its groups
+	ttl       time.Duration
+	now       func() time.Time
+
+	mu    sync.Mutex
+	hosts map[string]robotsEntry // scheme://host -> rules
+}
+
+// robotsEntry is the cached robots.txt of a host.
+type robotsEntry struct {
+	rules   []robotsRule
+	fetched time.Time
+}
+
+// robotsRule is an Allow or Disallow line of the group that applies to us.
+type robotsRule struct {
+	allow   bool
+	pattern string // As written, for picking the most specific match
+	match   *regexp.Regexp
+}
+
+func NewRobotsChecker(client *http.Client, userAgent string, ttl time.Duration) *RobotsChecker {
+	return &RobotsChecker{
+		client:    client,
+		userAgent: userAgent,
+		ttl:       ttl,
+		now:       time.Now,
+		hosts:     make(map[string]robotsEntry),
+	}
+}
+
+// Allowed reports whether robots.txt permits fetching pageURL. URLs that
+// don't parse are allowed; fetching them fails soon enough anyway.
+func (c *RobotsChecker) Allowed(ctx context.Context, pageURL string) bool {
+	u, err := url.Parse(pageURL)
+	if err != nil || u.Host == "" {
+		return true
+	}
+	origin := u.Scheme + "://" + u.Host
+
+	c.mu.Lock()
+	entry, ok := c.hosts[origin]
+	c.mu.Unlock()
+	if !ok || c.now().Sub(entry.fetched) >= c.ttl {
+		rules, err := c.fetchRules(ctx, origin)
+		if err != nil {
+			slog.Warn("Failed to fetch robots.txt, allowing the page", "origin", origin, "error", err)
+			return true
+		}
+		entry = robotsEntry{rules: rules, fetched: c.now()}
+		c.mu.Lock()
+		c.hosts[origin] = entry
+		c.mu.Unlock()
+	}
+
+	path := u.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if u.RawQuery != "" {
+		path += "?" + u.RawQuery
+	}
+	return robotsAllowed(entry.rules, path)
+}
+
+// fetchRules downloads origin's robots.txt and returns the rules that apply
+// to us. Any answer but 200 OK means there are none; only a failed request
+// is an error.
+func (c *RobotsChecker) fetchRules(ctx context.Context, origin string) ([]robotsRule, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, origin+"/robots.txt", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", c.userAgent)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, nil // No robots.txt
+	}
+	return parseRobotsTxt(io.LimitReader(resp.Body, maxRobotsTxtBytes), robotsAgentToken(c.userAgent))
+}
+
+// robotsAgentToken is the product token of a User-Agent, the part robots.txt
+// groups name: "Information-Broker" for "Information-Broker/1.0".
+func robotsAgentToken(userAgent string) string {
+	token, _, _ := strings.Cut(strings.TrimSpace(userAgent), "/")
+	if fields := strings.Fields(token); len(fields) > 0 {
+		token = fields[0]
+	}
+	return strings.ToLower(token)
+}
+
+// parseRobotsTxt returns the rules of the groups naming agent, or of the *
+// group if none does.
+func parseRobotsTxt(r io.Reader, agent string) ([]robotsRule, error) {
+	var ownRules, wildcardRules []robotsRule
+	var groupAgents []string
+	inRules := false // A rule line ended the current group's User-agent lines
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		key, value = strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			if inRules {
+				groupAgents, inRules = nil, false
+			}
+			groupAgents = append(groupAgents, strings.ToLower(value))
+		case "allow", "disallow":
+			inRules = true
+			if value == "" {
+				continue // An empty Disallow allows everything
+			}
+			rule := robotsRule{allow: key == "allow", pattern: value, match: robotsPattern(value)}
+			if slices.Contains(groupAgents, agent) {
+				ownRules = append(ownRules, rule)
+			} else if slices.Contains(groupAgents, "*") {
+				wildcardRules = append(wildcardRules, rule)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read robots.txt: %w", err)
+	}
+
+	if ownRules != nil {
+		return ownRules, nil
+	}
+	return wildcardRules, nil
+}
+
+// robotsPattern compiles a robots.txt path pattern: a prefix, in which *
+// matches anything and a trailing $ anchors the end.
+func robotsPattern(pattern string) *regexp.Regexp {
+	anchored := strings.HasSuffix(pattern, "$")
+	pattern = strings.TrimSuffix(pattern, "$")
+	expr := "^" + strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*")
+	if anchored {
+		expr += "$"
+	}
+	return regexp.MustCompile(expr)
+}
+
+// robotsAllowed applies rules to path: the longest matching pattern decides,
+// Allow winning a tie, and a path no rule matches is allowed.
+func robotsAllowed(rules []robotsRule, path string) bool {
+	allowed, longest := true, -1
+	for _, rule := range rules {
+		if !rule.match.MatchString(path) {
+			continue
+		}
+		if len(rule.pattern) > longest || (len(rule.pattern) == longest && rule.allow) {
+			allowed, longest = rule.allow, len(rule.pattern)
+		}
+	}
+	return allowed
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"information-broker/config"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+)
+
+const testRobotsTxt = `# Comments and unknown lines are ignored
+User-agent: Googlebot
+Disallow: /
+
+User-agent: information-broker
+User-agent: OtherBot
+Disallow: /private/
+Allow: /private/press/
+Disallow: /*.pdf$
+
+User-agent: *
+Disallow: /
+`
+
+func TestParseRobotsTxt(t *testing.T) {
+	tests := []struct {
+		agent string
+		path  string
+		want  bool
+	}{
+		{"information-broker", "/news/1", true},
+		{"information-broker", "/private/notes", false},
+		{"information-broker", "/private/press/release", true}, // The longer Allow wins
+		{"information-broker", "/files/report.pdf", false},
+		{"information-broker", "/files/report.pdf?page=2", true}, // $ anchors the end
+		{"otherbot", "/private/x", false},
+		{"somebot", "/news/1", false}, // Falls to the * group
+	}
+	for _, tt := range tests {
+		rules, err := parseRobotsTxt(strings.NewReader(testRobotsTxt), tt.agent)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := robotsAllowed(rules, tt.path); got != tt.want {
+			t.Errorf("%s %s allowed = %v, want %v", tt.agent, tt.path, got, tt.want)
+		}
+	}
+
+	if rules, _ := parseRobotsTxt(strings.NewReader("User-agent: *\nDisallow:\n"), "information-broker"); !robotsAllowed(rules, "/anything") {
+		t.Error("an empty Disallow should allow everything")
+	}
+	if got := robotsAgentToken("Information-Broker/1.0 (+https://example.com)"); got != "information-broker" {
+		t.Errorf("robotsAgentToken() = %q", got)
+	}
+}
+
+func TestRobotsCheckerCachesPerHost(t *testing.T) {
+	var fetches int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/robots.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		atomic.AddInt32(&fetches, 1)
+		io.WriteString(w, testRobotsTxt)
+	}))
+	defer srv.Close()
+
+	now := time.Now()
+	c := NewRobotsChecker(srv.Client(), "Information-Broker/1.0", time.Hour)
+	c.now = func() time.Time { return now }
+
+	if c.Allowed(context.Background(), srv.URL+"/private/notes") {
+		t.Error("disallowed path allowed")
+	}
+	if !c.Allowed(context.Background(), srv.URL+"/news/1") {
+		t.Error("allowed path disallowed")
+	}
+	if n := atomic.LoadInt32(&fetches); n != 1 {
+		t.Errorf("robots.txt fetched %d times within its TTL, want 1", n)
+	}
+	now = now.Add(time.Hour)
+	c.Allowed(context.Background(), srv.URL+"/news/2")
+	if n := atomic.LoadInt32(&fetches); n != 2 {
+		t.Errorf("robots.txt fetched %d times after its TTL, want 2", n)
+	}
+
+	// Hosts without a robots.txt, or that can't be reached, allow everything
+	missing := httptest.NewServer(http.NotFoundHandler())
+	defer missing.Close()
+	if !c.Allowed(context.Background(), missing.URL+"/private/notes") {
+		t.Error("host without robots.txt should allow everything")
+	}
+	if !c.Allowed(context.Background(), "http://127.0.0.1:1/page") {
+		t.Error("unreachable host should allow the page")
+	}
+}
+
+func TestLoadArticleContentRespectsRobotsTxt(t *testing.T) {
+	var pageFetches int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/robots.txt", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "User-agent: *\nDisallow: /members/\n")
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&pageFetches, 1)
+		io.WriteString(w, "<html><body><article>"+strings.Repeat("Page body. ", 20)+"</article></body></html>")
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	for _, respect := range []bool{false, true} {
+		atomic.StoreInt32(&pageFetches, 0)
+		cfg := &config.Config{
+			API:         config.APIConfig{Timeout: 5 * time.Second, UserAgent: "Information-Broker/1.0"},
+			Content:     config.ContentConfig{RespectRobotsTxt: respect, RobotsTxtCacheTTL: time.Hour},
+			Performance: config.PerformanceConfig{MaxArticleContentLength: 10000},
+		}
+		m := NewRSSMonitor(nil, nil, testMetrics(), cfg, NewCircuitBreakerManager(), nil, nil)
+		item := &gofeed.Item{Link: srv.URL + "/members/story", Description: "The feed's description."}
+
+		content, source, _, _ := m.loadArticleContent(item, srv.URL+"/feed", newContentBudget(0))
+		fetched := atomic.LoadInt32(&pageFetches)
+		if respect {
+			if fetched != 0 || source != contentSourceDescription || content != item.Description {
+				t.Errorf("disallowed page: fetched %d times, content from %s (%q), want the description unfetched", fetched, source, content)
+			}
+		} else if fetched != 1 || source != contentSourceScraped {
+			t.Errorf("robots.txt ignored: fetched %d times, content from %s, want the page", fetched, source)
+		}
+	}
+}
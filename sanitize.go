@@ -34,3 +34,25 @@ func safeTruncate(s string, maxBytes int) string {
 	}
 	return s[:maxBytes]
 }
+
+// buildArticlePreview returns a short plain-text preview of content for list
+// views: markup is stripped (content that fell back to the feed's HTML
+// description has plenty), whitespace is collapsed to single spaces and the
+// text is cut at the last word boundary within maxChars bytes, with "..."
+// appended when anything was dropped. A single word longer than maxChars is
+// cut on a rune boundary.
+func buildArticlePreview(content string, maxChars int) string {
+	text := plainText(content)
+	if len(text) <= maxChars {
+		return text
+	}
+
+	cut := safeTruncate(text, maxChars)
+	// Back off to the last space only if the cut landed inside a word
+	if text[len(cut)] != ' ' {
+		if i := strings.LastIndexByte(cut, ' '); i > 0 {
+			cut = cut[:i]
+		}
+	}
+	return strings.TrimRight(cut, " ") + "..."
+}
@@ -47,3 +47,45 @@ func TestSanitizeUTF8PreservesValid(t *testing.T) {
 		}
 	}
 }
+
+func TestBuildArticlePreview(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		maxChars int
+		want     string
+	}{
+		{"short content unchanged", "A short post.", 200, "A short post."},
+		{"whitespace collapsed", "  Line one.\n\n\tLine   two.  ", 200, "Line one. Line two."},
+		{"feed HTML stripped", `<p>Patch <a href="https://example.com/fix">now</a> &amp; reboot.</p>`, 200, "Patch now & reboot."},
+		{"feed HTML cut on its text", "<p>Attackers <b>exploited</b> a critical flaw in the gateway</p>", 24, "Attackers exploited a..."},
+		{"cut at word boundary", "Attackers exploited a critical flaw in the gateway", 24, "Attackers exploited a..."},
+		{"cut exactly before a space keeps the whole word", "Attackers exploited a critical flaw", 21, "Attackers exploited a..."},
+		{"single long word cut on rune boundary", "Supercalifragilistic", 10, "Supercalif..."},
+		{"empty content", "   ", 200, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := buildArticlePreview(tt.content, tt.maxChars)
+			if got != tt.want {
+				t.Errorf("buildArticlePreview(%q, %d) = %q, want %q", tt.content, tt.maxChars, got, tt.want)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("preview %q is not valid UTF-8", got)
+			}
+			if len(strings.TrimSuffix(got, "...")) > tt.maxChars {
+				t.Errorf("preview %q exceeds %d chars", got, tt.maxChars)
+			}
+		})
+	}
+
+	t.Run("multibyte text never splits a rune", func(t *testing.T) {
+		content := strings.Repeat("données… ", 40)
+		for n := 1; n < 60; n++ {
+			if got := buildArticlePreview(content, n); !utf8.ValidString(got) {
+				t.Fatalf("buildArticlePreview(_, %d) = %q is not valid UTF-8", n, got)
+			}
+		}
+	})
+}
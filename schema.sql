@@ -27,7 +27,45 @@ CREATE TABLE IF NOT EXISTS articles (
     -- similarity comparisons (no pgvector -- plain Postgres array, compared in Go);
     -- story_cluster_id is self-referencing (a cluster's seed article's own id).
     summary_embedding real[],
-    story_cluster_id BIGINT
+    story_cluster_id BIGINT,
+
+    -- Short plain-text excerpt of full_content for list views
+    preview TEXT,
+
+    -- Saved with the feed description because the feed's per-cycle
+    -- content-fetch budget ran out; cleared by `backfill --deferred`
+    needs_content_refetch BOOLEAN NOT NULL DEFAULT FALSE,
+
+    -- Discord post skipped while the Discord circuit breaker was open;
+    -- cleared when the scheduler replays it
+    discord_replay_pending BOOLEAN NOT NULL DEFAULT FALSE,
+
+    -- Link as the feed gave it, when url is its normalized form
+    original_url TEXT,
+
+    -- Summarization failed; retried until this time, then posted without a
+    -- summary (SUMMARY_GRACE_PERIOD)
+    summary_grace_until TIMESTAMP WITH TIME ZONE,
+    language TEXT,
+
+    -- The feed item the article came from, as JSON (CONTENT_STORE_RAW_ITEM)
+    raw_item JSONB,
+
+    -- Replays of a failed summarization, and whether they gave up on it
+    summary_replay_attempts INTEGER NOT NULL DEFAULT 0,
+    summary_abandoned BOOLEAN NOT NULL DEFAULT FALSE,
+
+    -- SimHash of the content and its bands, for near-duplicate lookup
+    -- (CONTENT_NEAR_DUPLICATE_INDEX)
+    simhash BIGINT,
+    simhash_bands INTEGER[],
+
+    -- When the article first reached Discord (posting SLA of /feeds/sla)
+    discord_posted_at TIMESTAMP WITH TIME ZONE,
+
+    -- The feed item's author name(s), and its categories as lowercased tags
+    author TEXT,
+    tags TEXT[] NOT NULL DEFAULT '{}'
 );
 
 -- Webhook logs table for tracking Discord webhook attempts
@@ -51,6 +89,7 @@ CREATE INDEX IF NOT EXISTS idx_articles_posted_to_discord ON articles(posted_to_
 CREATE INDEX IF NOT EXISTS idx_articles_feed_url ON articles(feed_url);
 CREATE INDEX IF NOT EXISTS idx_articles_created_at ON articles(created_at DESC);
 CREATE INDEX IF NOT EXISTS idx_articles_updated_at ON articles(updated_at DESC);
+CREATE INDEX IF NOT EXISTS idx_articles_discord_replay_pending ON articles(publish_date) WHERE discord_replay_pending;
 
 -- Trigram indexes back the /articles?q= ILIKE search (title/summary/full_content).
 CREATE EXTENSION IF NOT EXISTS pg_trgm;
@@ -58,6 +97,15 @@ CREATE INDEX IF NOT EXISTS idx_articles_title_trgm ON articles USING GIN (title
 CREATE INDEX IF NOT EXISTS idx_articles_summary_trgm ON articles USING GIN (summary gin_trgm_ops);
 CREATE INDEX IF NOT EXISTS idx_articles_full_content_trgm ON articles USING GIN (full_content gin_trgm_ops);
 
+-- Full-text index backing /search (must match articleSearchVector in search.go).
+CREATE INDEX IF NOT EXISTS idx_articles_search_fts ON articles USING GIN (to_tsvector('english', COALESCE(title, '') || ' ' || COALESCE(full_content, '')));
+
+-- Near-duplicate lookup by SimHash band (see simhash.go)
+CREATE INDEX IF NOT EXISTS idx_articles_simhash_bands ON articles USING GIN (simhash_bands);
+
+-- /articles?tag= filter
+CREATE INDEX IF NOT EXISTS idx_articles_tags ON articles USING GIN (tags);
+
 -- Story-clustering index
 CREATE INDEX IF NOT EXISTS idx_articles_story_cluster_id ON articles(story_cluster_id);
 
@@ -72,6 +120,53 @@ CREATE INDEX IF NOT EXISTS idx_articles_feed_publish ON articles(feed_url, publi
 CREATE INDEX IF NOT EXISTS idx_articles_discord_fetch ON articles(posted_to_discord, fetch_time DESC);
 CREATE INDEX IF NOT EXISTS idx_webhook_logs_article_attempt ON webhook_logs(article_id, attempt DESC);
 
+-- Feeds failing since failing_since, and those disabled for failing longer
+-- than DEAD_FEED_THRESHOLD; restored at startup
+CREATE TABLE IF NOT EXISTS feed_state (
+    feed_url TEXT PRIMARY KEY,
+    failing_since TIMESTAMP WITH TIME ZONE,
+    disabled BOOLEAN NOT NULL DEFAULT FALSE,
+    disabled_at TIMESTAMP WITH TIME ZONE,
+    last_error TEXT,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Last known state of each circuit breaker, restored at startup when
+-- CIRCUIT_BREAKER_PERSIST is on
+CREATE TABLE IF NOT EXISTS circuit_breaker_state (
+    name TEXT PRIMARY KEY,
+    state TEXT NOT NULL,
+    failure_count INTEGER NOT NULL DEFAULT 0,
+    success_count INTEGER NOT NULL DEFAULT 0,
+    last_failure_time TIMESTAMP WITH TIME ZONE,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Summarization requests that are queued or being processed, reloaded into
+-- the queue at startup so a restart doesn't lose them
+CREATE TABLE IF NOT EXISTS pending_summarizations (
+    article_url TEXT PRIMARY KEY,
+    article_title TEXT NOT NULL,
+    feed_url TEXT NOT NULL DEFAULT '',
+    lead TEXT NOT NULL DEFAULT '',
+    content TEXT NOT NULL DEFAULT '',
+    model TEXT NOT NULL,
+    content_source TEXT NOT NULL DEFAULT '',
+    priority INTEGER NOT NULL DEFAULT 0,
+    enqueued_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    request_id TEXT NOT NULL DEFAULT ''
+);
+
+-- Discord messages posted for each article, so DISCORD_ON_ARTICLE_UPDATE=edit
+-- can edit them when the article changes
+CREATE TABLE IF NOT EXISTS discord_messages (
+    article_url TEXT NOT NULL,
+    webhook_url TEXT NOT NULL,
+    message_id TEXT NOT NULL,
+    posted_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (article_url, webhook_url)
+);
+
 -- Function to automatically update updated_at timestamp
 CREATE OR REPLACE FUNCTION update_updated_at_column()
 RETURNS TRIGGER AS $$
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// articleSearchVector is the tsvector expression /search matches against.
+// createTables builds a GIN index on this exact expression, so the two must
+// stay in sync for the planner to use it.
+const articleSearchVector = `to_tsvector('english', COALESCE(title, '') || ' ' || COALESCE(full_content, ''))`
+
+// SearchResult is one /search hit: the usual list view plus its relevance.
+type SearchResult struct {
+	ArticleView
+	Rank float64 `json:"rank"`
+}
+
+// buildSearchQuery constructs the SQL and ordered args for a ranked full-text
+// search over title and full_content. Like buildArticlesQuery it returns the
+// preview rather than the full text.
+func buildSearchQuery(q string, limit, offset int) (string, []interface{}) {
+	query := fmt.Sprintf(`SELECT id, title, url, summary, COALESCE(preview, ''), publish_date, fetch_duration_ms, feed_url, content_hash, COALESCE(language, ''),
+			COALESCE(author, ''), tags,
+			ts_rank(%[1]s, plainto_tsquery('english', $1)) AS rank
+		FROM articles
+		WHERE %[1]s @@ plainto_tsquery('english', $1)
+		ORDER BY rank DESC, publish_date DESC
+		LIMIT $2 OFFSET $3`, articleSearchVector)
+	return query, []interface{}{q, limit, offset}
+}
+
+// searchArticles runs a full-text search for the q parameter, most relevant first.
+func (s *APIServer) searchArticles(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := strings.TrimSpace(r.URL.Query().Get("q"))
+	if q == "" {
+		http.Error(w, "Missing search query", http.StatusBadRequest)
+		return
+	}
+	limit, offset, err := parsePagination(r, 50, 100)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	query, args := buildSearchQuery(q, limit, offset)
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		slog.Error("Database query error", "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+	defer rows.Close()
+
+	results := []SearchResult{}
+	for rows.Next() {
+		var result SearchResult
+		var fetchDurationMs int64
+
+		err := rows.Scan(
+			&result.ID,
+			&result.Title,
+			&result.URL,
+			&result.Summary,
+			&result.Preview,
+			&result.PublishedAt,
+			&fetchDurationMs,
+			&result.FeedURL,
+			&result.ContentHash,
+			&result.Language,
+			&result.Author,
+			pq.Array(&result.Tags),
+			&result.Rank,
+		)
+		if err != nil {
+			slog.Error("Row scan error", "error", err)
+			continue
+		}
+
+		result.FetchDuration = time.Duration(fetchDurationMs) * time.Millisecond
+		results = append(results, result)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"query":    q,
+		"articles": results,
+		"count":    len(results),
+		"limit":    limit,
+		"offset":   offset,
+	})
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestBuildSearchQuery(t *testing.T) {
+	q, args := buildSearchQuery("ransomware gang", 20, 40)
+
+	if !strings.Contains(q, "plainto_tsquery('english', $1)") {
+		t.Fatalf("expected the search term bound as $1 via plainto_tsquery: %s", q)
+	}
+	if !strings.Contains(q, articleSearchVector+" @@") {
+		t.Fatalf("WHERE must use the indexed expression verbatim: %s", q)
+	}
+	if !strings.Contains(q, "ts_rank(") || !strings.Contains(q, "ORDER BY rank DESC") {
+		t.Fatalf("expected ranking by ts_rank: %s", q)
+	}
+	if strings.Contains(q[:strings.Index(q, "ts_rank")], "full_content,") {
+		t.Fatalf("search results should not select full_content: %s", q)
+	}
+	if len(args) != 3 || args[0] != "ransomware gang" || args[1] != 20 || args[2] != 40 {
+		t.Fatalf("unexpected args: %v", args)
+	}
+}
+
+func TestSearchArticlesRejectsBlankQuery(t *testing.T) {
+	s := &APIServer{}
+	for _, target := range []string{"/search", "/search?q=", "/search?q=%20%20%09"} {
+		rec := httptest.NewRecorder()
+		s.searchArticles(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("GET %s: status = %d, want 400", target, rec.Code)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"information-broker/config"
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// selfTestDBTimeout bounds the database ping; the other checks are bounded
+// by their own client timeouts.
+const selfTestDBTimeout = 10 * time.Second
+
+// selfTestArticle is the text summarized by the OLLAMA probe: short, so the
+// probe is quick, but a real summarization through the configured backends.
+const selfTestArticle = "Information Broker is starting up. This short text is summarized at startup to check that the configured summarization backend is reachable and answering."
+
+// selfTestCheck is one startup check.
+type selfTestCheck struct {
+	name string
+	run  func(ctx context.Context) error
+}
+
+// SelfTestResult is the outcome of one startup check.
+type SelfTestResult struct {
+	Name     string
+	Err      error
+	Duration time.Duration
+}
+
+// Passed reports whether the check succeeded.
+func (r SelfTestResult) Passed() bool {
+	return r.Err == nil
+}
+
+// runSelfTest runs every check in order, even after a failure, so the report
+// covers all of them.
+func runSelfTest(ctx context.Context, checks []selfTestCheck) []SelfTestResult {
+	results := make([]SelfTestResult, 0, len(checks))
+	for _, check := range checks {
+		start := time.Now()
+		err := check.run(ctx)
+		results = append(results, SelfTestResult{Name: check.name, Err: err, Duration: time.Since(start)})
+	}
+	return results
+}
+
+// startupSelfTestChecks returns the checks STARTUP_SELF_TEST runs: a
+// database ping, a tiny summarization, and, if a test webhook is
+// configured, a Discord test message.
+func startupSelfTestChecks(db *sql.DB, cfg *config.Config, summarizer *ArticleSummarizer, discordSender *DiscordWebhookSender) []selfTestCheck {
+	checks := []selfTestCheck{
+		{name: "database", run: func(ctx context.Context) error {
+			ctx, cancel := context.WithTimeout(ctx, selfTestDBTimeout)
+			defer cancel()
+			return db.PingContext(ctx)
+		}},
+		{name: "ollama", run: func(ctx context.Context) error {
+			return probeSummarizer(ctx, summarizer, cfg.OLLAMA.Model)
+		}},
+	}
+
+	if webhookURL := cfg.App.SelfTestDiscordWebhook; webhookURL != "" {
+		checks = append(checks, selfTestCheck{name: "discord", run: func(ctx context.Context) error {
+			_, err := discordSender.SendArticleWithFailover(ctx, []string{webhookURL}, ArticleMessage{
+				Title:       "Information Broker self-test",
+				URL:         fmt.Sprintf("http://localhost:%d/health", cfg.App.Port),
+				Summary:     "Startup self-test message. Discord notifications are configured correctly.",
+				PublishDate: time.Now(),
+			})
+			return err
+		}})
+	}
+	return checks
+}
+
+// probeSummarizer runs one summarization of selfTestArticle through the
+// configured backends, without the retries and logging of a real article.
+func probeSummarizer(ctx context.Context, summarizer *ArticleSummarizer, model string) error {
+	prompt := summarizer.createSummaryPrompt(summaryInput{Body: selfTestArticle})
+	summary, _, err := summarizer.summarizeWithFallback(ctx, prompt, model, summarizer.summaryLimit(summaryInput{}))
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(summary) == "" {
+		return fmt.Errorf("backend returned an empty summary")
+	}
+	return nil
+}
+
+// runStartupSelfTest runs the checks and logs pass/fail for each. If any
+// failed it returns an error when abort is set; otherwise failures are only
+// logged as warnings.
+func runStartupSelfTest(ctx context.Context, checks []selfTestCheck, abort bool) error {
+	slog.Info("Running startup self-test", "checks", len(checks))
+
+	var failed []string
+	for _, result := range runSelfTest(ctx, checks) {
+		if result.Passed() {
+			slog.Info("Self-test check passed", "check", result.Name, "duration", result.Duration.Round(time.Millisecond))
+		} else {
+			slog.Error("Self-test check failed", "check", result.Name, "duration", result.Duration.Round(time.Millisecond), "error", result.Err)
+			failed = append(failed, result.Name)
+		}
+	}
+
+	if len(failed) == 0 {
+		slog.Info("Startup self-test passed")
+		return nil
+	}
+	if abort {
+		return fmt.Errorf("startup self-test failed: %s", strings.Join(failed, ", "))
+	}
+	slog.Warn("Startup self-test failed; continuing because STARTUP_SELF_TEST_ABORT is off", "failed_checks", strings.Join(failed, ", "))
+	return nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestStartupSelfTestReportsFailingOllama(t *testing.T) {
+	var hits int32
+	ollama := newOllamaStub(t, http.StatusInternalServerError, "", &hits)
+	summarizer := newFallbackTestSummarizer(ollama.URL, nil, nil)
+
+	checks := []selfTestCheck{
+		{name: "database", run: func(context.Context) error { return nil }},
+		{name: "ollama", run: func(ctx context.Context) error { return probeSummarizer(ctx, summarizer, "llama2") }},
+	}
+
+	results := runSelfTest(context.Background(), checks)
+	if len(results) != 2 || !results[0].Passed() || results[1].Passed() {
+		t.Fatalf("results = %+v, want database passing and ollama failing", results)
+	}
+	if hits != 1 {
+		t.Errorf("ollama probe made %d requests, want 1", hits)
+	}
+
+	if err := runStartupSelfTest(context.Background(), checks, false); err != nil {
+		t.Errorf("warn mode returned %v, want startup to continue", err)
+	}
+	err := runStartupSelfTest(context.Background(), checks, true)
+	if err == nil || !strings.Contains(err.Error(), "ollama") || strings.Contains(err.Error(), "database") {
+		t.Errorf("abort mode returned %v, want an error naming only the ollama check", err)
+	}
+}
+
+func TestStartupSelfTestPasses(t *testing.T) {
+	var hits int32
+	ollama := newOllamaStub(t, http.StatusOK, "Information Broker is starting.", &hits)
+	summarizer := newFallbackTestSummarizer(ollama.URL, nil, nil)
+
+	checks := []selfTestCheck{
+		{name: "ollama", run: func(ctx context.Context) error { return probeSummarizer(ctx, summarizer, "llama2") }},
+	}
+	if err := runStartupSelfTest(context.Background(), checks, true); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	// Every check runs even after an earlier one fails
+	ran := 0
+	checks = []selfTestCheck{
+		{name: "first", run: func(context.Context) error { ran++; return errors.New("down") }},
+		{name: "second", run: func(context.Context) error { ran++; return nil }},
+	}
+	runSelfTest(context.Background(), checks)
+	if ran != 2 {
+		t.Errorf("ran %d checks, want 2", ran)
+	}
+}
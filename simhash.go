@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math/bits"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/lib/pq"
+)
+
+// simHashBands is how many 16-bit bands a fingerprint is split into for the
+// simhash_bands index. Two fingerprints at most simHashBands-1 bits apart
+// share at least one band (pigeonhole), so such near-duplicates are always
+// found; further apart they are only found when a band happens to match.
+const simHashBands = 4
+
+// simHashBandBits is the width of one band.
+const simHashBandBits = 64 / simHashBands
+
+// NearDuplicate is an article whose content fingerprint is within the
+// requested Hamming distance of another.
+type NearDuplicate struct {
+	ID       int64  `json:"id"`
+	URL      string `json:"url"`
+	Title    string `json:"title"`
+	Distance int    `json:"distance"`
+}
+
+// contentSimHash returns the 64-bit SimHash of text over its word bigrams
+// (single words for one-word texts), so texts that differ in a few words get
+// fingerprints only a few bits apart. Empty text gives 0, which isn't stored.
+func contentSimHash(text string) uint64 {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) == 0 {
+		return 0
+	}
+	features := words
+	if len(words) > 1 {
+		features = make([]string, 0, len(words)-1)
+		for i := 1; i < len(words); i++ {
+			features = append(features, words[i-1]+" "+words[i])
+		}
+	}
+
+	var weights [64]int
+	hasher := fnv.New64a()
+	for _, feature := range features {
+		hasher.Reset()
+		hasher.Write([]byte(feature))
+		sum := hasher.Sum64()
+		for bit := 0; bit < 64; bit++ {
+			if sum&(1<<bit) != 0 {
+				weights[bit]++
+			} else {
+				weights[bit]--
+			}
+		}
+	}
+
+	var fingerprint uint64
+	for bit, weight := range weights {
+		if weight > 0 {
+			fingerprint |= 1 << bit
+		}
+	}
+	return fingerprint
+}
+
+// hammingDistance counts the bits in which two fingerprints differ.
+func hammingDistance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// simHashBandKeys splits a fingerprint into its bands, each tagged with its
+// position so equal values in different bands don't match. Articles sharing
+// any key are near-duplicate candidates.
+func simHashBandKeys(fingerprint uint64) []int32 {
+	keys := make([]int32, simHashBands)
+	for band := range keys {
+		value := (fingerprint >> (band * simHashBandBits)) & (1<<simHashBandBits - 1)
+		keys[band] = int32(band<<simHashBandBits | int(value))
+	}
+	return keys
+}
+
+// simHashColumns returns the simhash and simhash_bands values stored for a
+// fingerprint, both NULL for 0 (no fingerprint).
+func simHashColumns(fingerprint uint64) (any, pq.Int32Array) {
+	if fingerprint == 0 {
+		return nil, nil
+	}
+	return int64(fingerprint), pq.Int32Array(simHashBandKeys(fingerprint))
+}
+
+// FindNearDuplicates returns the articles whose content fingerprint is at
+// most hammingThreshold bits from fingerprint, closest first. Candidates come
+// from the GIN index on simhash_bands rather than a scan, so the lookup stays
+// fast as the table grows; it is exact up to simHashBands-1 bits.
+func (ops *DatabaseOperations) FindNearDuplicates(fingerprint uint64, hammingThreshold int) ([]NearDuplicate, error) {
+	if fingerprint == 0 || hammingThreshold < 0 {
+		return nil, nil
+	}
+	rows, err := ops.db.Query(`
+		SELECT id, url, title, simhash
+		FROM articles
+		WHERE simhash_bands && $1`,
+		pq.Int32Array(simHashBandKeys(fingerprint)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query near-duplicate candidates: %w", err)
+	}
+	defer rows.Close()
+
+	var duplicates []NearDuplicate
+	for rows.Next() {
+		var duplicate NearDuplicate
+		var candidate int64
+		if err := rows.Scan(&duplicate.ID, &duplicate.URL, &duplicate.Title, &candidate); err != nil {
+			return nil, fmt.Errorf("failed to scan near-duplicate candidate: %w", err)
+		}
+		duplicate.Distance = hammingDistance(fingerprint, uint64(candidate))
+		if duplicate.Distance <= hammingThreshold {
+			duplicates = append(duplicates, duplicate)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate near-duplicate candidates: %w", err)
+	}
+
+	sort.Slice(duplicates, func(i, j int) bool {
+		if duplicates[i].Distance != duplicates[j].Distance {
+			return duplicates[i].Distance < duplicates[j].Distance
+		}
+		return duplicates[i].ID < duplicates[j].ID
+	})
+	return duplicates, nil
+}
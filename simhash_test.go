@@ -0,0 +1,132 @@
+package main
+
+import (
+	"database/sql/driver"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+const simHashArticle = `Researchers disclosed a critical remote code execution flaw in the
+management interface of a widely deployed VPN appliance on Tuesday. The vendor
+has released firmware updates for all supported branches and urges customers
+to patch immediately, since proof-of-concept code is already circulating and
+scanning activity against exposed devices has increased sharply over the past
+two days. Administrators who cannot update right away should restrict access
+to the management interface to trusted networks.`
+
+func TestContentSimHash(t *testing.T) {
+	original := contentSimHash(simHashArticle)
+	if original == 0 {
+		t.Fatal("contentSimHash() of an article = 0")
+	}
+	if got := contentSimHash(strings.ToUpper(simHashArticle)); got != original {
+		t.Errorf("case changed the fingerprint: distance %d", hammingDistance(original, got))
+	}
+
+	syndicated := strings.Replace(simHashArticle, "Tuesday", "Wednesday", 1)
+	if d := hammingDistance(original, contentSimHash(syndicated)); d > 6 {
+		t.Errorf("one changed word is %d bits away, want at most 6", d)
+	}
+	unrelated := contentSimHash(`The city council approved the new budget for public parks and
+		libraries after a long debate about funding for road repairs next year.`)
+	if d := hammingDistance(original, unrelated); d < 16 {
+		t.Errorf("an unrelated text is only %d bits away", d)
+	}
+	if got := contentSimHash(" ... "); got != 0 {
+		t.Errorf("contentSimHash() without words = %x, want 0", got)
+	}
+}
+
+func TestSimHashBandKeys(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		a := rng.Uint64()
+		b := a
+		for flips := rng.Intn(simHashBands); flips > 0; flips-- {
+			b ^= 1 << rng.Intn(64)
+		}
+		if !sharesKey(simHashBandKeys(a), simHashBandKeys(b)) {
+			t.Fatalf("%x and %x are %d bits apart but share no band", a, b, hammingDistance(a, b))
+		}
+	}
+	// The same value in different bands isn't a match
+	if sharesKey(simHashBandKeys(0x0004000300020001), simHashBandKeys(0x0001000400030002)) {
+		t.Error("bands at different positions matched")
+	}
+}
+
+func sharesKey(a, b []int32) bool {
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func TestFindNearDuplicates(t *testing.T) {
+	probe := contentSimHash(simHashArticle)
+	db, recorder := openExecRecorder(t)
+	// Candidates as the band index would return them: anything sharing a band
+	recorder.answer("simhash_bands &&",
+		[]driver.Value{int64(3), "https://b.example/3", "Two bits off", int64(probe ^ 0b101)},
+		[]driver.Value{int64(1), "https://a.example/1", "Same story", int64(probe)},
+		[]driver.Value{int64(2), "https://c.example/2", "Shares a band only", int64(probe ^ 0xffffffffffff0000)},
+	)
+
+	got, err := NewDatabaseOperations(db).FindNearDuplicates(probe, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].ID != 1 || got[0].Distance != 0 || got[1].ID != 3 || got[1].Distance != 2 {
+		t.Errorf("FindNearDuplicates() = %+v, want articles 1 (distance 0) and 3 (distance 2)", got)
+	}
+
+	if got, err := NewDatabaseOperations(db).FindNearDuplicates(0, 3); err != nil || got != nil {
+		t.Errorf("FindNearDuplicates(0) = %v, %v; want no lookup", got, err)
+	}
+}
+
+// BenchmarkFindNearDuplicates looks up a fingerprint among 100,000 stored
+// ones. The stub database returns what the band index would: only the rows
+// sharing a band with the probe, not the whole table.
+func BenchmarkFindNearDuplicates(b *testing.B) {
+	rng := rand.New(rand.NewSource(1))
+	index := make(map[int32][]uint64)
+	for i := 0; i < 100000; i++ {
+		fingerprint := rng.Uint64()
+		for _, key := range simHashBandKeys(fingerprint) {
+			index[key] = append(index[key], fingerprint)
+		}
+	}
+	probe := rng.Uint64()
+	for _, key := range simHashBandKeys(probe ^ 0b11) {
+		index[key] = append(index[key], probe^0b11)
+	}
+
+	var rows [][]driver.Value
+	seen := make(map[uint64]bool)
+	for _, key := range simHashBandKeys(probe) {
+		for _, fingerprint := range index[key] {
+			if !seen[fingerprint] {
+				seen[fingerprint] = true
+				rows = append(rows, []driver.Value{int64(len(rows) + 1), "https://example.com/a", "Article", int64(fingerprint)})
+			}
+		}
+	}
+	db, recorder := openExecRecorder(b)
+	recorder.answer("simhash_bands &&", rows...)
+	ops := NewDatabaseOperations(db)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		duplicates, err := ops.FindNearDuplicates(probe, 3)
+		if err != nil || len(duplicates) != 1 {
+			b.Fatalf("FindNearDuplicates() = %v, %v; want the planted duplicate", duplicates, err)
+		}
+	}
+	b.ReportMetric(float64(len(rows)), "candidates/op")
+}
@@ -0,0 +1,297 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// Supported OLLAMA.Backend values.
+const (
+	backendOllama          = "ollama"
+	backendChatCompletions = "openai"
+)
+
+// SummarizationBackend sends a prompt to one LLM server and returns the raw
+// completion. Cleanup, length limits and retries are the summarizer's job and
+// stay the same whichever backend is in use.
+type SummarizationBackend interface {
+	Summarize(ctx context.Context, prompt, model string) (string, error)
+}
+
+// BackendError is the common shape for an error reported by a summarization
+// backend, whatever its native error JSON looks like.
+type BackendError struct {
+	Backend    string // "ollama" or "openai"
+	StatusCode int    // HTTP status, or 200 for an error inside a successful response
+	Message    string
+}
+
+func (e *BackendError) Error() string {
+	return fmt.Sprintf("%s API returned status %d: %s", e.Backend, e.StatusCode, e.Message)
+}
+
+// backendOptions carries the settings shared by every backend constructor.
+type backendOptions struct {
+	apiKey    string // Bearer token, for backends that take one
+	userAgent string
+
+	// stream makes Ollama send the completion as it is generated; reading
+	// stops early once more than streamWordLimit visible words have arrived
+	// (0 = read to the end).
+	stream          bool
+	streamWordLimit int
+}
+
+// newSummarizationBackend returns the backend of the given kind talking to
+// baseURL. An empty kind means Ollama.
+func newSummarizationBackend(kind, baseURL string, opts backendOptions, client *http.Client) (SummarizationBackend, error) {
+	switch strings.ToLower(kind) {
+	case "", backendOllama:
+		return &ollamaBackend{baseURL: baseURL, opts: opts, client: client}, nil
+	case backendChatCompletions:
+		return &chatCompletionsBackend{baseURL: baseURL, apiKey: opts.apiKey, userAgent: opts.userAgent, client: client}, nil
+	default:
+		return nil, fmt.Errorf("unknown summarization backend %q", kind)
+	}
+}
+
+// ollamaBackend calls Ollama's /api/generate endpoint.
+type ollamaBackend struct {
+	baseURL string
+	opts    backendOptions
+	client  *http.Client
+}
+
+func (b *ollamaBackend) Summarize(ctx context.Context, prompt, model string) (string, error) {
+	if b.opts.stream {
+		return b.summarizeStream(ctx, prompt, model)
+	}
+
+	reqPayload := SummaryRequest{
+		Model:  model,
+		Prompt: prompt,
+		Stream: false, // We want the complete response, not streaming
+	}
+
+	body, status, err := postJSON(ctx, b.client, b.baseURL+"/api/generate", reqPayload, b.opts.userAgent, "")
+	if err != nil {
+		return "", err
+	}
+	if status != http.StatusOK {
+		return "", &BackendError{Backend: backendOllama, StatusCode: status, Message: backendErrorMessage(body)}
+	}
+
+	var summaryResp SummaryResponse
+	if err := json.Unmarshal(body, &summaryResp); err != nil {
+		return "", fmt.Errorf("failed to parse response JSON: %w", err)
+	}
+	if summaryResp.Error != "" {
+		return "", &BackendError{Backend: backendOllama, StatusCode: status, Message: summaryResp.Error}
+	}
+	return summaryResp.Response, nil
+}
+
+// summarizeStream requests a streamed completion and assembles the
+// newline-delimited JSON chunks until Ollama reports done. It returns early,
+// dropping the connection, once the text is past the word limit, since the
+// rest would be truncated anyway.
+func (b *ollamaBackend) summarizeStream(ctx context.Context, prompt, model string) (string, error) {
+	reqPayload := SummaryRequest{Model: model, Prompt: prompt, Stream: true}
+
+	req, err := newJSONRequest(ctx, b.baseURL+"/api/generate", reqPayload, b.opts.userAgent, "")
+	if err != nil {
+		return "", err
+	}
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("HTTP request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return "", &BackendError{Backend: backendOllama, StatusCode: resp.StatusCode, Message: backendErrorMessage(body)}
+	}
+
+	var text strings.Builder
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var chunk SummaryResponse
+		if err := json.Unmarshal(line, &chunk); err != nil {
+			return "", fmt.Errorf("failed to parse stream chunk: %w", err)
+		}
+		if chunk.Error != "" {
+			return "", &BackendError{Backend: backendOllama, StatusCode: resp.StatusCode, Message: chunk.Error}
+		}
+
+		text.WriteString(chunk.Response)
+		if chunk.Done {
+			return text.String(), nil
+		}
+		if b.opts.streamWordLimit > 0 && visibleWordCount(text.String()) > b.opts.streamWordLimit {
+			return text.String(), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("failed to read stream: %w", err)
+	}
+	return "", fmt.Errorf("stream ended before the response was done")
+}
+
+var (
+	// reasoningBlockRegex matches a closed reasoning block, as stripped by
+	// cleanSummaryContent.
+	reasoningBlockRegex = regexp.MustCompile(`(?is)<(think|thinking|reason|analysis)\s*>.*?</(think|thinking|reason|analysis)\s*>`)
+	// reasoningOpenRegex matches the opening tag of a reasoning block.
+	reasoningOpenRegex = regexp.MustCompile(`(?i)<(think|thinking|reason|analysis)\s*>`)
+)
+
+// visibleWordCount counts the words of a partial completion that will
+// survive cleanSummaryContent: reasoning blocks are skipped, including one
+// still being streamed, so a model thinking out loud isn't cut off early.
+func visibleWordCount(partial string) int {
+	partial = reasoningBlockRegex.ReplaceAllString(partial, "")
+	if loc := reasoningOpenRegex.FindStringIndex(partial); loc != nil {
+		partial = partial[:loc[0]]
+	}
+	return len(strings.Fields(partial))
+}
+
+// chatCompletionsBackend calls an OpenAI-compatible /v1/chat/completions
+// endpoint, sending the prompt as a single user message.
+type chatCompletionsBackend struct {
+	baseURL   string
+	apiKey    string
+	userAgent string
+	client    *http.Client
+}
+
+// chatMessage is one entry of a chat-completions messages array.
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// chatCompletionsRequest is the chat-completions request payload.
+type chatCompletionsRequest struct {
+	Model    string        `json:"model"`
+	Messages []chatMessage `json:"messages"`
+	Stream   bool          `json:"stream"`
+}
+
+// chatCompletionsResponse models the subset of the response we use.
+type chatCompletionsResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+}
+
+func (b *chatCompletionsBackend) Summarize(ctx context.Context, prompt, model string) (string, error) {
+	reqPayload := chatCompletionsRequest{
+		Model:    model,
+		Messages: []chatMessage{{Role: "user", Content: prompt}},
+		Stream:   false,
+	}
+
+	body, status, err := postJSON(ctx, b.client, chatCompletionsURL(b.baseURL), reqPayload, b.userAgent, b.apiKey)
+	if err != nil {
+		return "", err
+	}
+	if status != http.StatusOK {
+		return "", &BackendError{Backend: backendChatCompletions, StatusCode: status, Message: backendErrorMessage(body)}
+	}
+
+	var chatResp chatCompletionsResponse
+	if err := json.Unmarshal(body, &chatResp); err != nil {
+		return "", fmt.Errorf("failed to parse response JSON: %w", err)
+	}
+	if len(chatResp.Choices) == 0 {
+		return "", &BackendError{Backend: backendChatCompletions, StatusCode: status, Message: backendErrorMessage(body)}
+	}
+	return chatResp.Choices[0].Message.Content, nil
+}
+
+// chatCompletionsURL builds the endpoint URL, accepting base URLs given with
+// or without the conventional /v1 suffix.
+func chatCompletionsURL(baseURL string) string {
+	if strings.HasSuffix(baseURL, "/v1") {
+		return baseURL + "/chat/completions"
+	}
+	return baseURL + "/v1/chat/completions"
+}
+
+// newJSONRequest builds a POST request with payload as its JSON body. A
+// non-empty apiKey is sent as a bearer token.
+func newJSONRequest(ctx context.Context, endpoint string, payload interface{}, userAgent, apiKey string) (*http.Request, error) {
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+	return req, nil
+}
+
+// postJSON POSTs payload as JSON and returns the response body and status.
+func postJSON(ctx context.Context, client *http.Client, endpoint string, payload interface{}, userAgent, apiKey string) ([]byte, int, error) {
+	req, err := newJSONRequest(ctx, endpoint, payload, userAgent, apiKey)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("HTTP request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, resp.StatusCode, fmt.Errorf("failed to read response body: %w", err)
+	}
+	return body, resp.StatusCode, nil
+}
+
+// backendErrorMessage extracts a readable message from an error body in
+// either backend's format: Ollama's {"error": "..."} or OpenAI's
+// {"error": {"message": "..."}}. Anything else is returned as-is.
+func backendErrorMessage(body []byte) string {
+	var envelope struct {
+		Error json.RawMessage `json:"error"`
+	}
+	if err := json.Unmarshal(body, &envelope); err == nil && len(envelope.Error) > 0 {
+		var msg string
+		if json.Unmarshal(envelope.Error, &msg) == nil && msg != "" {
+			return msg
+		}
+		var detail struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(envelope.Error, &detail) == nil && detail.Message != "" {
+			return detail.Message
+		}
+	}
+	return strings.TrimSpace(string(body))
+}
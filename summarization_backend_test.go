@@ -0,0 +1,212 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"information-broker/config"
+)
+
+func TestChatCompletionsBackend(t *testing.T) {
+	var got chatCompletionsRequest
+	var gotPath, gotAuth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotAuth = r.URL.Path, r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&got)
+		io.WriteString(w, `{"id":"c1","choices":[{"index":0,"message":{"role":"assistant","content":"  A gateway summary.  "}}]}`)
+	}))
+	defer srv.Close()
+
+	cfg := &config.Config{
+		OLLAMA:      config.OLLAMAConfig{Backend: "openai", APIKey: "sk-test", URL: srv.URL, Timeout: 5 * time.Second, MaxRetries: 1},
+		Content:     config.ContentConfig{MaxSummaryLength: 200},
+		Performance: config.PerformanceConfig{MaxArticleContentLength: 10000},
+	}
+	s := &ArticleSummarizer{httpClient: &http.Client{}, config: cfg, metrics: testMetrics()}
+
+	summary, err := s.SummarizeArticle(context.Background(), "Some article text.", "https://example.com/a", "gpt-4o-mini")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary != "A gateway summary." {
+		t.Errorf("summary = %q", summary)
+	}
+	if gotPath != "/v1/chat/completions" {
+		t.Errorf("path = %q, want /v1/chat/completions", gotPath)
+	}
+	if gotAuth != "Bearer sk-test" {
+		t.Errorf("Authorization = %q", gotAuth)
+	}
+	if got.Model != "gpt-4o-mini" || len(got.Messages) != 1 || got.Messages[0].Role != "user" || got.Messages[0].Content == "" {
+		t.Errorf("unexpected request payload: %+v", got)
+	}
+}
+
+func TestChatCompletionsURL(t *testing.T) {
+	cases := map[string]string{
+		"https://gateway.example":    "https://gateway.example/v1/chat/completions",
+		"https://gateway.example/v1": "https://gateway.example/v1/chat/completions",
+	}
+	for in, want := range cases {
+		if got := chatCompletionsURL(in); got != want {
+			t.Errorf("chatCompletionsURL(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestBackendErrorsMapToCommonError(t *testing.T) {
+	tests := []struct {
+		name        string
+		kind        string
+		status      int
+		body        string
+		wantStatus  int
+		wantMessage string
+	}{
+		{"ollama http error", "ollama", http.StatusNotFound, `{"error":"model 'llama9' not found"}`, http.StatusNotFound, "model 'llama9' not found"},
+		{"ollama error in 200 body", "ollama", http.StatusOK, `{"error":"out of memory"}`, http.StatusOK, "out of memory"},
+		{"openai http error", "openai", http.StatusTooManyRequests, `{"error":{"message":"Rate limit reached","type":"requests","code":"rate_limit_exceeded"}}`, http.StatusTooManyRequests, "Rate limit reached"},
+		{"non-json error body", "openai", http.StatusBadGateway, "upstream unavailable\n", http.StatusBadGateway, "upstream unavailable"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				io.Copy(io.Discard, r.Body)
+				w.WriteHeader(tt.status)
+				io.WriteString(w, tt.body)
+			}))
+			defer srv.Close()
+
+			backend, err := newSummarizationBackend(tt.kind, srv.URL, backendOptions{userAgent: "test"}, &http.Client{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = backend.Summarize(context.Background(), "prompt", "model")
+
+			var backendErr *BackendError
+			if !errors.As(err, &backendErr) {
+				t.Fatalf("expected *BackendError, got %T: %v", err, err)
+			}
+			if backendErr.Backend != tt.kind || backendErr.StatusCode != tt.wantStatus || backendErr.Message != tt.wantMessage {
+				t.Errorf("got %+v, want backend=%s status=%d message=%q", backendErr, tt.kind, tt.wantStatus, tt.wantMessage)
+			}
+		})
+	}
+}
+
+func TestNewSummarizationBackendUnknownKind(t *testing.T) {
+	if _, err := newSummarizationBackend("anthropic", "http://x", backendOptions{}, &http.Client{}); err == nil {
+		t.Fatal("expected an error for an unknown backend kind")
+	}
+}
+
+// streamChunks serves an Ollama-style NDJSON stream of the given fragments,
+// flushing after each one. The last chunk is marked done if done is set.
+func streamChunks(t *testing.T, fragments []string, done bool) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req SummaryRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if !req.Stream {
+			t.Errorf("expected a streaming request")
+		}
+		flusher := w.(http.Flusher)
+		for i, fragment := range fragments {
+			last := done && i == len(fragments)-1
+			line, _ := json.Marshal(SummaryResponse{Response: fragment, Done: last})
+			if _, err := w.Write(append(line, '\n')); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestOllamaStreamAssemblesChunks(t *testing.T) {
+	srv := streamChunks(t, []string{"<think>plan ", "the summary</think>", "Patch ", "released ", "today."}, true)
+
+	cfg := &config.Config{
+		OLLAMA:      config.OLLAMAConfig{URL: srv.URL, Stream: true, Timeout: 5 * time.Second, MaxRetries: 1},
+		Content:     config.ContentConfig{MaxSummaryLength: 200},
+		Performance: config.PerformanceConfig{MaxArticleContentLength: 10000},
+	}
+	s := &ArticleSummarizer{httpClient: &http.Client{}, config: cfg, metrics: testMetrics()}
+
+	summary, err := s.SummarizeArticle(context.Background(), "Some article text.", "https://example.com/a", "llama3")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary != "Patch released today." {
+		t.Errorf("summary = %q, want the assembled text with reasoning stripped", summary)
+	}
+}
+
+func TestOllamaStreamStopsAtWordLimit(t *testing.T) {
+	fragments := []string{"<think>" + strings.Repeat("reasoning ", 30) + "</think>"}
+	for i := 0; i < 50; i++ {
+		fragments = append(fragments, "word ")
+	}
+	srv := streamChunks(t, fragments, true)
+
+	backend, _ := newSummarizationBackend("ollama", srv.URL, backendOptions{stream: true, streamWordLimit: 10}, &http.Client{})
+	text, err := backend.Summarize(context.Background(), "prompt", "llama3")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := visibleWordCount(text); got != 11 {
+		t.Errorf("read %d visible words, want to stop just past the limit (11)", got)
+	}
+	if !strings.Contains(text, "</think>") {
+		t.Errorf("reasoning words must not count toward the limit: %q", text)
+	}
+}
+
+func TestOllamaStreamErrors(t *testing.T) {
+	t.Run("error chunk", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			io.Copy(io.Discard, r.Body)
+			io.WriteString(w, `{"response":"partial","done":false}`+"\n"+`{"error":"model crashed"}`+"\n")
+		}))
+		defer srv.Close()
+
+		backend, _ := newSummarizationBackend("ollama", srv.URL, backendOptions{stream: true}, &http.Client{})
+		_, err := backend.Summarize(context.Background(), "prompt", "llama3")
+		var backendErr *BackendError
+		if !errors.As(err, &backendErr) || backendErr.Message != "model crashed" {
+			t.Errorf("got %v, want BackendError for the error chunk", err)
+		}
+	})
+
+	t.Run("stream cut off before done", func(t *testing.T) {
+		srv := streamChunks(t, []string{"half a "}, false)
+		backend, _ := newSummarizationBackend("ollama", srv.URL, backendOptions{stream: true}, &http.Client{})
+		if _, err := backend.Summarize(context.Background(), "prompt", "llama3"); err == nil {
+			t.Error("expected an error for a stream that never finished")
+		}
+	})
+}
+
+func TestVisibleWordCount(t *testing.T) {
+	cases := map[string]int{
+		"one two three":                         3,
+		"<think>a b c</think> one two":          2,
+		"one <THINKING>still going":             1,
+		"<analysis>x</analysis>one <reason>y z": 1,
+		"":                                      0,
+	}
+	for in, want := range cases {
+		if got := visibleWordCount(in); got != want {
+			t.Errorf("visibleWordCount(%q) = %d, want %d", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import "container/heap"
+
+// requestQueue is a priority queue of pending summarization requests: the
+// highest Priority comes out first, and equal priorities are served in
+// EnqueuedAt order. With fairByFeed set, requests of equal priority are
+// instead served round-robin across feeds, so one feed that lists many
+// articles at once can't hold up everyone else's. It is not safe for
+// concurrent use; the scheduler guards it with its mutex.
+type requestQueue struct {
+	items      requestHeap
+	seq        uint64
+	fairByFeed bool
+	served     uint64            // Requests popped in fair mode so far
+	lastServed map[string]uint64 // Feed URL -> value of served when it was last served
+}
+
+// queuedRequest pairs a request with its insertion sequence, which breaks
+// ties between requests stamped with the same EnqueuedAt.
+type queuedRequest struct {
+	request SummarizationRequest
+	seq     uint64
+}
+
+// requestHeap implements heap.Interface for requestQueue.
+type requestHeap []queuedRequest
+
+func (h requestHeap) Len() int { return len(h) }
+
+func (h requestHeap) Less(i, j int) bool { return requestBefore(h[i], h[j]) }
+
+// requestBefore reports whether a is served before b in the plain queue
+// order.
+func requestBefore(a, b queuedRequest) bool {
+	if a.request.Priority != b.request.Priority {
+		return a.request.Priority > b.request.Priority
+	}
+	if !a.request.EnqueuedAt.Equal(b.request.EnqueuedAt) {
+		return a.request.EnqueuedAt.Before(b.request.EnqueuedAt)
+	}
+	return a.seq < b.seq
+}
+
+func (h requestHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *requestHeap) Push(x any) { *h = append(*h, x.(queuedRequest)) }
+
+func (h *requestHeap) Pop() any {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = queuedRequest{} // drop the reference to the article content
+	*h = old[:n-1]
+	return item
+}
+
+// push adds a request to the queue.
+func (q *requestQueue) push(request SummarizationRequest) {
+	q.seq++
+	heap.Push(&q.items, queuedRequest{request: request, seq: q.seq})
+}
+
+// pop removes and returns the next request to process.
+func (q *requestQueue) pop() (SummarizationRequest, bool) {
+	if len(q.items) == 0 {
+		return SummarizationRequest{}, false
+	}
+	if !q.fairByFeed {
+		return heap.Pop(&q.items).(queuedRequest).request, true
+	}
+
+	// Fair mode scans the queue, which stays short enough (its capacity)
+	// for that to be cheap next to a summarization
+	next := 0
+	for i := range q.items {
+		if q.fairBefore(q.items[i], q.items[next]) {
+			next = i
+		}
+	}
+	item := heap.Remove(&q.items, next).(queuedRequest)
+	if q.lastServed == nil {
+		q.lastServed = make(map[string]uint64)
+	}
+	q.served++
+	q.lastServed[item.request.FeedURL] = q.served
+	return item.request, true
+}
+
+// fairBefore reports whether a is served before b in fair mode: higher
+// priority first as always, then the feed served least recently, then the
+// plain queue order.
+func (q *requestQueue) fairBefore(a, b queuedRequest) bool {
+	if a.request.Priority != b.request.Priority {
+		return a.request.Priority > b.request.Priority
+	}
+	if servedA, servedB := q.lastServed[a.request.FeedURL], q.lastServed[b.request.FeedURL]; servedA != servedB {
+		return servedA < servedB
+	}
+	return requestBefore(a, b)
+}
+
+// contains reports whether a request for the article is pending.
+func (q *requestQueue) contains(articleURL string) bool {
+	for _, item := range q.items {
+		if item.request.ArticleURL == articleURL {
+			return true
+		}
+	}
+	return false
+}
+
+// len returns the number of pending requests.
+func (q *requestQueue) len() int {
+	return len(q.items)
+}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"information-broker/config"
+)
+
+func TestRequestQueueOrder(t *testing.T) {
+	base := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	var q requestQueue
+
+	q.push(SummarizationRequest{ArticleTitle: "backfill-old", Priority: 0, EnqueuedAt: base})
+	q.push(SummarizationRequest{ArticleTitle: "normal-late", Priority: 1, EnqueuedAt: base.Add(2 * time.Second)})
+	q.push(SummarizationRequest{ArticleTitle: "breaking", Priority: 10, EnqueuedAt: base.Add(3 * time.Second)})
+	q.push(SummarizationRequest{ArticleTitle: "normal-early", Priority: 1, EnqueuedAt: base.Add(time.Second)})
+	q.push(SummarizationRequest{ArticleTitle: "normal-late-twin", Priority: 1, EnqueuedAt: base.Add(2 * time.Second)})
+
+	want := []string{"breaking", "normal-early", "normal-late", "normal-late-twin", "backfill-old"}
+	if q.len() != len(want) {
+		t.Fatalf("len() = %d, want %d", q.len(), len(want))
+	}
+	for i, title := range want {
+		got, ok := q.pop()
+		if !ok {
+			t.Fatalf("pop %d: queue empty", i)
+		}
+		if got.ArticleTitle != title {
+			t.Errorf("pop %d = %q, want %q", i, got.ArticleTitle, title)
+		}
+	}
+	if _, ok := q.pop(); ok {
+		t.Errorf("pop on empty queue should report false")
+	}
+}
+
+func TestRequestQueueFairByFeed(t *testing.T) {
+	base := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	fill := func(q *requestQueue) {
+		// A chatty feed dumps five articles before two quiet feeds get one in
+		for i := 0; i < 5; i++ {
+			q.push(SummarizationRequest{ArticleTitle: fmt.Sprintf("chatty-%d", i), FeedURL: "chatty", EnqueuedAt: base.Add(time.Duration(i) * time.Second)})
+		}
+		q.push(SummarizationRequest{ArticleTitle: "quiet-a", FeedURL: "quiet-a", EnqueuedAt: base.Add(10 * time.Second)})
+		q.push(SummarizationRequest{ArticleTitle: "quiet-b-0", FeedURL: "quiet-b", EnqueuedAt: base.Add(11 * time.Second)})
+		q.push(SummarizationRequest{ArticleTitle: "quiet-b-1", FeedURL: "quiet-b", EnqueuedAt: base.Add(12 * time.Second)})
+		q.push(SummarizationRequest{ArticleTitle: "urgent", FeedURL: "chatty", Priority: 5, EnqueuedAt: base.Add(13 * time.Second)})
+	}
+	drain := func(q *requestQueue) []string {
+		var titles []string
+		for {
+			request, ok := q.pop()
+			if !ok {
+				return titles
+			}
+			titles = append(titles, request.ArticleTitle)
+		}
+	}
+
+	fair := &requestQueue{fairByFeed: true}
+	fill(fair)
+	want := []string{"urgent", "quiet-a", "quiet-b-0", "chatty-0", "quiet-b-1", "chatty-1", "chatty-2", "chatty-3", "chatty-4"}
+	if got := drain(fair); !reflect.DeepEqual(got, want) {
+		t.Errorf("fair order = %v, want %v", got, want)
+	}
+
+	plain := &requestQueue{}
+	fill(plain)
+	want = []string{"urgent", "chatty-0", "chatty-1", "chatty-2", "chatty-3", "chatty-4", "quiet-a", "quiet-b-0", "quiet-b-1"}
+	if got := drain(plain); !reflect.DeepEqual(got, want) {
+		t.Errorf("plain order = %v, want %v", got, want)
+	}
+}
+
+func TestEnqueueSummarizationBackpressure(t *testing.T) {
+	s := &SummarizationScheduler{
+		config:     &config.Config{OLLAMA: config.OLLAMAConfig{Model: "llama3"}},
+		metrics:    testMetrics(),
+		queueCap:   2,
+		queueReady: make(chan struct{}, 1),
+	}
+
+	for i, priority := range []int{0, 5} {
+		if err := s.EnqueueSummarization(SummarizationRequest{ArticleTitle: "a", Priority: priority}); err != nil {
+			t.Fatalf("enqueue %d: unexpected error %v", i, err)
+		}
+	}
+	if err := s.EnqueueSummarization(SummarizationRequest{ArticleTitle: "overflow", Priority: 100}); err == nil {
+		t.Fatalf("expected queue-full error, even for a high-priority request")
+	}
+
+	stats := s.GetStats()
+	if stats["queue_depth"] != 2 || stats["queue_capacity"] != 2 {
+		t.Errorf("stats queue_depth=%v queue_capacity=%v, want 2 and 2", stats["queue_depth"], stats["queue_capacity"])
+	}
+
+	request, ok := s.dequeue()
+	if !ok || request.Priority != 5 || request.Model != "llama3" {
+		t.Errorf("dequeue = %+v, want the priority-5 request with the default model", request)
+	}
+	if len(request.RequestID) != 16 {
+		t.Errorf("RequestID = %q, want one assigned on enqueue", request.RequestID)
+	}
+	if depth := s.GetStats()["queue_depth"]; depth != 1 {
+		t.Errorf("queue_depth after dequeue = %v, want 1", depth)
+	}
+	if s.GetStats()["current_request"] != true {
+		t.Errorf("dequeued request should be reported as current")
+	}
+}
+
+func TestEnqueueSummarizationConcurrentCap(t *testing.T) {
+	db, recorder := openExecRecorder(t)
+	s := &SummarizationScheduler{
+		db:         db,
+		config:     &config.Config{OLLAMA: config.OLLAMAConfig{Model: "llama3"}},
+		metrics:    testMetrics(),
+		queueCap:   3,
+		queueReady: make(chan struct{}, 1),
+	}
+
+	// Enqueues race through the unlocked write to pending_summarizations
+	var wg sync.WaitGroup
+	var queued int32
+	for i := 0; i < 30; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if s.EnqueueSummarization(SummarizationRequest{ArticleURL: fmt.Sprintf("https://example.com/%d", i)}) == nil {
+				atomic.AddInt32(&queued, 1)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if depth := s.getQueueDepth(); depth > 3 || int32(depth) != queued {
+		t.Errorf("queue depth = %d with %d enqueues accepted, want at most the cap of 3", depth, queued)
+	}
+	if rows := len(recorder.recordedWrites("INSERT")) - len(recorder.recordedWrites("DELETE")); int32(rows) != queued {
+		t.Errorf("%d pending rows left for %d queued requests, want rejected requests' rows deleted", rows, queued)
+	}
+}
+
+func TestStopDrainsQueuedRequests(t *testing.T) {
+	var hits int32
+	ollama := newOllamaStub(t, http.StatusOK, "A summary.", &hits)
+	db, recorder := openExecRecorder(t)
+
+	metrics := testMetrics()
+	cfg := &config.Config{
+		OLLAMA:        config.OLLAMAConfig{Model: "llama3", MaxRetries: 1},
+		Summarization: config.SummarizationConfig{MaxRetries: 1, WorkerTimeout: 5 * time.Second},
+	}
+	summarizer := newFallbackTestSummarizer(ollama.URL, nil, NewCircuitBreakerManager())
+	summarizer.metrics = metrics
+	summarizer.config.OLLAMA.MaxRetries = 1
+
+	s := &SummarizationScheduler{
+		db:            db,
+		config:        cfg,
+		metrics:       metrics,
+		summarizer:    summarizer,
+		discordSender: &DiscordWebhookSender{config: &cfg.Discord},
+		queueCap:      10,
+		queueReady:    make(chan struct{}, 1),
+		shutdown:      make(chan struct{}),
+		done:          make(chan struct{}),
+		isRunning:     true,
+	}
+	for _, url := range []string{"https://example.com/1", "https://example.com/2", "https://example.com/3"} {
+		if err := s.EnqueueSummarization(SummarizationRequest{ArticleURL: url, ArticleTitle: url, Content: "Article body."}); err != nil {
+			t.Fatalf("enqueue %s: %v", url, err)
+		}
+	}
+
+	go s.worker(context.Background())
+	if err := s.Stop(); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+
+	if depth := s.getQueueDepth(); depth != 0 {
+		t.Errorf("%d request(s) left in the queue after Stop", depth)
+	}
+	if got := atomic.LoadInt32(&hits); got != 3 {
+		t.Errorf("summarizer called %d times, want all 3 queued requests processed", got)
+	}
+	if saved := recorder.recorded(); len(saved) != 3 {
+		t.Errorf("saved %d summaries, want 3", len(saved))
+	}
+	if persisted, forgotten := recorder.recordedWrites("INSERT"), recorder.recordedWrites("DELETE"); len(persisted) != 3 || len(forgotten) != 3 {
+		t.Errorf("persisted %d and deleted %d pending rows, want each request recorded and removed once handled", len(persisted), len(forgotten))
+	}
+
+	err := s.EnqueueSummarization(SummarizationRequest{ArticleURL: "https://example.com/late", ArticleTitle: "late"})
+	if !errors.Is(err, ErrSchedulerDraining) {
+		t.Errorf("enqueue after Stop returned %v, want ErrSchedulerDraining", err)
+	}
+}
+
+func TestRestorePendingRequests(t *testing.T) {
+	db, recorder := openExecRecorder(t)
+	enqueuedAt := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	recorder.answer("FROM pending_summarizations",
+		[]driver.Value{"https://example.com/a", "A", "https://example.com/feed", "", "Body A.", "llama3", "scraped", int64(0), enqueuedAt, "a1", false, "", int64(0), ""},
+		[]driver.Value{"https://example.com/b", "B", "https://example.com/feed", "", "Body B.", "llama3", "scraped", int64(5), enqueuedAt, "b2", true, "advisory", int64(60), "security"},
+	)
+
+	s := &SummarizationScheduler{
+		db:         db,
+		config:     &config.Config{OLLAMA: config.OLLAMAConfig{Model: "llama3"}},
+		metrics:    testMetrics(),
+		queueCap:   10,
+		queueReady: make(chan struct{}, 1),
+	}
+	// Already queued by this run; restoring it again would summarize it twice
+	s.queue.push(SummarizationRequest{ArticleURL: "https://example.com/a", ArticleTitle: "A"})
+
+	restored, err := s.restorePendingRequests()
+	if err != nil {
+		t.Fatalf("restorePendingRequests: %v", err)
+	}
+	if restored != 1 || s.queue.len() != 2 {
+		t.Fatalf("restored %d (queue depth %d), want only the request not already queued", restored, s.queue.len())
+	}
+	if len(recorder.recordedWrites("DELETE")) != 1 {
+		t.Error("rows of articles summarized since weren't dropped before restoring")
+	}
+
+	request, _ := s.dequeue()
+	if request.ArticleURL != "https://example.com/b" || request.Priority != 5 || request.Content != "Body B." ||
+		!request.EnqueuedAt.Equal(enqueuedAt) || request.RequestID != "b2" {
+		t.Errorf("restored request = %+v, want B with its priority, content, request ID and original enqueue time", request)
+	}
+	if !request.Update || request.ContentType != "advisory" || request.SummaryWords != 60 || request.Category != "security" {
+		t.Errorf("restored request = %+v, want it still an update, with its feed's directives", request)
+	}
+	select {
+	case <-s.queueReady:
+	default:
+		t.Error("restoring requests didn't wake the worker")
+	}
+}
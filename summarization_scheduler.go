@@ -2,25 +2,65 @@ package main
 
 import (
 	"context"
+	"crypto/rand"
 	"database/sql"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"information-broker/config"
-	"log"
+	"log/slog"
 	"math"
 	"strings"
 	"sync"
 	"time"
 )
 
+// ErrSchedulerDraining is returned by EnqueueSummarization once Stop has
+// been called: the worker is finishing the queued requests and takes no more.
+var ErrSchedulerDraining = errors.New("scheduler draining")
+
+// schedulerDrainTimeout bounds how long Stop waits for the worker to finish
+// the queued requests.
+const schedulerDrainTimeout = 30 * time.Second
+
 // SummarizationRequest represents a request for article summarization
 type SummarizationRequest struct {
-	ArticleURL   string
-	ArticleTitle string
-	Content      string
-	Model        string
-	Priority     int // Higher values = higher priority
-	EnqueuedAt   time.Time
-	ResponseChan chan SummarizationResponse // Optional channel for response
+	RequestID     string // Ties the request's log lines and summary_logs rows together; set on enqueue
+	ArticleURL    string
+	ArticleTitle  string
+	FeedURL       string // Decides the summary grace period; may be empty
+	Lead          string // Standfirst from the feed, when it isn't already part of Content
+	Content       string
+	Model         string
+	ContentSource string // Where Content came from (scraped, feed_content, description), for metrics
+	Priority      int    // Higher values = higher priority
+	EnqueuedAt    time.Time
+	Update        bool                       // The article was posted before and has changed since (see sendArticleUpdate)
+	ContentType   string                     // Selects the prompt template (see promptTemplateFor)
+	SummaryWords  int                        // Summary length for this article's feed; 0 = Content.MaxSummaryLength
+	Category      string                     // The feed's category, for Discord branding
+	ResponseChan  chan SummarizationResponse // Optional channel for response
+}
+
+// withFeedDirectives returns request, rebuilt from the articles table, with
+// its feed's directives filled in, so a retried or replayed summary follows
+// them like the first one did.
+func (s *SummarizationScheduler) withFeedDirectives(request SummarizationRequest) SummarizationRequest {
+	if s.feeds != nil {
+		s.feeds(request.FeedURL).applyDirectives(&request)
+	}
+	return request
+}
+
+// newRequestID returns a random ID for a summarization request, to follow
+// one article through the logs of the monitor, scheduler, summarizer and
+// Discord sender.
+func newRequestID() string {
+	var b [8]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return fmt.Sprintf("%016x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b[:])
 }
 
 // SummarizationResponse represents the response from summarization
@@ -35,23 +75,33 @@ type SummarizationResponse struct {
 // SummarizationScheduler manages a centralized queue for Ollama API calls
 type SummarizationScheduler struct {
 	// Core components
-	queue         chan SummarizationRequest
-	summarizer    *ArticleSummarizer
-	db            *sql.DB
-	config        *config.Config
-	metrics       *PrometheusMetrics
-	discordSender *DiscordWebhookSender
+	summarizer     *ArticleSummarizer
+	db             *sql.DB
+	config         *config.Config
+	metrics        *PrometheusMetrics
+	discordSender  *DiscordWebhookSender
+	discordBreaker *CircuitBreaker // Trips when Discord as a whole is failing; nil if disabled
+	deferredPosts  *deferredPostQueue
+	notifier       *NotificationWebhookSender
+	events         EventPublisher            // Pipeline event stream; nil drops events
+	feeds          func(feedURL string) Feed // The monitored feed, for requests rebuilt from the database; nil without a monitor
 
 	// Control channels
 	shutdown chan struct{}
 	done     chan struct{}
 
+	// Pending requests, highest priority first. queue is guarded by mu;
+	// queueReady wakes the worker after an enqueue.
+	queue      requestQueue
+	queueCap   int
+	queueReady chan struct{}
+
 	// State tracking
 	mu             sync.RWMutex
-	queueDepth     int
 	totalProcessed int64
 	totalErrors    int64
 	isRunning      bool
+	draining       bool // Set by Stop; new requests are refused while the queue drains
 
 	// Worker state
 	currentRequest   *SummarizationRequest
@@ -69,29 +119,31 @@ type SummarizationSchedulerConfig struct {
 }
 
 // NewSummarizationScheduler creates a new centralized summarization scheduler
-func NewSummarizationScheduler(db *sql.DB, cfg *config.Config, metrics *PrometheusMetrics) *SummarizationScheduler {
+func NewSummarizationScheduler(db *sql.DB, cfg *config.Config, metrics *PrometheusMetrics, breakers *CircuitBreakerManager, events EventPublisher) *SummarizationScheduler {
 	// Load scheduler-specific config
 	schedulerConfig := loadSchedulerConfig(cfg)
 
-	// Create the buffered channel for queuing requests
-	queue := make(chan SummarizationRequest, schedulerConfig.MaxQueueSize)
-
 	// Create summarizer instance
-	summarizer := NewArticleSummarizer(db, cfg, metrics)
+	summarizer := NewArticleSummarizer(db, cfg, metrics, breakers)
 
 	// Create Discord webhook sender
-	discordSender := NewDiscordWebhookSender(db, metrics)
+	discordSender := NewDiscordWebhookSender(db, metrics, &cfg.Discord)
 
 	scheduler := &SummarizationScheduler{
-		queue:         queue,
-		summarizer:    summarizer,
-		db:            db,
-		config:        cfg,
-		metrics:       metrics,
-		discordSender: discordSender,
-		shutdown:      make(chan struct{}),
-		done:          make(chan struct{}),
-		queueDepth:    0,
+		queue:          requestQueue{fairByFeed: cfg.Summarization.Fairness},
+		queueCap:       schedulerConfig.MaxQueueSize,
+		queueReady:     make(chan struct{}, 1),
+		summarizer:     summarizer,
+		db:             db,
+		config:         cfg,
+		metrics:        metrics,
+		discordSender:  discordSender,
+		discordBreaker: newDiscordBreaker(breakers, cfg.Discord),
+		deferredPosts:  &deferredPostQueue{},
+		notifier:       NewNotificationWebhookSender(&cfg.Notifications, cfg.API.UserAgent),
+		events:         events,
+		shutdown:       make(chan struct{}),
+		done:           make(chan struct{}),
 	}
 
 	// Initialize metrics with queue capacity
@@ -122,7 +174,14 @@ func (s *SummarizationScheduler) Start(ctx context.Context) error {
 	s.isRunning = true
 	s.mu.Unlock()
 
-	log.Println("Starting summarization scheduler with single worker")
+	slog.Info("Starting summarization scheduler with single worker")
+
+	// Pick up the requests still queued when the previous run stopped
+	if restored, err := s.restorePendingRequests(); err != nil {
+		slog.Error("Failed to restore pending summarization requests", "error", err)
+	} else if restored > 0 {
+		slog.Info("Restored pending summarization requests from the previous run", "requests", restored)
+	}
 
 	// Start the single worker goroutine
 	go s.worker(ctx)
@@ -130,19 +189,45 @@ func (s *SummarizationScheduler) Start(ctx context.Context) error {
 	// Start metrics collection goroutine
 	go s.metricsCollector(ctx)
 
+	// Start the quiet-hours release loop if quiet hours are configured
+	if _, _, ok := s.config.Discord.QuietHoursWindow(); ok {
+		slog.Info("Discord quiet hours enabled", "quiet_hours", s.config.Discord.QuietHours, "timezone", s.config.Discord.DisplayTimezone)
+		go s.deferredReleaser(ctx)
+	}
+
+	// Replay posts the Discord breaker skipped once it lets posts through again
+	if s.discordBreaker != nil {
+		go s.discordReplayer(ctx)
+	}
+
+	// Retry failed summaries within their grace period, then post them raw
+	if s.config.Content.SummaryGracePeriod > 0 {
+		go s.summaryGraceRetrier(ctx)
+	}
+
+	// Replay failed summarizations in the background until they succeed or
+	// run out of attempts
+	if s.config.Summarization.DeadLetterReplayInterval > 0 {
+		go s.summaryReplayer(ctx)
+	}
+
 	return nil
 }
 
-// Stop gracefully stops the scheduler
+// Stop gracefully stops the scheduler. New requests are refused from now
+// on, and the worker finishes the ones already queued before it exits, for
+// up to schedulerDrainTimeout; whatever is still queued then is dropped.
 func (s *SummarizationScheduler) Stop() error {
 	s.mu.Lock()
 	if !s.isRunning {
 		s.mu.Unlock()
 		return fmt.Errorf("scheduler is not running")
 	}
+	s.draining = true
+	depth := s.queue.len()
 	s.mu.Unlock()
 
-	log.Println("Stopping summarization scheduler...")
+	slog.Info("Stopping summarization scheduler, draining queued requests", "queue_depth", depth)
 
 	// Signal shutdown
 	close(s.shutdown)
@@ -150,9 +235,14 @@ func (s *SummarizationScheduler) Stop() error {
 	// Wait for worker to finish
 	select {
 	case <-s.done:
-		log.Println("Summarization scheduler stopped gracefully")
-	case <-time.After(30 * time.Second):
-		log.Println("Summarization scheduler shutdown timeout")
+		slog.Info("Summarization scheduler stopped gracefully")
+	case <-time.After(schedulerDrainTimeout):
+		slog.Warn("Summarization scheduler shutdown timeout, requests still queued", "queue_depth", s.getQueueDepth())
+	}
+
+	// Send whatever is waiting for the next Discord digest rather than drop it
+	if s.discordSender.DigestEnabled() {
+		s.discordSender.FlushDigest(context.Background())
 	}
 
 	s.mu.Lock()
@@ -167,40 +257,100 @@ func (s *SummarizationScheduler) EnqueueSummarization(request SummarizationReque
 	// Set enqueue timestamp
 	request.EnqueuedAt = time.Now()
 
+	// Requests re-queued by retries and replays get an ID here
+	if request.RequestID == "" {
+		request.RequestID = newRequestID()
+	}
+
 	// Set default model if not specified
 	if request.Model == "" {
 		request.Model = s.config.OLLAMA.Model
 	}
 
-	// Attempt to enqueue with timeout to prevent blocking
-	select {
-	case s.queue <- request:
-		s.mu.Lock()
-		s.queueDepth++
-		newDepth := s.queueDepth
-		s.mu.Unlock()
+	// Per-request models bypass the startup check, so enforce the allowlist here too
+	if !s.config.OLLAMA.IsModelAllowed(request.Model) {
+		s.metrics.RecordSummaryAPIError(request.Model, "model_not_allowed")
+		return fmt.Errorf("model %q is not in OLLAMA_ALLOWED_MODELS", request.Model)
+	}
 
-		// Update metrics immediately
-		s.metrics.UpdateSummarizationQueueDepth(newDepth)
+	// Never block the caller: reject instead when the queue is full
+	s.mu.Lock()
+	err := s.admissionError()
+	s.mu.Unlock()
+	if err != nil {
+		return s.rejectRequest(request, err)
+	}
+
+	// Record the request durably before queuing it, so it survives a restart
+	inserted := s.persistRequest(request)
 
-		log.Printf("Enqueued summarization request for article: %s (queue depth: %d)",
-			request.ArticleTitle, newDepth)
-		return nil
+	// The lock was released for the write, so other enqueues may have
+	// filled the queue, or Stop begun draining it, in the meantime
+	s.mu.Lock()
+	if err := s.admissionError(); err != nil {
+		s.mu.Unlock()
+		if inserted {
+			s.forgetRequest(request.ArticleURL)
+		}
+		return s.rejectRequest(request, err)
+	}
+	s.queue.push(request)
+	newDepth := s.queue.len()
+	s.mu.Unlock()
 
+	// Wake the worker; a pending wake-up already covers this request
+	select {
+	case s.queueReady <- struct{}{}:
 	default:
+	}
+
+	// Update metrics immediately
+	s.metrics.UpdateSummarizationQueueDepth(newDepth)
+
+	slog.Debug("Enqueued summarization request", "feed_url", request.FeedURL, "article_url", request.ArticleURL, "request_id", request.RequestID,
+		"priority", request.Priority, "queue_depth", newDepth)
+	return nil
+}
+
+// admissionError returns why a request can't be queued right now, or nil.
+// The caller holds s.mu.
+func (s *SummarizationScheduler) admissionError() error {
+	if s.draining {
+		return ErrSchedulerDraining
+	}
+	if s.queue.len() >= s.queueCap {
 		// Queue is full - apply backpressure
-		s.mu.Lock()
 		s.totalErrors++
-		s.mu.Unlock()
+		return fmt.Errorf("summarization queue is full (max size: %d)", s.queueCap)
+	}
+	return nil
+}
 
-		err := fmt.Errorf("summarization queue is full (max size: %d)", cap(s.queue))
-		log.Printf("Failed to enqueue summarization request for %s: %v", request.ArticleTitle, err)
+// rejectRequest records the refusal of request for err, from
+// admissionError, and returns err.
+func (s *SummarizationScheduler) rejectRequest(request SummarizationRequest, err error) error {
+	if errors.Is(err, ErrSchedulerDraining) {
+		s.metrics.RecordSummaryAPIError(request.Model, "draining")
+		return err
+	}
+	slog.Warn("Failed to enqueue summarization request", "article_url", request.ArticleURL, "request_id", request.RequestID, "error", err)
+	s.metrics.RecordSummaryAPIError(request.Model, "queue_full")
+	return err
+}
 
-		// Record metrics for queue full condition
-		s.metrics.RecordSummaryAPIError(request.Model, "queue_full")
+// dequeue pops the highest-priority pending request and marks it as the one
+// being processed.
+func (s *SummarizationScheduler) dequeue() (SummarizationRequest, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-		return err
+	request, ok := s.queue.pop()
+	if !ok {
+		return request, false
 	}
+	s.currentRequest = &request
+	s.requestStartTime = time.Now()
+	return request, true
 }
 
 // EnqueueSummarizationSync enqueues and waits for the summarization to complete
@@ -228,82 +378,148 @@ func (s *SummarizationScheduler) worker(ctx context.Context) {
 	defer close(s.done)
 
 	config := loadSchedulerConfig(s.config)
-	log.Printf("Summarization worker started with timeout: %v", config.WorkerTimeout)
+	slog.Info("Summarization worker started", "timeout", config.WorkerTimeout.String())
 
 	for {
 		select {
 		case <-ctx.Done():
-			log.Println("Summarization worker stopping due to context cancellation")
+			slog.Info("Summarization worker stopping due to context cancellation")
 			return
 
 		case <-s.shutdown:
-			log.Println("Summarization worker stopping due to shutdown signal")
+			s.processQueued(ctx, config)
+			slog.Info("Summarization worker stopping due to shutdown signal", "queue_depth", s.getQueueDepth())
 			return
 
-		case request := <-s.queue:
-			s.mu.Lock()
-			s.queueDepth--
-			s.currentRequest = &request
-			s.requestStartTime = time.Now()
-			s.mu.Unlock()
+		case <-s.queueReady:
+			// Drain the queue, re-checking priorities after every request so
+			// urgent work that arrives meanwhile goes next
+			if !s.processQueued(ctx, config) {
+				slog.Info("Summarization worker stopping due to context cancellation")
+				return
+			}
+		}
+	}
+}
 
-			// Process the request with timeout
-			response := s.processRequest(ctx, request, config)
+// processQueued handles queued requests until the queue is empty, and
+// reports false if it stopped early because ctx was cancelled. Once Stop has
+// been called nothing new is queued, so this drains what was left.
+func (s *SummarizationScheduler) processQueued(ctx context.Context, config SummarizationSchedulerConfig) bool {
+	for {
+		if ctx.Err() != nil {
+			return false
+		}
+		request, ok := s.dequeue()
+		if !ok {
+			return true
+		}
+		s.handleRequest(ctx, request, config)
+	}
+}
 
-			// Calculate wait time and record metrics
-			waitTime := s.requestStartTime.Sub(request.EnqueuedAt)
-			s.metrics.RecordSummarizationQueueWait(request.Model, waitTime)
+// handleRequest processes one dequeued request and delivers its result.
+func (s *SummarizationScheduler) handleRequest(ctx context.Context, request SummarizationRequest, config SummarizationSchedulerConfig) {
+	// Process the request with timeout
+	response := s.processRequest(ctx, request, config)
 
-			// Record processing metrics
-			status := "success"
-			if response.Error != nil {
-				status = "error"
-			}
-			s.metrics.RecordSummarizationProcessing(request.Model, status, response.Duration)
+	// Calculate wait time and record metrics
+	s.mu.RLock()
+	waitTime := s.requestStartTime.Sub(request.EnqueuedAt)
+	s.mu.RUnlock()
+	s.metrics.RecordSummarizationQueueWait(request.Model, waitTime)
 
-			// Update statistics
-			s.mu.Lock()
-			s.totalProcessed++
-			if response.Error != nil {
-				s.totalErrors++
-			}
-			s.currentRequest = nil
-			s.mu.Unlock()
-
-			// Send response if channel is provided
-			if request.ResponseChan != nil {
-				select {
-				case request.ResponseChan <- response:
-				default:
-					log.Printf("Failed to send response to channel for article: %s", request.ArticleTitle)
-				}
-			}
+	// Record processing metrics
+	status := "success"
+	if response.Error != nil {
+		status = "error"
+	}
+	s.metrics.RecordSummarizationProcessing(request.Model, status, request.ContentSource, response.Duration)
 
-			// Save summary to database regardless of how it was requested
-			if err := s.updateArticleSummary(request.ArticleURL, response.Summary); err != nil {
-				log.Printf("Failed to save summary to database for %s: %v", request.ArticleURL, err)
-			}
+	// Update statistics
+	s.mu.Lock()
+	s.totalProcessed++
+	if response.Error != nil {
+		s.totalErrors++
+	}
+	s.currentRequest = nil
+	s.mu.Unlock()
 
-			// Send Discord notification if summarization was successful and webhooks are configured
-			if response.Error == nil {
-				webhookURLs := s.config.Discord.GetWebhookURLs()
-				if len(webhookURLs) > 0 {
-					go s.sendDiscordNotification(request, response.Summary)
-				}
-			}
+	// Send response if channel is provided
+	if request.ResponseChan != nil {
+		select {
+		case request.ResponseChan <- response:
+		default:
+			slog.Warn("Failed to send response to channel", "article_url", request.ArticleURL, "request_id", request.RequestID)
 		}
 	}
+
+	// Save summary to database regardless of how it was requested
+	if err := NewDatabaseOperations(s.db).SetArticleSummary(request.ArticleURL, response.Summary); err != nil {
+		slog.Error("Failed to save summary to database", "article_url", request.ArticleURL, "request_id", request.RequestID, "error", err)
+	}
+
+	// The request is done with unless it was cut short by shutdown, in which
+	// case its row stays behind for the next run to retry
+	if ctx.Err() == nil {
+		s.forgetRequest(request.ArticleURL)
+	}
+
+	// Send Discord notification if summarization was successful and webhooks are configured
+	if response.Error == nil {
+		s.notifySummary(request, response.Summary)
+	} else {
+		s.startSummaryGrace(request)
+	}
+}
+
+// notifySummary sends a finished summary to Discord and the generic
+// notification webhooks, whichever are configured.
+func (s *SummarizationScheduler) notifySummary(request SummarizationRequest, summary string) {
+	publishEvent(s.events, PipelineEvent{
+		Type:       eventArticleSummarized,
+		ArticleURL: request.ArticleURL,
+		Title:      request.ArticleTitle,
+		FeedURL:    request.FeedURL,
+		Model:      request.Model,
+	})
+
+	// An updated article was announced already; only Discord hears of the
+	// update, and only if OnArticleUpdate asks for it
+	if request.Update {
+		go s.sendArticleUpdate(request, summary)
+		return
+	}
+
+	webhookURLs := s.config.Discord.GetWebhookURLs()
+	if len(webhookURLs) > 0 {
+		go s.sendDiscordNotification(request, summary)
+	}
+	if len(s.config.Notifications.WebhookURLs) > 0 {
+		go s.sendWebhookNotifications(request, summary)
+	}
+}
+
+// PublishFeedSummary stores a summary the feed itself provided and sends it
+// out like a model summary, without queueing or calling the model.
+func (s *SummarizationScheduler) PublishFeedSummary(request SummarizationRequest, summary string) {
+	if err := NewDatabaseOperations(s.db).SetArticleSummary(request.ArticleURL, summary); err != nil {
+		slog.Error("Failed to save feed summary to database", "article_url", request.ArticleURL, "request_id", request.RequestID, "error", err)
+	}
+	s.notifySummary(request, summary)
 }
 
 // processRequest processes a single summarization request with retries and exponential backoff
 func (s *SummarizationScheduler) processRequest(ctx context.Context, request SummarizationRequest, config SummarizationSchedulerConfig) SummarizationResponse {
 	startTime := time.Now()
 
-	log.Printf("Processing summarization request for: %s (model: %s)", request.ArticleTitle, request.Model)
+	slog.Debug("Processing summarization request", "feed_url", request.FeedURL, "article_url", request.ArticleURL, "request_id", request.RequestID, "model", request.Model)
 
 	var lastErr error
 
-	// Create a timeout context for this specific request
+	// Create a timeout context for this specific request. WorkerTimeout is the
+	// overall budget across all attempts; each OLLAMA call inside is bounded
+	// separately by OLLAMA.Timeout so one slow call doesn't consume it all.
 	requestCtx, cancel := context.WithTimeout(ctx, config.WorkerTimeout)
 	defer cancel()
 
@@ -312,14 +528,23 @@ func (s *SummarizationScheduler) processRequest(ctx context.Context, request Sum
 		attemptStart := time.Now()
 
 		// Call the summarizer (this is the ONLY place Ollama is called)
-		summary, err := s.summarizer.SummarizeArticleWithModel(requestCtx, request.Content, request.ArticleURL, request.Model)
+		summary, err := s.summarizer.SummarizeArticleInput(requestCtx, summaryInput{
+			RequestID: request.RequestID,
+
+			Title: request.ArticleTitle,
+			Lead:  request.Lead,
+			Body:  request.Content,
+
+			ContentType:  request.ContentType,
+			SummaryWords: request.SummaryWords,
+		}, request.ArticleURL, request.Model)
 		attemptDuration := time.Since(attemptStart)
 
 		if err == nil {
 			// Success!
 			totalDuration := time.Since(startTime)
-			log.Printf("Successfully summarized article '%s' in %v (attempt %d/%d)",
-				request.ArticleTitle, totalDuration, attempt, config.MaxRetries)
+			slog.Info("Summarization request succeeded", "article_url", request.ArticleURL, "request_id", request.RequestID,
+				"duration_ms", totalDuration.Milliseconds(), "attempt", attempt, "max_retries", config.MaxRetries)
 
 			return SummarizationResponse{
 				Summary:   summary,
@@ -331,8 +556,8 @@ func (s *SummarizationScheduler) processRequest(ctx context.Context, request Sum
 		}
 
 		lastErr = err
-		log.Printf("Summarization attempt %d/%d failed for '%s': %v (took %v)",
-			attempt, config.MaxRetries, request.ArticleTitle, err, attemptDuration)
+		slog.Warn("Summarization attempt failed", "article_url", request.ArticleURL, "request_id", request.RequestID,
+			"attempt", attempt, "max_retries", config.MaxRetries, "duration_ms", attemptDuration.Milliseconds(), "error", err)
 
 		// Don't wait after the last attempt
 		if attempt < config.MaxRetries {
@@ -343,7 +568,7 @@ func (s *SummarizationScheduler) processRequest(ctx context.Context, request Sum
 			case <-requestCtx.Done():
 				// Context cancelled/timed out
 				totalDuration := time.Since(startTime)
-				log.Printf("Summarization context cancelled for '%s' after %v", request.ArticleTitle, totalDuration)
+				slog.Warn("Summarization context cancelled", "article_url", request.ArticleURL, "request_id", request.RequestID, "duration_ms", totalDuration.Milliseconds())
 
 				return SummarizationResponse{
 					Summary:   "summary unavailable",
@@ -355,14 +580,14 @@ func (s *SummarizationScheduler) processRequest(ctx context.Context, request Sum
 
 			case <-time.After(backoffDuration):
 				// Continue to next attempt
-				log.Printf("Retrying summarization for '%s' after %v backoff", request.ArticleTitle, backoffDuration)
+				slog.Debug("Retrying summarization", "article_url", request.ArticleURL, "request_id", request.RequestID, "backoff", backoffDuration.String())
 			}
 		}
 	}
 
 	// All retries failed
 	totalDuration := time.Since(startTime)
-	log.Printf("All summarization attempts failed for '%s' after %v", request.ArticleTitle, totalDuration)
+	slog.Error("All summarization attempts failed", "article_url", request.ArticleURL, "request_id", request.RequestID, "duration_ms", totalDuration.Milliseconds())
 
 	return SummarizationResponse{
 		Summary:   "summary unavailable",
@@ -394,7 +619,7 @@ func (s *SummarizationScheduler) metricsCollector(ctx context.Context) {
 // updateMetrics updates Prometheus metrics with current scheduler state
 func (s *SummarizationScheduler) updateMetrics() {
 	s.mu.RLock()
-	queueDepth := s.queueDepth
+	queueDepth := s.queue.len()
 	currentRequest := s.currentRequest
 	requestStartTime := s.requestStartTime
 	s.mu.RUnlock()
@@ -403,26 +628,24 @@ func (s *SummarizationScheduler) updateMetrics() {
 	s.metrics.UpdateSummarizationQueueDepth(queueDepth)
 
 	// Log current state for debugging
-	log.Printf("Summarization scheduler metrics - Queue depth: %d, Processing: %v",
-		queueDepth, currentRequest != nil)
+	slog.Debug("Summarization scheduler metrics", "queue_depth", queueDepth, "processing", currentRequest != nil)
 
 	if currentRequest != nil {
 		processingDuration := time.Since(requestStartTime)
-		log.Printf("Current request processing time: %v for article: %s",
-			processingDuration, currentRequest.ArticleTitle)
+		slog.Debug("Current summarization request", "article_url", currentRequest.ArticleURL, "request_id", currentRequest.RequestID,
+			"processing_ms", processingDuration.Milliseconds())
 	}
 }
 
-// updateArticleSummary updates the summary in the database
-func (s *SummarizationScheduler) updateArticleSummary(articleURL, summary string) error {
-	query := `UPDATE articles SET summary = $1, updated_at = NOW() WHERE url = $2`
-	_, err := s.db.Exec(query, summary, articleURL)
-	return err
-}
-
-// updateArticleDiscordStatus updates the posted_to_discord status in the database
+// updateArticleDiscordStatus updates the posted_to_discord status in the
+// database, stamping discord_posted_at the first time it is posted
 func (s *SummarizationScheduler) updateArticleDiscordStatus(articleURL string, posted bool) error {
-	query := `UPDATE articles SET posted_to_discord = $1, updated_at = NOW() WHERE url = $2`
+	query := `
+		UPDATE articles
+		SET posted_to_discord = $1,
+		    discord_posted_at = CASE WHEN $1 THEN COALESCE(discord_posted_at, NOW()) ELSE discord_posted_at END,
+		    updated_at = NOW()
+		WHERE url = $2`
 	_, err := s.db.Exec(query, posted, articleURL)
 	return err
 }
@@ -443,20 +666,20 @@ func (s *SummarizationScheduler) isArticlePostedToDiscord(articleURL string) (bo
 
 // sendDiscordNotification sends Discord notifications to all configured webhooks for a successfully summarized article
 func (s *SummarizationScheduler) sendDiscordNotification(request SummarizationRequest, summary string) {
-	// Get all configured webhook URLs
-	webhookURLs := s.config.Discord.GetWebhookURLs()
-	if len(webhookURLs) == 0 {
+	// Get all configured webhook failover groups
+	webhookGroups := s.config.Discord.WebhookGroups()
+	if len(webhookGroups) == 0 {
 		return
 	}
 
 	// Check if article has already been posted to Discord
 	alreadyPosted, err := s.isArticlePostedToDiscord(request.ArticleURL)
 	if err != nil {
-		log.Printf("Failed to check Discord status for article %s: %v", request.ArticleURL, err)
+		slog.Error("Failed to check Discord status", "article_url", request.ArticleURL, "request_id", request.RequestID, "error", err)
 		return
 	}
 	if alreadyPosted {
-		log.Printf("Skipping Discord notification for article %s: already posted to Discord", request.ArticleTitle)
+		slog.Debug("Skipping Discord notification: already posted", "article_url", request.ArticleURL, "request_id", request.RequestID)
 		return
 	}
 
@@ -466,72 +689,149 @@ func (s *SummarizationScheduler) sendDiscordNotification(request SummarizationRe
 	// Skip feeds the operator has excluded from Discord (e.g. high-volume CVE
 	// feeds). The article is still stored and summarized; it is just never posted.
 	if s.config.Discord.IsFeedExcluded(feedURL) {
-		log.Printf("Skipping Discord notification for article %s: feed %q is excluded from Discord", request.ArticleTitle, feedURL)
+		slog.Debug("Skipping Discord notification: feed is excluded from Discord", "feed_url", feedURL, "article_url", request.ArticleURL, "request_id", request.RequestID)
+		s.metrics.RecordArticleDropped(feedURL, dropExcludedFeed)
 		return
 	}
 
 	// Check if article was published before the cutoff date
 	cutoffDate := s.config.App.ArticleCutoffDate.UTC()
 	if publishDate.UTC().Before(cutoffDate) {
-		log.Printf("Skipping Discord notification for article published before cutoff date: %s (published: %s, cutoff: %s)",
-			request.ArticleTitle, publishDate.Format("2006-01-02T15:04:05Z"), cutoffDate.Format("2006-01-02T15:04:05Z"))
+		slog.Debug("Skipping Discord notification for article published before cutoff date", "feed_url", feedURL, "article_url", request.ArticleURL, "request_id", request.RequestID,
+			"published", publishDate.UTC(), "cutoff", cutoffDate)
+		s.metrics.RecordArticleDropped(feedURL, dropBeforeCutoff)
+		return
+	}
+
+	// Hold the post until quiet hours end; deferredReleaser sends it later.
+	if s.config.Discord.InQuietHours(time.Now()) {
+		s.deferredPosts.add(deferredPost{request: request, summary: summary, deferredAt: time.Now()})
+		s.metrics.RecordDiscordDeferredPost("deferred")
+		slog.Info("Deferring Discord notification: quiet hours", "feed_url", feedURL, "article_url", request.ArticleURL, "request_id", request.RequestID, "quiet_hours", s.config.Discord.QuietHours)
 		return
 	}
 
 	// Create ArticleMessage for Discord
 	articleMessage := ArticleMessage{
+		RequestID:    request.RequestID,
+		Title:        request.ArticleTitle,
+		URL:          request.ArticleURL,
+		Summary:      summary,
+		PublishDate:  publishDate,
+		FeedTitle:    feedTitle,
+		FeedURL:      feedURL,
+		Category:     request.Category,
+		Priority:     request.Priority,
+		SummaryLimit: contentSummaryLimit(s.config.Content, request.SummaryWords),
+	}
+
+	if s.discordSender.DigestEnabled() {
+		s.queueDigestArticle(request, articleMessage, webhookGroups)
+		return
+	}
+
+	slog.Debug("Sending Discord notifications", "feed_url", feedURL, "article_url", request.ArticleURL, "request_id", request.RequestID, "webhooks", len(webhookGroups))
+
+	successCount, skipped := s.postArticleToDiscord(request, articleMessage, webhookGroups)
+	if skipped {
+		return
+	}
+
+	// Update Discord status only if at least one webhook was successful
+	if successCount > 0 {
+		if err := s.updateArticleDiscordStatus(request.ArticleURL, true); err != nil {
+			slog.Error("Failed to update Discord status", "article_url", request.ArticleURL, "request_id", request.RequestID, "error", err)
+		} else {
+			slog.Debug("Updated Discord status to posted", "article_url", request.ArticleURL, "request_id", request.RequestID)
+		}
+		s.publishPosted(request)
+	} else {
+		s.metrics.RecordArticleDropped(feedURL, dropPostFailed)
+	}
+
+	slog.Info("Completed sending Discord notifications", "feed_url", feedURL, "article_url", request.ArticleURL, "request_id", request.RequestID,
+		"webhooks", len(webhookGroups), "successful", successCount)
+}
+
+// queueDigestArticle buffers the article for the next Discord digest to each
+// webhook group and marks it posted once any group's digest goes out.
+func (s *SummarizationScheduler) queueDigestArticle(request SummarizationRequest, articleMessage ArticleMessage, webhookGroups [][]string) {
+	slog.Debug("Queueing article for the next Discord digest", "article_url", request.ArticleURL, "request_id", request.RequestID, "webhooks", len(webhookGroups))
+
+	var posted sync.Once
+	for _, group := range webhookGroups {
+		s.discordSender.QueueArticle(group, articleMessage, func(err error) {
+			if err != nil {
+				return
+			}
+			if err := s.updateArticleDiscordStatus(request.ArticleURL, true); err != nil {
+				slog.Error("Failed to update Discord status", "article_url", request.ArticleURL, "request_id", request.RequestID, "error", err)
+			}
+			posted.Do(func() { s.publishPosted(request) })
+		})
+	}
+}
+
+// publishPosted emits the article_posted event of an article that reached
+// at least one Discord webhook.
+func (s *SummarizationScheduler) publishPosted(request SummarizationRequest) {
+	publishEvent(s.events, PipelineEvent{
+		Type:       eventArticlePosted,
+		ArticleURL: request.ArticleURL,
+		Title:      request.ArticleTitle,
+		FeedURL:    request.FeedURL,
+	})
+}
+
+// sendWebhookNotifications posts a summarized article to every generic
+// notification webhook. Unlike Discord posts these aren't tracked in the
+// database; a failed delivery is logged and not retried.
+func (s *SummarizationScheduler) sendWebhookNotifications(request SummarizationRequest, summary string) {
+	feedURL, _, publishDate := s.getArticleDetails(request.ArticleURL)
+	event := ArticleEvent{
 		Title:       request.ArticleTitle,
 		URL:         request.ArticleURL,
 		Summary:     summary,
-		PublishDate: publishDate,
-		FeedTitle:   feedTitle,
+		FeedURL:     feedURL,
+		PublishedAt: publishDate,
 	}
 
-	log.Printf("Sending Discord notifications to %d webhook(s) for article: %s", len(webhookURLs), request.ArticleTitle)
+	for _, webhookURL := range s.config.Notifications.WebhookURLs {
+		if err := s.notifier.Send(context.Background(), webhookURL, event); err != nil {
+			slog.Warn("Failed to send notification", "webhook", backendLabel(webhookURL), "article_url", request.ArticleURL, "request_id", request.RequestID, "error", err)
+		}
+	}
+}
 
-	// Send to all webhooks concurrently
+// sendToWebhookGroups sends the message to all webhook groups concurrently and
+// returns how many accepted it. Each group fails over to its backups
+// internally, with a Timeout budget per URL tried.
+func (s *SummarizationScheduler) sendToWebhookGroups(request SummarizationRequest, articleMessage ArticleMessage, webhookGroups [][]string) int64 {
 	var wg sync.WaitGroup
 	var successCount int64
 	var mu sync.Mutex
 
-	for i, webhookURL := range webhookURLs {
+	for i, group := range webhookGroups {
 		wg.Add(1)
-		go func(url string, webhookIndex int) {
+		go func(group []string, webhookIndex int) {
 			defer wg.Done()
 
-			// Create context with timeout for each webhook call
-			ctx, cancel := context.WithTimeout(context.Background(), s.config.Discord.Timeout)
-			defer cancel()
-
-			if err := s.discordSender.SendArticleToDiscord(ctx, url, articleMessage); err != nil {
-				log.Printf("Failed to send Discord notification to webhook %d for article %s: %v",
-					webhookIndex+1, request.ArticleTitle, err)
+			if _, err := s.discordSender.SendArticleWithFailover(context.Background(), group, articleMessage); err != nil {
+				slog.Warn("Failed to send Discord notification", "webhook", webhookIndex+1, "article_url", request.ArticleURL, "request_id", request.RequestID, "error", err)
 			} else {
-				log.Printf("Successfully sent Discord notification to webhook %d for article: %s",
-					webhookIndex+1, request.ArticleTitle)
+				slog.Debug("Sent Discord notification", "webhook", webhookIndex+1, "article_url", request.ArticleURL, "request_id", request.RequestID)
 
 				// Track successful sends
 				mu.Lock()
 				successCount++
 				mu.Unlock()
 			}
-		}(webhookURL, i)
+		}(group, i)
 	}
 
 	// Wait for all webhook calls to complete
 	wg.Wait()
-
-	// Update Discord status only if at least one webhook was successful
-	if successCount > 0 {
-		if err := s.updateArticleDiscordStatus(request.ArticleURL, true); err != nil {
-			log.Printf("Failed to update Discord status for article %s: %v", request.ArticleURL, err)
-		} else {
-			log.Printf("Updated Discord status to posted for article: %s", request.ArticleTitle)
-		}
-	}
-
-	log.Printf("Completed sending Discord notifications to %d webhook(s) for article: %s (successful: %d)",
-		len(webhookURLs), request.ArticleTitle, successCount)
+	return successCount
 }
 
 // getArticleDetails retrieves the raw feed URL, a display feed title, and the
@@ -542,7 +842,7 @@ func (s *SummarizationScheduler) getArticleDetails(articleURL string) (string, s
 	query := `SELECT feed_url, publish_date FROM articles WHERE url = $1 LIMIT 1`
 
 	if err := s.db.QueryRow(query, articleURL).Scan(&feedURL, &publishDate); err != nil {
-		log.Printf("Failed to get article details for %s: %v", articleURL, err)
+		slog.Warn("Failed to get article details", "article_url", articleURL, "error", err)
 		return "", "Unknown Feed", time.Now()
 	}
 
@@ -562,7 +862,7 @@ func (s *SummarizationScheduler) getArticleDetails(articleURL string) (string, s
 func (s *SummarizationScheduler) getQueueDepth() int {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	return s.queueDepth
+	return s.queue.len()
 }
 
 // GetStats returns scheduler statistics
@@ -571,8 +871,8 @@ func (s *SummarizationScheduler) GetStats() map[string]interface{} {
 	defer s.mu.RUnlock()
 
 	stats := map[string]interface{}{
-		"queue_depth":     s.queueDepth,
-		"queue_capacity":  cap(s.queue),
+		"queue_depth":     s.queue.len(),
+		"queue_capacity":  s.queueCap,
 		"total_processed": s.totalProcessed,
 		"total_errors":    s.totalErrors,
 		"is_running":      s.isRunning,
@@ -0,0 +1,451 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"information-broker/config"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var (
+	testMetricsOnce sync.Once
+	testMetricsInst *PrometheusMetrics
+)
+
+// testMetrics returns a shared metrics instance; NewPrometheusMetrics registers
+// collectors globally, so it can only be called once per process.
+func testMetrics() *PrometheusMetrics {
+	testMetricsOnce.Do(func() { testMetricsInst = NewPrometheusMetrics() })
+	return testMetricsInst
+}
+
+// newOllamaStub returns a server that answers /api/generate with the given
+// status and summary, counting how many requests it received.
+func newOllamaStub(t *testing.T, status int, summary string, hits *int32) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(hits, 1)
+		if status != http.StatusOK {
+			http.Error(w, "backend unavailable", status)
+			return
+		}
+		json.NewEncoder(w).Encode(SummaryResponse{Response: summary, Done: true})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newFallbackTestSummarizer(primary string, fallbacks []string, breakers *CircuitBreakerManager) *ArticleSummarizer {
+	cfg := &config.Config{
+		OLLAMA:  config.OLLAMAConfig{URL: primary, FallbackURLs: fallbacks, Timeout: 5 * time.Second},
+		Content: config.ContentConfig{MaxSummaryLength: 200},
+	}
+	return &ArticleSummarizer{
+		httpClient: &http.Client{Timeout: cfg.OLLAMA.Timeout},
+		config:     cfg,
+		breakers:   breakers,
+	}
+}
+
+func TestSummarizeWithFallback(t *testing.T) {
+	t.Run("fails over when the primary errors and stops at the first success", func(t *testing.T) {
+		var primaryHits, secondaryHits, tertiaryHits int32
+		primary := newOllamaStub(t, http.StatusInternalServerError, "", &primaryHits)
+		secondary := newOllamaStub(t, http.StatusOK, "summary from secondary", &secondaryHits)
+		tertiary := newOllamaStub(t, http.StatusOK, "summary from tertiary", &tertiaryHits)
+
+		s := newFallbackTestSummarizer(primary.URL, []string{secondary.URL, tertiary.URL}, NewCircuitBreakerManager())
+		summary, backend, err := s.summarizeWithFallback(context.Background(), "prompt", "llama2", summaryLimit{Unit: summaryUnitWords, Max: 200})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if summary != "summary from secondary" || backend != secondary.URL {
+			t.Errorf("got summary %q from %s, want secondary", summary, backend)
+		}
+		if primaryHits != 1 || secondaryHits != 1 || tertiaryHits != 0 {
+			t.Errorf("hits primary=%d secondary=%d tertiary=%d, want 1/1/0", primaryHits, secondaryHits, tertiaryHits)
+		}
+	})
+
+	t.Run("skips a primary whose breaker is open", func(t *testing.T) {
+		var primaryHits, secondaryHits int32
+		primary := newOllamaStub(t, http.StatusOK, "summary from primary", &primaryHits)
+		secondary := newOllamaStub(t, http.StatusOK, "summary from secondary", &secondaryHits)
+
+		breakers := NewCircuitBreakerManager()
+		breaker := breakers.GetOrCreateBreaker("summary_backend_"+primary.URL, &CircuitBreakerConfig{
+			FailureThreshold: 1, SuccessThreshold: 1, Timeout: time.Hour, ResetTimeout: time.Hour,
+		})
+		breaker.Execute(func() error { return context.DeadlineExceeded }, nil)
+
+		s := newFallbackTestSummarizer(primary.URL, []string{secondary.URL}, breakers)
+		summary, backend, err := s.summarizeWithFallback(context.Background(), "prompt", "llama2", summaryLimit{Unit: summaryUnitWords, Max: 200})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if backend != secondary.URL || summary != "summary from secondary" {
+			t.Errorf("got summary %q from %s, want secondary", summary, backend)
+		}
+		if primaryHits != 0 {
+			t.Errorf("primary with open breaker was called %d times", primaryHits)
+		}
+	})
+
+	t.Run("single backend keeps the primary-only behavior", func(t *testing.T) {
+		var hits int32
+		primary := newOllamaStub(t, http.StatusOK, "only summary", &hits)
+
+		s := newFallbackTestSummarizer(primary.URL, nil, nil)
+		summary, backend, err := s.summarizeWithFallback(context.Background(), "prompt", "llama2", summaryLimit{Unit: summaryUnitWords, Max: 200})
+		if err != nil || summary != "only summary" || backend != primary.URL {
+			t.Errorf("got (%q, %q, %v), want only summary from primary", summary, backend, err)
+		}
+	})
+
+	t.Run("returns the last error when every backend fails", func(t *testing.T) {
+		var primaryHits, secondaryHits int32
+		primary := newOllamaStub(t, http.StatusBadGateway, "", &primaryHits)
+		secondary := newOllamaStub(t, http.StatusServiceUnavailable, "", &secondaryHits)
+
+		s := newFallbackTestSummarizer(primary.URL, []string{secondary.URL}, nil)
+		if _, _, err := s.summarizeWithFallback(context.Background(), "prompt", "llama2", summaryLimit{Unit: summaryUnitWords, Max: 200}); err == nil {
+			t.Fatal("expected an error when all backends fail")
+		}
+		if primaryHits != 1 || secondaryHits != 1 {
+			t.Errorf("hits primary=%d secondary=%d, want each tried once", primaryHits, secondaryHits)
+		}
+	})
+}
+
+func TestSummarizeArticleAttemptTimeout(t *testing.T) {
+	// slowThenFast stalls its first request past the attempt timeout and
+	// answers every later one immediately.
+	slowThenFast := func(hits *int32) *httptest.Server {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Drain the body so the server notices when the client gives up
+			io.Copy(io.Discard, r.Body)
+			if atomic.AddInt32(hits, 1) == 1 {
+				select {
+				case <-r.Context().Done():
+				case <-time.After(5 * time.Second):
+				}
+				return
+			}
+			json.NewEncoder(w).Encode(SummaryResponse{Response: "recovered summary", Done: true})
+		}))
+		t.Cleanup(srv.Close)
+		return srv
+	}
+
+	newSummarizer := func(url string, attemptTimeout time.Duration) *ArticleSummarizer {
+		cfg := &config.Config{
+			OLLAMA:      config.OLLAMAConfig{URL: url, Timeout: attemptTimeout, MaxRetries: 3},
+			Content:     config.ContentConfig{MaxSummaryLength: 200},
+			Performance: config.PerformanceConfig{MaxArticleContentLength: 10000},
+		}
+		return &ArticleSummarizer{httpClient: &http.Client{}, config: cfg, metrics: testMetrics()}
+	}
+
+	t.Run("slow first attempt times out and the retry succeeds within budget", func(t *testing.T) {
+		var hits int32
+		srv := slowThenFast(&hits)
+		s := newSummarizer(srv.URL, 200*time.Millisecond)
+
+		// Budget covers one timed-out attempt, the 1s backoff and a retry
+		ctx, cancel := context.WithTimeout(context.Background(), 4*time.Second)
+		defer cancel()
+
+		start := time.Now()
+		summary, err := s.SummarizeArticle(ctx, "article text", "https://example.com/a", "llama2")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if summary != "recovered summary" {
+			t.Errorf("summary = %q, want recovered summary", summary)
+		}
+		if hits := atomic.LoadInt32(&hits); hits != 2 {
+			t.Errorf("backend hit %d times, want 2", hits)
+		}
+		if elapsed := time.Since(start); elapsed > 3*time.Second {
+			t.Errorf("slow attempt was not cut off at the attempt timeout (took %v)", elapsed)
+		}
+	})
+
+	t.Run("no retry once the overall budget is spent", func(t *testing.T) {
+		var hits int32
+		srv := slowThenFast(&hits)
+		s := newSummarizer(srv.URL, 5*time.Second)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+
+		if _, err := s.SummarizeArticle(ctx, "article text", "https://example.com/b", "llama2"); err == nil {
+			t.Fatal("expected an error once the request budget ran out")
+		}
+		if hits := atomic.LoadInt32(&hits); hits != 1 {
+			t.Errorf("backend hit %d times, want 1", hits)
+		}
+	})
+}
+
+func TestSummaryLogsCarryRequestID(t *testing.T) {
+	var hits int32
+	srv := newOllamaStub(t, http.StatusOK, "A short summary.", &hits)
+	db, recorder := openExecRecorder(t)
+	cfg := &config.Config{
+		OLLAMA:      config.OLLAMAConfig{URL: srv.URL, Timeout: 5 * time.Second, MaxRetries: 1},
+		Content:     config.ContentConfig{MaxSummaryLength: 200},
+		Performance: config.PerformanceConfig{MaxArticleContentLength: 10000},
+	}
+	s := &ArticleSummarizer{db: db, httpClient: &http.Client{}, config: cfg, metrics: testMetrics()}
+
+	input := summaryInput{RequestID: "5eed1d", Body: "article text"}
+	if _, err := s.SummarizeArticleInput(context.Background(), input, "https://example.com/a", "llama2"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	input.Body = ""
+	s.SummarizeArticleInput(context.Background(), input, "https://example.com/b", "llama2")
+
+	inserts := recorder.recordedWrites("INSERT INTO summary_logs")
+	if len(inserts) != 2 {
+		t.Fatalf("logged %d summary_logs rows, want the success and the failure", len(inserts))
+	}
+	for _, args := range inserts {
+		if requestID := args[len(args)-1]; requestID != "5eed1d" {
+			t.Errorf("summary_logs row for %v has request_id %v, want 5eed1d", args[0], requestID)
+		}
+	}
+}
+
+func TestSummarizeArticleLanguageMismatchRetry(t *testing.T) {
+	const (
+		article       = "Les attaquants ont exploité une faille dans la passerelle et le fournisseur a publié un correctif pour les clients qui sont concernés."
+		englishResult = "Attackers exploited a flaw in the gateway and the vendor has released a patch for the customers that are affected."
+		frenchResult  = "Des attaquants ont exploité une faille dans la passerelle; un correctif est disponible pour les clients qui sont concernés."
+	)
+
+	var prompts []string
+	var mu sync.Mutex
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req SummaryRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		mu.Lock()
+		prompts = append(prompts, req.Prompt)
+		mu.Unlock()
+
+		// Only answer in French once the prompt asks for it explicitly
+		response := englishResult
+		if strings.Contains(req.Prompt, "Write the summary in French") {
+			response = frenchResult
+		}
+		json.NewEncoder(w).Encode(SummaryResponse{Response: response, Done: true})
+	}))
+	defer srv.Close()
+
+	cfg := &config.Config{
+		OLLAMA:      config.OLLAMAConfig{URL: srv.URL, Timeout: 5 * time.Second, MaxRetries: 3},
+		Content:     config.ContentConfig{MaxSummaryLength: 200, SummaryLanguageCheck: true},
+		Performance: config.PerformanceConfig{MaxArticleContentLength: 10000},
+	}
+	s := &ArticleSummarizer{httpClient: &http.Client{}, config: cfg, metrics: testMetrics()}
+
+	summary, err := s.SummarizeArticle(context.Background(), article, "https://example.fr/a", "llama2")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if summary != frenchResult {
+		t.Errorf("summary = %q, want the French retry result", summary)
+	}
+	if len(prompts) != 2 {
+		t.Fatalf("expected 2 attempts, got %d", len(prompts))
+	}
+	if strings.Contains(prompts[0], "Write the summary in") {
+		t.Errorf("first attempt should use the plain prompt")
+	}
+	if !strings.Contains(prompts[1], "Write the summary in French") {
+		t.Errorf("retry prompt missing the language instruction: %q", prompts[1])
+	}
+
+	t.Run("auto summary language asks for French up front", func(t *testing.T) {
+		prompts = nil
+		cfg.Content.SummaryLanguage = "auto"
+		defer func() { cfg.Content.SummaryLanguage = "" }()
+		summary, err := s.SummarizeArticle(context.Background(), article, "https://example.fr/c", "llama2")
+		if err != nil || summary != frenchResult || len(prompts) != 1 {
+			t.Errorf("got (%q, %v) after %d attempts, want French summary in one attempt", summary, err, len(prompts))
+		}
+	})
+
+	t.Run("check disabled accepts the mismatched summary", func(t *testing.T) {
+		prompts = nil
+		cfg.Content.SummaryLanguageCheck = false
+		summary, err := s.SummarizeArticle(context.Background(), article, "https://example.fr/b", "llama2")
+		if err != nil || summary != englishResult || len(prompts) != 1 {
+			t.Errorf("got (%q, %v) after %d attempts, want English summary in one attempt", summary, err, len(prompts))
+		}
+	})
+}
+
+func TestCallOllamaAPIStreamedBody(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		want    string
+		wantErr string
+	}{
+		{"single object", `{"model":"llama3","response":"A patch is out.","done":true}`, "A patch is out.", ""},
+		{"ndjson", "{\"model\":\"llama3\",\"response\":\"A patch\",\"done\":false}\n" +
+			"{\"model\":\"llama3\",\"response\":\" is out.\",\"done\":false}\n" +
+			"{\"model\":\"llama3\",\"response\":\"\",\"done\":true}\n", "A patch is out.", ""},
+		{"ndjson error", "{\"response\":\"A\",\"done\":false}\n{\"error\":\"model unloaded\"}\n", "", "model unloaded"},
+		{"garbage", "{\"response\":\"A\"}\nnot json\n", "", "failed to parse response JSON"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				io.WriteString(w, tt.body)
+			}))
+			defer srv.Close()
+
+			got, err := callOllamaAPIStandalone(context.Background(), srv.Client(), srv.URL, "prompt", "llama3")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want one mentioning %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("summary = (%q, %v), want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestSummarizeArticleOllamaResponses(t *testing.T) {
+	longSummary := strings.TrimSpace(strings.Repeat("word ", 300))
+	tests := []struct {
+		name     string
+		status   int
+		response SummaryResponse
+		want     string
+		wantErr  string
+		wantHits int32
+	}{
+		{"success", http.StatusOK, SummaryResponse{Response: "  A patch is out for the gateway flaw.  ", Done: true}, "A patch is out for the gateway flaw.", "", 1},
+		{"http 500 is retried until attempts run out", http.StatusInternalServerError, SummaryResponse{}, "summary unavailable", "status 500", 3},
+		{"empty response", http.StatusOK, SummaryResponse{Response: " \n ", Done: true}, "summary unavailable", "empty summary", 3},
+		{"api error field", http.StatusOK, SummaryResponse{Error: "model 'llama2' not found"}, "summary unavailable", "model 'llama2' not found", 3},
+		{"cut to the word limit", http.StatusOK, SummaryResponse{Response: longSummary, Done: true}, strings.TrimSpace(strings.Repeat("word ", 50)) + "...", "", 1},
+		{"slack above the word limit is kept", http.StatusOK, SummaryResponse{Response: strings.Join(strings.Fields(longSummary)[:65], " "), Done: true},
+			strings.Join(strings.Fields(longSummary)[:65], " "), "", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var hits int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&hits, 1)
+				if tt.status != http.StatusOK {
+					http.Error(w, "backend unavailable", tt.status)
+					return
+				}
+				json.NewEncoder(w).Encode(tt.response)
+			}))
+			defer srv.Close()
+
+			cfg := &config.Config{
+				OLLAMA:      config.OLLAMAConfig{URL: srv.URL, Timeout: 5 * time.Second, MaxRetries: 3},
+				Content:     config.ContentConfig{MaxSummaryLength: 50},
+				Performance: config.PerformanceConfig{MaxArticleContentLength: 10000},
+			}
+			s := &ArticleSummarizer{httpClient: srv.Client(), config: cfg, metrics: testMetrics(), retryBackoff: time.Millisecond}
+
+			summary, err := s.SummarizeArticle(context.Background(), "article text", "https://example.com/"+strings.ReplaceAll(tt.name, " ", "-"), "llama2")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("error = %v, want one mentioning %q", err, tt.wantErr)
+				}
+			} else if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if summary != tt.want {
+				t.Errorf("summary = %q, want %q", summary, tt.want)
+			}
+			if hits != tt.wantHits {
+				t.Errorf("backend hit %d times, want %d", hits, tt.wantHits)
+			}
+		})
+	}
+}
+
+func TestSummarizeArticleCancelledDuringBackoff(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		http.Error(w, "backend unavailable", http.StatusInternalServerError)
+		// Cancel once the summarizer has moved on to waiting out the backoff
+		time.AfterFunc(50*time.Millisecond, cancel)
+	}))
+	defer srv.Close()
+
+	cfg := &config.Config{
+		OLLAMA:      config.OLLAMAConfig{URL: srv.URL, Timeout: 5 * time.Second, MaxRetries: 3},
+		Content:     config.ContentConfig{MaxSummaryLength: 50},
+		Performance: config.PerformanceConfig{MaxArticleContentLength: 10000},
+	}
+	metrics := testMetrics()
+	s := &ArticleSummarizer{httpClient: srv.Client(), config: cfg, metrics: metrics, retryBackoff: time.Hour}
+
+	before := counterValue(t, metrics.summaryAPIErrors.WithLabelValues("llama2", "context_cancelled"))
+	start := time.Now()
+	_, err := s.SummarizeArticle(ctx, "article text", "https://example.com/cancelled", "llama2")
+	if err == nil || !strings.Contains(err.Error(), "context cancelled") {
+		t.Fatalf("error = %v, want the request reported as cancelled", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("cancellation didn't cut the backoff short (took %v)", elapsed)
+	}
+	if hits != 1 {
+		t.Errorf("backend hit %d times, want 1", hits)
+	}
+	if got := counterValue(t, metrics.summaryAPIErrors.WithLabelValues("llama2", "context_cancelled")) - before; got != 1 {
+		t.Errorf("context_cancelled errors grew by %v, want 1", got)
+	}
+}
+
+func TestCleanSummaryContent(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		want     string
+	}{
+		{"plain summary", "A patch is out.", "A patch is out."},
+		{"think block", "<think>The user wants a summary.</think>A patch is out.", "A patch is out."},
+		{"multiline think block", "<think>\nFirst, read the article.\nThen summarize.\n</think>\n\nA patch is out.", "A patch is out."},
+		{"uppercase tags with spaces", "<THINK >reasoning</Think >A patch is out.", "A patch is out."},
+		{"thinking block", "<thinking>hmm</thinking> A patch is out.", "A patch is out."},
+		{"reason block", "A patch <reason>why</reason>is out.", "A patch is out."},
+		{"analysis block", "<analysis>The article covers a flaw.</analysis>A patch is out.", "A patch is out."},
+		{"several blocks", "<think>a</think><analysis>b</analysis>A patch<reason>c</reason> is out.", "A patch is out."},
+		{"unclosed think tag", "<think>A patch is out.", "A patch is out."},
+		{"stray closing tag", "reasoning leaked</think> A patch is out.", "reasoning leaked A patch is out."},
+		{"whitespace collapsed", "A  patch\n\tis   out.", "A patch is out."},
+		{"nothing left", "<think>only reasoning</think>", filteredSummaryFallback},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cleanSummaryContent(tt.response); got != tt.want {
+				t.Errorf("cleanSummaryContent(%q) = %q, want %q", tt.response, got, tt.want)
+			}
+		})
+	}
+}
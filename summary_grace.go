@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// summaryGraceBatchSize bounds the articles one grace pass re-queues or
+// releases, so a backlog drains over several passes instead of flooding the
+// queue.
+const summaryGraceBatchSize = 50
+
+// graceArticle is an article in its summary grace period.
+type graceArticle struct {
+	request SummarizationRequest
+	preview string    // Posted in place of the summary once the grace expires
+	until   time.Time // End of the grace period
+}
+
+// startSummaryGrace opens the grace period of an article whose summarization
+// just failed, if its feed has one. An article already in its grace period
+// keeps its original deadline, so retries don't extend it.
+func (s *SummarizationScheduler) startSummaryGrace(request SummarizationRequest) {
+	if !s.config.Content.IsSummaryGraceFeed(request.FeedURL) {
+		return
+	}
+
+	until := time.Now().Add(s.config.Content.SummaryGracePeriod)
+	_, err := s.db.Exec(`
+		UPDATE articles SET summary_grace_until = $1
+		WHERE url = $2 AND summary_grace_until IS NULL AND NOT COALESCE(posted_to_discord, FALSE)`,
+		until, request.ArticleURL)
+	if err != nil {
+		slog.Error("Failed to start summary grace period", "article_url", request.ArticleURL, "request_id", request.RequestID, "error", err)
+	}
+}
+
+// summaryGraceRetrier periodically retries the summaries of articles in
+// their grace period and releases those whose grace has run out.
+func (s *SummarizationScheduler) summaryGraceRetrier(ctx context.Context) {
+	interval := s.config.Content.SummaryGraceRetryInterval
+	if interval <= 0 {
+		interval = 5 * time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.shutdown:
+			return
+		case <-ticker.C:
+			s.retryGraceArticles()
+		}
+	}
+}
+
+// retryGraceArticles runs one grace pass over the articles that still have
+// no summary and haven't been posted, earliest deadline first.
+func (s *SummarizationScheduler) retryGraceArticles() {
+	rows, err := s.db.Query(`
+		SELECT url, title, COALESCE(feed_url, ''), COALESCE(full_content, ''), COALESCE(preview, ''), summary_grace_until
+		FROM articles
+		WHERE summary_grace_until IS NOT NULL
+		  AND NOT COALESCE(posted_to_discord, FALSE)
+		  AND (summary IS NULL OR summary = 'summary unavailable')
+		ORDER BY summary_grace_until
+		LIMIT $1`, summaryGraceBatchSize)
+	if err != nil {
+		slog.Error("Failed to load articles in their summary grace period", "error", err)
+		return
+	}
+
+	var articles []graceArticle
+	for rows.Next() {
+		var article graceArticle
+		if err := rows.Scan(&article.request.ArticleURL, &article.request.ArticleTitle, &article.request.FeedURL,
+			&article.request.Content, &article.preview, &article.until); err != nil {
+			slog.Error("Failed to scan article in its summary grace period", "error", err)
+			continue
+		}
+		article.request = s.withFeedDirectives(article.request)
+		articles = append(articles, article)
+	}
+	rows.Close()
+
+	s.handleGraceArticles(articles, time.Now())
+}
+
+// handleGraceArticles re-queues the summarization of each article still
+// within its grace period, unless it is already queued, and posts the rest
+// to Discord with their preview in place of a summary. The deadline is
+// cleared before such a post, so the article is released only once.
+func (s *SummarizationScheduler) handleGraceArticles(articles []graceArticle, now time.Time) {
+	for _, article := range articles {
+		request := article.request
+
+		if now.Before(article.until) {
+			if s.isQueued(request.ArticleURL) {
+				continue
+			}
+			slog.Info("Retrying summarization within its grace period", "title", request.ArticleTitle, "article_url", request.ArticleURL)
+			if err := s.EnqueueSummarization(request); err != nil {
+				slog.Error("Failed to re-queue summarization", "article_url", request.ArticleURL, "error", err)
+			}
+			continue
+		}
+
+		if _, err := s.db.Exec(`UPDATE articles SET summary_grace_until = NULL WHERE url = $1`, request.ArticleURL); err != nil {
+			slog.Error("Failed to clear summary grace period", "article_url", request.ArticleURL, "error", err)
+			continue
+		}
+		slog.Info("Summary grace period expired, posting without a summary", "title", request.ArticleTitle, "article_url", request.ArticleURL)
+		s.sendDiscordNotification(request, article.preview)
+	}
+}
+
+// isQueued reports whether a summarization of the article is pending or in
+// progress.
+func (s *SummarizationScheduler) isQueued(articleURL string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.currentRequest != nil && s.currentRequest.ArticleURL == articleURL {
+		return true
+	}
+	return s.queue.contains(articleURL)
+}
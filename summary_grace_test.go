@@ -0,0 +1,176 @@
+package main
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"information-broker/config"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newGraceTestScheduler returns a scheduler with a one-hour summary grace
+// period for feeds matching "important", posting to a Discord stub whose
+// received messages it returns.
+func newGraceTestScheduler(t *testing.T, summarizerURL string) (*SummarizationScheduler, *execRecorder, func() []DiscordWebhookMessage) {
+	t.Helper()
+	var mu sync.Mutex
+	var received []DiscordWebhookMessage
+	discord := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg DiscordWebhookMessage
+		json.NewDecoder(r.Body).Decode(&msg)
+		mu.Lock()
+		received = append(received, msg)
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(discord.Close)
+
+	db, recorder := openExecRecorder(t)
+	recorder.answer("SELECT posted_to_discord", []driver.Value{false})
+	recorder.answer("SELECT feed_url, publish_date", []driver.Value{"https://important.example/feed", time.Now()})
+
+	metrics := testMetrics()
+	cfg := &config.Config{
+		OLLAMA:  config.OLLAMAConfig{Model: "llama3", MaxRetries: 1},
+		Discord: config.DiscordConfig{WebhookURL: discord.URL, Timeout: 5 * time.Second},
+		Content: config.ContentConfig{
+			SummaryGracePeriod: time.Hour,
+			SummaryGraceFeeds:  []string{"important"},
+		},
+	}
+	summarizer := newFallbackTestSummarizer(summarizerURL, nil, NewCircuitBreakerManager())
+	summarizer.metrics = metrics
+	summarizer.config.OLLAMA.MaxRetries = 1
+
+	s := &SummarizationScheduler{
+		db:         db,
+		config:     cfg,
+		metrics:    metrics,
+		summarizer: summarizer,
+		discordSender: &DiscordWebhookSender{
+			httpClient: &http.Client{Timeout: 5 * time.Second},
+			metrics:    metrics,
+			config:     &cfg.Discord,
+		},
+		deferredPosts: &deferredPostQueue{},
+		queueCap:      10,
+		queueReady:    make(chan struct{}, 1),
+	}
+	return s, recorder, func() []DiscordWebhookMessage {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]DiscordWebhookMessage(nil), received...)
+	}
+}
+
+// waitForMessages waits for the Discord stub to have received n messages.
+func waitForMessages(t *testing.T, received func() []DiscordWebhookMessage, n int) []DiscordWebhookMessage {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for len(received()) < n && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	return received()
+}
+
+func TestSummaryGracePostsOnceSummarized(t *testing.T) {
+	var failing atomic.Bool
+	failing.Store(true)
+	ollama := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			http.Error(w, "model loading", http.StatusServiceUnavailable)
+			return
+		}
+		json.NewEncoder(w).Encode(SummaryResponse{Response: "The retried summary.", Done: true})
+	}))
+	defer ollama.Close()
+
+	s, recorder, received := newGraceTestScheduler(t, ollama.URL)
+	schedulerConfig := SummarizationSchedulerConfig{MaxRetries: 1, WorkerTimeout: 5 * time.Second}
+	request := SummarizationRequest{
+		ArticleURL:   "https://important.example/a",
+		ArticleTitle: "Important article",
+		FeedURL:      "https://important.example/feed",
+		Content:      "The article body.",
+		Model:        "llama3",
+	}
+
+	s.handleRequest(context.Background(), request, schedulerConfig)
+	var graceUntil time.Time
+	for _, args := range recorder.recorded() {
+		if until, ok := args[0].(time.Time); ok && args[1] == request.ArticleURL {
+			graceUntil = until
+		}
+	}
+	if graceUntil.IsZero() {
+		t.Fatalf("failed summarization didn't start a grace period: %v", recorder.recorded())
+	}
+	if got := received(); len(got) != 0 {
+		t.Fatalf("posted %d message(s) without a summary inside the grace period", len(got))
+	}
+
+	// Within the grace the pass re-queues the article, once
+	article := graceArticle{request: request, preview: "The article preview.", until: graceUntil}
+	s.handleGraceArticles([]graceArticle{article}, time.Now())
+	s.handleGraceArticles([]graceArticle{article}, time.Now())
+	if depth := s.queue.len(); depth != 1 {
+		t.Fatalf("queue depth = %d, want the article re-queued once", depth)
+	}
+
+	failing.Store(false)
+	queued, _ := s.dequeue()
+	s.handleRequest(context.Background(), queued, schedulerConfig)
+
+	got := waitForMessages(t, received, 1)
+	if len(got) != 1 || len(got[0].Embeds) != 1 || got[0].Embeds[0].Description != "The retried summary." {
+		t.Errorf("received %+v, want one post with the retried summary", got)
+	}
+}
+
+func TestSummaryGraceExpiryPostsRaw(t *testing.T) {
+	s, recorder, received := newGraceTestScheduler(t, "http://127.0.0.1:0")
+
+	article := graceArticle{
+		request: SummarizationRequest{ArticleURL: "https://important.example/b", ArticleTitle: "Unsummarized article"},
+		preview: "The article preview.",
+		until:   time.Now().Add(-time.Minute),
+	}
+	s.handleGraceArticles([]graceArticle{article}, time.Now())
+
+	if depth := s.queue.len(); depth != 0 {
+		t.Errorf("expired article was re-queued (queue depth %d)", depth)
+	}
+	updates := recorder.recorded()
+	if len(updates) == 0 || len(updates[0]) != 1 || updates[0][0] != article.request.ArticleURL {
+		t.Errorf("updates = %v, want the grace deadline cleared first", updates)
+	}
+	got := waitForMessages(t, received, 1)
+	if len(got) != 1 || got[0].Embeds[0].Description != "The article preview." {
+		t.Errorf("received %+v, want one post with the preview in place of a summary", got)
+	}
+}
+
+func TestIsSummaryGraceFeed(t *testing.T) {
+	c := config.ContentConfig{SummaryGracePeriod: time.Hour, SummaryGraceFeeds: []string{"Important.example"}}
+	if !c.IsSummaryGraceFeed("https://important.example/feed") || c.IsSummaryGraceFeed("https://other.example/feed") {
+		t.Error("SummaryGraceFeeds should match feed URLs case-insensitively by substring")
+	}
+
+	c.SummaryGraceFeeds = nil
+	if !c.IsSummaryGraceFeed("https://other.example/feed") {
+		t.Error("an empty SummaryGraceFeeds should cover every feed")
+	}
+	if c.IsSummaryGraceFeed("") {
+		t.Error("an article without a feed URL got a grace period")
+	}
+
+	c.SummaryGracePeriod = 0
+	if c.IsSummaryGraceFeed("https://important.example/feed") {
+		t.Error("grace period applied while disabled")
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/mmcdole/gofeed"
+)
+
+// maxLeadLength caps the lead passed to the model. A standfirst is a sentence
+// or two; anything much longer is the feed repeating the body.
+const maxLeadLength = 600
+
+// summaryInput is the article as handed to the summarization prompt. Title and
+// Lead are optional and labeled separately from Body so the model can anchor
+// on them; Body is the full article text.
+type summaryInput struct {
+	RequestID string // The summarization request, for logs and summary_logs; not part of the prompt
+
+	Title string
+	Lead  string
+	Body  string
+
+	ContentType  string // The feed's content_type directive; "" for the generic prompt
+	SummaryWords int    // The feed's summary_words directive; 0 for Content.MaxSummaryLength
+}
+
+// articleLead returns the feed item's description as a plain-text lead for
+// the summarization prompt. It returns "" when the description is what body
+// was built from, or when body already contains it, since repeating it would
+// only spend prompt space.
+func articleLead(item *gofeed.Item, body, source string) string {
+	if source == contentSourceDescription || strings.TrimSpace(item.Description) == "" {
+		return ""
+	}
+
+	lead := plainText(item.Description)
+	if lead == "" || strings.Contains(strings.Join(strings.Fields(body), " "), lead) {
+		return ""
+	}
+	return buildArticlePreview(lead, maxLeadLength)
+}
+
+// plainText strips the markup from a feed's HTML snippet and collapses its
+// whitespace onto one line.
+func plainText(html string) string {
+	if doc, err := goquery.NewDocumentFromReader(strings.NewReader(html)); err == nil {
+		html = doc.Text()
+	}
+	return strings.Join(strings.Fields(html), " ")
+}
+
+// labeledArticleText renders input as the article section of the prompt:
+// the title and lead on their own labeled lines (when enabled and present)
+// followed by the labeled body.
+func (s *ArticleSummarizer) labeledArticleText(input summaryInput) string {
+	var b strings.Builder
+	if title := s.promptTitle(input); title != "" {
+		b.WriteString("Title: " + title + "\n")
+	}
+	if lead := s.promptLead(input); lead != "" {
+		b.WriteString("Lead: " + lead + "\n")
+	}
+	if b.Len() > 0 {
+		b.WriteString("\n")
+	}
+	b.WriteString("Article text:\n" + input.Body)
+	return b.String()
+}
+
+// promptTitle returns the title to include in the prompt, collapsed onto one
+// line, or "" if titles are disabled.
+func (s *ArticleSummarizer) promptTitle(input summaryInput) string {
+	if !s.config.Content.SummaryIncludeTitle {
+		return ""
+	}
+	return strings.Join(strings.Fields(input.Title), " ")
+}
+
+// promptLead returns the lead to include in the prompt, collapsed onto one
+// line, or "" if leads are disabled.
+func (s *ArticleSummarizer) promptLead(input summaryInput) string {
+	if !s.config.Content.SummaryIncludeLead {
+		return ""
+	}
+	return strings.Join(strings.Fields(input.Lead), " ")
+}
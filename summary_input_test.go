@@ -0,0 +1,230 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"information-broker/config"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mmcdole/gofeed"
+)
+
+func TestArticleLead(t *testing.T) {
+	body := "The vendor shipped a fix on Tuesday. Customers should update now."
+
+	tests := []struct {
+		name        string
+		description string
+		source      string
+		want        string
+	}{
+		{"separate standfirst", "<p>Attackers exploited a <b>zero-day</b> in the gateway.</p>", contentSourceScraped, "Attackers exploited a zero-day in the gateway."},
+		{"description is the body", "Attackers exploited a zero-day.", contentSourceDescription, ""},
+		{"already in the body", "<p>The vendor shipped a fix\non Tuesday.</p>", contentSourceFeedContent, ""},
+		{"empty description", "  ", contentSourceScraped, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := &gofeed.Item{Description: tt.description}
+			if got := articleLead(item, body, tt.source); got != tt.want {
+				t.Errorf("articleLead() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	long := strings.Repeat("word ", maxLeadLength)
+	if got := articleLead(&gofeed.Item{Description: long}, body, contentSourceScraped); len(got) > maxLeadLength+len("...") {
+		t.Errorf("lead not capped: %d bytes", len(got))
+	}
+}
+
+func TestCreateSummaryPromptIncludesTitleAndLead(t *testing.T) {
+	cfg := &config.Config{
+		Performance: config.PerformanceConfig{MaxArticleContentLength: 10000},
+		Content:     config.ContentConfig{MaxSummaryLength: 50, SummaryIncludeTitle: true, SummaryIncludeLead: true},
+	}
+	s := &ArticleSummarizer{config: cfg}
+	input := summaryInput{
+		Title: "Gateway zero-day\nexploited in the wild",
+		Lead:  "Attackers are hitting unpatched gateways.",
+		Body:  "The vendor shipped a fix on Tuesday.",
+	}
+
+	want := `Please provide a concise summary of the following article in exactly 50 words or less. The summary should be:
+- Written in clear, simple language that non-technical users can understand
+- Focused on the main points and key takeaways
+- Objective and factual
+- Complete sentences with proper grammar
+- Led by the main point given in the title and lead
+
+Title: Gateway zero-day exploited in the wild
+Lead: Attackers are hitting unpatched gateways.
+
+Article text:
+The vendor shipped a fix on Tuesday.
+
+Summary:`
+	if got := s.createSummaryPrompt(input); got != want {
+		t.Errorf("prompt mismatch:\ngot:\n%s\n\nwant:\n%s", got, want)
+	}
+
+	cfg.Content.SummaryIncludeTitle = false
+	cfg.Content.SummaryIncludeLead = false
+	got := s.createSummaryPrompt(input)
+	if strings.Contains(got, "Title:") || strings.Contains(got, "Lead:") || strings.Contains(got, "title and lead") {
+		t.Errorf("title and lead should be left out when disabled:\n%s", got)
+	}
+	if !strings.Contains(got, "Article text:\nThe vendor shipped a fix on Tuesday.") {
+		t.Errorf("body missing from prompt:\n%s", got)
+	}
+
+	cfg.Content.SummaryIncludeTitle = true
+	cfg.Content.PromptInjectionGuard = true
+	got = s.createSummaryPrompt(input)
+	wrapped := got[strings.Index(got, articleOpenDelimiter+"\n")+1:]
+	if end := strings.Index(wrapped, articleCloseDelimiter); end == -1 || !strings.Contains(wrapped[:end], "Title: Gateway zero-day") {
+		t.Errorf("title must sit inside the untrusted article delimiters:\n%s", got)
+	}
+}
+
+// TestSummarizeArticleInputAnchorsOnTitle checks, against a stub model that
+// leads with the first thing the prompt labels, that a title-rich article
+// whose body buries the news comes back with the news when the title is sent.
+func TestSummarizeArticleInputAnchorsOnTitle(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req SummaryRequest
+		json.NewDecoder(r.Body).Decode(&req)
+
+		summary := ""
+		for _, line := range strings.Split(req.Prompt, "\n") {
+			if title, ok := strings.CutPrefix(line, "Title: "); ok {
+				summary = title + "."
+				break
+			}
+		}
+		if summary == "" {
+			_, body, _ := strings.Cut(req.Prompt, "Article text:\n")
+			summary, _, _ = strings.Cut(body, ".")
+			summary += "."
+		}
+		json.NewEncoder(w).Encode(SummaryResponse{Response: summary, Done: true})
+	}))
+	defer srv.Close()
+
+	cfg := &config.Config{
+		OLLAMA:      config.OLLAMAConfig{URL: srv.URL, Timeout: 5 * time.Second, MaxRetries: 1},
+		Content:     config.ContentConfig{MaxSummaryLength: 200, SummaryIncludeTitle: true},
+		Performance: config.PerformanceConfig{MaxArticleContentLength: 10000},
+	}
+	s := &ArticleSummarizer{httpClient: &http.Client{}, config: cfg, metrics: testMetrics()}
+	input := summaryInput{
+		Title: "Ransomware gang leaks hospital patient records",
+		Body:  "Hospitals have long relied on legacy systems. Last week a ransomware gang leaked patient records.",
+	}
+
+	summary, err := s.SummarizeArticleInput(context.Background(), input, "https://example.com/a", "llama2")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(summary, "Ransomware") {
+		t.Errorf("summary with title = %q, want it to lead with the ransomware news", summary)
+	}
+
+	cfg.Content.SummaryIncludeTitle = false
+	summary, err = s.SummarizeArticleInput(context.Background(), input, "https://example.com/b", "llama2")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(strings.ToLower(summary), "ransomware") {
+		t.Errorf("summary without title = %q; fixture no longer shows the difference", summary)
+	}
+}
+
+func TestFeedSummaryFeedsSkipTheModel(t *testing.T) {
+	db, recorder := openExecRecorder(t)
+	scheduler := &SummarizationScheduler{
+		db:         db,
+		config:     &config.Config{OLLAMA: config.OLLAMAConfig{Model: "llama3"}},
+		metrics:    testMetrics(),
+		queueCap:   10,
+		queueReady: make(chan struct{}, 1),
+	}
+	m := &RSSMonitor{
+		feeds: []Feed{
+			{URL: "https://curated.example/feed", UseFeedSummary: true},
+			{URL: "https://plain.example/feed"},
+		},
+		config:    &config.Config{Content: config.ContentConfig{PreviewLength: 200}},
+		scheduler: scheduler,
+	}
+
+	item := &gofeed.Item{Description: "<p>Editors' <b>summary</b> of the\n story.</p>"}
+	if got := m.feedSummary(item, "https://plain.example/feed"); got != "" {
+		t.Errorf("plain feed got a feed summary %q", got)
+	}
+	curated := Article{URL: "https://curated.example/a", Title: "A", Content: "Full text.", FeedURL: "https://curated.example/feed"}
+	curated.FeedSummary = m.feedSummary(item, curated.FeedURL)
+	m.generateSummaryAsync(curated)
+
+	if depth := scheduler.queue.len(); depth != 0 {
+		t.Fatalf("curated feed's article was queued for the model (queue depth %d)", depth)
+	}
+	updates := recorder.recorded()
+	if len(updates) != 1 || updates[0][0] != "Editors' summary of the story." || updates[0][1] != curated.URL {
+		t.Errorf("saved %v, want the cleaned feed summary for %s", updates, curated.URL)
+	}
+
+	// Other feeds still go through the model
+	m.generateSummaryAsync(Article{URL: "https://plain.example/a", Title: "B", Content: "Full text.", FeedURL: "https://plain.example/feed"})
+	if depth := scheduler.queue.len(); depth != 1 {
+		t.Errorf("queue depth = %d, want the plain feed's article queued", depth)
+	}
+}
+
+// Feed summaries skip the model but not the feed's directives: the post is
+// branded and highlighted, and cut to summary_words, like a model summary.
+func TestFeedSummaryKeepsFeedDirectives(t *testing.T) {
+	s, _, received := newGraceTestScheduler(t, "http://127.0.0.1:0")
+	events := &fakePublisher{}
+	s.events = events
+	s.config.Discord.FeedBranding = []string{"category:security=username:Security Desk"}
+	s.config.Discord.ImportancePriorityWeight = 1
+	s.config.Discord.ImportanceThreshold = 5
+
+	feedURL := "https://important.example/feed"
+	m := &RSSMonitor{
+		feeds:     []Feed{{URL: feedURL, UseFeedSummary: true, Category: "security", Priority: 5, SummaryWords: 4}},
+		config:    s.config,
+		scheduler: s,
+	}
+	m.generateSummaryAsync(Article{
+		URL:         "https://important.example/a",
+		Title:       "Advisory",
+		Content:     "Full text.",
+		FeedURL:     feedURL,
+		FeedSummary: strings.Repeat("word ", 40),
+	})
+
+	summarized := events.ofType(eventArticleSummarized)
+	if len(summarized) != 1 || summarized[0].FeedURL != feedURL {
+		t.Errorf("article_summarized events = %+v, want one carrying %s", summarized, feedURL)
+	}
+	messages := waitForMessages(t, received, 1)
+	if len(messages) != 1 {
+		t.Fatalf("got %d Discord messages, want 1", len(messages))
+	}
+	if messages[0].Username != "Security Desk" {
+		t.Errorf("username = %q, want the category's branding", messages[0].Username)
+	}
+	embed := messages[0].Embeds[0]
+	if embed.Color != importantEmbedColor {
+		t.Errorf("embed color = %#x, want the feed's priority to highlight it", embed.Color)
+	}
+	if got := strings.Fields(embed.Description); len(got) != 4 {
+		t.Errorf("description = %q, want it cut to the feed's 4 words", embed.Description)
+	}
+}
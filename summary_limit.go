@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"information-broker/config"
+	"strings"
+	"unicode/utf8"
+)
+
+// Units a summary length limit can be given in (Content.SummaryLimitUnit).
+const (
+	summaryUnitWords      = "words"
+	summaryUnitCharacters = "characters"
+)
+
+// summaryWordSlack is how many words past a word limit a summary may run
+// before it is cut: models count words loosely, and cutting a summary a few
+// words over reads worse than letting it be.
+const summaryWordSlack = 20
+
+// charsPerWord is the average length of an English word with its space,
+// for comparing word counts with character limits.
+const charsPerWord = 6
+
+// maxDiscordDescriptionChars is Discord's limit on an embed description,
+// which bounds a posted summary whatever the configured limit.
+const maxDiscordDescriptionChars = 4096
+
+// summaryLimit is how long a summary may be: Max words or characters. The
+// zero value sets no limit.
+type summaryLimit struct {
+	Unit string // summaryUnitWords or summaryUnitCharacters
+	Max  int
+}
+
+// contentSummaryLimit returns the limit for a summary: override words, a
+// feed's summary_words directive, if set, or else Content.MaxSummaryLength
+// in Content.SummaryLimitUnit. The directive counts words whatever the
+// unit, as its name says.
+func contentSummaryLimit(content config.ContentConfig, override int) summaryLimit {
+	if override > 0 {
+		return summaryLimit{Unit: summaryUnitWords, Max: override}
+	}
+	limit := summaryLimit{Unit: summaryUnitWords, Max: content.MaxSummaryLength}
+	if content.SummaryLimitUnit == summaryUnitCharacters {
+		limit.Unit = summaryUnitCharacters
+	}
+	return limit
+}
+
+// String phrases the limit for the prompt, e.g. "200 words".
+func (l summaryLimit) String() string {
+	return fmt.Sprintf("%d %s", l.Max, l.Unit)
+}
+
+// streamWords is how many words of a streamed summary are worth reading:
+// past it the summary gets cut by fit anyway. Every word but the last takes
+// at least two characters with its space, so half a character limit in
+// words always covers it.
+func (l summaryLimit) streamWords() int {
+	if l.Max <= 0 {
+		return 0
+	}
+	if l.Unit == summaryUnitCharacters {
+		return l.Max/2 + 1
+	}
+	return l.Max + summaryWordSlack
+}
+
+// minWords caps Content.SummaryMinWords at what the limit leaves room for,
+// so a short limit, such as a feed's small summary_words, doesn't reject
+// every summary that keeps to it.
+func (l summaryLimit) minWords(configured int) int {
+	if l.Max <= 0 {
+		return configured
+	}
+	room := l.Max
+	if l.Unit == summaryUnitCharacters {
+		room = l.Max / charsPerWord
+	}
+	return min(configured, room)
+}
+
+// fit cuts a summary that is over the limit, ending it in "...". Words
+// limits allow summaryWordSlack extra words and cut back to Max; character
+// limits cut at the last word boundary that leaves room for the "...". A
+// summary fit once is left alone by later calls, so the cut the summarizer
+// makes is the only one the reader sees.
+func (l summaryLimit) fit(summary string) string {
+	if l.Max <= 0 {
+		return summary
+	}
+	if l.Unit == summaryUnitCharacters {
+		return truncateAtWord(summary, l.Max)
+	}
+	words := strings.Fields(summary)
+	if len(words) > l.Max+summaryWordSlack {
+		return strings.Join(words[:l.Max], " ") + "..."
+	}
+	return summary
+}
+
+// truncateAtWord cuts s to at most maxChars characters, "..." included,
+// preferring the last word boundary unless that loses more than half.
+func truncateAtWord(s string, maxChars int) string {
+	if utf8.RuneCountInString(s) <= maxChars {
+		return s
+	}
+	if maxChars <= 3 {
+		return string([]rune(s)[:maxChars])
+	}
+	truncated := string([]rune(s)[:maxChars-3])
+	if lastSpace := strings.LastIndex(truncated, " "); lastSpace > len(truncated)/2 {
+		truncated = truncated[:lastSpace]
+	}
+	return strings.TrimRight(truncated, " ,;:") + "..."
+}
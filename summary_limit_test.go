@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"information-broker/config"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSummaryLimitFit(t *testing.T) {
+	words := func(n int) string { return strings.TrimSpace(strings.Repeat("word ", n)) }
+	tests := []struct {
+		name    string
+		limit   summaryLimit
+		summary string
+		want    string
+	}{
+		{"no limit", summaryLimit{}, words(500), words(500)},
+		{"words within the slack", summaryLimit{summaryUnitWords, 50}, words(70), words(70)},
+		{"words past the slack", summaryLimit{summaryUnitWords, 50}, words(71), words(50) + "..."},
+		{"characters within", summaryLimit{summaryUnitCharacters, 20}, "A patch is out now.", "A patch is out now."},
+		{"characters cut at a word", summaryLimit{summaryUnitCharacters, 20}, "A patch is out for the gateway flaw.", "A patch is out..."},
+		{"characters counted as runes", summaryLimit{summaryUnitCharacters, 12}, "Échec à Zürich", "Échec à..."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.limit.fit(tt.summary)
+			if got != tt.want {
+				t.Errorf("fit = %q, want %q", got, tt.want)
+			}
+			if again := tt.limit.fit(got); again != got {
+				t.Errorf("fitting twice cut again: %q", again)
+			}
+		})
+	}
+}
+
+func TestContentSummaryLimit(t *testing.T) {
+	content := config.ContentConfig{MaxSummaryLength: 200}
+	if got := contentSummaryLimit(content, 0); got != (summaryLimit{summaryUnitWords, 200}) {
+		t.Errorf("default limit = %+v", got)
+	}
+	content.SummaryLimitUnit = "characters"
+	if got := contentSummaryLimit(content, 0); got != (summaryLimit{summaryUnitCharacters, 200}) || got.String() != "200 characters" {
+		t.Errorf("default limit in characters = %+v", got)
+	}
+	// summary_words stays in words when the global limit counts characters
+	if got := contentSummaryLimit(content, 80); got != (summaryLimit{summaryUnitWords, 80}) || got.String() != "80 words" {
+		t.Errorf("feed override under a characters limit = %+v", got)
+	}
+	if got := (summaryLimit{summaryUnitCharacters, 500}).streamWords(); got < 250 {
+		t.Errorf("streamWords = %d, too few to fill 500 characters", got)
+	}
+}
+
+// A summary written under the limit reaches Discord whole, where the embed
+// used to cut every summary to 300 characters.
+func TestSummaryLimitSharedWithDiscord(t *testing.T) {
+	long := "Attackers exploited a flaw in the gateway for weeks before the vendor shipped a fix, " +
+		"and customers are urged to update now because proof-of-concept code is circulating widely " +
+		"on forums and researchers expect mass exploitation to follow within days of the disclosure. " +
+		"The vendor says the update also hardens the login page against brute force attempts."
+	var prompt string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req SummaryRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		prompt = req.Prompt
+		json.NewEncoder(w).Encode(SummaryResponse{Response: long, Done: true})
+	}))
+	defer srv.Close()
+
+	cfg := &config.Config{
+		OLLAMA:      config.OLLAMAConfig{URL: srv.URL, MaxRetries: 1},
+		Content:     config.ContentConfig{MaxSummaryLength: 200, SummaryLimitUnit: "characters"},
+		Performance: config.PerformanceConfig{MaxArticleContentLength: 10000},
+	}
+	s := &ArticleSummarizer{httpClient: srv.Client(), config: cfg, metrics: testMetrics()}
+
+	summary, err := s.SummarizeArticle(context.Background(), "article text", "https://example.com/limit", "llama2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(prompt, "in exactly 200 characters or less") {
+		t.Errorf("prompt doesn't ask for 200 characters:\n%s", prompt)
+	}
+	if n := utf8.RuneCountInString(summary); n > 200 || !strings.HasSuffix(summary, "...") {
+		t.Errorf("summary is %d characters: %q", n, summary)
+	}
+
+	d := &DiscordWebhookSender{}
+	for _, limit := range []summaryLimit{contentSummaryLimit(cfg.Content, 0), {summaryUnitWords, 200}} {
+		if limit.Unit == summaryUnitWords {
+			summary = long // Some 60 words, well within 200
+		}
+		embed := d.createDiscordEmbed(ArticleMessage{Title: "Gateway flaw", Summary: summary, SummaryLimit: limit})
+		if embed.Description != summary {
+			t.Errorf("%s: embed description = %q, want the summary whole", limit, embed.Description)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+// Feed content types, set with the content_type feeds file directive. Each
+// frames the summarization prompt for what its articles usually are; feeds
+// without one get the generic framing.
+const (
+	contentTypeNews     = "news"
+	contentTypeAdvisory = "advisory"
+	contentTypeRelease  = "release"
+	contentTypeBlog     = "blog"
+)
+
+// promptTemplate is the part of the summarization prompt that depends on
+// the content type: what the text is called and what the summary focuses on.
+type promptTemplate struct {
+	noun  string
+	focus string
+}
+
+var genericPromptTemplate = promptTemplate{
+	noun:  "article",
+	focus: "Focused on the main points and key takeaways",
+}
+
+var promptTemplates = map[string]promptTemplate{
+	contentTypeNews: {
+		noun:  "news article",
+		focus: "Focused on what happened, who is affected and why it matters",
+	},
+	contentTypeAdvisory: {
+		noun:  "security advisory",
+		focus: "Focused on the affected products and versions, the severity and impact, and the fix or mitigation to apply",
+	},
+	contentTypeRelease: {
+		noun:  "release note",
+		focus: "Focused on the version released and its notable new features, fixes and breaking changes",
+	},
+	contentTypeBlog: {
+		noun:  "blog post",
+		focus: "Focused on the author's main argument and conclusion, attributed to the author rather than stated as fact",
+	},
+}
+
+// isContentType reports whether contentType names a prompt template.
+func isContentType(contentType string) bool {
+	_, ok := promptTemplates[contentType]
+	return ok
+}
+
+// promptTemplateFor returns the template of contentType, or the generic one
+// for "" and unknown types.
+func promptTemplateFor(contentType string) promptTemplate {
+	if template, ok := promptTemplates[contentType]; ok {
+		return template
+	}
+	return genericPromptTemplate
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"information-broker/config"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCreateSummaryPromptContentType(t *testing.T) {
+	tests := []struct {
+		contentType string
+		wantNoun    string
+		wantFocus   string
+	}{
+		{"", "following article", "- Focused on the main points and key takeaways\n"},
+		{"news", "following news article", "- Focused on what happened, who is affected and why it matters\n"},
+		{"advisory", "following security advisory", "- Focused on the affected products and versions, the severity and impact, and the fix or mitigation to apply\n"},
+		{"release", "following release note", "- Focused on the version released and its notable new features, fixes and breaking changes\n"},
+		{"blog", "following blog post", "- Focused on the author's main argument and conclusion, attributed to the author rather than stated as fact\n"},
+		{"podcast", "following article", "- Focused on the main points and key takeaways\n"},
+	}
+
+	cfg := &config.Config{
+		Performance: config.PerformanceConfig{MaxArticleContentLength: 10000},
+		Content:     config.ContentConfig{MaxSummaryLength: 50},
+	}
+	s := &ArticleSummarizer{config: cfg}
+	for _, tt := range tests {
+		t.Run(tt.contentType, func(t *testing.T) {
+			prompt := s.createSummaryPrompt(summaryInput{Body: "Version 2.0 fixes CVE-2024-0001.", ContentType: tt.contentType})
+			if !strings.Contains(prompt, "concise summary of the "+tt.wantNoun+" in") || !strings.Contains(prompt, tt.wantFocus) {
+				t.Errorf("prompt for %q doesn't use its template:\n%s", tt.contentType, prompt)
+			}
+			if strings.Count(prompt, "- Focused on") != 1 {
+				t.Errorf("prompt for %q should have exactly one focus line:\n%s", tt.contentType, prompt)
+			}
+		})
+	}
+
+	// The injection guard keeps the tailored framing
+	cfg.Content.PromptInjectionGuard = true
+	prompt := s.createSummaryPrompt(summaryInput{Body: "Patch now.", ContentType: "advisory"})
+	if !strings.Contains(prompt, "summary of the security advisory between the") {
+		t.Errorf("guarded prompt lost the advisory framing:\n%s", prompt)
+	}
+}
+
+func TestSummaryWordsOverride(t *testing.T) {
+	var prompts []string
+	answer := strings.TrimSpace(strings.Repeat("word ", 100))
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req SummaryRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		prompts = append(prompts, req.Prompt)
+		json.NewEncoder(w).Encode(SummaryResponse{Response: answer, Done: true})
+	}))
+	defer srv.Close()
+
+	s := newFallbackTestSummarizer(srv.URL, nil, nil)
+	s.metrics = testMetrics()
+	s.config.OLLAMA.MaxRetries = 1
+	s.config.Performance.MaxArticleContentLength = 10000
+
+	tests := []struct {
+		name       string
+		words      int
+		wantPrompt string
+		wantWords  int
+	}{
+		{"global length", 0, "in exactly 200 words or less", 100}, // Within 200: kept whole
+		{"feed override", 50, "in exactly 50 words or less", 50},  // Trimmed to 50, the last ending in "..."
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prompts = nil
+			summary, err := s.SummarizeArticleInput(context.Background(), summaryInput{Body: "The article body.", SummaryWords: tt.words}, "https://example.com/a", "llama3")
+			if err != nil {
+				t.Fatalf("SummarizeArticleInput: %v", err)
+			}
+			if len(prompts) != 1 || !strings.Contains(prompts[0], tt.wantPrompt) {
+				t.Errorf("prompt doesn't ask for %q:\n%s", tt.wantPrompt, prompts)
+			}
+			if got := len(strings.Fields(summary)); got != tt.wantWords {
+				t.Errorf("summary has %d words, want %d", got, tt.wantWords)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// The first three match the chatty openings cleanSummaryContent strips;
+// leftoverPreamble catches what still reads like one afterwards, which
+// summaryQualityProblem rejects: an introduction ending in a colon, too long
+// for summaryPreambleRegex, or a refusal. Plain sentences that merely start
+// with "The following" or "Certainly" are left alone.
+var (
+	summaryAckRegex      = regexp.MustCompile(`(?i)^(?:sure|certainly|of course|okay|ok)\b[,.!]?\s*`)
+	summaryPreambleRegex = regexp.MustCompile(`(?i)^(?:here(?:'s|’s| is| are)|below is|the following is)\b[^:.!?]{0,80}:\s*`)
+	summaryLabelRegex    = regexp.MustCompile(`(?i)^(?:\*\*|#+\s*)?(?:brief |short )?summary(?:\*\*)?\s*:\s*(?:\*\*\s*)?`)
+	leftoverPreamble     = regexp.MustCompile(`(?i)^(?:(?:here(?:'s|’s| is| are)|below is|the following|sure\b|certainly\b)[^:.!?\n]*:|as an ai\b|i (?:can|cannot|can't|am unable)\b)`)
+)
+
+// stripSummaryPreamble removes a leading acknowledgement ("Sure!"),
+// introduction ("Here is a brief summary of the article:") or label
+// ("Summary:") from a model response.
+func stripSummaryPreamble(summary string) string {
+	summary = summaryAckRegex.ReplaceAllString(summary, "")
+	summary = summaryPreambleRegex.ReplaceAllString(summary, "")
+	return summaryLabelRegex.ReplaceAllString(summary, "")
+}
+
+// titleOverlap returns the share of summary's distinct words that also
+// appear in title, 0 when either has none.
+func titleOverlap(summary, title string) float64 {
+	titleWords := make(map[string]bool)
+	for _, word := range strings.Fields(normalizeTitle(title)) {
+		titleWords[word] = true
+	}
+	summaryWords := make(map[string]bool)
+	for _, word := range strings.Fields(normalizeTitle(summary)) {
+		summaryWords[word] = true
+	}
+	if len(titleWords) == 0 || len(summaryWords) == 0 {
+		return 0
+	}
+	shared := 0
+	for word := range summaryWords {
+		if titleWords[word] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(summaryWords))
+}
+
+// summaryQualityProblem checks a cleaned summary against the
+// Content.SummaryMinWords and Content.SummaryMaxTitleSimilarity thresholds
+// (0 skips a check) and for a leftover preamble. It returns what is wrong,
+// or "" for a summary worth keeping.
+func summaryQualityProblem(summary, title string, minWords int, maxTitleSimilarity float64) string {
+	if summary == filteredSummaryFallback {
+		return "nothing left after removing reasoning tags"
+	}
+	if leftoverPreamble.MatchString(summary) {
+		return "starts with a preamble"
+	}
+	if words := len(strings.Fields(summary)); minWords > 0 && words < minWords {
+		return fmt.Sprintf("%d words, want at least %d", words, minWords)
+	}
+	if maxTitleSimilarity > 0 {
+		if overlap := titleOverlap(summary, title); overlap > maxTitleSimilarity {
+			return fmt.Sprintf("restates the title (%.0f%% of its words)", overlap*100)
+		}
+	}
+	return ""
+}
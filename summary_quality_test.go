@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"information-broker/config"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCleanSummaryContentStripsPreamble(t *testing.T) {
+	const want = "The vendor patched the flaw."
+	for _, response := range []string{
+		"Here is a brief summary of the article:\n\n" + want,
+		"Sure! Here's the summary: " + want,
+		"**Summary:** " + want,
+		"<think>plan</think>Certainly. Summary: " + want,
+		want,
+	} {
+		if got := cleanSummaryContent(response); got != want {
+			t.Errorf("cleanSummaryContent(%q) = %q, want %q", response, got, want)
+		}
+	}
+	if got := cleanSummaryContent("Here are the facts. " + want); !strings.HasPrefix(got, "Here are the facts.") {
+		t.Errorf("a sentence without a colon was stripped: %q", got)
+	}
+}
+
+func TestSummaryQualityProblem(t *testing.T) {
+	const title = "Vendor patches critical gateway flaw"
+	tests := []struct {
+		summary string
+		bad     bool
+	}{
+		{"Attackers exploited a gateway flaw for weeks before the vendor shipped a fix to customers.", false},
+		{"Vendor patches critical gateway flaw.", true},
+		{"Vendor patches the critical gateway flaw.", true},
+		{"Too short.", true},
+		{"Here is a rundown of what the article says about the gateway flaw, the vendor's patch and who is affected by it: the flaw was exploited.", true},
+		{"I cannot summarize this article.", true},
+		{"Here is what the article says about the gateway flaw and the patch that fixes it.", false},
+		{"The following products are affected by the gateway flaw, which the vendor patched on Tuesday.", false},
+		{"Certainly the worst gateway flaw this year, the bug let attackers skip the login page entirely.", false},
+		{filteredSummaryFallback, true},
+	}
+	for _, tt := range tests {
+		if problem := summaryQualityProblem(tt.summary, title, 5, 0.8); (problem != "") != tt.bad {
+			t.Errorf("summaryQualityProblem(%q) = %q, want bad = %v", tt.summary, problem, tt.bad)
+		}
+	}
+	if problem := summaryQualityProblem("Vendor patches critical gateway flaw.", title, 0, 0); problem != "" {
+		t.Errorf("disabled thresholds still rejected the summary: %s", problem)
+	}
+
+	// A short per-feed limit lowers the minimum instead of failing every summary
+	for _, tt := range []struct {
+		limit summaryLimit
+		want  int
+	}{
+		{summaryLimit{Unit: summaryUnitWords, Max: 200}, 8},
+		{summaryLimit{Unit: summaryUnitWords, Max: 5}, 5},
+		{summaryLimit{Unit: summaryUnitCharacters, Max: 30}, 5},
+		{summaryLimit{}, 8},
+	} {
+		if got := tt.limit.minWords(8); got != tt.want {
+			t.Errorf("%+v.minWords(8) = %d, want %d", tt.limit, got, tt.want)
+		}
+	}
+}
+
+func TestSummarizeArticleRetriesLowQualitySummary(t *testing.T) {
+	const (
+		title   = "Vendor patches critical gateway flaw"
+		good    = "Attackers exploited a gateway flaw for weeks before the vendor shipped a fix to customers."
+		article = "Attackers exploited the flaw for weeks. The vendor has now shipped a patch."
+	)
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		response := "Here is the summary: " + title + "."
+		if atomic.AddInt32(&hits, 1) > 1 {
+			response = good
+		}
+		json.NewEncoder(w).Encode(SummaryResponse{Response: response, Done: true})
+	}))
+	defer srv.Close()
+
+	cfg := &config.Config{
+		OLLAMA:      config.OLLAMAConfig{URL: srv.URL, Timeout: 5 * time.Second, MaxRetries: 2},
+		Content:     config.ContentConfig{MaxSummaryLength: 200, SummaryMinWords: 8, SummaryMaxTitleSimilarity: 0.8},
+		Performance: config.PerformanceConfig{MaxArticleContentLength: 10000},
+	}
+	metrics := testMetrics()
+	s := &ArticleSummarizer{httpClient: &http.Client{}, config: cfg, metrics: metrics}
+
+	before := counterValue(t, metrics.summaryAPIErrors.WithLabelValues("llama2", "low_quality"))
+	summary, err := s.SummarizeArticleInput(context.Background(), summaryInput{Title: title, Body: article}, "https://example.com/q", "llama2")
+	if err != nil || summary != good {
+		t.Fatalf("got (%q, %v), want the second attempt's summary", summary, err)
+	}
+	if hits != 2 {
+		t.Errorf("backend called %d times, want 2", hits)
+	}
+	if got := counterValue(t, metrics.summaryAPIErrors.WithLabelValues("llama2", "low_quality")) - before; got != 1 {
+		t.Errorf("low_quality errors grew by %v, want 1", got)
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// summaryReplayBatchSize bounds the failed articles one replay pass picks
+// up, so a recovered backend works through a backlog at a steady rate
+// behind fresh articles rather than all at once.
+const summaryReplayBatchSize = 20
+
+// deadLetterArticle is an article whose summarization failed, as loaded by
+// a replay pass.
+type deadLetterArticle struct {
+	request  SummarizationRequest
+	attempts int // Replays already queued
+}
+
+// summaryReplayer periodically re-queues the articles whose summarization
+// failed, so they recover on their own once the backend is back.
+func (s *SummarizationScheduler) summaryReplayer(ctx context.Context) {
+	ticker := time.NewTicker(s.config.Summarization.DeadLetterReplayInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.shutdown:
+			return
+		case <-ticker.C:
+			s.replayDeadLetters()
+		}
+	}
+}
+
+// replayDeadLetters runs one replay pass over the articles that have
+// content but only the placeholder summary, or none, newest first. Articles
+// in their summary grace period are left to the grace retrier, and
+// abandoned ones are left alone.
+func (s *SummarizationScheduler) replayDeadLetters() {
+	rows, err := s.db.Query(`
+		SELECT url, title, COALESCE(feed_url, ''), full_content, summary_replay_attempts
+		FROM articles
+		WHERE (summary IS NULL OR summary = 'summary unavailable')
+		  AND COALESCE(full_content, '') <> ''
+		  AND summary_grace_until IS NULL
+		  AND NOT summary_abandoned
+		ORDER BY publish_date DESC
+		LIMIT $1`, summaryReplayBatchSize)
+	if err != nil {
+		slog.Error("Failed to load failed summarizations for replay", "error", err)
+		return
+	}
+
+	var articles []deadLetterArticle
+	for rows.Next() {
+		article := deadLetterArticle{request: SummarizationRequest{Priority: summaryRetryPriority}}
+		if err := rows.Scan(&article.request.ArticleURL, &article.request.ArticleTitle, &article.request.FeedURL,
+			&article.request.Content, &article.attempts); err != nil {
+			slog.Error("Failed to scan failed summarization", "error", err)
+			continue
+		}
+		article.request = s.withFeedDirectives(article.request)
+		articles = append(articles, article)
+	}
+	rows.Close()
+
+	s.handleDeadLetters(articles)
+}
+
+// handleDeadLetters re-queues each article at low priority and counts the
+// replay, unless it is already queued. An article that has used up
+// DeadLetterMaxAttempts replays is flagged as abandoned instead.
+func (s *SummarizationScheduler) handleDeadLetters(articles []deadLetterArticle) {
+	maxAttempts := s.config.Summarization.DeadLetterMaxAttempts
+	for _, article := range articles {
+		request := article.request
+
+		if article.attempts >= maxAttempts {
+			if _, err := s.db.Exec(`UPDATE articles SET summary_abandoned = TRUE WHERE url = $1`, request.ArticleURL); err != nil {
+				slog.Error("Failed to abandon summarization", "article_url", request.ArticleURL, "error", err)
+				continue
+			}
+			slog.Warn("Abandoning summarization after repeated replays", "title", request.ArticleTitle, "article_url", request.ArticleURL, "replays", article.attempts)
+			continue
+		}
+		if s.isQueued(request.ArticleURL) {
+			continue
+		}
+
+		if err := s.EnqueueSummarization(request); err != nil {
+			slog.Error("Failed to replay summarization", "article_url", request.ArticleURL, "error", err)
+			return // Full or shutting down; the next pass tries again
+		}
+		if _, err := s.db.Exec(`UPDATE articles SET summary_replay_attempts = summary_replay_attempts + 1 WHERE url = $1`, request.ArticleURL); err != nil {
+			slog.Error("Failed to count summarization replay", "article_url", request.ArticleURL, "error", err)
+		}
+		slog.Info("Replaying failed summarization", "title", request.ArticleTitle, "article_url", request.ArticleURL, "attempt", article.attempts+1, "max_attempts", maxAttempts)
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"database/sql/driver"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestReplayDeadLettersRecovers(t *testing.T) {
+	var hits int32
+	ollama := newOllamaStub(t, http.StatusOK, "The backend is back.", &hits)
+	s, recorder, received := newGraceTestScheduler(t, ollama.URL)
+	s.config.Summarization.DeadLetterMaxAttempts = 3
+	s.feeds = func(feedURL string) Feed {
+		return Feed{URL: feedURL, ContentType: "advisory", SummaryWords: 50, Category: "security"}
+	}
+	recorder.answer("full_content, summary_replay_attempts",
+		[]driver.Value{"https://example.com/a", "Stuck article", "https://example.com/feed", "The article body.", int64(1)})
+
+	s.replayDeadLetters()
+	request, ok := s.dequeue()
+	if !ok || request.ArticleURL != "https://example.com/a" || request.Priority != summaryRetryPriority {
+		t.Fatalf("dequeued (%+v, %v), want a low-priority replay of the stuck article", request, ok)
+	}
+	if request.ContentType != "advisory" || request.SummaryWords != 50 || request.Category != "security" {
+		t.Errorf("replay = %+v, want the feed's directives", request)
+	}
+	if counted := recorder.recordedWrites("UPDATE articles SET summary_replay_attempts"); len(counted) != 1 || counted[0][0] != "https://example.com/a" {
+		t.Errorf("replay counted as %v, want one increment for the stuck article", counted)
+	}
+
+	s.handleRequest(context.Background(), request, SummarizationSchedulerConfig{MaxRetries: 1, WorkerTimeout: 5 * time.Second})
+	var saved bool
+	for _, args := range recorder.recordedWrites("UPDATE articles SET summary =") {
+		saved = saved || (args[0] == "The backend is back." && args[1] == "https://example.com/a")
+	}
+	if !saved {
+		t.Errorf("replayed summary not saved: %v", recorder.recordedWrites("UPDATE articles SET summary ="))
+	}
+	if got := waitForMessages(t, received, 1); len(got) != 1 {
+		t.Errorf("Discord received %d messages, want the recovered article posted once", len(got))
+	}
+}
+
+func TestHandleDeadLettersAbandonsAfterCap(t *testing.T) {
+	var hits int32
+	ollama := newOllamaStub(t, http.StatusServiceUnavailable, "", &hits)
+	s, recorder, _ := newGraceTestScheduler(t, ollama.URL)
+	s.config.Summarization.DeadLetterMaxAttempts = 2
+	article := deadLetterArticle{request: SummarizationRequest{
+		ArticleURL:   "https://example.com/broken",
+		ArticleTitle: "Broken article",
+		Content:      "The article body.",
+		Priority:     summaryRetryPriority,
+	}}
+
+	// Each pass replays the article, which fails again
+	for article.attempts = 0; article.attempts < 2; article.attempts++ {
+		s.handleDeadLetters([]deadLetterArticle{article})
+		request, ok := s.dequeue()
+		if !ok {
+			t.Fatalf("replay %d wasn't queued", article.attempts+1)
+		}
+		s.handleRequest(context.Background(), request, SummarizationSchedulerConfig{MaxRetries: 1, WorkerTimeout: 5 * time.Second})
+	}
+	if abandoned := recorder.recordedWrites("UPDATE articles SET summary_abandoned"); len(abandoned) != 0 {
+		t.Fatalf("abandoned before the cap: %v", abandoned)
+	}
+
+	s.handleDeadLetters([]deadLetterArticle{article})
+	if request, ok := s.dequeue(); ok {
+		t.Errorf("queued %+v past the cap", request)
+	}
+	if abandoned := recorder.recordedWrites("UPDATE articles SET summary_abandoned"); len(abandoned) != 1 || abandoned[0][0] != "https://example.com/broken" {
+		t.Errorf("abandoned %v, want the broken article", abandoned)
+	}
+	if n := len(recorder.recordedWrites("UPDATE articles SET summary_replay_attempts")); n != 2 {
+		t.Errorf("counted %d replays, want 2", n)
+	}
+}
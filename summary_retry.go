@@ -0,0 +1,150 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+)
+
+// summaryRetryPriority queues bulk retries behind freshly fetched articles,
+// which get their feed's priority (0 unless the feed sets one).
+const summaryRetryPriority = -1
+
+// summaryRetryLimit bounds how many failed articles one all_failed retry
+// picks up, newest first; repeat the call to work through more.
+const summaryRetryLimit = 500
+
+// SummaryRetryResponse is the body returned by /summarization/retry.
+type SummaryRetryResponse struct {
+	Matched  int `json:"matched"`            // Articles selected for a retry
+	Enqueued int `json:"enqueued"`           // Requests added to the queue
+	Skipped  int `json:"skipped"`            // Already queued or in progress
+	Rejected int `json:"rejected,omitempty"` // Left out because the queue filled up
+}
+
+// retrySummaries re-queues the summarization of one article, given by ?id=
+// or ?url=, or with ?all_failed=true of every article whose summary failed
+// or never came, and reports how many were queued.
+func (s *APIServer) retrySummaries(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if !s.requireAdmin(w, r) {
+		return
+	}
+
+	query := r.URL.Query()
+	var requests []SummarizationRequest
+	var err error
+	switch {
+	case query.Get("all_failed") != "":
+		allFailed, parseErr := strconv.ParseBool(query.Get("all_failed"))
+		if parseErr != nil || !allFailed {
+			http.Error(w, "all_failed must be true", http.StatusBadRequest)
+			return
+		}
+		requests, err = s.failedSummaryRequests()
+	case query.Get("id") != "":
+		id, parseErr := parseArticleID(query.Get("id"))
+		if parseErr != nil {
+			http.Error(w, "Invalid id", http.StatusBadRequest)
+			return
+		}
+		requests, err = s.summaryRetryRequest(`id = $1`, id)
+	case query.Get("url") != "":
+		requests, err = s.summaryRetryRequest(`url = $1`, query.Get("url"))
+	default:
+		http.Error(w, "One of id, url or all_failed=true is required", http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		slog.Error("Failed to load articles for summary retry", "error", err)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	response, err := s.scheduler.enqueueRetries(requests)
+	if errors.Is(err, ErrSchedulerDraining) {
+		http.Error(w, "Shutting down", http.StatusServiceUnavailable)
+		return
+	}
+	slog.Info("Summary retry requested", "matched", response.Matched, "enqueued", response.Enqueued,
+		"already_queued", response.Skipped, "rejected", response.Rejected)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(response)
+}
+
+// summaryRetryRequest builds the retry of the single article matching where.
+// It is queued with its feed's priority, like a freshly fetched article.
+func (s *APIServer) summaryRetryRequest(where string, arg any) ([]SummarizationRequest, error) {
+	var request SummarizationRequest
+	err := s.db.QueryRow(`
+		SELECT url, title, COALESCE(feed_url, ''), COALESCE(full_content, '')
+		FROM articles WHERE `+where, arg).Scan(
+		&request.ArticleURL, &request.ArticleTitle, &request.FeedURL, &request.Content)
+	if err != nil {
+		return nil, err
+	}
+	if s.monitor != nil {
+		request.Priority = s.monitor.feedPriority(request.FeedURL)
+	}
+	return []SummarizationRequest{s.scheduler.withFeedDirectives(request)}, nil
+}
+
+// failedSummaryRequests builds low-priority retries for the articles that
+// have content but only the placeholder summary, or none at all.
+func (s *APIServer) failedSummaryRequests() ([]SummarizationRequest, error) {
+	rows, err := s.db.Query(`
+		SELECT url, title, COALESCE(feed_url, ''), full_content
+		FROM articles
+		WHERE (summary IS NULL OR summary = 'summary unavailable')
+		  AND COALESCE(full_content, '') <> ''
+		ORDER BY publish_date DESC
+		LIMIT $1`, summaryRetryLimit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var requests []SummarizationRequest
+	for rows.Next() {
+		request := SummarizationRequest{Priority: summaryRetryPriority}
+		if err := rows.Scan(&request.ArticleURL, &request.ArticleTitle, &request.FeedURL, &request.Content); err != nil {
+			return nil, err
+		}
+		requests = append(requests, s.scheduler.withFeedDirectives(request))
+	}
+	return requests, rows.Err()
+}
+
+// enqueueRetries queues requests for articles not already queued, stopping
+// once the queue is full. It fails only if the scheduler is shutting down.
+func (s *SummarizationScheduler) enqueueRetries(requests []SummarizationRequest) (SummaryRetryResponse, error) {
+	response := SummaryRetryResponse{Matched: len(requests)}
+	for i, request := range requests {
+		if s.isQueued(request.ArticleURL) {
+			response.Skipped++
+			continue
+		}
+		err := s.EnqueueSummarization(request)
+		if errors.Is(err, ErrSchedulerDraining) {
+			return response, err
+		}
+		if err != nil {
+			response.Rejected = len(requests) - i
+			break
+		}
+		response.Enqueued++
+	}
+	return response, nil
+}
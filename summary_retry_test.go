@@ -0,0 +1,61 @@
+package main
+
+import (
+	"errors"
+	"information-broker/config"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestEnqueueRetries(t *testing.T) {
+	s := &SummarizationScheduler{
+		config:     &config.Config{OLLAMA: config.OLLAMAConfig{Model: "llama3"}},
+		metrics:    testMetrics(),
+		queueCap:   2,
+		queueReady: make(chan struct{}, 1),
+	}
+	s.queue.push(SummarizationRequest{ArticleURL: "https://example.com/queued"})
+
+	response, err := s.enqueueRetries([]SummarizationRequest{
+		{ArticleURL: "https://example.com/queued", Priority: summaryRetryPriority},
+		{ArticleURL: "https://example.com/a", Priority: summaryRetryPriority},
+		{ArticleURL: "https://example.com/b", Priority: summaryRetryPriority},
+		{ArticleURL: "https://example.com/c", Priority: summaryRetryPriority},
+	})
+	if err != nil {
+		t.Fatalf("enqueueRetries: %v", err)
+	}
+	want := SummaryRetryResponse{Matched: 4, Enqueued: 1, Skipped: 1, Rejected: 2}
+	if response != want {
+		t.Errorf("response = %+v, want %+v", response, want)
+	}
+
+	s.draining = true
+	if _, err := s.enqueueRetries([]SummarizationRequest{{ArticleURL: "https://example.com/d"}}); !errors.Is(err, ErrSchedulerDraining) {
+		t.Errorf("enqueueRetries while draining returned %v, want ErrSchedulerDraining", err)
+	}
+}
+
+func TestRetrySummariesValidatesRequest(t *testing.T) {
+	tests := []struct {
+		method string
+		target string
+		want   int
+	}{
+		{http.MethodGet, "/summarization/retry?id=1", http.StatusMethodNotAllowed},
+		{http.MethodPost, "/summarization/retry", http.StatusBadRequest},
+		{http.MethodPost, "/summarization/retry?id=abc", http.StatusBadRequest},
+		{http.MethodPost, "/summarization/retry?all_failed=false", http.StatusBadRequest},
+	}
+	s := &APIServer{config: &config.Config{Security: config.SecurityConfig{AdminToken: "s3cret"}}}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.target, nil)
+		req.Header.Set("Authorization", "Bearer s3cret")
+		rec := httptest.NewRecorder()
+		s.retrySummaries(rec, req)
+		if rec.Code != tt.want {
+			t.Errorf("%s %s = %d, want %d", tt.method, tt.target, rec.Code, tt.want)
+		}
+	}
+}
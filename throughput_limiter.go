@@ -0,0 +1,49 @@
+package main
+
+import (
+	"sync"
+	"time"
+)
+
+// throughputLimiter caps how many new articles the pipeline takes in per
+// clock hour across all feeds. Articles over the cap are left unseen, so
+// the next fetch of their feed offers them again, in a later hour if the
+// cap still holds. A nil limiter admits everything.
+type throughputLimiter struct {
+	mu      sync.Mutex
+	limit   int
+	hour    time.Time // Start of the hour count belongs to
+	count   int
+	now     func() time.Time
+	limited bool // The cap was reached this hour, and logged
+}
+
+// newThroughputLimiter returns a limiter admitting perHour articles an hour,
+// or nil when perHour is not positive.
+func newThroughputLimiter(perHour int) *throughputLimiter {
+	if perHour <= 0 {
+		return nil
+	}
+	return &throughputLimiter{limit: perHour, now: time.Now}
+}
+
+// allow takes one article from this hour's allowance and reports whether
+// there was any left. reached is true for the first refusal of the hour.
+func (l *throughputLimiter) allow() (ok, reached bool) {
+	if l == nil {
+		return true, false
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if hour := l.now().Truncate(time.Hour); !hour.Equal(l.hour) {
+		l.hour, l.count, l.limited = hour, 0, false
+	}
+	if l.count >= l.limit {
+		reached = !l.limited
+		l.limited = true
+		return false, reached
+	}
+	l.count++
+	return true, false
+}
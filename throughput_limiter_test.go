@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"information-broker/config"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestThroughputLimiter(t *testing.T) {
+	if ok, _ := (*throughputLimiter)(nil).allow(); !ok {
+		t.Error("a nil limiter should admit everything")
+	}
+
+	now := time.Date(2024, 5, 1, 10, 15, 0, 0, time.UTC)
+	l := newThroughputLimiter(2)
+	l.now = func() time.Time { return now }
+
+	var got []string
+	for i := 0; i < 4; i++ {
+		ok, reached := l.allow()
+		got = append(got, fmt.Sprintf("%v/%v", ok, reached))
+	}
+	now = now.Add(50 * time.Minute) // Into the next clock hour
+	ok, reached := l.allow()
+	got = append(got, fmt.Sprintf("%v/%v", ok, reached))
+
+	if want := "true/false true/false false/true false/false true/false"; strings.Join(got, " ") != want {
+		t.Errorf("allow() = %s, want %s", strings.Join(got, " "), want)
+	}
+}
+
+func TestProcessArticleThroughputCap(t *testing.T) {
+	mux := http.NewServeMux()
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+	mux.HandleFunc("/feed", func(w http.ResponseWriter, r *http.Request) {
+		// The feed never changes, so a conditional fetch gets a 304
+		w.Header().Set("ETag", `"v1"`)
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		io.WriteString(w, `<?xml version="1.0"?><rss version="2.0"><channel><title>Test</title>`)
+		for i := 1; i <= 3; i++ {
+			fmt.Fprintf(w, `<item><title>Article %d</title><link>%s/article/%d</link><pubDate>%s</pubDate></item>`,
+				i, srv.URL, i, time.Now().UTC().Format(time.RFC1123Z))
+		}
+		io.WriteString(w, `</channel></rss>`)
+	})
+	mux.HandleFunc("/article/", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "<html><body><article>"+strings.Repeat("Article body. ", 20)+"</article></body></html>")
+	})
+	feedURL := srv.URL + "/feed"
+
+	db, recorder := openExecRecorder(t)
+	cfg := &config.Config{
+		App:         config.AppConfig{MaxArticlesPerHour: 2},
+		API:         config.APIConfig{Timeout: 5 * time.Second},
+		Performance: config.PerformanceConfig{MaxConcurrentFeeds: 1, MaxArticleContentLength: 10000},
+	}
+	metrics := testMetrics()
+	scheduler := &SummarizationScheduler{config: cfg, metrics: metrics, queueCap: 10, queueReady: make(chan struct{}, 1)}
+	m := NewRSSMonitor(db, []Feed{{URL: feedURL}}, metrics, cfg, NewCircuitBreakerManager(), scheduler, nil)
+	now := time.Now()
+	m.throughput.now = func() time.Time { return now }
+
+	inserted := func() int { return len(recorder.recordedWrites("INSERT INTO articles")) }
+	deferred := func() float64 { return counterValue(t, metrics.articlesDeferredThroughput.WithLabelValues(feedURL)) }
+
+	m.fetchFeed(context.Background(), feedURL)
+	if inserted() != 2 || deferred() != 1 {
+		t.Fatalf("first fetch inserted %d and deferred %v articles, want 2 and 1", inserted(), deferred())
+	}
+
+	// The cap holds for the rest of the hour
+	m.fetchFeed(context.Background(), feedURL)
+	if inserted() != 2 || deferred() != 2 {
+		t.Fatalf("fetch in the same hour inserted %d and deferred %v articles in all, want 2 and 2", inserted(), deferred())
+	}
+
+	// The next hour, the deferred article is taken in, and with nothing
+	// left deferred the feed's ETag is kept
+	now = now.Add(time.Hour)
+	m.fetchFeed(context.Background(), feedURL)
+	if inserted() != 3 || deferred() != 2 {
+		t.Fatalf("fetch in the next hour inserted %d articles in all (deferred %v), want 3", inserted(), deferred())
+	}
+	m.fetchFeed(context.Background(), feedURL)
+	if logs := recorder.recordedWrites("INSERT INTO fetch_logs"); logs[len(logs)-1][1] != "not_modified" {
+		t.Errorf("last fetch logged %v, want a 304 once nothing is deferred", logs[len(logs)-1][1])
+	}
+	if args := recorder.recordedWrites("INSERT INTO articles")[2]; args[1] != srv.URL+"/article/3" {
+		t.Errorf("next hour inserted %v, want the deferred article 3", args[1])
+	}
+}
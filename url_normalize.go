@@ -0,0 +1,61 @@
+package main
+
+import (
+	"net/url"
+	"strings"
+)
+
+// normalizeURL returns the canonical form of an article link used for
+// deduplication and storage: the scheme and host are lowercased, the
+// fragment is dropped, query parameters named in trackingParams are removed
+// and trailing slashes on the path are collapsed. A trackingParams entry
+// ending in "*" matches any parameter with that prefix (e.g. "utm_*").
+// Parameter names match case-insensitively. Links that aren't absolute URLs
+// are returned trimmed but otherwise unchanged.
+func normalizeURL(rawURL string, trackingParams []string) string {
+	rawURL = strings.TrimSpace(rawURL)
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return rawURL
+	}
+
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	u.Fragment = ""
+	u.RawFragment = ""
+
+	if u.RawQuery != "" {
+		query := u.Query()
+		for name := range query {
+			if isTrackingParam(name, trackingParams) {
+				query.Del(name)
+			}
+		}
+		u.RawQuery = query.Encode()
+	}
+	u.ForceQuery = false
+
+	u.Path = strings.TrimRight(u.Path, "/")
+	if u.RawPath != "" {
+		u.RawPath = strings.TrimRight(u.RawPath, "/")
+	}
+
+	return u.String()
+}
+
+// isTrackingParam reports whether a query parameter name matches one of the
+// tracking parameter patterns.
+func isTrackingParam(name string, trackingParams []string) bool {
+	name = strings.ToLower(name)
+	for _, pattern := range trackingParams {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(name, prefix) {
+				return true
+			}
+		} else if name == pattern {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,42 @@
+package main
+
+import "testing"
+
+func TestNormalizeURL(t *testing.T) {
+	params := []string{"utm_*", "fbclid", "gclid"}
+
+	tests := []struct {
+		name, in, want string
+	}{
+		{"utm parameters", "https://example.com/post?utm_source=rss&utm_medium=feed", "https://example.com/post"},
+		{"keeps other parameters", "https://example.com/post?id=42&fbclid=abc&page=2", "https://example.com/post?id=42&page=2"},
+		{"case-insensitive parameter names", "https://example.com/post?UTM_Campaign=x&GCLID=y", "https://example.com/post"},
+		{"lowercases scheme and host only", "HTTPS://Example.COM/Post/Title", "https://example.com/Post/Title"},
+		{"strips fragment", "https://example.com/post#comments", "https://example.com/post"},
+		{"collapses trailing slashes", "https://example.com/2024/05/post//", "https://example.com/2024/05/post"},
+		{"root path", "https://example.com/", "https://example.com"},
+		{"empty query", "https://example.com/post?", "https://example.com/post"},
+		{"all combined", " https://WWW.Example.com/a/?utm_source=x&ref=home#top ", "https://www.example.com/a?ref=home"},
+		{"not absolute", "/relative/path?utm_source=x", "/relative/path?utm_source=x"},
+		{"unparseable", "http://[::1", "http://[::1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizeURL(tt.in, params); got != tt.want {
+				t.Errorf("normalizeURL(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+
+	// Variants of one article normalize to the same URL
+	a := normalizeURL("https://example.com/story?utm_source=twitter", params)
+	b := normalizeURL("https://example.com/story/?fbclid=123#top", params)
+	if a != b {
+		t.Errorf("variants normalized differently: %q vs %q", a, b)
+	}
+
+	// Only the configured parameters are stripped
+	if got := normalizeURL("https://example.com/post?utm_source=x&ref=rss", []string{"ref"}); got != "https://example.com/post?utm_source=x" {
+		t.Errorf("custom parameter list: got %q", got)
+	}
+}
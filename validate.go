@@ -0,0 +1,121 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"information-broker/config"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// validateFeedTimeout bounds each feed reachability request.
+const validateFeedTimeout = 15 * time.Second
+
+// validateRequested reports whether the command line asks for validate
+// mode: `information-broker --validate [--check-feeds]`.
+func validateRequested(args []string) (validate, checkFeeds bool) {
+	for _, arg := range args {
+		switch arg {
+		case "--validate", "-validate":
+			validate = true
+		case "--check-feeds", "-check-feeds":
+			checkFeeds = true
+		}
+	}
+	return validate, checkFeeds
+}
+
+// runValidation checks that the service could start as configured, without
+// starting it: the configuration, the database and its schema (opened with
+// openDB), the feeds file, the extraction rules and, with checkFeeds, that
+// every feed answers a HEAD request. It writes a report to w and returns an
+// error naming the failed checks.
+func runValidation(ctx context.Context, cfg *config.Config, checkFeeds bool, openDB func(*config.Config) (*sql.DB, error), w io.Writer) error {
+	var feeds []Feed
+	checks := []selfTestCheck{
+		{name: "config", run: func(context.Context) error {
+			return cfg.Validate()
+		}},
+		{name: "database", run: func(context.Context) error {
+			db, err := openDB(cfg)
+			if err != nil {
+				return err
+			}
+			return db.Close()
+		}},
+		{name: "feeds file", run: func(context.Context) error {
+			var err error
+			feeds, err = loadFeeds(cfg.App.RSSFeedsFile)
+			if err == nil && len(feeds) == 0 {
+				err = fmt.Errorf("%s lists no feeds", cfg.App.RSSFeedsFile)
+			}
+			return err
+		}},
+		{name: "extraction rules", run: func(context.Context) error {
+			_, err := LoadContentExtractor(cfg.Content.ExtractionRulesFile)
+			return err
+		}},
+	}
+	results := runSelfTest(ctx, checks)
+
+	if checkFeeds {
+		client := &http.Client{Timeout: validateFeedTimeout}
+		var feedChecks []selfTestCheck
+		for _, feed := range feeds {
+			feedURL := feed.URL
+			feedChecks = append(feedChecks, selfTestCheck{name: "feed " + feedURL, run: func(ctx context.Context) error {
+				return checkFeedReachable(ctx, client, feedURL, cfg.API.UserAgent)
+			}})
+		}
+		results = append(results, runSelfTest(ctx, feedChecks)...)
+	}
+
+	var failed []string
+	for _, result := range results {
+		if result.Passed() {
+			fmt.Fprintf(w, "PASS  %s (%v)\n", result.Name, result.Duration.Round(time.Millisecond))
+		} else {
+			fmt.Fprintf(w, "FAIL  %s (%v): %v\n", result.Name, result.Duration.Round(time.Millisecond), result.Err)
+			failed = append(failed, result.Name)
+		}
+	}
+	if len(failed) > 0 {
+		fmt.Fprintf(w, "%d of %d checks failed\n", len(failed), len(results))
+		return fmt.Errorf("validation failed: %s", strings.Join(failed, ", "))
+	}
+	fmt.Fprintf(w, "All %d checks passed (%d feeds)\n", len(results), len(feeds))
+	return nil
+}
+
+// checkFeedReachable sends feedURL a HEAD request, falling back to GET for
+// servers that don't allow HEAD, and fails on an error status.
+func checkFeedReachable(ctx context.Context, client *http.Client, feedURL, userAgent string) error {
+	status, err := requestStatus(ctx, client, http.MethodHead, feedURL, userAgent)
+	if err == nil && (status == http.StatusMethodNotAllowed || status == http.StatusNotImplemented) {
+		status, err = requestStatus(ctx, client, http.MethodGet, feedURL, userAgent)
+	}
+	if err != nil {
+		return err
+	}
+	if status >= 400 {
+		return fmt.Errorf("HTTP %d", status)
+	}
+	return nil
+}
+
+func requestStatus(ctx context.Context, client *http.Client, method, url, userAgent string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
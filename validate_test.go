@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"information-broker/config"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateRequested(t *testing.T) {
+	if validate, checkFeeds := validateRequested([]string{"--validate", "--check-feeds"}); !validate || !checkFeeds {
+		t.Errorf("validateRequested(--validate --check-feeds) = %v, %v", validate, checkFeeds)
+	}
+	if validate, _ := validateRequested([]string{"backfill", "example.com"}); validate {
+		t.Error("backfill shouldn't request validation")
+	}
+}
+
+func TestRunValidation(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/feed":
+		case "/no-head":
+			if r.Method == http.MethodHead {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+			}
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	feedsFile := filepath.Join(t.TempDir(), "feeds.txt")
+	feeds := srv.URL + "/feed\n" + srv.URL + "/no-head\n" + srv.URL + "/gone|priority=1\n"
+	if err := os.WriteFile(feedsFile, []byte(feeds), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	newConfig := func() *config.Config {
+		cfg := config.Load()
+		cfg.App.RSSFeedsFile = feedsFile
+		cfg.API = config.APIConfig{Timeout: 5 * time.Second, UserAgent: "Information-Broker/1.0"}
+		return cfg
+	}
+	openDB := func(*config.Config) (*sql.DB, error) {
+		db, _ := openExecRecorder(t)
+		return db, nil
+	}
+
+	t.Run("without feed checks", func(t *testing.T) {
+		var report strings.Builder
+		if err := runValidation(context.Background(), newConfig(), false, openDB, &report); err != nil {
+			t.Fatalf("runValidation = %v\n%s", err, report.String())
+		}
+		if !strings.Contains(report.String(), "All 4 checks passed (3 feeds)") {
+			t.Errorf("report:\n%s", report.String())
+		}
+	})
+
+	t.Run("with feed checks", func(t *testing.T) {
+		var report strings.Builder
+		err := runValidation(context.Background(), newConfig(), true, openDB, &report)
+		if err == nil || !strings.Contains(err.Error(), "feed "+srv.URL+"/gone") {
+			t.Fatalf("runValidation = %v, want the unreachable feed named\n%s", err, report.String())
+		}
+		for _, want := range []string{"PASS  feed " + srv.URL + "/feed ", "PASS  feed " + srv.URL + "/no-head ", "FAIL  feed " + srv.URL + "/gone (", "HTTP 404", "1 of 7 checks failed"} {
+			if !strings.Contains(report.String(), want) {
+				t.Errorf("report lacks %q:\n%s", want, report.String())
+			}
+		}
+	})
+
+	t.Run("broken setup", func(t *testing.T) {
+		cfg := newConfig()
+		cfg.Discord.OnArticleUpdate = "shout"
+		cfg.App.RSSFeedsFile = filepath.Join(t.TempDir(), "missing.txt")
+		failingDB := func(*config.Config) (*sql.DB, error) { return nil, errors.New("connection refused") }
+
+		var report strings.Builder
+		err := runValidation(context.Background(), cfg, true, failingDB, &report)
+		if err == nil || err.Error() != "validation failed: config, database, feeds file" {
+			t.Errorf("runValidation = %v\n%s", err, report.String())
+		}
+	})
+}